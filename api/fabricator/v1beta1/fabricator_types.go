@@ -5,20 +5,26 @@ package v1beta1
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"net/netip"
 	"reflect"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"dario.cat/mergo"
 	"github.com/go-playground/validator/v10"
 	fmeta "go.githedgehog.com/fabric/api/meta"
 	"go.githedgehog.com/fabricator/api/meta"
 	"go.githedgehog.com/libmeta/pkg/alloy"
+	"k8s.io/apimachinery/pkg/api/resource"
 	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
 )
 
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
@@ -60,12 +66,28 @@ type FabricatorStatus struct {
 
 	Components ComponentsStatus `json:"components,omitempty"`
 
+	// Expiration of the fab CA and certificates issued by it
+	Certificates CertificatesStatus `json:"certificates,omitempty"`
+
 	Release        string `json:"release,omitempty"`
 	ReleaseChannel string `json:"releaseChannel,omitempty"`
 
 	// TODO reserved VLANs, subnets, etc.
 }
 
+type CertificatesStatus struct {
+	// Expiration of the active fab CA and its chain, first one is used to issue certificates
+	FabCA []kmetav1.Time `json:"fabCA,omitempty"`
+	// Expiration of the new fab CA trusted while it's distributed before the rotation
+	NextFabCA *kmetav1.Time `json:"nextFabCA,omitempty"`
+	// Expiration of the previous fab CA trusted until it's retired after the rotation
+	PreviousFabCA *kmetav1.Time `json:"previousFabCA,omitempty"`
+	// Expiration of the additionally trusted CAs from the fab CA config
+	TrustedCA []kmetav1.Time `json:"trustedCA,omitempty"`
+	// Expiration of the certificates issued by the fab CA by name
+	Issued map[string]kmetav1.Time `json:"issued,omitempty"`
+}
+
 type ComponentStatus string
 
 const (
@@ -101,6 +123,7 @@ type ComponentsStatus struct {
 	ControlProxy         ComponentStatus            `json:"controlProxy,omitempty"`
 	ControlAlloy         ComponentStatus            `json:"controlAlloy,omitempty"`
 	GatewayAlloy         ComponentStatus            `json:"gatewayAlloy,omitempty"`
+	Monitoring           ComponentStatus            `json:"monitoring,omitempty"`
 	GatewayDataplane     map[string]ComponentStatus `json:"gatewayDataplane,omitempty"`
 	GatewayFRR           map[string]ComponentStatus `json:"gatewayFRR,omitempty"`
 }
@@ -122,6 +145,10 @@ func (c *ComponentsStatus) IsReady(cfg Fabricator, nodes []FabNode) bool {
 		c.ControlProxy == CompStatusReady &&
 		c.ControlAlloy == CompStatusReady
 
+	if cfg.Spec.Config.Observability.Stack.Enable {
+		res = res && c.Monitoring == CompStatusReady
+	}
+
 	if cfg.Spec.Config.Gateway.Enable {
 		res = res &&
 			c.FabricAPI == CompStatusReady && // GW API is part of the Fabric API now
@@ -227,7 +254,150 @@ type ControlConfig struct {
 
 	NTPServers []string `json:"ntpServers,omitempty"`
 
+	// Observability of the control nodes, unset fields are defaulted one by one based on the observability defaults,
+	// explicitly disabled ones are kept as is
 	Observability *ControlObservability `json:"observability,omitempty"`
+
+	// FabCA allows to use externally provided (e.g. enterprise PKI) CA instead of the self-generated one
+	FabCA *FabCAConfig `json:"fabCA,omitempty"`
+}
+
+type FabCAConfig struct {
+	// PEM encoded intermediate CA certificate optionally followed by its chain up to the root CA, it's used by
+	// the fab-ca issuer to sign all fabric certificates instead of the self-generated CA
+	Cert string `json:"cert,omitempty"`
+	// PEM encoded private key of the intermediate CA, it's only used as an installer input and moved to the keySecret
+	// during installation, use keySecret to switch to the external CA on a running fabric
+	Key string `json:"key,omitempty"`
+	// Name of the Secret in the fab namespace with the PEM encoded private key of the intermediate CA in tls.key
+	KeySecret string `json:"keySecret,omitempty"`
+	// Existing cert-manager issuer (e.g. Vault or ACME) to issue certificates managed by the fabricator (e.g. registry)
+	// instead of the fab-ca issuer, it should be created in the cluster during or before installation
+	Issuer *FabCAIssuerRef `json:"issuer,omitempty"`
+	// PEM encoded CA certificates to be trusted by nodes, switches and gateways in addition to the fab CA, it's
+	// required when issuer is used and should include the CA chain of the issuer
+	TrustedCerts string `json:"trustedCerts,omitempty"`
+}
+
+type FabCAIssuerRef struct {
+	Name string `json:"name,omitempty"`
+	// Issuer (in the fab namespace) or ClusterIssuer, Issuer by default
+	Kind string `json:"kind,omitempty"`
+}
+
+const (
+	FabCAIssuerKindIssuer        = "Issuer"
+	FabCAIssuerKindClusterIssuer = "ClusterIssuer"
+)
+
+// IsExternal returns true if fab CA key is provided and should be used instead of the self-generated one
+func (c *FabCAConfig) IsExternal() bool {
+	return c != nil && c.Cert != ""
+}
+
+func (c *FabCAConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.Key != "" && c.KeySecret != "" {
+		return fmt.Errorf("external fab CA key and key secret are mutually exclusive") //nolint:goerr113
+	}
+
+	if (c.Cert == "") != (c.Key == "" && c.KeySecret == "") {
+		return fmt.Errorf("both cert and key (or key secret) are required for external fab CA") //nolint:goerr113
+	}
+
+	if c.Cert != "" && c.KeySecret != "" {
+		crts, err := parseCertificates(c.Cert)
+		if err != nil {
+			return fmt.Errorf("parsing external fab CA cert: %w", err)
+		}
+
+		if err := validateFabCACert(crts[0]); err != nil {
+			return err
+		}
+	}
+
+	if c.Cert != "" && c.Key != "" {
+		pair, err := tls.X509KeyPair([]byte(c.Cert), []byte(c.Key))
+		if err != nil {
+			return fmt.Errorf("parsing external fab CA cert and key: %w", err)
+		}
+
+		ca, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("parsing external fab CA cert: %w", err)
+		}
+
+		if err := validateFabCACert(ca); err != nil {
+			return err
+		}
+	}
+
+	if c.Issuer != nil {
+		if c.Cert != "" {
+			return fmt.Errorf("external fab CA cert and issuer are mutually exclusive") //nolint:goerr113
+		}
+
+		if c.Issuer.Name == "" {
+			return fmt.Errorf("fab CA issuer name is required") //nolint:goerr113
+		}
+
+		if c.Issuer.Kind != "" && c.Issuer.Kind != FabCAIssuerKindIssuer && c.Issuer.Kind != FabCAIssuerKindClusterIssuer {
+			return fmt.Errorf("invalid fab CA issuer kind %q", c.Issuer.Kind) //nolint:goerr113
+		}
+
+		if c.TrustedCerts == "" {
+			return fmt.Errorf("trusted certs are required when fab CA issuer is used") //nolint:goerr113
+		}
+	}
+
+	if c.TrustedCerts != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(c.TrustedCerts)) {
+		return fmt.Errorf("parsing fab CA trusted certs") //nolint:goerr113
+	}
+
+	return nil
+}
+
+func validateFabCACert(ca *x509.Certificate) error {
+	if !ca.IsCA || !ca.BasicConstraintsValid || ca.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return fmt.Errorf("external fab CA cert is not a usable CA") //nolint:goerr113
+	}
+
+	if time.Now().After(ca.NotAfter) {
+		return fmt.Errorf("external fab CA cert is expired") //nolint:goerr113
+	}
+
+	return nil
+}
+
+func parseCertificates(data string) ([]*x509.Certificate, error) {
+	res := []*x509.Certificate{}
+
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		crt, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing certificate: %w", err)
+		}
+		res = append(res, crt)
+	}
+
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no certificates found") //nolint:goerr113
+	}
+
+	return res, nil
 }
 
 type ControlUser struct {
@@ -235,9 +405,31 @@ type ControlUser struct {
 	AuthorizedKeys []string `json:"authorizedKeys,omitempty"`
 }
 
+// ControlObservability uses pointers for the toggles so unset ones could be defaulted without overriding explicit false
 type ControlObservability struct {
-	KubePodLogs bool `json:"kubePodLogs,omitempty"`
-	KubeEvents  bool `json:"kubeEvents,omitempty"`
+	KubePodLogs    *bool                       `json:"kubePodLogs,omitempty"`
+	KubeEvents     *bool                       `json:"kubeEvents,omitempty"`
+	K3sLogs        *bool                       `json:"k3sLogs,omitempty"`
+	ContainerdLogs *bool                       `json:"containerdLogs,omitempty"`
+	Unix           ControlObservabilityUnix    `json:"unix,omitempty"`
+	APIServer      ControlObservabilityMetrics `json:"apiServer,omitempty"`
+	Etcd           ControlObservabilityMetrics `json:"etcd,omitempty"`
+	Registry       ControlObservabilityMetrics `json:"registry,omitempty"`
+	Fabric         ControlObservabilityMetrics `json:"fabric,omitempty"`
+	Fabricator     ControlObservabilityMetrics `json:"fabricator,omitempty"`
+}
+
+type ControlObservabilityMetrics struct {
+	Metrics         *bool                     `json:"metrics,omitempty"`
+	MetricsInterval uint                      `json:"metricsInterval,omitempty"`
+	MetricsRelabel  []alloy.ScrapeRelabelRule `json:"metricsRelabel,omitempty"`
+}
+
+type ControlObservabilityUnix struct {
+	Metrics           *bool                     `json:"metrics,omitempty"`
+	MetricsInterval   uint                      `json:"metricsInterval,omitempty"`
+	MetricsRelabel    []alloy.ScrapeRelabelRule `json:"metricsRelabel,omitempty"`
+	MetricsCollectors []string                  `json:"metricsCollectors,omitempty"`
 }
 
 type RegistryMode string
@@ -350,6 +542,23 @@ type ObservabilityConfig struct {
 	Defaults ObservabilityDefaults `json:"defaults,omitempty"`
 	Labels   map[string]string     `json:"labels,omitempty"`
 	Targets  alloy.Targets         `json:"targets,omitempty"`
+	// Stack is an optional on-prem Prometheus, Loki and Grafana automatically added as targets
+	Stack ObservabilityStack `json:"stack,omitempty"`
+}
+
+type ObservabilityStack struct {
+	Enable bool `json:"enable,omitempty"`
+	// Node is the name of the FabNode to run the stack on, control node is used if empty
+	Node string `json:"node,omitempty"`
+	// RetentionDays is how long metrics and logs are kept, 15 days by default
+	RetentionDays uint `json:"retentionDays,omitempty"`
+	// StorageSize is the size of the volumes for metrics and logs each, 20Gi by default
+	StorageSize string `json:"storageSize,omitempty"`
+	// Name of the Secret in the fab namespace with the Grafana admin password in admin-password, it enables
+	// Grafana admin login, otherwise login is disabled
+	GrafanaAdminSecret string `json:"grafanaAdminSecret,omitempty"`
+	// GrafanaAnonymous enables anonymous read-only access to Grafana exposed on the control VIP port 31300
+	GrafanaAnonymous bool `json:"grafanaAnonymous,omitempty"`
 }
 
 type ObservabilityDefaults string
@@ -390,6 +599,10 @@ type PlatformVersions struct {
 	ControlProxyChart meta.Version `json:"controlProxyChart,omitempty"`
 	BashCompletion    meta.Version `json:"bashCompletion,omitempty"`
 	HostBGPContainer  meta.Version `json:"hostBGPContainer,omitempty"`
+	Prometheus        meta.Version `json:"prometheus,omitempty"`
+	Loki              meta.Version `json:"loki,omitempty"`
+	Grafana           meta.Version `json:"grafana,omitempty"`
+	MonitoringChart   meta.Version `json:"monitoringChart,omitempty"`
 }
 
 type FabricatorVersions struct {
@@ -559,10 +772,41 @@ func (f *Fabricator) Default() {
 	o11yNotNone := f.Spec.Config.Observability.Defaults != ObservabilityDefaultsNone
 	o11yMinimal := f.Spec.Config.Observability.Defaults == ObservabilityDefaultsMinimal
 
-	if f.Spec.Config.Control.Observability == nil {
-		f.Spec.Config.Control.Observability = &ControlObservability{
-			KubePodLogs: o11yNotNone,
-			KubeEvents:  o11yNotNone,
+	{
+		if f.Spec.Config.Control.Observability == nil {
+			f.Spec.Config.Control.Observability = &ControlObservability{}
+		}
+		o11y := f.Spec.Config.Control.Observability
+
+		// only unset fields are defaulted, so explicit false is kept
+		for _, enable := range []**bool{&o11y.KubePodLogs, &o11y.KubeEvents, &o11y.K3sLogs, &o11y.ContainerdLogs, &o11y.Unix.Metrics} {
+			if *enable == nil {
+				*enable = ptr.To(o11yNotNone)
+			}
+		}
+		if o11y.Unix.MetricsInterval == 0 {
+			o11y.Unix.MetricsInterval = 60
+		}
+		if o11y.Unix.MetricsCollectors == nil {
+			o11y.Unix.MetricsCollectors = []string{"cpu", "loadavg", "meminfo", "filesystem"}
+		}
+		if o11y.Unix.MetricsRelabel == nil && o11yMinimal {
+			o11y.Unix.MetricsRelabel = []alloy.ScrapeRelabelRule{
+				{
+					Action:       "keep",
+					Regex:        ".*(_load).*",
+					SourceLabels: []string{"__name__"},
+				},
+			}
+		}
+
+		for _, metrics := range []*ControlObservabilityMetrics{&o11y.APIServer, &o11y.Etcd, &o11y.Registry, &o11y.Fabric, &o11y.Fabricator} {
+			if metrics.Metrics == nil {
+				metrics.Metrics = ptr.To(o11yNotNone)
+			}
+			if metrics.MetricsInterval == 0 {
+				metrics.MetricsInterval = 60
+			}
 		}
 	}
 
@@ -674,6 +918,15 @@ func (f *Fabricator) Default() {
 		f.Spec.Config.Observability.Targets.Pyroscope = map[string]alloy.PyroscopeTarget{}
 	}
 
+	if f.Spec.Config.Observability.Stack.Enable {
+		if f.Spec.Config.Observability.Stack.RetentionDays == 0 {
+			f.Spec.Config.Observability.Stack.RetentionDays = 15
+		}
+		if f.Spec.Config.Observability.Stack.StorageSize == "" {
+			f.Spec.Config.Observability.Stack.StorageSize = "20Gi"
+		}
+	}
+
 	f.Spec.Config.Fabric.DefaultAlloyConfig = fmeta.AlloyConfig{}
 
 	for name, target := range f.Spec.Config.Observability.Targets.Prometheus {
@@ -817,6 +1070,10 @@ func (f *Fabricator) Validate(ctx context.Context) error {
 		return fmt.Errorf("parsing dummy subnet: %w", err)
 	}
 
+	if err := f.Spec.Config.Control.FabCA.Validate(); err != nil {
+		return fmt.Errorf("validating fab CA: %w", err)
+	}
+
 	{
 		ph := f.Spec.Config.Control.DefaultUser.PasswordHash
 		if ph == "" {
@@ -978,6 +1235,12 @@ func (f *Fabricator) Validate(ctx context.Context) error {
 		return fmt.Errorf("invalid observability defaults mode: %s", f.Spec.Config.Observability.Defaults) //nolint:err113
 	}
 
+	if f.Spec.Config.Observability.Stack.Enable {
+		if _, err := resource.ParseQuantity(f.Spec.Config.Observability.Stack.StorageSize); err != nil {
+			return fmt.Errorf("invalid observability stack storage size %q: %w", f.Spec.Config.Observability.Stack.StorageSize, err)
+		}
+	}
+
 	for prioStr, commStr := range f.Spec.Config.Gateway.Communities {
 		if _, err := strconv.ParseUint(prioStr, 10, 32); err != nil {
 			return fmt.Errorf("config: gatewayCommunity priority %s is invalid: %w", prioStr, err)
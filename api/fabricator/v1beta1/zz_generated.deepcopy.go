@@ -15,6 +15,50 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificatesStatus) DeepCopyInto(out *CertificatesStatus) {
+	*out = *in
+	if in.FabCA != nil {
+		in, out := &in.FabCA, &out.FabCA
+		*out = make([]v1.Time, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextFabCA != nil {
+		in, out := &in.NextFabCA, &out.NextFabCA
+		*out = (*in).DeepCopy()
+	}
+	if in.PreviousFabCA != nil {
+		in, out := &in.PreviousFabCA, &out.PreviousFabCA
+		*out = (*in).DeepCopy()
+	}
+	if in.TrustedCA != nil {
+		in, out := &in.TrustedCA, &out.TrustedCA
+		*out = make([]v1.Time, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Issued != nil {
+		in, out := &in.Issued, &out.Issued
+		*out = make(map[string]v1.Time, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificatesStatus.
+func (in *CertificatesStatus) DeepCopy() *CertificatesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificatesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComponentsStatus) DeepCopyInto(out *ComponentsStatus) {
 	*out = *in
@@ -68,7 +112,12 @@ func (in *ControlConfig) DeepCopyInto(out *ControlConfig) {
 	if in.Observability != nil {
 		in, out := &in.Observability, &out.Observability
 		*out = new(ControlObservability)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FabCA != nil {
+		in, out := &in.FabCA, &out.FabCA
+		*out = new(FabCAConfig)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -258,6 +307,32 @@ func (in *ControlNodeStatus) DeepCopy() *ControlNodeStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ControlObservability) DeepCopyInto(out *ControlObservability) {
 	*out = *in
+	if in.KubePodLogs != nil {
+		in, out := &in.KubePodLogs, &out.KubePodLogs
+		*out = new(bool)
+		**out = **in
+	}
+	if in.KubeEvents != nil {
+		in, out := &in.KubeEvents, &out.KubeEvents
+		*out = new(bool)
+		**out = **in
+	}
+	if in.K3sLogs != nil {
+		in, out := &in.K3sLogs, &out.K3sLogs
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ContainerdLogs != nil {
+		in, out := &in.ContainerdLogs, &out.ContainerdLogs
+		*out = new(bool)
+		**out = **in
+	}
+	in.Unix.DeepCopyInto(&out.Unix)
+	in.APIServer.DeepCopyInto(&out.APIServer)
+	in.Etcd.DeepCopyInto(&out.Etcd)
+	in.Registry.DeepCopyInto(&out.Registry)
+	in.Fabric.DeepCopyInto(&out.Fabric)
+	in.Fabricator.DeepCopyInto(&out.Fabricator)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlObservability.
@@ -270,6 +345,65 @@ func (in *ControlObservability) DeepCopy() *ControlObservability {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControlObservabilityMetrics) DeepCopyInto(out *ControlObservabilityMetrics) {
+	*out = *in
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MetricsRelabel != nil {
+		in, out := &in.MetricsRelabel, &out.MetricsRelabel
+		*out = make([]alloy.ScrapeRelabelRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlObservabilityMetrics.
+func (in *ControlObservabilityMetrics) DeepCopy() *ControlObservabilityMetrics {
+	if in == nil {
+		return nil
+	}
+	out := new(ControlObservabilityMetrics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControlObservabilityUnix) DeepCopyInto(out *ControlObservabilityUnix) {
+	*out = *in
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MetricsRelabel != nil {
+		in, out := &in.MetricsRelabel, &out.MetricsRelabel
+		*out = make([]alloy.ScrapeRelabelRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MetricsCollectors != nil {
+		in, out := &in.MetricsCollectors, &out.MetricsCollectors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlObservabilityUnix.
+func (in *ControlObservabilityUnix) DeepCopy() *ControlObservabilityUnix {
+	if in == nil {
+		return nil
+	}
+	out := new(ControlObservabilityUnix)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ControlUser) DeepCopyInto(out *ControlUser) {
 	*out = *in
@@ -290,6 +424,41 @@ func (in *ControlUser) DeepCopy() *ControlUser {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FabCAConfig) DeepCopyInto(out *FabCAConfig) {
+	*out = *in
+	if in.Issuer != nil {
+		in, out := &in.Issuer, &out.Issuer
+		*out = new(FabCAIssuerRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabCAConfig.
+func (in *FabCAConfig) DeepCopy() *FabCAConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(FabCAConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FabCAIssuerRef) DeepCopyInto(out *FabCAIssuerRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabCAIssuerRef.
+func (in *FabCAIssuerRef) DeepCopy() *FabCAIssuerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(FabCAIssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FabConfig) DeepCopyInto(out *FabConfig) {
 	*out = *in
@@ -586,6 +755,7 @@ func (in *FabricatorStatus) DeepCopyInto(out *FabricatorStatus) {
 		}
 	}
 	in.Components.DeepCopyInto(&out.Components)
+	in.Certificates.DeepCopyInto(&out.Certificates)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricatorStatus.
@@ -755,6 +925,7 @@ func (in *ObservabilityConfig) DeepCopyInto(out *ObservabilityConfig) {
 		}
 	}
 	in.Targets.DeepCopyInto(&out.Targets)
+	out.Stack = in.Stack
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObservabilityConfig.
@@ -767,6 +938,21 @@ func (in *ObservabilityConfig) DeepCopy() *ObservabilityConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObservabilityStack) DeepCopyInto(out *ObservabilityStack) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObservabilityStack.
+func (in *ObservabilityStack) DeepCopy() *ObservabilityStack {
+	if in == nil {
+		return nil
+	}
+	out := new(ObservabilityStack)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PlatformVersions) DeepCopyInto(out *PlatformVersions) {
 	*out = *in
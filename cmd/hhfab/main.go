@@ -27,7 +27,9 @@ import (
 	"go.githedgehog.com/fabricator/pkg/fab"
 	"go.githedgehog.com/fabricator/pkg/fab/recipe"
 	"go.githedgehog.com/fabricator/pkg/hhfab"
+	"go.githedgehog.com/fabricator/pkg/hhfab/cabling"
 	"go.githedgehog.com/fabricator/pkg/hhfab/diagram"
+	"go.githedgehog.com/fabricator/pkg/hhfab/netbox"
 	"go.githedgehog.com/fabricator/pkg/hhfab/pdu"
 	"go.githedgehog.com/fabricator/pkg/version"
 	"golang.org/x/term"
@@ -67,6 +69,8 @@ const (
 	FlagNameKillStale             = "kill-stale"
 	FlagNameControlsRestricted    = "controls-restricted"
 	FlagNameServersRestricted     = "servers-restricted"
+	FlagNameServerMode            = "server-mode"
+	FlagNameSkipPreflight         = "skip-preflight"
 	FlagNameReCreate              = "recreate"
 	FlagNameBuildMode             = "build-mode"
 	FlagNameBuildControls         = "build-controls"
@@ -92,7 +96,13 @@ const (
 	FlagShowTech                  = "show-tech"
 	FlagIPerfsSpeed               = "iperfs-speed"
 	FlagReleaseTestOnReadyOnly    = "release-test-on-ready-only"
+	FlagReleaseTestShard          = "release-test-shard"
 	FlagOnReadyOnly               = "on-ready-only"
+	FlagTestFile                  = "test-file"
+	FlagShard                     = "shard"
+	FlagRunID                     = "run-id"
+	FlagHistoryDir                = "history-dir"
+	FlagWhatIf                    = "what-if"
 )
 
 func main() {
@@ -431,6 +441,13 @@ func Run(ctx context.Context) error {
 		briefFlag,
 	}
 
+	reachabilityFlags := []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  FlagWhatIf,
+			Usage: "YAML `FILE` with objects (e.g. peerings) to add or replace before evaluation (can be repeated)",
+		},
+	}
+
 	onReadyCommands := []string{}
 	for _, cmd := range hhfab.AllOnReady {
 		onReadyCommands = append(onReadyCommands, string(cmd))
@@ -441,6 +458,11 @@ func Run(ctx context.Context) error {
 		buildModes = append(buildModes, string(m))
 	}
 
+	serverModes := []string{}
+	for _, m := range hhfab.VLABServerModes {
+		serverModes = append(serverModes, string(m))
+	}
+
 	reinstallModes := []string{}
 	for _, m := range hhfab.ReinstallModes {
 		reinstallModes = append(reinstallModes, string(m))
@@ -454,12 +476,12 @@ func Run(ctx context.Context) error {
 	pduFlags := []cli.Flag{
 		&cli.StringFlag{
 			Name:    "pdu-username",
-			Usage:   "PDU username to attempt a reboot (" + string(hhfab.ReinstallModeHardReset) + " mode only)",
+			Usage:   "PDU or BMC username to attempt a reboot (" + string(hhfab.ReinstallModeHardReset) + " mode only)",
 			EnvVars: []string{hhfab.VLABEnvPDUUsername},
 		},
 		&cli.StringFlag{
 			Name:    "pdu-password",
-			Usage:   "PDU password to attempt a reboot (" + string(hhfab.ReinstallModeHardReset) + " mode only)",
+			Usage:   "PDU or BMC password (SNMP community for SNMP PDUs) to attempt a reboot (" + string(hhfab.ReinstallModeHardReset) + " mode only)",
 			EnvVars: []string{hhfab.VLABEnvPDUPassword},
 		},
 	}
@@ -492,6 +514,94 @@ func Run(ctx context.Context) error {
 		},
 	}
 
+	vmSizesFlags := []cli.Flag{
+		&cli.UintFlag{
+			Category: FlagCatVMSizes,
+			Name:     "control-cpus",
+			Usage:    fmt.Sprintf("override control node VM number of CPUs (if not set: %d)", hhfab.DefaultSizes.Control.CPU),
+			EnvVars:  []string{"HHFAB_VLAB_CTRL_CPUS"},
+		},
+		&cli.UintFlag{
+			Category: FlagCatVMSizes,
+			Name:     "control-ram",
+			Usage:    fmt.Sprintf("override control node VM RAM (in MB) (if not set: %d)", hhfab.DefaultSizes.Control.RAM),
+			EnvVars:  []string{"HHFAB_VLAB_CTRL_RAM"},
+		},
+		&cli.UintFlag{
+			Category: FlagCatVMSizes,
+			Name:     "control-disk",
+			Usage:    fmt.Sprintf("override control node VM disk size (in GB) (if not set: %d)", hhfab.DefaultSizes.Control.Disk),
+			EnvVars:  []string{"HHFAB_VLAB_CTRL_DISK"},
+		},
+		&cli.UintFlag{
+			Category: FlagCatVMSizes,
+			Name:     "gateway-cpus",
+			Usage:    fmt.Sprintf("override gateway node VM number of CPUs (if not set: %d)", hhfab.DefaultSizes.Gateway.CPU),
+			EnvVars:  []string{"HHFAB_VLAB_GW_CPUS"},
+		},
+		&cli.UintFlag{
+			Category: FlagCatVMSizes,
+			Name:     "gateway-ram",
+			Usage:    fmt.Sprintf("override gateway node VM RAM (in MB) (if not set: %d)", hhfab.DefaultSizes.Gateway.RAM),
+			EnvVars:  []string{"HHFAB_VLAB_GW_RAM"},
+		},
+		&cli.UintFlag{
+			Category: FlagCatVMSizes,
+			Name:     "gateway-disk",
+			Usage:    fmt.Sprintf("override gateway node VM disk size (in GB) (if not set: %d)", hhfab.DefaultSizes.Gateway.Disk),
+			EnvVars:  []string{"HHFAB_VLAB_GW_DISK"},
+		},
+		&cli.UintFlag{
+			Category: FlagCatVMSizes,
+			Name:     "server-cpus",
+			Usage:    fmt.Sprintf("override server VM number of CPUs (if not set: %d)", hhfab.DefaultSizes.Server.CPU),
+			EnvVars:  []string{"HHFAB_VLAB_SRV_CPUS"},
+		},
+		&cli.UintFlag{
+			Category: FlagCatVMSizes,
+			Name:     "server-ram",
+			Usage:    fmt.Sprintf("override server VM RAM (in MB) (if not set: %d)", hhfab.DefaultSizes.Server.RAM),
+			EnvVars:  []string{"HHFAB_VLAB_SRV_RAM"},
+		},
+		&cli.UintFlag{
+			Category: FlagCatVMSizes,
+			Name:     "server-disk",
+			Usage:    fmt.Sprintf("override server VM disk size (in GB) (if not set: %d)", hhfab.DefaultSizes.Server.Disk),
+			EnvVars:  []string{"HHFAB_VLAB_SRV_DISK"},
+		},
+		&cli.StringFlag{
+			Name:    FlagNameServerMode,
+			Usage:   "run servers as full VMs or as lightweight network namespaces on the host (applied on VLAB creation, defaults to vm, netns requires " + strings.Join(hhfab.VLABNetnsCmds, ", ") + " on the host): one of " + strings.Join(serverModes, ", "),
+			EnvVars: []string{"HHFAB_VLAB_SERVER_MODE"},
+			Action: func(_ *cli.Context, v string) error {
+				if !slices.Contains(hhfab.VLABServerModes, hhfab.VLABServerMode(v)) {
+					return fmt.Errorf("invalid server mode %q", v) //nolint:goerr113
+				}
+
+				return nil
+			},
+		},
+	}
+	vmSizesOverrides := func(c *cli.Context) hhfab.VMSizes {
+		return hhfab.VMSizes{
+			Control: hhfab.VMSize{
+				CPU:  c.Uint("control-cpus"),
+				RAM:  c.Uint("control-ram"),
+				Disk: c.Uint("control-disk"),
+			},
+			Gateway: hhfab.VMSize{
+				CPU:  c.Uint("gateway-cpus"),
+				RAM:  c.Uint("gateway-ram"),
+				Disk: c.Uint("gateway-disk"),
+			},
+			Server: hhfab.VMSize{
+				CPU:  c.Uint("server-cpus"),
+				RAM:  c.Uint("server-ram"),
+				Disk: c.Uint("server-disk"),
+			},
+		}
+	}
+
 	var joinToken string
 	joinTokenFlags := []cli.Flag{
 		&cli.StringFlag{
@@ -786,6 +896,9 @@ func Run(ctx context.Context) error {
 			                      - View online: https://mermaid.live
 			                      - Or use a Markdown editor with Mermaid support
 
+			   svg, png        - Renders an image directly without any external tools, e.g. for CI artifacts
+			                      or air-gapped environments
+
 			EXAMPLES:
 			   # Generate default draw.io diagram
 			   hhfab diagram
@@ -794,7 +907,10 @@ func Run(ctx context.Context) error {
 			   hhfab diagram --format dot
 
 			   # Generate draw.io diagram with custom style
-			   hhfab diagram --format drawio --style hedgehog`),
+			   hhfab diagram --format drawio --style hedgehog
+
+			   # Render PNG image directly
+			   hhfab diagram --format png`),
 				Flags: flatten(defaultFlags, []cli.Flag{
 					&cli.StringFlag{
 						Name:    "format",
@@ -806,7 +922,7 @@ func Run(ctx context.Context) error {
 					&cli.StringFlag{
 						Name:    "style",
 						Aliases: []string{"s"},
-						Usage: "diagram style (only applies to drawio format): " + strings.Join(lo.Map(diagram.StyleTypes,
+						Usage: "diagram style (only applies to drawio, svg and png formats): " + strings.Join(lo.Map(diagram.StyleTypes,
 							func(item diagram.StyleType, _ int) string { return string(item) }), ", "),
 						Value: string(diagram.StyleTypeDefault),
 					},
@@ -831,6 +947,332 @@ func Run(ctx context.Context) error {
 					return nil
 				},
 			},
+			{
+				Name:  "reachability",
+				Usage: "explain expected reachability between servers and externals offline (no VLAB needed)",
+				UsageText: strings.TrimSpace(`
+			Evaluate the expected reachability from the config (fab.yaml and include/*) without any running VLAB using the
+			same checks as the connectivity tests: VPC subnet isolation, VPC and external peerings on the switches and
+			gateway peerings with their address translation. Pairs the checks can't model (e.g. gateway NAT) are reported
+			as "unknown" with the translation shown.
+
+			Use --what-if to add or replace objects (e.g. a new peering) before the evaluation without changing include/*.
+
+			EXAMPLES:
+			   hhfab reachability explain --from server-01 --to server-07
+			   hhfab reachability explain --from server-01 --to default --what-if new-peering.yaml
+			   hhfab reachability matrix --format html`),
+				Subcommands: []*cli.Command{
+					{
+						Name:  "explain",
+						Usage: "print verdict, reason chain and translation for all attachments of a pair",
+						Flags: flatten(defaultFlags, reachabilityFlags, []cli.Flag{
+							&cli.StringFlag{
+								Name:     "from",
+								Usage:    "source server name",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:     "to",
+								Usage:    "destination server or external name",
+								Required: true,
+							},
+						}),
+						Before: before(false),
+						Action: func(c *cli.Context) error {
+							if err := hhfab.ReachabilityExplain(ctx, workDir, cacheDir, hhfab.ReachabilityExplainOpts{
+								ReachabilityOpts: hhfab.ReachabilityOpts{WhatIf: c.StringSlice(FlagWhatIf)},
+								From:             c.String("from"),
+								To:               c.String("to"),
+							}); err != nil {
+								return fmt.Errorf("explaining reachability: %w", err)
+							}
+
+							return nil
+						},
+					},
+					{
+						Name:  "matrix",
+						Usage: "export expected reachability for all server attachments and externals",
+						Flags: flatten(defaultFlags, reachabilityFlags, []cli.Flag{
+							&cli.StringFlag{
+								Name:    "format",
+								Aliases: []string{"f"},
+								Usage: "output format: " + strings.Join(lo.Map(hhfab.ReachabilityFormats,
+									func(item hhfab.ReachabilityFormat, _ int) string { return string(item) }), ", "),
+								Value: string(hhfab.ReachabilityFormatCSV),
+							},
+							&cli.StringFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "output file path (default: result/" + hhfab.ReachabilityFilename + ".{format})",
+							},
+						}),
+						Before: before(false),
+						Action: func(c *cli.Context) error {
+							if err := hhfab.ReachabilityMatrix(ctx, workDir, cacheDir, hhfab.ReachabilityMatrixOpts{
+								ReachabilityOpts: hhfab.ReachabilityOpts{WhatIf: c.StringSlice(FlagWhatIf)},
+								Format:           hhfab.ReachabilityFormat(strings.ToLower(c.String("format"))),
+								Output:           c.String("output"),
+							}); err != nil {
+								return fmt.Errorf("exporting reachability matrix: %w", err)
+							}
+
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:  "wiring",
+				Usage: "wiring related tools",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "cabling",
+						Usage: "generate cabling plan and bill of materials from the wiring",
+						UsageText: strings.TrimSpace(`
+			Generate a per-cable run sheet (rack, device, port and NOS port on both sides, breakout, speed and connection type)
+			together with a bill of materials counting cables and optics by speed and type.
+
+			Rack is taken from the "` + cabling.RackAnnotation + `" annotation on switches, servers and gateway nodes.
+
+			FORMATS:
+			   csv (default) - cabling.csv and cabling-bom.csv
+			   xlsx          - single workbook with "Cables" and "BOM" sheets
+			   md            - single Markdown file with both tables`),
+						Flags: flatten(defaultFlags, []cli.Flag{
+							&cli.StringFlag{
+								Name:    "format",
+								Aliases: []string{"f"},
+								Usage: "output format: " + strings.Join(lo.Map(cabling.Formats,
+									func(item cabling.Format, _ int) string { return string(item) }), ", "),
+								Value: string(cabling.FormatCSV),
+							},
+							&cli.StringFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "output file path (default: result/cabling.{format})",
+							},
+						}),
+						Before: before(false),
+						Action: func(c *cli.Context) error {
+							format := cabling.Format(strings.ToLower(c.String("format")))
+							if err := hhfab.WiringCabling(ctx, workDir, cacheDir, format, c.String("output")); err != nil {
+								return fmt.Errorf("generating cabling plan: %w", err)
+							}
+
+							return nil
+						},
+					},
+					{
+						Name:      "diff",
+						Usage:     "compare two wirings and report changes and impacted switches",
+						ArgsUsage: "<old> <new>",
+						UsageText: strings.TrimSpace(`
+			Compare two wirings (YAML file, directory with YAML files or hhfab working dir with include/ subdir) and
+			report added, removed and changed switches, servers, connections, VPCs, externals and related objects
+			together with the switches that would need agent config update.
+
+			EXAMPLES:
+			   hhfab wiring diff old/ new/
+			   hhfab wiring diff --diagram drawio old/wiring.yaml new/wiring.yaml`),
+						Flags: flatten(defaultFlags, []cli.Flag{
+							&cli.StringFlag{
+								Name:  "diagram",
+								Usage: "also render diagram with changes highlighted: drawio, mermaid",
+							},
+							&cli.StringFlag{
+								Name:    "style",
+								Aliases: []string{"s"},
+								Usage: "diagram style (only applies to drawio format): " + strings.Join(lo.Map(diagram.StyleTypes,
+									func(item diagram.StyleType, _ int) string { return string(item) }), ", "),
+								Value: string(diagram.StyleTypeDefault),
+							},
+							&cli.StringFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "output file path for the diagram (default: result/diff-diagram.{format})",
+							},
+						}),
+						Before: before(false),
+						Action: func(c *cli.Context) error {
+							if c.NArg() != 2 {
+								return fmt.Errorf("expected old and new wiring as arguments") //nolint:goerr113
+							}
+
+							format := diagram.Format(strings.ToLower(c.String("diagram")))
+							styleType := diagram.StyleType(c.String("style"))
+							if err := hhfab.WiringDiff(ctx, workDir, c.Args().Get(0), c.Args().Get(1), format, styleType, c.String("output")); err != nil {
+								return fmt.Errorf("comparing wiring: %w", err)
+							}
+
+							return nil
+						},
+					},
+					{
+						Name:  "netbox",
+						Usage: "export wiring to or import it from NetBox bulk import data",
+						Subcommands: []*cli.Command{
+							{
+								Name:  "export",
+								Usage: "export switches, servers and connections as NetBox devices, interfaces and cables",
+								UsageText: strings.TrimSpace(`
+			Export the wiring as NetBox bulk import data: devices (switches and servers), interfaces and cables.
+			Switch groups are exported as device tags, redundancy as "` + netbox.FieldRedundancyGroup + `" and
+			"` + netbox.FieldRedundancyType + `" custom fields and connection names as cable labels. Works offline.
+
+			FORMATS:
+			   json (default) - single netbox.json file with all lists
+			   csv            - netbox/ directory with devices.csv, interfaces.csv and cables.csv`),
+								Flags: flatten(defaultFlags, []cli.Flag{
+									&cli.StringFlag{
+										Name:    "format",
+										Aliases: []string{"f"},
+										Usage: "output format: " + strings.Join(lo.Map(netbox.Formats,
+											func(item netbox.Format, _ int) string { return string(item) }), ", "),
+										Value: string(netbox.FormatJSON),
+									},
+									&cli.StringFlag{
+										Name:  "site",
+										Usage: "NetBox site to assign devices to",
+										Value: netbox.DefaultSite,
+									},
+									&cli.StringFlag{
+										Name:    "output",
+										Aliases: []string{"o"},
+										Usage:   "output file (json) or directory (csv) path (default: result/netbox[.json])",
+									},
+								}),
+								Before: before(false),
+								Action: func(c *cli.Context) error {
+									format := netbox.Format(strings.ToLower(c.String("format")))
+									if err := hhfab.WiringNetBoxExport(ctx, workDir, cacheDir, format, c.String("site"), c.String("output")); err != nil {
+										return fmt.Errorf("exporting to netbox: %w", err)
+									}
+
+									return nil
+								},
+							},
+							{
+								Name:      "import",
+								Usage:     "generate wiring from NetBox bulk import data",
+								ArgsUsage: "<netbox.json|netbox-csv-dir>",
+								UsageText: strings.TrimSpace(`
+			Generate switches, switch groups, servers and connections from NetBox data in the same format as produced by
+			export (JSON file or directory with CSV files). Devices with switch roles (spine, server-leaf, border-leaf,
+			mixed-leaf or leaf) become switches with device type mapped to a switch profile using --profile flag (or used
+			as is if not mapped), devices with "server" role become servers and devices with "gateway" role are only used
+			for the gateway connections. Cables are grouped into connections by label, connection type is taken from the
+			label (e.g. server-01--eslag--leaf-01--leaf-02) or derived from the device roles. External connections
+			aren't imported as their remote side isn't part of the NetBox data. Works offline.
+
+			Resulting file could be used with "hhfab init --wiring".`),
+								Flags: flatten(defaultFlags, []cli.Flag{
+									&cli.StringFlag{
+										Name:    "output",
+										Aliases: []string{"o"},
+										Usage:   "output wiring file path",
+										Value:   "netbox.yaml",
+									},
+									&cli.StringSliceFlag{
+										Name:  "profile",
+										Usage: "map NetBox device type to the switch profile (device-type=profile)",
+									},
+									&cli.StringSliceFlag{
+										Name:  "vlan-range",
+										Usage: "VLAN range for the default VLAN namespace (from-to)",
+										Value: cli.NewStringSlice(fmt.Sprintf("%d-%d", netbox.DefaultVLANRange.From, netbox.DefaultVLANRange.To)),
+									},
+									&cli.StringSliceFlag{
+										Name:  "ipv4-subnet",
+										Usage: "IPv4 subnet for the default IPv4 namespace",
+										Value: cli.NewStringSlice(netbox.DefaultIPv4Subnet),
+									},
+								}),
+								Before: before(false),
+								Action: func(c *cli.Context) error {
+									if c.NArg() != 1 {
+										return fmt.Errorf("expected NetBox data file or directory as an argument") //nolint:goerr113
+									}
+
+									opts := netbox.ImportOpts{
+										Profiles:    map[string]string{},
+										IPv4Subnets: c.StringSlice("ipv4-subnet"),
+									}
+									for _, entry := range c.StringSlice("profile") {
+										parts := strings.SplitN(entry, "=", 2)
+										if len(parts) != 2 {
+											return fmt.Errorf("invalid device type to profile mapping: %s", entry) //nolint:err113
+										}
+										opts.Profiles[parts[0]] = parts[1]
+									}
+									for _, entry := range c.StringSlice("vlan-range") {
+										vlanRange, err := netbox.ParseVLANRange(entry)
+										if err != nil {
+											return fmt.Errorf("parsing vlan range: %w", err)
+										}
+										opts.VLANRanges = append(opts.VLANRanges, vlanRange)
+									}
+
+									if err := hhfab.WiringNetBoxImport(ctx, c.Args().First(), c.String("output"), opts); err != nil {
+										return fmt.Errorf("importing from netbox: %w", err)
+									}
+
+									return nil
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:  "inspect",
+				Usage: "inspect running fabric",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "cabling",
+						Usage: "validate cabling against LLDP neighbors reported by the switch agents",
+						UsageText: strings.TrimSpace(`
+			Compare every wiring connection against the LLDP neighbors reported by the switch agents and report
+			miswired, missing and unexpected links together with suggested fixes. External links are skipped.
+
+			Uses VLAB kubeconfig from the working dir by default, use --kubeconfig for the real hardware.`),
+						Flags: flatten(defaultFlags, []cli.Flag{
+							&cli.StringFlag{
+								Name:  "kubeconfig",
+								Usage: "kubeconfig to use (default: VLAB kubeconfig)",
+							},
+							&cli.StringSliceFlag{
+								Name:    "switch",
+								Aliases: []string{"s"},
+								Usage:   "only validate specified switches, can be repeated",
+							},
+							&cli.BoolFlag{
+								Name:  "server",
+								Usage: "validate server links (servers should run LLDP)",
+								Value: true,
+							},
+							&cli.BoolFlag{
+								Name:  "strict",
+								Usage: "fail if any mismatch found",
+							},
+						}),
+						Before: before(false),
+						Action: func(c *cli.Context) error {
+							if err := hhfab.InspectCabling(ctx, workDir, hhfab.InspectCablingOpts{
+								Kubeconfig: c.String("kubeconfig"),
+								Switches:   c.StringSlice("switch"),
+								Server:     c.Bool("server"),
+								Strict:     c.Bool("strict"),
+							}); err != nil {
+								return fmt.Errorf("inspecting cabling: %w", err)
+							}
+
+							return nil
+						},
+					},
+				},
+			},
 			{
 				Name:   "versions",
 				Usage:  "print versions of all components",
@@ -910,8 +1352,38 @@ func Run(ctx context.Context) error {
 						Name:    "generate",
 						Aliases: []string{"gen"},
 						Usage:   "generate VLAB wiring diagram",
-						Flags:   flatten(defaultFlags, vlabWiringGenFlags, []cli.Flag{yesFlag}),
-						Before:  before(false),
+						UsageText: strings.TrimSpace(`
+			Generate VLAB wiring diagram either from the flags or from the topology spec file (--spec). Spec allows to
+			describe each switch group and leaf individually (server mix, profile, fabric links and external connections),
+			topology flags are ignored if spec is used while profile flags are still used as defaults.
+
+			SPEC EXAMPLE:
+			   spines:
+			     count: 2
+			     fabricLinks: 1
+			   switchGroups:
+			     - eslagServers: 2
+			       leaves:
+			         - servers: {unbundled: 1}
+			         - servers: {bundled: 1}
+			   leaves:
+			     - fabricLinks: 2
+			       servers: {unbundled: 3}
+			       externals: 1
+			     - profile: vs
+			   multiHomedServers: 1
+			   gateway:
+			     uplinks: 2
+			   externals:
+			     bgp: 1`),
+						Flags: flatten(defaultFlags, vlabWiringGenFlags, []cli.Flag{
+							yesFlag,
+							&cli.StringFlag{
+								Name:  "spec",
+								Usage: "generate from the topology spec file instead of the flags",
+							},
+						}),
+						Before: before(false),
 						Action: func(c *cli.Context) error {
 							overrides := map[string]string{}
 							if wgSwitchProfileOverrides != nil {
@@ -923,6 +1395,30 @@ func Run(ctx context.Context) error {
 									overrides[parts[0]] = parts[1]
 								}
 							}
+
+							if specFile := c.String("spec"); specFile != "" {
+								spec, err := hhfab.LoadVLABSpec(specFile)
+								if err != nil {
+									return fmt.Errorf("loading VLAB spec: %w", err)
+								}
+
+								builder := &hhfab.VLABBuilderSpec{
+									Spec:    *spec,
+									YesFlag: yes,
+									VLABBuilderBase: hhfab.VLABBuilderBase{
+										DefaultSwitchProfile:   wgDefaultSwitchProfile,
+										SwitchProfileOverrides: overrides,
+										ServerPortBase:         wgServerPortBase,
+									},
+								}
+
+								if err := hhfab.VLABGenerate(ctx, workDir, cacheDir, builder, hhfab.DefaultVLABGeneratedFile); err != nil {
+									return fmt.Errorf("generating VLAB wiring diagram: %w", err)
+								}
+
+								return nil
+							}
+
 							gwTags := map[string]string{}
 							for _, entry := range c.StringSlice(FlagGatewayTag) {
 								parts := strings.SplitN(entry, "=", 2)
@@ -1063,7 +1559,7 @@ func Run(ctx context.Context) error {
 					{
 						Name:  "up",
 						Usage: "run VLAB",
-						Flags: flatten(defaultFlags, hModeFlags, builFlags, joinTokenFlags, []cli.Flag{
+						Flags: flatten(defaultFlags, hModeFlags, builFlags, joinTokenFlags, vmSizesFlags, []cli.Flag{
 							&cli.BoolFlag{
 								Name:    FlagNameReCreate,
 								Aliases: []string{"f"},
@@ -1075,6 +1571,11 @@ func Run(ctx context.Context) error {
 								EnvVars: []string{"HHFAB_KILL_STALE"},
 								Value:   true,
 							},
+							&cli.BoolFlag{
+								Name:    FlagNameSkipPreflight,
+								Usage:   "skip checking if VLAB fits the host resources (see vlab plan)",
+								EnvVars: []string{"HHFAB_VLAB_SKIP_PREFLIGHT"},
+							},
 							&cli.BoolFlag{
 								Name:    FlagNameControlsRestricted,
 								Usage:   "restrict control nodes from having access to the host (effectively access to internet)",
@@ -1151,59 +1652,10 @@ func Run(ctx context.Context) error {
 								Aliases: []string{"rt-or"},
 								Usage:   "run only the special on-ready suite (used when --ready=release-test)",
 							},
-							&cli.UintFlag{
-								Category: FlagCatVMSizes,
-								Name:     "control-cpus",
-								Usage:    fmt.Sprintf("override control node VM number of CPUs (if not set: %d)", hhfab.DefaultSizes.Control.CPU),
-								EnvVars:  []string{"HHFAB_VLAB_CTRL_CPUS"},
-							},
-							&cli.UintFlag{
-								Category: FlagCatVMSizes,
-								Name:     "control-ram",
-								Usage:    fmt.Sprintf("override control node VM RAM (in MB) (if not set: %d)", hhfab.DefaultSizes.Control.RAM),
-								EnvVars:  []string{"HHFAB_VLAB_CTRL_RAM"},
-							},
-							&cli.UintFlag{
-								Category: FlagCatVMSizes,
-								Name:     "control-disk",
-								Usage:    fmt.Sprintf("override control node VM disk size (in GB) (if not set: %d)", hhfab.DefaultSizes.Control.Disk),
-								EnvVars:  []string{"HHFAB_VLAB_CTRL_DISK"},
-							},
-							&cli.UintFlag{
-								Category: FlagCatVMSizes,
-								Name:     "gateway-cpus",
-								Usage:    fmt.Sprintf("override gateway node VM number of CPUs (if not set: %d)", hhfab.DefaultSizes.Gateway.CPU),
-								EnvVars:  []string{"HHFAB_VLAB_GW_CPUS"},
-							},
-							&cli.UintFlag{
-								Category: FlagCatVMSizes,
-								Name:     "gateway-ram",
-								Usage:    fmt.Sprintf("override gateway node VM RAM (in MB) (if not set: %d)", hhfab.DefaultSizes.Gateway.RAM),
-								EnvVars:  []string{"HHFAB_VLAB_GW_RAM"},
-							},
-							&cli.UintFlag{
-								Category: FlagCatVMSizes,
-								Name:     "gateway-disk",
-								Usage:    fmt.Sprintf("override gateway node VM disk size (in GB) (if not set: %d)", hhfab.DefaultSizes.Gateway.Disk),
-								EnvVars:  []string{"HHFAB_VLAB_GW_DISK"},
-							},
-							&cli.UintFlag{
-								Category: FlagCatVMSizes,
-								Name:     "server-cpus",
-								Usage:    fmt.Sprintf("override server VM number of CPUs (if not set: %d)", hhfab.DefaultSizes.Server.CPU),
-								EnvVars:  []string{"HHFAB_VLAB_SRV_CPUS"},
-							},
-							&cli.UintFlag{
-								Category: FlagCatVMSizes,
-								Name:     "server-ram",
-								Usage:    fmt.Sprintf("override server VM RAM (in MB) (if not set: %d)", hhfab.DefaultSizes.Server.RAM),
-								EnvVars:  []string{"HHFAB_VLAB_SRV_RAM"},
-							},
-							&cli.UintFlag{
-								Category: FlagCatVMSizes,
-								Name:     "server-disk",
-								Usage:    fmt.Sprintf("override server VM disk size (in GB) (if not set: %d)", hhfab.DefaultSizes.Server.Disk),
-								EnvVars:  []string{"HHFAB_VLAB_SRV_DISK"},
+							&cli.StringFlag{
+								Name:    FlagReleaseTestShard,
+								Aliases: []string{"rt-shard"},
+								Usage:   "run only i-th of N shards of release tests, e.g. 2/4 (used when --ready=release-test)",
 							},
 						}),
 						Before: before(false),
@@ -1223,29 +1675,20 @@ func Run(ctx context.Context) error {
 								return err
 							}
 
+							rtShard, err := hhfab.ParseReleaseTestShard(c.String(FlagReleaseTestShard))
+							if err != nil {
+								return fmt.Errorf("parsing --%s: %w", FlagReleaseTestShard, err)
+							}
+
 							if err := hhfab.VLABUp(ctx, workDir, cacheDir, extraCacheDirs.Value(), hhfab.VLABUpOpts{
 								HydrateMode:          hhfab.HydrateMode(hydrateMode),
 								ReCreate:             c.Bool(FlagNameReCreate),
 								BuildMode:            recipe.BuildMode(c.String(FlagNameBuildMode)),
 								SetJoinToken:         joinToken,
 								ObservabilityTargets: c.String(FlagNameObservabilityTargets),
-								VMSizesOverrides: hhfab.VMSizes{
-									Control: hhfab.VMSize{
-										CPU:  c.Uint("control-cpus"),
-										RAM:  c.Uint("control-ram"),
-										Disk: c.Uint("control-disk"),
-									},
-									Gateway: hhfab.VMSize{
-										CPU:  c.Uint("gateway-cpus"),
-										RAM:  c.Uint("gateway-ram"),
-										Disk: c.Uint("gateway-disk"),
-									},
-									Server: hhfab.VMSize{
-										CPU:  c.Uint("server-cpus"),
-										RAM:  c.Uint("server-ram"),
-										Disk: c.Uint("server-disk"),
-									},
-								},
+								ServerMode:           hhfab.VLABServerMode(c.String(FlagNameServerMode)),
+								SkipPreflight:        c.Bool(FlagNameSkipPreflight),
+								VMSizesOverrides:     vmSizesOverrides(c),
 								VLABRunOpts: hhfab.VLABRunOpts{
 									KillStale:                c.Bool(FlagNameKillStale),
 									ControlsRestricted:       c.Bool(FlagNameControlsRestricted),
@@ -1262,6 +1705,7 @@ func Run(ctx context.Context) error {
 									ReleaseTestRegexes:       c.StringSlice(FlagReleaseTestRegexes),
 									ReleaseTestRegexesInvert: c.Bool(FlagReleaseTestRegexesInvert),
 									ReleaseTestOnReadyOnly:   c.Bool(FlagReleaseTestOnReadyOnly),
+									ReleaseTestShard:         rtShard,
 									InterfaceMTU:             ifMTU,
 								},
 							}); err != nil {
@@ -1271,14 +1715,48 @@ func Run(ctx context.Context) error {
 							return nil
 						},
 					},
+					{
+						Name:  "plan",
+						Usage: "check if VLAB fits the host resources and suggest smaller VM sizes or topology",
+						Flags: flatten(defaultFlags, vmSizesFlags, []cli.Flag{
+							&cli.BoolFlag{
+								Name:    FlagNameKillStale,
+								Usage:   "count memory of the stale VMs as available as they are killed automatically on vlab up",
+								EnvVars: []string{"HHFAB_KILL_STALE"},
+								Value:   true,
+							},
+						}),
+						Before: before(false),
+						Action: func(c *cli.Context) error {
+							if err := hhfab.DoVLABPlan(ctx, workDir, cacheDir, hhfab.VLABUpOpts{
+								HydrateMode:      hhfab.HydrateModeIfNotPresent,
+								VMSizesOverrides: vmSizesOverrides(c),
+								ServerMode:       hhfab.VLABServerMode(c.String(FlagNameServerMode)),
+								VLABRunOpts: hhfab.VLABRunOpts{
+									KillStale: c.Bool(FlagNameKillStale),
+								},
+							}, os.Stdout); err != nil {
+								return fmt.Errorf("planning VLAB: %w", err)
+							}
+
+							return nil
+						},
+					},
 					{
 						Hidden: !preview,
 						Name:   "air",
 						Usage:  "[PREVIEW] generate nvidia air topology and helpers",
-						Flags:  flatten(defaultFlags, hModeFlags),
+						Flags: flatten(defaultFlags, hModeFlags, []cli.Flag{
+							&cli.StringFlag{
+								Name:  "gateway-os",
+								Usage: "air image (flatcar uploaded to air) to use for the gateway nodes",
+							},
+						}),
 						Before: before(false),
-						Action: func(_ *cli.Context) error {
-							if err := hhfab.AirGenerate(ctx, workDir, cacheDir, hhfab.HydrateMode(hydrateMode)); err != nil {
+						Action: func(c *cli.Context) error {
+							if err := hhfab.AirGenerate(ctx, workDir, cacheDir, hhfab.HydrateMode(hydrateMode), hhfab.AirOpts{
+								GatewayOS: c.String("gateway-os"),
+							}); err != nil {
 								return fmt.Errorf("air: %w", err)
 							}
 
@@ -1648,6 +2126,156 @@ Examples:
 							return nil
 						},
 					},
+					{
+						Name:  "snapshot",
+						Usage: "save and restore snapshots of the running VLAB to skip its installation",
+						UsageText: strings.TrimSpace(`
+			Save the state of all running VLAB VMs (disks and memory) into internal qcow2 snapshots of their OS images
+			using QMP and restore it later in minutes instead of reinstalling the whole fabric. All VMs are paused
+			together so the snapshot is consistent across the fabric. It could be taken right after "hhfab vlab up"
+			is ready or after VPCs are set up, e.g. "hhfab vlab up --ready setup-vpcs --ready wait".
+
+			After restore clocks on all VMs are stepped to the current time and DHCP leases are renewed on servers.
+			VLAB should be up with the same VMs as when the snapshot was saved.`),
+						Subcommands: []*cli.Command{
+							{
+								Name:      "save",
+								Usage:     "save snapshot of the running VLAB",
+								ArgsUsage: "<name>",
+								Flags:     defaultFlags,
+								Before:    before(false),
+								Action: func(c *cli.Context) error {
+									if c.NArg() != 1 {
+										return fmt.Errorf("expected snapshot name as an argument") //nolint:goerr113
+									}
+
+									if err := hhfab.DoVLABSnapshotSave(ctx, workDir, cacheDir, c.Args().First()); err != nil {
+										return fmt.Errorf("saving snapshot: %w", err)
+									}
+
+									return nil
+								},
+							},
+							{
+								Name:      "restore",
+								Usage:     "restore running VLAB from a snapshot",
+								ArgsUsage: "<name>",
+								Flags: flatten(defaultFlags, []cli.Flag{
+									&cli.BoolFlag{
+										Name:  "wait",
+										Usage: "wait for switches to be ready after restore",
+										Value: true,
+									},
+								}),
+								Before: before(false),
+								Action: func(c *cli.Context) error {
+									if c.NArg() != 1 {
+										return fmt.Errorf("expected snapshot name as an argument") //nolint:goerr113
+									}
+
+									if err := hhfab.DoVLABSnapshotRestore(ctx, workDir, cacheDir, c.Args().First(), hhfab.VLABSnapshotOpts{
+										WaitReady: c.Bool("wait"),
+									}); err != nil {
+										return fmt.Errorf("restoring snapshot: %w", err)
+									}
+
+									return nil
+								},
+							},
+							{
+								Name:      "delete",
+								Usage:     "delete snapshot",
+								ArgsUsage: "<name>",
+								Flags:     defaultFlags,
+								Before:    before(false),
+								Action: func(c *cli.Context) error {
+									if c.NArg() != 1 {
+										return fmt.Errorf("expected snapshot name as an argument") //nolint:goerr113
+									}
+
+									if err := hhfab.DoVLABSnapshotDelete(ctx, workDir, cacheDir, c.Args().First()); err != nil {
+										return fmt.Errorf("deleting snapshot: %w", err)
+									}
+
+									return nil
+								},
+							},
+							{
+								Name:    "list",
+								Aliases: []string{"ls"},
+								Usage:   "list snapshots",
+								Flags:   defaultFlags,
+								Before:  before(false),
+								Action: func(_ *cli.Context) error {
+									if err := hhfab.DoVLABSnapshotList(workDir, os.Stdout); err != nil {
+										return fmt.Errorf("listing snapshots: %w", err)
+									}
+
+									return nil
+								},
+							},
+						},
+					},
+					{
+						Name:  "chaos",
+						Usage: "inject faults into the running VLAB by schedule and report time to recover",
+						UsageText: strings.TrimSpace(`
+			Inject faults into the running VLAB according to the schedule file while continuously checking
+			connectivity between all servers and externals using the connectivity matrix built from the current
+			VPCs and peerings. For each fault, report whether connectivity was impacted and how long it took to
+			recover after the fault was cleared. All faults are cleared on exit.
+
+			FAULT TYPES:
+			   link          - link down (or flapping) on both ends using QMP set_link, target: <vm>/<port>
+			   netem         - delay, jitter and loss on the link in both directions using tc netem (on the loopback
+			                   for the links between VMs, on the tap for the management NICs), target: <vm>/<port>
+			   vm-pause      - pause the VM for the duration, target: <vm>
+			   vm-reset      - hard reset of the VM (power cycle), target: <vm>
+			   agent-stop    - stop the agent for the duration, target: <switch>
+			   agent-restart - restart the agent, target: <switch>
+
+			EXAMPLE:
+			   pingsCount: 3
+			   recoverTimeout: 10m
+			   faults:
+			     - type: link
+			       target: leaf-01/E1/1
+			       at: 30s
+			       duration: 1m
+			       flaps: 3
+			     - type: netem
+			       target: leaf-02/E1/1
+			       at: 2m
+			       duration: 1m
+			       delay: 100ms
+			       jitter: 20ms
+			       loss: 5
+			     - type: agent-restart
+			       target: spine-01
+			       at: 4m`),
+						Flags: flatten(defaultFlags, []cli.Flag{
+							&cli.StringFlag{
+								Name:     "schedule",
+								Aliases:  []string{"s"},
+								Usage:    "chaos schedule file",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:  "report",
+								Usage: "write per fault results to this JSON file",
+							},
+						}),
+						Before: before(false),
+						Action: func(c *cli.Context) error {
+							if err := hhfab.DoVLABChaos(ctx, workDir, cacheDir, c.String("schedule"), hhfab.ChaosOpts{
+								ReportFile: c.String("report"),
+							}); err != nil {
+								return fmt.Errorf("chaos: %w", err)
+							}
+
+							return nil
+						},
+					},
 					{
 						Name:    "inspect-switches",
 						Aliases: []string{"inspect"},
@@ -1742,6 +2370,23 @@ Examples:
 								Aliases: []string{"on-ready", "o"},
 								Usage:   "Run only the special OnReady test suite, replacing old on-ready vlab jobs",
 							},
+							&cli.StringSliceFlag{
+								Name:    FlagTestFile,
+								Aliases: []string{"t"},
+								Usage:   "declarative release test file (YAML) or directory with them to run after the built-in suites. can be repeated",
+							},
+							&cli.StringFlag{
+								Name:  FlagShard,
+								Usage: "run only i-th of N shards of the tests, e.g. 2/4 (use release-test merge to combine results)",
+							},
+							&cli.StringFlag{
+								Name:  FlagHistoryDir,
+								Usage: "dir to store the run record in for the release test report (not recorded if not set)",
+							},
+							&cli.StringFlag{
+								Name:  FlagRunID,
+								Usage: "ID shared by all shards of the run to combine their records in the history (required with --" + FlagShard + " and --" + FlagHistoryDir + ")",
+							},
 						}),
 						Before: before(false),
 						Action: func(c *cli.Context) error {
@@ -1749,6 +2394,10 @@ Examples:
 							if iperfsSpeed < 0 {
 								return fmt.Errorf("--%s must be >= 0, got %g", FlagIPerfsSpeed, iperfsSpeed) //nolint:goerr113
 							}
+							shard, err := hhfab.ParseReleaseTestShard(c.String(FlagShard))
+							if err != nil {
+								return fmt.Errorf("parsing --%s: %w", FlagShard, err)
+							}
 							opts := hhfab.ReleaseTestOpts{
 								Regexes:        c.StringSlice(FlagRegEx),
 								InvertRegex:    c.Bool(FlagInvertRegex),
@@ -1762,6 +2411,10 @@ Examples:
 								ShowTechDump:   c.Bool(FlagShowTech),
 								IPerfsMinSpeed: iperfsSpeed,
 								OnReadyTest:    c.Bool(FlagOnReadyOnly),
+								TestFiles:      c.StringSlice(FlagTestFile),
+								Shard:          shard,
+								HistoryDir:     c.String(FlagHistoryDir),
+								RunID:          c.String(FlagRunID),
 							}
 							if err := hhfab.DoVLABReleaseTest(ctx, workDir, cacheDir, opts); err != nil {
 								return fmt.Errorf("release-test: %w", err)
@@ -1769,6 +2422,74 @@ Examples:
 
 							return nil
 						},
+						Subcommands: []*cli.Command{
+							{
+								Name:  "report",
+								Usage: "report pass rates, flaky tests and duration/iperf3 speed regressions from the release test history",
+								Flags: flatten(defaultFlags, []cli.Flag{
+									&cli.StringFlag{
+										Name:     FlagHistoryDir,
+										Usage:    "release test history dir, as passed to release-test --" + FlagHistoryDir,
+										Required: true,
+									},
+									&cli.IntFlag{
+										Name:  "runs",
+										Usage: "only use last N runs (0 for all)",
+										Value: 20,
+									},
+									&cli.Float64Flag{
+										Name:  "duration-threshold",
+										Usage: "warn if test duration increased over baseline by more than this ratio",
+										Value: 0.25,
+									},
+									&cli.Float64Flag{
+										Name:  "speed-threshold",
+										Usage: "warn if iperf3 speed decreased over baseline by more than this ratio",
+										Value: 0.1,
+									},
+									&cli.Float64Flag{
+										Name:  "speed-margin",
+										Usage: "warn if iperf3 speed is within this ratio over the min speed",
+										Value: 0.1,
+									},
+								}),
+								Before: before(false),
+								Action: func(c *cli.Context) error {
+									if err := hhfab.ReleaseTestReport(os.Stdout, hhfab.ReleaseTestReportOpts{
+										HistoryDir:        c.String(FlagHistoryDir),
+										Runs:              c.Int("runs"),
+										DurationThreshold: c.Float64("duration-threshold"),
+										SpeedThreshold:    c.Float64("speed-threshold"),
+										SpeedMargin:       c.Float64("speed-margin"),
+									}); err != nil {
+										return fmt.Errorf("release-test report: %w", err)
+									}
+
+									return nil
+								},
+							},
+							{
+								Name:      "merge",
+								Usage:     "merge JUnit results of the sharded release test runs into a single report",
+								ArgsUsage: "<results-file>...",
+								Flags: flatten(defaultFlags, []cli.Flag{
+									&cli.StringFlag{
+										Name:     "output",
+										Aliases:  []string{"o"},
+										Usage:    "path to write the merged JUnit XML report to",
+										Required: true,
+									},
+								}),
+								Before: before(false),
+								Action: func(c *cli.Context) error {
+									if err := hhfab.MergeJUnitReports(c.Args().Slice(), c.String("output")); err != nil {
+										return fmt.Errorf("release-test merge: %w", err)
+									}
+
+									return nil
+								},
+							},
+						},
 					},
 					{
 						Name:  "switch",
@@ -1844,10 +2565,6 @@ Examples:
 										}
 									}
 
-									if mode == string(hhfab.ReinstallModeHardReset) && (c.String("pdu-username") == "" || c.String("pdu-password") == "") {
-										return fmt.Errorf("PDU credentials required for hard reset mode") //nolint:goerr113
-									}
-
 									opts := hhfab.SwitchReinstallOpts{
 										Switches:       c.StringSlice("name"),
 										Mode:           hhfab.SwitchReinstallMode(mode),
@@ -1867,7 +2584,7 @@ Examples:
 							},
 							{
 								Name:  "power",
-								Usage: "manage switch power state using the PDU (if no switches specified, all switches will be affected)",
+								Usage: "manage switch power state using the PDU or BMC (if no switches specified, all switches will be affected)",
 								Flags: flatten(pduFlags, []cli.Flag{
 									&cli.StringSliceFlag{
 										Name:    "name",
@@ -1930,8 +2647,8 @@ Examples:
 										return fmt.Errorf("count must be zero or positive") //nolint:goerr113
 									}
 
-									if v > 100 {
-										return fmt.Errorf("count must be less than 100") //nolint:goerr113
+									if v > hhfab.VLABMaxTaps {
+										return fmt.Errorf("count must be less than %d", hhfab.VLABMaxTaps) //nolint:goerr113
 									}
 
 									return nil
@@ -1965,6 +2682,25 @@ Examples:
 							return nil
 						},
 					},
+					{
+						Name:  "netns-servers",
+						Usage: "run VLAB servers as network namespaces until terminated",
+						Flags: flatten(defaultFlags, []cli.Flag{
+							&cli.StringFlag{
+								Name:     FlagNameConfig,
+								Usage:    "netns servers config file",
+								Required: true,
+							},
+						}),
+						Before: before(true),
+						Action: func(c *cli.Context) error {
+							if err := hhfab.RunNetnsServers(ctx, c.String(FlagNameConfig)); err != nil {
+								return fmt.Errorf("running netns servers: %w", err)
+							}
+
+							return nil
+						},
+					},
 					{
 						Name:   "kill-stale-vms",
 						Usage:  "kill stale VLAB VMs",
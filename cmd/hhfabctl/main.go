@@ -16,7 +16,9 @@ import (
 	"github.com/lmittmann/tint"
 	"github.com/mattn/go-isatty"
 	"github.com/urfave/cli/v2"
+	"go.githedgehog.com/fabric/pkg/hhfctl/inspect"
 	"go.githedgehog.com/fabricator/pkg/hhfabctl"
+	"go.githedgehog.com/fabricator/pkg/support"
 	"go.githedgehog.com/fabricator/pkg/version"
 	"k8s.io/klog/v2"
 	kctrl "sigs.k8s.io/controller-runtime"
@@ -27,6 +29,21 @@ const (
 	FlagName    = "name"
 	FlagForce   = "force"
 	FlagYes     = "yes"
+	FlagOutput  = "output"
+	FlagDetails = "details"
+
+	FlagSkipLocal = "skip-local"
+
+	FlagSwitches        = "switches"
+	FlagHosts           = "hosts"
+	FlagSSHKey          = "ssh-key"
+	FlagSwitchUser      = "switch-user"
+	FlagSwitchPassword  = "switch-password"
+	FlagHostUser        = "host-user"
+	FlagConcurrency     = "concurrency"
+	FlagTargetTimeout   = "target-timeout"
+	FlagShowTech        = "show-tech"
+	FlagShowTechTimeout = "show-tech-timeout"
 )
 
 func setupLogger(verbose bool) error {
@@ -156,6 +173,52 @@ func main() {
 								Usage:   "working directory for creating support dump",
 								Value:   workdirDefault,
 							},
+							&cli.BoolFlag{
+								Name:  FlagSwitches,
+								Usage: "collect diagnostics (show commands, agent logs, running config) from switches over SSH",
+							},
+							&cli.BoolFlag{
+								Name:  FlagHosts,
+								Usage: "collect diagnostics (journals, dmesg, disk/memory, nftables) from control and gateway nodes over SSH",
+							},
+							&cli.StringFlag{
+								Name:  FlagSSHKey,
+								Usage: "SSH private key to access switches and nodes for diagnostics",
+							},
+							&cli.StringFlag{
+								Name:  FlagSwitchUser,
+								Usage: "switch user for diagnostics",
+								Value: support.DefaultDiagnosticsSwitchUser,
+							},
+							&cli.StringFlag{
+								Name:    FlagSwitchPassword,
+								Usage:   "switch password for diagnostics (used instead of SSH key if set)",
+								EnvVars: []string{"HHFAB_SWITCH_PASSWORD"},
+							},
+							&cli.StringFlag{
+								Name:  FlagHostUser,
+								Usage: "node user for diagnostics",
+								Value: support.DefaultDiagnosticsHostUser,
+							},
+							&cli.IntFlag{
+								Name:  FlagConcurrency,
+								Usage: "max number of switches and nodes to collect diagnostics from in parallel",
+								Value: support.DefaultDiagnosticsConcurrency,
+							},
+							&cli.DurationFlag{
+								Name:  FlagTargetTimeout,
+								Usage: "timeout for collecting diagnostics from a single switch or node",
+								Value: support.DefaultDiagnosticsTargetTimeout,
+							},
+							&cli.BoolFlag{
+								Name:  FlagShowTech,
+								Usage: "collect NOS show tech-support from switches (slow, requires --" + FlagSwitches + ")",
+							},
+							&cli.DurationFlag{
+								Name:  FlagShowTechTimeout,
+								Usage: "timeout for collecting show tech-support from a single switch",
+								Value: support.DefaultDiagnosticsShowTechTimeout,
+							},
 						},
 						Before: func(_ *cli.Context) error {
 							return setupLogger(verbose)
@@ -169,10 +232,152 @@ func main() {
 								WorkDir: cCtx.String(FlagWorkDir),
 								Name:    cCtx.String(FlagName),
 								Force:   cCtx.Bool(FlagForce),
+								Diagnostics: support.DiagnosticsOpts{
+									Switches:        cCtx.Bool(FlagSwitches),
+									Hosts:           cCtx.Bool(FlagHosts),
+									SSHKeyPath:      cCtx.String(FlagSSHKey),
+									SwitchUser:      cCtx.String(FlagSwitchUser),
+									SwitchPassword:  cCtx.String(FlagSwitchPassword),
+									HostUser:        cCtx.String(FlagHostUser),
+									Concurrency:     cCtx.Int(FlagConcurrency),
+									TargetTimeout:   cCtx.Duration(FlagTargetTimeout),
+									ShowTech:        cCtx.Bool(FlagShowTech),
+									ShowTechTimeout: cCtx.Duration(FlagShowTechTimeout),
+								},
 							}); err != nil {
 								return fmt.Errorf("collecting support dump: %w", err)
 							}
 
+							return nil
+						},
+					},
+					{
+						Name:      "analyze",
+						Usage:     "analyze support dump offline and report prioritized findings",
+						ArgsUsage: "<dump>",
+						Flags: []cli.Flag{
+							verboseFlag,
+							&cli.StringFlag{
+								Name:    FlagOutput,
+								Aliases: []string{"o"},
+								Usage:   "output format, one of text, json, yaml",
+								Value:   "text",
+							},
+							&cli.BoolFlag{
+								Name:  FlagDetails,
+								Usage: "print details (e.g. logs of the crashed containers) for the findings",
+							},
+						},
+						Before: func(_ *cli.Context) error {
+							return setupLogger(verbose)
+						},
+						Action: func(cCtx *cli.Context) error {
+							if cCtx.NArg() != 1 {
+								return cli.Exit("exactly one support dump file should be specified", 1)
+							}
+
+							if err := hhfabctl.SupportAnalyze(ctx, cCtx.Args().First(), hhfabctl.SupportAnalyzeOpts{
+								Output:  inspect.OutputType(cCtx.String(FlagOutput)),
+								Details: cCtx.Bool(FlagDetails),
+							}); err != nil {
+								return fmt.Errorf("analyzing support dump: %w", err)
+							}
+
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:  "ca",
+				Usage: "fab CA helpers",
+				Flags: []cli.Flag{
+					verboseFlag,
+				},
+				Subcommands: []*cli.Command{
+					{
+						Name:  "show",
+						Usage: "Show trusted fab CAs and expiration of the certificates issued by the fab CA",
+						Flags: []cli.Flag{
+							verboseFlag,
+						},
+						Before: func(_ *cli.Context) error {
+							return setupLogger(verbose)
+						},
+						Action: func(_ *cli.Context) error {
+							if err := hhfabctl.CAShow(ctx); err != nil {
+								return fmt.Errorf("showing fab CA: %w", err)
+							}
+
+							return nil
+						},
+					},
+					{
+						Name:  "rotate",
+						Usage: "Generate new fab CA, trust it alongside the current one and re-issue all certificates",
+						Flags: []cli.Flag{
+							verboseFlag,
+							&cli.BoolFlag{
+								Name:  FlagSkipLocal,
+								Usage: "don't update trusted CAs on the node hhfabctl is running on",
+							},
+						},
+						Before: func(_ *cli.Context) error {
+							return setupLogger(verbose)
+						},
+						Action: func(cCtx *cli.Context) error {
+							if err := hhfabctl.CARotate(ctx, hhfabctl.CAOpts{
+								SkipLocal: cCtx.Bool(FlagSkipLocal),
+							}); err != nil {
+								return fmt.Errorf("rotating fab CA: %w", err)
+							}
+
+							return nil
+						},
+					},
+					{
+						Name:  "renew",
+						Usage: "Re-issue all certificates issued by the fab CA using the active CA",
+						Flags: []cli.Flag{
+							verboseFlag,
+						},
+						Before: func(_ *cli.Context) error {
+							return setupLogger(verbose)
+						},
+						Action: func(_ *cli.Context) error {
+							if err := hhfabctl.CARenew(ctx); err != nil {
+								return fmt.Errorf("renewing certificates: %w", err)
+							}
+
+							return nil
+						},
+					},
+					{
+						Name:  "retire",
+						Usage: "Stop trusting the previous fab CA after rotation",
+						Flags: []cli.Flag{
+							verboseFlag,
+							&cli.BoolFlag{
+								Name:  FlagSkipLocal,
+								Usage: "don't update trusted CAs on the node hhfabctl is running on",
+							},
+							&cli.BoolFlag{
+								Name:    FlagForce,
+								Aliases: []string{"f"},
+								Usage:   "retire previous CA even if some certificates aren't re-issued yet",
+							},
+						},
+						Before: func(_ *cli.Context) error {
+							return setupLogger(verbose)
+						},
+						Action: func(cCtx *cli.Context) error {
+							if err := hhfabctl.CARetire(ctx, hhfabctl.CAOpts{
+								SkipLocal: cCtx.Bool(FlagSkipLocal),
+								Force:     cCtx.Bool(FlagForce),
+							}); err != nil {
+								return fmt.Errorf("retiring previous fab CA: %w", err)
+							}
+
 							return nil
 						},
 					},
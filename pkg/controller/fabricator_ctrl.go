@@ -21,6 +21,7 @@ import (
 	"go.githedgehog.com/fabricator/pkg/fab/comp/fabric"
 	"go.githedgehog.com/fabricator/pkg/fab/comp/gateway"
 	"go.githedgehog.com/fabricator/pkg/fab/comp/k3s"
+	"go.githedgehog.com/fabricator/pkg/fab/comp/monitoring"
 	"go.githedgehog.com/fabricator/pkg/fab/comp/ntp"
 	"go.githedgehog.com/fabricator/pkg/fab/comp/reloader"
 	"go.githedgehog.com/fabricator/pkg/fab/comp/zot"
@@ -58,7 +59,8 @@ import (
 // +kubebuilder:rbac:groups=helm.cattle.io,resources=helmcharts/status,verbs=get
 
 // +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates/status,verbs=get
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cert-manager.io,resources=issuers,verbs=get;list;watch;create;update;patch;delete
 
 type FabricatorReconciler struct {
 	kclient.Client
@@ -84,9 +86,15 @@ func SetupFabricatorReconcilerWith(mgr kctrl.Manager) error {
 	return nil
 }
 
-func (r *FabricatorReconciler) Reconcile(ctx context.Context, req kctrl.Request) (kctrl.Result, error) {
+func (r *FabricatorReconciler) Reconcile(ctx context.Context, req kctrl.Request) (_ kctrl.Result, err error) {
 	l := kctrllog.FromContext(ctx)
 
+	start := time.Now()
+	applied := false
+	defer func() {
+		observeReconcile(start, applied, err)
+	}()
+
 	if req.Name != comp.FabName && req.Namespace != comp.FabNamespace {
 		l.Info("Ignoring incorrect Fabricator")
 
@@ -101,6 +109,8 @@ func (r *FabricatorReconciler) Reconcile(ctx context.Context, req kctrl.Request)
 
 	l = l.WithValues("gen", f.Generation, "res", f.ResourceVersion)
 
+	updateStatusMetrics(f)
+
 	if f.Status.Conditions == nil {
 		f.Status.Conditions = []kmetav1.Condition{}
 	}
@@ -201,6 +211,10 @@ func (r *FabricatorReconciler) Reconcile(ctx context.Context, req kctrl.Request)
 			return kctrl.Result{}, fmt.Errorf("enforcing cert-manager install: %w", err)
 		}
 
+		if err := certmanager.EnforceFabCA(ctx, r.Client, *f); err != nil {
+			return kctrl.Result{}, fmt.Errorf("enforcing fab-ca: %w", err)
+		}
+
 		if err := comp.EnforceKubeInstall(ctx, r.Client, *f, zot.Install); err != nil {
 			return kctrl.Result{}, fmt.Errorf("enforcing zot install: %w", err)
 		}
@@ -235,6 +249,14 @@ func (r *FabricatorReconciler) Reconcile(ctx context.Context, req kctrl.Request)
 			return kctrl.Result{}, fmt.Errorf("enforcing node config install: %w", err)
 		}
 
+		if monitoring.Enabled(*f) {
+			if err := comp.EnforceKubeInstall(ctx, r.Client, *f, monitoring.Install); err != nil {
+				return kctrl.Result{}, fmt.Errorf("enforcing monitoring install: %w", err)
+			}
+		} else if err := monitoring.Uninstall(ctx, r.Client); err != nil {
+			return kctrl.Result{}, fmt.Errorf("uninstalling monitoring: %w", err)
+		}
+
 		if err := comp.EnforceKubeInstall(ctx, r.Client, *f, alloy.Install); err != nil {
 			return kctrl.Result{}, fmt.Errorf("enforcing alloy install: %w", err)
 		}
@@ -261,6 +283,9 @@ func (r *FabricatorReconciler) Reconcile(ctx context.Context, req kctrl.Request)
 			return kctrl.Result{}, fmt.Errorf("updating applied status: %w", err)
 		}
 
+		applied = true
+		updateStatusMetrics(f)
+
 		l.Info("Reconciled Fabricator")
 	}
 
@@ -275,6 +300,15 @@ func (r *FabricatorReconciler) statusCheck(ctx context.Context, l logr.Logger, f
 	r.status.Lock()
 	defer r.status.Unlock()
 
+	start := time.Now()
+	err := r.checkStatus(ctx, l, f, nodes)
+	observeStatusCheck(start, err)
+	updateStatusMetrics(f)
+
+	return err
+}
+
+func (r *FabricatorReconciler) checkStatus(ctx context.Context, l logr.Logger, f *fabapi.Fabricator, nodes []fabapi.FabNode) error {
 	l.Info("Checking for components status")
 
 	var err error
@@ -364,6 +398,16 @@ func (r *FabricatorReconciler) statusCheck(ctx context.Context, l logr.Logger, f
 		return fmt.Errorf("getting ctrl alloy status: %w", err)
 	}
 
+	f.Status.Components.Monitoring, err = monitoring.Status(ctx, r.Client, *f)
+	if err != nil {
+		return fmt.Errorf("getting monitoring status: %w", err)
+	}
+
+	f.Status.Certificates, err = certmanager.StatusCertificates(ctx, r.Client, *f)
+	if err != nil {
+		return fmt.Errorf("getting certificates status: %w", err)
+	}
+
 	if f.Status.Components.IsReady(*f, nodes) {
 		if !kmeta.IsStatusConditionTrue(f.Status.Conditions, fabapi.ConditionReady) {
 			l.Info("All components are ready now")
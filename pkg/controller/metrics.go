@@ -0,0 +1,154 @@
+// Copyright 2026 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
+	kmeta "k8s.io/apimachinery/pkg/api/meta"
+	kmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace = "fabricator"
+
+	metricsResultSuccess = "success"
+	metricsResultError   = "error"
+)
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of the Fabricator reconciliation by result and whether config was applied",
+		Buckets:   []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"result", "applied"})
+
+	statusCheckDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "status_check_duration_seconds",
+		Help:      "Duration of the components status check by result",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"result"})
+
+	componentStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "component_status",
+		Help:      "Status of the component, 1 for the current status and 0 for others",
+	}, []string{"component", "node", "status"})
+
+	conditionStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "condition",
+		Help:      "Fabricator condition, 1 if true and 0 otherwise",
+	}, []string{"condition"})
+
+	generation = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "generation",
+		Help:      "Current generation of the Fabricator config",
+	})
+
+	lastAppliedGeneration = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "last_applied_generation",
+		Help:      "Generation of the last successfully applied Fabricator config",
+	})
+
+	applyLag = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "apply_lag",
+		Help:      "Number of Fabricator config generations not applied yet",
+	})
+
+	lastAppliedTime = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "last_applied_timestamp_seconds",
+		Help:      "Time of the last successfully applied Fabricator config",
+	})
+)
+
+func init() {
+	kmetrics.Registry.MustRegister(
+		reconcileDuration,
+		statusCheckDuration,
+		componentStatus,
+		conditionStatus,
+		generation,
+		lastAppliedGeneration,
+		applyLag,
+		lastAppliedTime,
+	)
+}
+
+func metricsResult(err error) string {
+	if err != nil {
+		return metricsResultError
+	}
+
+	return metricsResultSuccess
+}
+
+func observeReconcile(start time.Time, applied bool, err error) {
+	reconcileDuration.WithLabelValues(metricsResult(err), strconv.FormatBool(applied)).Observe(time.Since(start).Seconds())
+}
+
+func observeStatusCheck(start time.Time, err error) {
+	statusCheckDuration.WithLabelValues(metricsResult(err)).Observe(time.Since(start).Seconds())
+}
+
+// updateStatusMetrics exports generations, conditions and components status from the Fabricator status
+func updateStatusMetrics(f *fabapi.Fabricator) {
+	generation.Set(float64(f.Generation))
+	lastAppliedGeneration.Set(float64(f.Status.LastAppliedGen))
+	applyLag.Set(float64(max(f.Generation-f.Status.LastAppliedGen, 0)))
+	if !f.Status.LastAppliedTime.IsZero() {
+		lastAppliedTime.Set(float64(f.Status.LastAppliedTime.Unix()))
+	}
+
+	for _, cond := range []string{fabapi.ConditionApplied, fabapi.ConditionReady, fabapi.ConditionGatewayReady} {
+		conditionStatus.WithLabelValues(cond).Set(boolGauge(kmeta.IsStatusConditionTrue(f.Status.Conditions, cond)))
+	}
+
+	// reset to drop series for removed nodes
+	componentStatus.Reset()
+
+	comps := reflect.ValueOf(f.Status.Components)
+	for idx := range comps.NumField() {
+		name := strings.Split(comps.Type().Field(idx).Tag.Get("json"), ",")[0]
+
+		switch field := comps.Field(idx).Interface().(type) {
+		case fabapi.ComponentStatus:
+			setComponentStatus(name, "", field)
+		case map[string]fabapi.ComponentStatus:
+			for node, status := range field {
+				setComponentStatus(name, node, status)
+			}
+		}
+	}
+}
+
+func setComponentStatus(name, node string, current fabapi.ComponentStatus) {
+	for _, status := range fabapi.ComponentStatuses {
+		label := string(status)
+		if status == fabapi.CompStatusUnknown {
+			label = "Unknown"
+		}
+
+		componentStatus.WithLabelValues(name, node, label).Set(boolGauge(status == current))
+	}
+}
+
+func boolGauge(v bool) float64 {
+	if v {
+		return 1
+	}
+
+	return 0
+}
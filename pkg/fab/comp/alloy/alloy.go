@@ -9,15 +9,21 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 
 	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
 	"go.githedgehog.com/fabricator/api/meta"
 	"go.githedgehog.com/fabricator/pkg/fab/comp"
 	"go.githedgehog.com/fabricator/pkg/fab/comp/controlproxy"
+	"go.githedgehog.com/fabricator/pkg/fab/comp/f8r"
 	"go.githedgehog.com/fabricator/pkg/fab/comp/gateway"
+	"go.githedgehog.com/fabricator/pkg/fab/comp/k3s"
+	"go.githedgehog.com/fabricator/pkg/fab/comp/monitoring"
+	"go.githedgehog.com/fabricator/pkg/fab/comp/zot"
 	"go.githedgehog.com/libmeta/pkg/alloy"
 	"go.githedgehog.com/libmeta/pkg/tmpl"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
 	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 	kyaml "sigs.k8s.io/yaml"
 )
@@ -26,6 +32,9 @@ const (
 	BinRef   = "fabricator/alloy-bin" // used for fabric switches
 	ImageRef = "fabricator/alloy"
 	ChartRef = "fabricator/charts/alloy"
+
+	fabricCtrl            = "fabric-ctrl"
+	fabricCtrlMetricsPort = 8080
 )
 
 func Version(f fabapi.Fabricator) meta.Version {
@@ -38,11 +47,15 @@ var gwValuesTmpl string
 //go:embed ctrl_values.tmpl.yaml
 var ctrlValuesTmpl string
 
+//go:embed ctrl_extra.alloy.tmpl
+var ctrlExtraTmpl string
+
 var _ comp.KubeInstall = Install
 
 func Install(cfg fabapi.Fabricator) ([]kclient.Object, error) {
 	res := []kclient.Object{}
 	chartVersion := string(Version(cfg))
+	targets := monitoring.Targets(cfg)
 	registryURL, err := comp.RegistryURL(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("getting registry URL: %w", err)
@@ -69,7 +82,7 @@ func Install(cfg fabapi.Fabricator) ([]kclient.Object, error) {
 		gwAlloyCfg := alloy.Config{
 			ProxyURL:     proxyURL,
 			AutoHostname: true,
-			Targets:      cfg.Spec.Config.Observability.Targets,
+			Targets:      targets,
 
 			Scrapes:  map[string]alloy.Scrape{},
 			LogFiles: map[string]alloy.LogFile{},
@@ -130,28 +143,103 @@ func Install(cfg fabapi.Fabricator) ([]kclient.Object, error) {
 	}
 
 	{
+		obs := cfg.Spec.Config.Control.Observability
+		if obs == nil {
+			obs = &fabapi.ControlObservability{}
+		}
+
 		ctrlAlloyCfg := alloy.Config{
 			AutoHostname: true,
-			Targets:      cfg.Spec.Config.Observability.Targets,
+			Targets:      targets,
 
 			Scrapes:  map[string]alloy.Scrape{},
 			LogFiles: map[string]alloy.LogFile{},
 			Kube: alloy.Kube{
-				PodLogs: cfg.Spec.Config.Control.Observability.KubePodLogs,
-				Events:  cfg.Spec.Config.Control.Observability.KubeEvents,
+				PodLogs: ptr.Deref(obs.KubePodLogs, false),
+				Events:  ptr.Deref(obs.KubeEvents, false),
 			},
 		}
+		if ptr.Deref(obs.Unix.Metrics, false) {
+			ctrlAlloyCfg.Scrapes["unix"] = alloy.Scrape{
+				Unix: alloy.ScrapeUnix{
+					Enable:     true,
+					Collectors: obs.Unix.MetricsCollectors,
+				},
+				IntervalSeconds: obs.Unix.MetricsInterval,
+				Relabel:         obs.Unix.MetricsRelabel,
+			}
+		}
+		if ptr.Deref(obs.Fabricator.Metrics, false) {
+			ctrlAlloyCfg.Scrapes["fabricator"] = alloy.Scrape{
+				Address: net.JoinHostPort(fmt.Sprintf("%s.%s.svc.%s", f8r.CtrlMetricsService, comp.FabNamespace, comp.ClusterDomain),
+					strconv.Itoa(f8r.CtrlMetricsPort)),
+				IntervalSeconds: obs.Fabricator.MetricsInterval,
+				Relabel:         obs.Fabricator.MetricsRelabel,
+			}
+		}
+		if ptr.Deref(obs.ContainerdLogs, false) {
+			ctrlAlloyCfg.LogFiles["containerd"] = alloy.LogFile{
+				PathTargets: []alloy.LogFilePathTarget{
+					{Path: k3s.ContainerdLogDir + "/containerd.log"},
+				},
+			}
+		}
 
 		ctrlAlloyConfigData, err := ctrlAlloyCfg.Render()
 		if err != nil {
 			return nil, fmt.Errorf("ctrl alloy config: %w", err)
 		}
 
+		relabel := map[string]string{}
+		for name, metrics := range map[string]fabapi.ControlObservabilityMetrics{
+			"apiserver": obs.APIServer,
+			"etcd":      obs.Etcd,
+			"registry":  obs.Registry,
+			"fabric":    obs.Fabric,
+		} {
+			if !ptr.Deref(metrics.Metrics, false) {
+				continue
+			}
+
+			relabel[name], err = relabelComponent(targets, name, metrics.MetricsRelabel)
+			if err != nil {
+				return nil, fmt.Errorf("ctrl alloy %s relabel: %w", name, err)
+			}
+		}
+
+		ctrlAlloyExtraData, err := tmpl.Render("extra", ctrlExtraTmpl, map[string]any{
+			"Relabel":              relabel,
+			"Targets":              targets,
+			"Namespace":            comp.FabNamespace,
+			"K3sLogs":              ptr.Deref(obs.K3sLogs, false),
+			"K3sUnit":              k3s.ServerServiceName,
+			"APIServer":            ctrlExtraMetrics(obs.APIServer),
+			"APIServerAddress":     net.JoinHostPort("kubernetes.default.svc."+comp.ClusterDomain, "443"),
+			"Etcd":                 ctrlExtraMetrics(obs.Etcd),
+			"SupervisorPort":       k3s.APIPort,
+			"Registry":             ctrlExtraMetrics(obs.Registry),
+			"RegistryAddress":      net.JoinHostPort(fmt.Sprintf("%s.%s.svc.%s", zot.ServiceName, comp.FabNamespace, comp.ClusterDomain), "5000"),
+			"RegistrySecret":       comp.RegistryUserReaderSecret,
+			"BasicAuthUsernameKey": comp.BasicAuthUsernameKey,
+			"BasicAuthPasswordKey": comp.BasicAuthPasswordKey,
+			"FabCAConfigMap":       comp.FabCAConfigMap,
+			"FabCAConfigMapKey":    comp.FabCAConfigMapKey,
+			"Fabric":               ctrlExtraMetrics(obs.Fabric),
+			"FabricCtrl":           fabricCtrl,
+			"FabricMetricsPort":    fabricCtrlMetricsPort,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ctrl alloy extra config: %w", err)
+		}
+		ctrlAlloyConfigData = append(ctrlAlloyConfigData, '\n', '\n')
+		ctrlAlloyConfigData = append(ctrlAlloyConfigData, ctrlAlloyExtraData...)
+
 		ctrlAlloyValues, err := tmpl.Render("values", ctrlValuesTmpl, map[string]any{
-			"Registry": registryURL,
-			"Image":    comp.JoinURLParts(comp.RegPrefix, ImageRef),
-			"Version":  string(Version(cfg)),
-			"Config":   string(ctrlAlloyConfigData),
+			"Registry":         registryURL,
+			"Image":            comp.JoinURLParts(comp.RegPrefix, ImageRef),
+			"Version":          string(Version(cfg)),
+			"Config":           string(ctrlAlloyConfigData),
+			"ContainerdLogDir": k3s.ContainerdLogDir,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("ctrl alloy values: %w", err)
@@ -168,6 +256,38 @@ func Install(cfg fabapi.Fabricator) ([]kclient.Object, error) {
 	return res, nil
 }
 
+// relabelComponent renders prometheus.relabel component for the scrape with the common Alloy config, so it's the same
+// as for the scrapes rendered by it
+func relabelComponent(targets alloy.Targets, name string, rules []alloy.ScrapeRelabelRule) (string, error) {
+	cfg := alloy.Config{
+		Targets: targets,
+		Scrapes: map[string]alloy.Scrape{
+			name: {
+				Address: "localhost",
+				Relabel: rules,
+			},
+		},
+	}
+
+	data, err := cfg.Render()
+	if err != nil {
+		return "", fmt.Errorf("rendering: %w", err)
+	}
+
+	res := string(data)
+	start := strings.Index(res, `prometheus.relabel "`+name+`" {`)
+	if start < 0 {
+		return "", nil // no prometheus targets
+	}
+
+	end := strings.Index(res[start:], "\n}\n")
+	if end < 0 {
+		return "", fmt.Errorf("relabel component isn't terminated") //nolint:goerr113
+	}
+
+	return res[start : start+end+len("\n}\n")], nil
+}
+
 var _ comp.ListOCIArtifacts = Artifacts
 
 func Artifacts(cfg fabapi.Fabricator) (comp.OCIArtifacts, error) {
@@ -205,3 +325,11 @@ func StatusControl(ctx context.Context, kube kclient.Reader, cfg fabapi.Fabricat
 
 	return comp.GetDaemonSetStatus("alloy-ctrl", "alloy", image)(ctx, kube, cfg)
 }
+
+// ctrlExtraMetrics resolves the optional toggle as text/template treats any non-nil pointer as true
+func ctrlExtraMetrics(metrics fabapi.ControlObservabilityMetrics) map[string]any {
+	return map[string]any{
+		"Metrics":         ptr.Deref(metrics.Metrics, false),
+		"MetricsInterval": metrics.MetricsInterval,
+	}
+}
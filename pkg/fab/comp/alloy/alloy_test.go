@@ -0,0 +1,34 @@
+// Copyright 2026 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package alloy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.githedgehog.com/libmeta/pkg/alloy"
+)
+
+func TestRelabelComponent(t *testing.T) {
+	targets := alloy.Targets{
+		Prometheus: map[string]alloy.PrometheusTarget{
+			"grafana_cloud": {Target: alloy.Target{URL: "https://prometheus.example.com/api/v1/push"}},
+		},
+	}
+
+	res, err := relabelComponent(targets, "etcd", []alloy.ScrapeRelabelRule{
+		{SourceLabels: []string{"__name__"}, Regex: "etcd_.*", Action: "keep"},
+	})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(res, `prometheus.relabel "etcd" {`))
+	require.True(t, strings.HasSuffix(res, "\n}\n"))
+	require.Contains(t, res, "prometheus.remote_write.grafana_cloud.receiver")
+	require.Contains(t, res, `regex = "etcd_.*"`)
+	require.NotContains(t, res, "prometheus.scrape")
+
+	res, err = relabelComponent(alloy.Targets{}, "etcd", nil)
+	require.NoError(t, err)
+	require.Empty(t, res)
+}
@@ -5,6 +5,7 @@ package certmanager
 
 import (
 	"bytes"
+	"context"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	cryptorand "crypto/rand"
@@ -12,12 +13,20 @@ import (
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"fmt"
+	"log/slog"
 	"math/big"
 	mathrand "math/rand"
+	"slices"
+	"strings"
 	"time"
 
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
 	"go.githedgehog.com/fabricator/pkg/fab/comp"
+	coreapi "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -25,29 +34,538 @@ const (
 	BlockTypeCert = "CERTIFICATE"
 	BlockTypeKey  = "EC PRIVATE KEY"
 	FabCAPath     = "/etc/ssl/certs/hh-fab-ca.pem"
+	// FabCATrustedPath holds CAs trusted alongside the active fab CA, e.g. previous one while it's being rotated
+	FabCATrustedPath = "/etc/ssl/certs/hh-fab-ca-trusted.pem"
 )
 
-func InstallFabCA(ca *CA) comp.KubeInstall {
+// InstallFabCA installs the CA used to issue all fabric certificates, trusted CAs are appended to the CA bundle
+// distributed to the nodes and switches, it's used to keep the previous CA trusted while rotating
+func InstallFabCA(ca *CA, trusted ...string) comp.KubeInstall {
+	return func(cfg fabapi.Fabricator) ([]kclient.Object, error) {
+		crts, err := SplitCABundle(ca.Crt)
+		if err != nil {
+			return nil, fmt.Errorf("splitting fab-ca cert: %w", err)
+		}
+
+		bundle, err := InstallFabCABundle(append(crts, trusted...)...)(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		issuer, err := installFabCAIssuer(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		secret, err := InstallFabCASecret(ca)(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return slices.Concat(secret, bundle, issuer), nil
+	}
+}
+
+// InstallFabCASecret only replaces the active fab CA used by the issuer
+func InstallFabCASecret(ca *CA) comp.KubeInstall {
 	return func(_ fabapi.Fabricator) ([]kclient.Object, error) {
 		return []kclient.Object{
 			comp.NewSecret(comp.FabCASecret, comp.SecretTypeOpaque, map[string]string{
 				"tls.crt": ca.Crt,
 				"tls.key": ca.Key,
 			}),
+		}, nil
+	}
+}
+
+func installFabCAIssuer(_ fabapi.Fabricator) ([]kclient.Object, error) {
+	return []kclient.Object{
+		comp.NewIssuer(comp.FabCAIssuer, comp.IssuerSpec{
+			IssuerConfig: comp.IssuerConfig{
+				CA: &comp.CAIssuer{
+					SecretName: comp.FabCASecret,
+				},
+			},
+		}),
+	}, nil
+}
+
+// ConfiguredFabCA returns externally provided fab CA or nil if the self-generated one should be used, the key is
+// taken from the key secret unless it's provided inline (only the case for the installer)
+func ConfiguredFabCA(ctx context.Context, kube kclient.Reader, cfg fabapi.Fabricator) (*CA, error) {
+	if !cfg.Spec.Config.Control.FabCA.IsExternal() {
+		return nil, nil
+	}
+
+	ca := &CA{
+		Crt: cfg.Spec.Config.Control.FabCA.Cert,
+		Key: cfg.Spec.Config.Control.FabCA.Key,
+	}
+	if ca.Key != "" {
+		return ca, nil
+	}
+
+	name := cfg.Spec.Config.Control.FabCA.KeySecret
+	secret := &coreapi.Secret{}
+	if err := kube.Get(ctx, kclient.ObjectKey{Name: name, Namespace: comp.FabNamespace}, secret); err != nil {
+		return nil, fmt.Errorf("getting external fab-ca key secret %q: %w", name, err)
+	}
+
+	ca.Key = string(secret.Data["tls.key"])
+	if ca.Key == "" {
+		return nil, fmt.Errorf("external fab-ca key secret %q missing tls.key", name) //nolint:goerr113
+	}
+
+	return ca, nil
+}
+
+// InstallFabCAKeySecret keeps the external fab CA key in the secret so it's not stored in the fabricator config
+func InstallFabCAKeySecret(ca *CA) comp.KubeInstall {
+	return func(_ fabapi.Fabricator) ([]kclient.Object, error) {
+		return []kclient.Object{
+			comp.NewSecret(comp.FabCAExternalSecret, comp.SecretTypeOpaque, map[string]string{
+				"tls.crt": ca.Crt,
+				"tls.key": ca.Key,
+			}),
+		}, nil
+	}
+}
+
+// ConfiguredTrustedCerts returns CAs that should be trusted in addition to the fab CA
+func ConfiguredTrustedCerts(cfg fabapi.Fabricator) ([]string, error) {
+	if cfg.Spec.Config.Control.FabCA == nil || cfg.Spec.Config.Control.FabCA.TrustedCerts == "" {
+		return nil, nil
+	}
+
+	crts, err := SplitCABundle(cfg.Spec.Config.Control.FabCA.TrustedCerts)
+	if err != nil {
+		return nil, fmt.Errorf("splitting fab-ca trusted certs: %w", err)
+	}
+
+	return crts, nil
+}
+
+// FabCAState is the fab CA rotation state, hhfabctl ca commands only change it (next and previous CA secrets) and
+// the CA bundle is always built from it by EnforceFabCA, so the controller never fights the rotation
+type FabCAState struct {
+	Current  *CA
+	Next     string
+	Previous string
+}
+
+func (s FabCAState) Equal(other FabCAState) bool {
+	return s.Current.Crt == other.Current.Crt && s.Current.Key == other.Current.Key &&
+		s.Next == other.Next && s.Previous == other.Previous
+}
+
+// IsRotating returns true if the new CA is pending or the previous one isn't retired yet
+func (s FabCAState) IsRotating() bool {
+	return s.Next != "" || s.Previous != ""
+}
+
+// Bundle returns all CAs to be trusted, active one is the first
+func (s FabCAState) Bundle(trusted ...string) ([]string, error) {
+	crts, err := SplitCABundle(s.Current.Crt)
+	if err != nil {
+		return nil, fmt.Errorf("splitting fab-ca cert: %w", err)
+	}
+
+	return slices.Concat(crts, []string{s.Next, s.Previous}, trusted), nil
+}
+
+func GetFabCAState(ctx context.Context, kube kclient.Reader) (FabCAState, error) {
+	res := FabCAState{}
+
+	secret := &coreapi.Secret{}
+	if err := kube.Get(ctx, kclient.ObjectKey{Name: comp.FabCASecret, Namespace: comp.FabNamespace}, secret); err != nil {
+		return res, fmt.Errorf("getting fab-ca secret: %w", err)
+	}
+
+	res.Current = &CA{
+		Crt: string(secret.Data["tls.crt"]),
+		Key: string(secret.Data["tls.key"]),
+	}
+	if res.Current.Crt == "" || res.Current.Key == "" {
+		return res, fmt.Errorf("fab-ca secret missing cert or key") //nolint:goerr113
+	}
+
+	for name, crt := range map[string]*string{
+		comp.FabCANextSecret:     &res.Next,
+		comp.FabCAPreviousSecret: &res.Previous,
+	} {
+		secret := &coreapi.Secret{}
+		if err := kube.Get(ctx, kclient.ObjectKey{Name: name, Namespace: comp.FabNamespace}, secret); err == nil {
+			*crt = string(secret.Data["tls.crt"])
+		} else if !kapierrors.IsNotFound(err) {
+			return res, fmt.Errorf("getting %s secret: %w", name, err)
+		}
+	}
+
+	return res, nil
+}
+
+// EnforceFabCA makes sure that the fab CA issuer and the CA bundle match the config and the rotation state, if the
+// active CA is replaced by the external one, previous CA is kept trusted until it's retired (same as during the
+// rotation), all certificates not issued by the active CA yet are re-issued
+func EnforceFabCA(ctx context.Context, kube kclient.Client, cfg fabapi.Fabricator) error {
+	external, err := ConfiguredFabCA(ctx, kube, cfg)
+	if err != nil {
+		return err
+	}
+
+	trusted, err := ConfiguredTrustedCerts(cfg)
+	if err != nil {
+		return err
+	}
+
+	// state could be changed by hhfabctl ca while we're enforcing it, so repeat until it's stable
+	for attempt := 0; ; attempt++ {
+		if attempt >= 5 {
+			return fmt.Errorf("fab-ca state keeps changing") //nolint:goerr113
+		}
+
+		state, err := GetFabCAState(ctx, kube)
+		if err != nil {
+			return err
+		}
+
+		if external != nil && strings.TrimSpace(external.Crt) != strings.TrimSpace(state.Current.Crt) {
+			if state.IsRotating() {
+				return fmt.Errorf("can't switch to external fab-ca while rotation is in progress, retire previous fab-ca first") //nolint:goerr113
+			}
+
+			if err := comp.EnforceKubeInstall(ctx, kube, cfg, InstallFabCAPrevious(state.Current.Crt)); err != nil {
+				return fmt.Errorf("saving previous fab-ca: %w", err)
+			}
+			if err := comp.EnforceKubeInstall(ctx, kube, cfg, InstallFabCA(external, slices.Concat([]string{state.Current.Crt}, trusted)...)); err != nil {
+				return fmt.Errorf("enforcing external fab-ca: %w", err)
+			}
+
+			continue
+		}
+
+		bundle, err := state.Bundle(trusted...)
+		if err != nil {
+			return err
+		}
+
+		if err := comp.EnforceKubeInstall(ctx, kube, cfg, InstallFabCABundle(bundle...), installFabCAIssuer); err != nil {
+			return fmt.Errorf("enforcing fab-ca bundle: %w", err)
+		}
+
+		after, err := GetFabCAState(ctx, kube)
+		if err != nil {
+			return err
+		}
+		if !after.Equal(state) {
+			continue
+		}
+
+		if _, err := RenewFabCACertificates(ctx, kube, state.Current.Crt); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// InstallFabCABundle only updates the CA bundle trusted by the nodes and switches, first CA is the active one
+func InstallFabCABundle(crts ...string) comp.KubeInstall {
+	return func(_ fabapi.Fabricator) ([]kclient.Object, error) {
+		if len(crts) == 0 {
+			return nil, fmt.Errorf("empty fab-ca bundle") //nolint:goerr113
+		}
+
+		return []kclient.Object{
 			comp.NewConfigMap(comp.FabCAConfigMap, map[string]string{
-				comp.FabCAConfigMapKey: ca.Crt, // changing key will break fabric manager
+				comp.FabCAConfigMapKey: NewCABundle(crts...), // changing key will break fabric manager
 			}),
-			comp.NewIssuer(comp.FabCAIssuer, comp.IssuerSpec{
-				IssuerConfig: comp.IssuerConfig{
-					CA: &comp.CAIssuer{
-						SecretName: comp.FabCASecret,
-					},
-				},
+		}, nil
+	}
+}
+
+// InstallFabCAPrevious keeps the previous fab CA certificate while rotation is in progress
+func InstallFabCAPrevious(crt string) comp.KubeInstall {
+	return func(_ fabapi.Fabricator) ([]kclient.Object, error) {
+		return []kclient.Object{
+			comp.NewSecret(comp.FabCAPreviousSecret, comp.SecretTypeOpaque, map[string]string{
+				"tls.crt": crt,
+			}),
+		}, nil
+	}
+}
+
+// InstallFabCANext keeps the new fab CA while it's distributed to the nodes and switches before it's used to issue
+func InstallFabCANext(ca *CA) comp.KubeInstall {
+	return func(_ fabapi.Fabricator) ([]kclient.Object, error) {
+		return []kclient.Object{
+			comp.NewSecret(comp.FabCANextSecret, comp.SecretTypeOpaque, map[string]string{
+				"tls.crt": ca.Crt,
+				"tls.key": ca.Key,
 			}),
 		}, nil
 	}
 }
 
+// NewCABundle concatenates PEM encoded certificates skipping duplicates
+func NewCABundle(crts ...string) string {
+	res := []string{}
+	for _, crt := range crts {
+		crt = strings.TrimSpace(crt)
+		if crt == "" || slices.Contains(res, crt) {
+			continue
+		}
+		res = append(res, crt)
+	}
+
+	return strings.Join(res, "\n") + "\n"
+}
+
+// SplitCABundle splits PEM encoded bundle into separate PEM encoded certificates preserving the order
+func SplitCABundle(bundle string) ([]string, error) {
+	res := []string{}
+
+	rest := []byte(bundle)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != BlockTypeCert {
+			return nil, fmt.Errorf("unexpected PEM block type %q", block.Type) //nolint:goerr113
+		}
+
+		res = append(res, string(pem.EncodeToMemory(block)))
+	}
+
+	if strings.TrimSpace(string(rest)) != "" {
+		return nil, fmt.Errorf("trailing data after PEM blocks") //nolint:goerr113
+	}
+
+	return res, nil
+}
+
+// ParseCertificates parses all certificates from the PEM encoded bundle preserving the order
+func ParseCertificates(bundle string) ([]*x509.Certificate, error) {
+	crts, err := SplitCABundle(bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []*x509.Certificate{}
+	for _, crt := range crts {
+		block, _ := pem.Decode([]byte(crt))
+		parsed, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing certificate: %w", err)
+		}
+		res = append(res, parsed)
+	}
+
+	return res, nil
+}
+
+func containsCertificate(crts []*x509.Certificate, crt *x509.Certificate) bool {
+	return slices.ContainsFunc(crts, crt.Equal)
+}
+
+// IsIssuedBy checks that the first certificate in the PEM encoded chain is signed by the PEM encoded CA
+func IsIssuedBy(chain, ca string) (bool, error) {
+	crts, err := ParseCertificates(chain)
+	if err != nil {
+		return false, fmt.Errorf("parsing chain: %w", err)
+	}
+	if len(crts) == 0 {
+		return false, fmt.Errorf("empty chain") //nolint:goerr113
+	}
+
+	cas, err := ParseCertificates(ca)
+	if err != nil {
+		return false, fmt.Errorf("parsing ca: %w", err)
+	}
+	if len(cas) == 0 {
+		return false, fmt.Errorf("empty ca") //nolint:goerr113
+	}
+
+	return crts[0].CheckSignatureFrom(cas[0]) == nil, nil
+}
+
+// IsFabCAIssued returns true if the Certificate is issued by the fab CA issuer
+func IsFabCAIssued(cert cmapi.Certificate) bool {
+	return cert.Spec.IssuerRef.Name == comp.FabCAIssuer &&
+		(cert.Spec.IssuerRef.Kind == "" || cert.Spec.IssuerRef.Kind == cmapi.IssuerKind)
+}
+
+func ListFabCACertificates(ctx context.Context, kube kclient.Reader) ([]cmapi.Certificate, error) {
+	certs := &cmapi.CertificateList{}
+	if err := kube.List(ctx, certs, kclient.InNamespace(comp.FabNamespace)); err != nil {
+		return nil, fmt.Errorf("listing certificates: %w", err)
+	}
+
+	res := []cmapi.Certificate{}
+	for _, cert := range certs.Items {
+		if IsFabCAIssued(cert) {
+			res = append(res, cert)
+		}
+	}
+
+	return res, nil
+}
+
+// IsCertificateIssuedBy returns false if the Certificate isn't issued yet or its certificate isn't signed by the CA
+func IsCertificateIssuedBy(ctx context.Context, kube kclient.Reader, cert cmapi.Certificate, ca string) (bool, error) {
+	secret := &coreapi.Secret{}
+	if err := kube.Get(ctx, kclient.ObjectKey{Name: cert.Spec.SecretName, Namespace: cert.Namespace}, secret); err != nil {
+		if kapierrors.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("getting certificate secret: %w", err)
+	}
+
+	chain := string(secret.Data["tls.crt"])
+	if chain == "" {
+		return false, nil
+	}
+
+	return IsIssuedBy(chain, ca)
+}
+
+// RenewFabCACertificates triggers re-issuance (same way as cmctl renew does) of all certificates issued by the fab CA
+// issuer that aren't issued by the CA yet, it returns the list of certificates that aren't issued by it yet
+func RenewFabCACertificates(ctx context.Context, kube kclient.Client, ca string) ([]cmapi.Certificate, error) {
+	certs, err := ListFabCACertificates(ctx, kube)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []cmapi.Certificate{}
+	for _, cert := range certs {
+		ok, err := IsCertificateIssuedBy(ctx, kube, cert, ca)
+		if err != nil {
+			return nil, fmt.Errorf("checking certificate %q: %w", cert.Name, err)
+		}
+		if ok {
+			continue
+		}
+
+		pending = append(pending, cert)
+
+		issuing := false
+		for _, cond := range cert.Status.Conditions {
+			if cond.Type == cmapi.CertificateConditionIssuing && cond.Status == cmmeta.ConditionTrue {
+				issuing = true
+			}
+		}
+		if issuing {
+			continue
+		}
+
+		slog.Info("Re-issuing certificate by the active fab CA", "name", cert.Name)
+
+		now := kmetav1.Now()
+		cond := cmapi.CertificateCondition{
+			Type:               cmapi.CertificateConditionIssuing,
+			Status:             cmmeta.ConditionTrue,
+			Reason:             "ManuallyTriggered",
+			Message:            "Certificate re-issuance triggered by fab CA change",
+			LastTransitionTime: &now,
+			ObservedGeneration: cert.Generation,
+		}
+
+		found := false
+		for idx := range cert.Status.Conditions {
+			if cert.Status.Conditions[idx].Type == cmapi.CertificateConditionIssuing {
+				cert.Status.Conditions[idx] = cond
+				found = true
+			}
+		}
+		if !found {
+			cert.Status.Conditions = append(cert.Status.Conditions, cond)
+		}
+
+		if err := kube.Status().Update(ctx, &cert); err != nil {
+			return nil, fmt.Errorf("triggering certificate %q re-issuance: %w", cert.Name, err)
+		}
+	}
+
+	return pending, nil
+}
+
+// StatusCertificates reports expiration of the trusted fab CAs and of the certificates issued by the fab CA, CAs in the
+// bundle are classified using the rotation state and the rest of them are the additionally trusted ones
+func StatusCertificates(ctx context.Context, kube kclient.Reader, cfg fabapi.Fabricator) (fabapi.CertificatesStatus, error) {
+	res := fabapi.CertificatesStatus{}
+
+	caCM := &coreapi.ConfigMap{}
+	if err := kube.Get(ctx, kclient.ObjectKey{Name: comp.FabCAConfigMap, Namespace: comp.FabNamespace}, caCM); err != nil {
+		if !kapierrors.IsNotFound(err) {
+			return res, fmt.Errorf("getting fab-ca config map: %w", err)
+		}
+	} else {
+		cas, err := ParseCertificates(caCM.Data[comp.FabCAConfigMapKey])
+		if err != nil {
+			return res, fmt.Errorf("parsing fab-ca bundle: %w", err)
+		}
+
+		state, err := GetFabCAState(ctx, kube)
+		if err != nil {
+			return res, err
+		}
+
+		current, err := ParseCertificates(state.Current.Crt)
+		if err != nil {
+			return res, fmt.Errorf("parsing fab-ca cert: %w", err)
+		}
+		next, err := ParseCertificates(state.Next)
+		if err != nil {
+			return res, fmt.Errorf("parsing next fab-ca cert: %w", err)
+		}
+		previous, err := ParseCertificates(state.Previous)
+		if err != nil {
+			return res, fmt.Errorf("parsing previous fab-ca cert: %w", err)
+		}
+
+		for _, ca := range cas {
+			notAfter := kmetav1.NewTime(ca.NotAfter)
+
+			switch {
+			case containsCertificate(current, ca):
+				res.FabCA = append(res.FabCA, notAfter)
+			case containsCertificate(next, ca):
+				res.NextFabCA = &notAfter
+			case containsCertificate(previous, ca):
+				res.PreviousFabCA = &notAfter
+			default:
+				res.TrustedCA = append(res.TrustedCA, notAfter)
+			}
+		}
+	}
+
+	certs := &cmapi.CertificateList{}
+	if err := kube.List(ctx, certs, kclient.InNamespace(comp.FabNamespace)); err != nil {
+		return res, fmt.Errorf("listing certificates: %w", err)
+	}
+
+	issuerRef := comp.FabIssuerRef(cfg)
+	for _, cert := range certs.Items {
+		if cert.Status.NotAfter == nil {
+			continue
+		}
+		if !IsFabCAIssued(cert) && (cert.Spec.IssuerRef.Name != issuerRef.Name || cert.Spec.IssuerRef.Kind != issuerRef.Kind) {
+			continue
+		}
+
+		if res.Issued == nil {
+			res.Issued = map[string]kmetav1.Time{}
+		}
+		res.Issued[cert.Name] = *cert.Status.NotAfter
+	}
+
+	return res, nil
+}
+
 type CA struct {
 	Crt string `json:"crt,omitempty"`
 	Key string `json:"key,omitempty"`
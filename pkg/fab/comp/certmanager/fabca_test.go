@@ -0,0 +1,282 @@
+// Copyright 2026 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package certmanager
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	cryptorand "crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/stretchr/testify/require"
+	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
+	"go.githedgehog.com/fabricator/pkg/fab/comp"
+	coreapi "k8s.io/api/core/v1"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// mergeStringData does the same as the API server for secrets created or updated using StringData
+func mergeStringData(obj kclient.Object) {
+	secret, ok := obj.(*coreapi.Secret)
+	if !ok || secret.StringData == nil {
+		return
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	for k, v := range secret.StringData {
+		secret.Data[k] = []byte(v)
+	}
+	secret.StringData = nil
+}
+
+func issueTestLeaf(t *testing.T, ca *CA) string {
+	t.Helper()
+
+	caBlock, _ := pem.Decode([]byte(ca.Crt))
+	require.NotNil(t, caBlock)
+	caCrt, err := x509.ParseCertificate(caBlock.Bytes)
+	require.NoError(t, err)
+
+	keyBlock, _ := pem.Decode([]byte(ca.Key))
+	require.NotNil(t, keyBlock)
+	caKey, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), cryptorand.Reader)
+	require.NoError(t, err)
+
+	leaf := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "leaf"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(cryptorand.Reader, leaf, caCrt, &key.PublicKey, caKey)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: BlockTypeCert, Bytes: der}))
+}
+
+func TestNewCABundle(t *testing.T) {
+	first, err := NewFabCA()
+	require.NoError(t, err)
+	second, err := NewFabCA()
+	require.NoError(t, err)
+
+	bundle := NewCABundle(first.Crt, "", "  \n", second.Crt, "\n"+first.Crt+"\n")
+	require.Equal(t, strings.TrimSpace(first.Crt)+"\n"+strings.TrimSpace(second.Crt)+"\n", bundle)
+
+	crts, err := SplitCABundle(bundle)
+	require.NoError(t, err)
+	require.Equal(t, []string{first.Crt, second.Crt}, crts)
+
+	require.Equal(t, "\n", NewCABundle())
+}
+
+func TestSplitCABundle(t *testing.T) {
+	first, err := NewFabCA()
+	require.NoError(t, err)
+	second, err := NewFabCA()
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name   string
+		bundle string
+		want   []string
+		err    bool
+	}{
+		{name: "empty", bundle: "", want: []string{}},
+		{name: "single", bundle: first.Crt, want: []string{first.Crt}},
+		{name: "order-preserved", bundle: second.Crt + first.Crt, want: []string{second.Crt, first.Crt}},
+		{name: "whitespace", bundle: "\n" + first.Crt + "\n\n" + second.Crt + "\n", want: []string{first.Crt, second.Crt}},
+		{name: "key-block", bundle: first.Crt + first.Key, err: true},
+		{name: "trailing-data", bundle: first.Crt + "garbage", err: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			crts, err := SplitCABundle(tt.bundle)
+			if tt.err {
+				require.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, crts)
+		})
+	}
+}
+
+func TestIsIssuedBy(t *testing.T) {
+	ca, err := NewFabCA()
+	require.NoError(t, err)
+	other, err := NewFabCA()
+	require.NoError(t, err)
+
+	leaf := issueTestLeaf(t, ca)
+
+	ok, err := IsIssuedBy(leaf, ca.Crt)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = IsIssuedBy(leaf, other.Crt)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// only the first cert of the chain and the first (active) CA of the bundle matter
+	ok, err = IsIssuedBy(leaf+ca.Crt, NewCABundle(ca.Crt, other.Crt))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = IsIssuedBy(leaf, NewCABundle(other.Crt, ca.Crt))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = IsIssuedBy("", ca.Crt)
+	require.Error(t, err)
+
+	_, err = IsIssuedBy(leaf, "")
+	require.Error(t, err)
+
+	_, err = IsIssuedBy("garbage", ca.Crt)
+	require.Error(t, err)
+}
+
+func TestEnforceFabCARotation(t *testing.T) {
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, coreapi.AddToScheme(scheme))
+	require.NoError(t, cmapi.AddToScheme(scheme))
+	require.NoError(t, fabapi.AddToScheme(scheme))
+
+	current, err := NewFabCA()
+	require.NoError(t, err)
+	next, err := NewFabCA()
+	require.NoError(t, err)
+	external, err := NewFabCA()
+	require.NoError(t, err)
+
+	cert := &cmapi.Certificate{
+		ObjectMeta: kmetav1.ObjectMeta{Name: "leaf", Namespace: comp.FabNamespace},
+		Spec: cmapi.CertificateSpec{
+			SecretName: "leaf-tls",
+			IssuerRef:  cmmeta.IssuerReference{Name: comp.FabCAIssuer},
+		},
+	}
+	objs := []kclient.Object{
+		comp.NewSecret(comp.FabCASecret, comp.SecretTypeOpaque, map[string]string{"tls.crt": current.Crt, "tls.key": current.Key}),
+		comp.NewSecret("leaf-tls", comp.SecretTypeOpaque, map[string]string{"tls.crt": issueTestLeaf(t, current)}),
+		cert,
+	}
+	for _, obj := range objs {
+		mergeStringData(obj)
+	}
+	kube := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(cert).WithObjects(objs...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, kube kclient.WithWatch, obj kclient.Object, opts ...kclient.CreateOption) error {
+				mergeStringData(obj)
+
+				return kube.Create(ctx, obj, opts...) //nolint:wrapcheck
+			},
+			Update: func(ctx context.Context, kube kclient.WithWatch, obj kclient.Object, opts ...kclient.UpdateOption) error {
+				mergeStringData(obj)
+
+				return kube.Update(ctx, obj, opts...) //nolint:wrapcheck
+			},
+		}).Build()
+
+	cfg := fabapi.Fabricator{}
+	bundle := func() string {
+		cm := &coreapi.ConfigMap{}
+		require.NoError(t, kube.Get(ctx, kclient.ObjectKey{Name: comp.FabCAConfigMap, Namespace: comp.FabNamespace}, cm))
+
+		return cm.Data[comp.FabCAConfigMapKey]
+	}
+	isIssuing := func() bool {
+		cert := &cmapi.Certificate{}
+		require.NoError(t, kube.Get(ctx, kclient.ObjectKey{Name: "leaf", Namespace: comp.FabNamespace}, cert))
+		for _, cond := range cert.Status.Conditions {
+			if cond.Type == cmapi.CertificateConditionIssuing && cond.Status == cmmeta.ConditionTrue {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	// new CA is pending, reconcile should trust it but keep issuing with the current one
+	require.NoError(t, comp.EnforceKubeInstall(ctx, kube, cfg, InstallFabCANext(next)))
+	require.NoError(t, EnforceFabCA(ctx, kube, cfg))
+	require.Equal(t, NewCABundle(current.Crt, next.Crt), bundle())
+	require.False(t, isIssuing(), "leaf issued by the active CA shouldn't be re-issued")
+	status, err := StatusCertificates(ctx, kube, cfg)
+	require.NoError(t, err)
+	require.Len(t, status.FabCA, 1)
+	require.NotNil(t, status.NextFabCA)
+	require.Nil(t, status.PreviousFabCA)
+	require.Empty(t, status.TrustedCA)
+
+	// switched to the new CA, reconcile should keep the previous one trusted
+	require.NoError(t, comp.EnforceKubeInstall(ctx, kube, cfg, InstallFabCAPrevious(current.Crt), InstallFabCASecret(next)))
+	require.NoError(t, comp.DeleteIfPresent(ctx, kube, &coreapi.Secret{ObjectMeta: kmetav1.ObjectMeta{Name: comp.FabCANextSecret, Namespace: comp.FabNamespace}}))
+	require.NoError(t, EnforceFabCA(ctx, kube, cfg))
+	require.Equal(t, NewCABundle(next.Crt, current.Crt), bundle())
+	require.True(t, isIssuing(), "leaf issued by the previous CA should be re-issued")
+	status, err = StatusCertificates(ctx, kube, cfg)
+	require.NoError(t, err)
+	require.Len(t, status.FabCA, 1)
+	require.Nil(t, status.NextFabCA)
+	require.NotNil(t, status.PreviousFabCA)
+
+	// retired, reconcile shouldn't bring the previous one back
+	require.NoError(t, comp.DeleteIfPresent(ctx, kube, &coreapi.Secret{ObjectMeta: kmetav1.ObjectMeta{Name: comp.FabCAPreviousSecret, Namespace: comp.FabNamespace}}))
+	require.NoError(t, EnforceFabCA(ctx, kube, cfg))
+	require.Equal(t, NewCABundle(next.Crt), bundle())
+
+	// additionally trusted CAs aren't reported as the fab CA or its rotation
+	trustedCfg := *cfg.DeepCopy()
+	trustedCfg.Spec.Config.Control.FabCA = &fabapi.FabCAConfig{TrustedCerts: current.Crt}
+	require.NoError(t, EnforceFabCA(ctx, kube, trustedCfg))
+	require.Equal(t, NewCABundle(next.Crt, current.Crt), bundle())
+	status, err = StatusCertificates(ctx, kube, trustedCfg)
+	require.NoError(t, err)
+	require.Len(t, status.FabCA, 1)
+	require.Nil(t, status.PreviousFabCA)
+	require.Len(t, status.TrustedCA, 1)
+	require.NoError(t, EnforceFabCA(ctx, kube, cfg))
+	require.Equal(t, NewCABundle(next.Crt), bundle())
+
+	// switched to the external CA with the key in the secret, certificates should be re-issued
+	require.NoError(t, comp.EnforceKubeInstall(ctx, kube, cfg, InstallFabCAKeySecret(external)))
+	cfg.Spec.Config.Control.FabCA = &fabapi.FabCAConfig{Cert: external.Crt, KeySecret: comp.FabCAExternalSecret}
+	cert = &cmapi.Certificate{}
+	require.NoError(t, kube.Get(ctx, kclient.ObjectKey{Name: "leaf", Namespace: comp.FabNamespace}, cert))
+	cert.Status.Conditions = nil
+	require.NoError(t, kube.Status().Update(ctx, cert))
+
+	require.NoError(t, EnforceFabCA(ctx, kube, cfg))
+	require.Equal(t, NewCABundle(external.Crt, next.Crt), bundle())
+	state, err := GetFabCAState(ctx, kube)
+	require.NoError(t, err)
+	require.Equal(t, external.Key, state.Current.Key)
+	require.Equal(t, next.Crt, state.Previous)
+	require.True(t, isIssuing())
+
+	cfg.Spec.Config.Control.FabCA = &fabapi.FabCAConfig{Cert: current.Crt, Key: current.Key}
+	require.Error(t, EnforceFabCA(ctx, kube, cfg), "switching external CA during rotation should fail")
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/samber/lo"
 	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
 	"go.githedgehog.com/fabricator/pkg/fab/comp"
+	"go.githedgehog.com/fabricator/pkg/fab/comp/monitoring"
 	"go.githedgehog.com/fabricator/pkg/util/tmplutil"
 	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -33,8 +34,10 @@ func Install(cfg fabapi.Fabricator) ([]kclient.Object, error) {
 		return nil, fmt.Errorf("getting image URL for %q: %w", ImageRef, err)
 	}
 
+	targets := monitoring.Targets(cfg)
+
 	urls := []string{}
-	for _, val := range cfg.Spec.Config.Observability.Targets.Prometheus {
+	for _, val := range targets.Prometheus {
 		u, err := url.Parse(val.URL)
 		if err != nil {
 			return nil, fmt.Errorf("url parsing prometheus target failed: %w", err)
@@ -43,7 +46,7 @@ func Install(cfg fabapi.Fabricator) ([]kclient.Object, error) {
 			urls = append(urls, hostname)
 		}
 	}
-	for _, val := range cfg.Spec.Config.Observability.Targets.Loki {
+	for _, val := range targets.Loki {
 		u, err := url.Parse(val.URL)
 		if err != nil {
 			return nil, fmt.Errorf("url parsing loki target failed: %w", err)
@@ -52,7 +55,7 @@ func Install(cfg fabapi.Fabricator) ([]kclient.Object, error) {
 			urls = append(urls, hostname)
 		}
 	}
-	for _, val := range cfg.Spec.Config.Observability.Targets.Pyroscope {
+	for _, val := range targets.Pyroscope {
 		u, err := url.Parse(val.URL)
 		if err != nil {
 			return nil, fmt.Errorf("url parsing pyroscope target failed: %w", err)
@@ -25,6 +25,9 @@ const (
 	BinDir         = "/opt/bin"
 	CtlBinName     = "hhfabctl"
 	CtlDestBinName = "kubectl-hhfab"
+
+	CtrlMetricsService = "fabricator-ctrl-metrics-service"
+	CtrlMetricsPort    = 8080
 )
 
 //go:embed values.tmpl.yaml
@@ -20,6 +20,7 @@ import (
 	"go.githedgehog.com/fabricator/pkg/fab/comp/flatcar"
 	"go.githedgehog.com/fabricator/pkg/fab/comp/gateway"
 	"go.githedgehog.com/fabricator/pkg/fab/comp/k3s"
+	"go.githedgehog.com/fabricator/pkg/fab/comp/monitoring"
 	"go.githedgehog.com/fabricator/pkg/util/tmplutil"
 	corev1 "k8s.io/api/core/v1"
 	kclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -274,7 +275,7 @@ func GetFabricConfig(f fabapi.Fabricator) (*fmeta.FabricConfig, error) {
 		ESLAGESIPrefix:           f.Spec.Config.Fabric.ESLAGESIPrefix,
 		AlloyRepo:                comp.JoinURLParts(registry, comp.RegistryPrefix, alloy.BinRef),
 		AlloyVersion:             string(alloy.Version(f)),
-		AlloyTargets:             f.Spec.Config.Observability.Targets,
+		AlloyTargets:             monitoring.Targets(f),
 		Observability:            observability,
 		ControlProxyURL:          fmt.Sprintf("http://%s:%d", controlVIP.Addr().String(), controlproxy.NodePort),
 		DefaultMaxPathsEBGP:      64,
@@ -29,6 +29,7 @@ const (
 	ServerServiceName  = "k3s.service"
 	AgentServiceName   = "k3s-agent.service"
 	APIPort            = 6443
+	ContainerdLogDir   = "/var/lib/rancher/k3s/agent/containerd"
 	ConfigDir          = "/etc/rancher/k3s"
 	ConfigPath         = "/etc/rancher/k3s/config.yaml"
 	KubeConfigPath     = "/etc/rancher/k3s/k3s.yaml"
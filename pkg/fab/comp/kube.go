@@ -36,8 +36,13 @@ const (
 	FabCASecret                = FabCAIssuer
 	FabCAConfigMap             = FabCAIssuer // changing name will break fabric manager
 	FabCAConfigMapKey          = "ca.crt"
+	FabCAPreviousSecret        = FabCAIssuer + "-previous"
+	FabCANextSecret            = FabCAIssuer + "-next"
+	FabCAExternalSecret        = FabCAIssuer + "-external"
 	FabNodeRegistriesSecret    = "fab-node-registries" //nolint:gosec
 	FabNodeRegistriesSecretKey = "registries.yaml"     //nolint:gosec
+	// RestartedAtAnnotation is set on the pod template by the rollout restart, it's kept on update
+	RestartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
 
 	RegistryUserAdmin              = "admin"
 	RegistryUserWriter             = "writer"
@@ -67,6 +72,8 @@ type (
 	Node                  = coreapi.Node
 	Deployment            = appsapi.Deployment
 	Issuer                = cmapi.Issuer
+	ClusterIssuer         = cmapi.ClusterIssuer
+	IssuerCondition       = cmapi.IssuerCondition
 	SecretType            = coreapi.SecretType
 )
 
@@ -243,6 +250,24 @@ func NewIssuerRef(name string) CMObjectReference {
 	}
 }
 
+// FabIssuerRef returns issuer for the certificates managed by fabricator, it's fab-ca unless external one configured
+func FabIssuerRef(cfg fabapi.Fabricator) CMObjectReference {
+	if cfg.Spec.Config.Control.FabCA != nil && cfg.Spec.Config.Control.FabCA.Issuer != nil {
+		kind := cfg.Spec.Config.Control.FabCA.Issuer.Kind
+		if kind == "" {
+			kind = fabapi.FabCAIssuerKindIssuer
+		}
+
+		return CMObjectReference{
+			Group: "cert-manager.io",
+			Kind:  kind,
+			Name:  cfg.Spec.Config.Control.FabCA.Issuer.Name,
+		}
+	}
+
+	return NewIssuerRef(FabCAIssuer)
+}
+
 func NewNamespace(name string) kclient.Object {
 	return &coreapi.Namespace{
 		TypeMeta: kmetav1.TypeMeta{
@@ -385,6 +410,15 @@ func CreateOrUpdate(ctx context.Context, kube kclient.Client, obj kclient.Object
 	case *appsapi.DaemonSet:
 		tmp := &appsapi.DaemonSet{ObjectMeta: obj.ObjectMeta}
 		res, err = ctrlutil.CreateOrUpdate(ctx, kube, tmp, func() error {
+			// keep rollout restart (e.g. after fab CA bundle change) instead of restarting pods once again
+			if restartedAt, ok := tmp.Spec.Template.Annotations[RestartedAtAnnotation]; ok {
+				obj = obj.DeepCopy()
+				if obj.Spec.Template.Annotations == nil {
+					obj.Spec.Template.Annotations = map[string]string{}
+				}
+				obj.Spec.Template.Annotations[RestartedAtAnnotation] = restartedAt
+			}
+
 			tmp.Spec = obj.Spec
 
 			return nil
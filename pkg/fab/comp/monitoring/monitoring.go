@@ -0,0 +1,232 @@
+// Copyright 2026 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package monitoring
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"maps"
+	"net"
+	"path"
+	"strconv"
+
+	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
+	"go.githedgehog.com/fabricator/api/meta"
+	"go.githedgehog.com/fabricator/pkg/fab/comp"
+	"go.githedgehog.com/fabricator/pkg/util/tmplutil"
+	"go.githedgehog.com/libmeta/pkg/alloy"
+	corev1 "k8s.io/api/core/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+	kyaml "sigs.k8s.io/yaml"
+)
+
+const (
+	ChartRef      = "fabricator/charts/monitoring"
+	PrometheusRef = "fabricator/prometheus"
+	LokiRef       = "fabricator/loki"
+	GrafanaRef    = "fabricator/grafana"
+
+	ReleaseName         = "monitoring"
+	PrometheusService   = ReleaseName + "-prometheus"
+	LokiService         = ReleaseName + "-loki"
+	GrafanaService      = ReleaseName + "-grafana"
+	DashboardsConfigMap = ReleaseName + "-dashboards"
+	GrafanaAdminKey     = "admin-password"
+
+	PrometheusPort  = 9090
+	LokiPort        = 3100
+	GrafanaPort     = 3000
+	GrafanaNodePort = 31300
+
+	// TargetName is the name of the on-prem stack in the Alloy targets
+	TargetName = "onprem"
+)
+
+//go:embed values.tmpl.yaml
+var valuesTmpl string
+
+//go:embed dashboards/*.json
+var dashboards embed.FS
+
+func Enabled(cfg fabapi.Fabricator) bool {
+	return cfg.Spec.Config.Observability.Stack.Enable
+}
+
+var _ comp.KubeInstall = Install
+
+func Install(cfg fabapi.Fabricator) ([]kclient.Object, error) {
+	if !Enabled(cfg) {
+		return nil, nil
+	}
+
+	stack := cfg.Spec.Config.Observability.Stack
+
+	prometheusRepo, err := comp.ImageURL(cfg, PrometheusRef)
+	if err != nil {
+		return nil, fmt.Errorf("getting image URL for %q: %w", PrometheusRef, err)
+	}
+	lokiRepo, err := comp.ImageURL(cfg, LokiRef)
+	if err != nil {
+		return nil, fmt.Errorf("getting image URL for %q: %w", LokiRef, err)
+	}
+	grafanaRepo, err := comp.ImageURL(cfg, GrafanaRef)
+	if err != nil {
+		return nil, fmt.Errorf("getting image URL for %q: %w", GrafanaRef, err)
+	}
+
+	tolerations := []corev1.Toleration{}
+	for _, role := range fabapi.NodeRoles {
+		tolerations = append(tolerations, corev1.Toleration{
+			Key:      fabapi.RoleTaintKey(role),
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoExecute,
+		})
+	}
+	tolerationsData, err := kyaml.Marshal(tolerations)
+	if err != nil {
+		return nil, fmt.Errorf("tolerations: %w", err)
+	}
+
+	values, err := tmplutil.FromTemplate("values", valuesTmpl, map[string]any{
+		"RetentionDays":       stack.RetentionDays,
+		"StorageSize":         stack.StorageSize,
+		"Node":                stack.Node,
+		"Tolerations":         string(tolerationsData),
+		"PrometheusRepo":      prometheusRepo,
+		"PrometheusTag":       string(cfg.Status.Versions.Platform.Prometheus),
+		"PrometheusPort":      PrometheusPort,
+		"LokiRepo":            lokiRepo,
+		"LokiTag":             string(cfg.Status.Versions.Platform.Loki),
+		"LokiPort":            LokiPort,
+		"GrafanaRepo":         grafanaRepo,
+		"GrafanaTag":          string(cfg.Status.Versions.Platform.Grafana),
+		"GrafanaPort":         GrafanaPort,
+		"GrafanaNodePort":     GrafanaNodePort,
+		"GrafanaAdminSecret":  stack.GrafanaAdminSecret,
+		"GrafanaAdminKey":     GrafanaAdminKey,
+		"GrafanaAnonymous":    stack.GrafanaAnonymous,
+		"DashboardsConfigMap": DashboardsConfigMap,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("values: %w", err)
+	}
+
+	chart, err := comp.NewHelmChart(cfg, ReleaseName, ChartRef, string(cfg.Status.Versions.Platform.MonitoringChart), "", false, values)
+	if err != nil {
+		return nil, fmt.Errorf("chart: %w", err)
+	}
+
+	files, err := dashboards.ReadDir("dashboards")
+	if err != nil {
+		return nil, fmt.Errorf("reading dashboards: %w", err)
+	}
+
+	dashboardsData := map[string]string{}
+	for _, file := range files {
+		data, err := dashboards.ReadFile(path.Join("dashboards", file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading dashboard %q: %w", file.Name(), err)
+		}
+		dashboardsData[file.Name()] = string(data)
+	}
+
+	return []kclient.Object{
+		comp.NewConfigMap(DashboardsConfigMap, dashboardsData),
+		chart,
+	}, nil
+}
+
+// Uninstall removes the stack if it was disabled, persistent volumes are removed with the chart
+func Uninstall(ctx context.Context, kube kclient.Client) error {
+	if err := comp.DeleteHelmChartIfPresent(ctx, kube, ReleaseName); err != nil {
+		return fmt.Errorf("deleting chart: %w", err)
+	}
+
+	if err := comp.DeleteIfPresent(ctx, kube, comp.NewConfigMap(DashboardsConfigMap, nil)); err != nil {
+		return fmt.Errorf("deleting dashboards: %w", err)
+	}
+
+	return nil
+}
+
+// Targets returns the configured Alloy targets with the on-prem stack added if enabled
+func Targets(cfg fabapi.Fabricator) alloy.Targets {
+	targets := *cfg.Spec.Config.Observability.Targets.DeepCopy()
+	if !Enabled(cfg) {
+		return targets
+	}
+
+	labels := maps.Clone(cfg.Spec.Config.Observability.Labels)
+
+	targets.Prometheus = maps.Clone(targets.Prometheus)
+	if targets.Prometheus == nil {
+		targets.Prometheus = map[string]alloy.PrometheusTarget{}
+	}
+	targets.Prometheus[TargetName] = alloy.PrometheusTarget{
+		Target: alloy.Target{
+			URL:    fmt.Sprintf("http://%s/api/v1/write", serviceAddress(PrometheusService, PrometheusPort)),
+			Labels: labels,
+		},
+	}
+
+	targets.Loki = maps.Clone(targets.Loki)
+	if targets.Loki == nil {
+		targets.Loki = map[string]alloy.LokiTarget{}
+	}
+	targets.Loki[TargetName] = alloy.LokiTarget{
+		Target: alloy.Target{
+			URL:    fmt.Sprintf("http://%s/loki/api/v1/push", serviceAddress(LokiService, LokiPort)),
+			Labels: labels,
+		},
+	}
+
+	return targets
+}
+
+func serviceAddress(name string, port int) string {
+	return net.JoinHostPort(fmt.Sprintf("%s.%s.svc.%s", name, comp.FabNamespace, comp.ClusterDomain), strconv.Itoa(port))
+}
+
+var _ comp.ListOCIArtifacts = Artifacts
+
+func Artifacts(cfg fabapi.Fabricator) (comp.OCIArtifacts, error) {
+	if !Enabled(cfg) {
+		return comp.OCIArtifacts{}, nil
+	}
+
+	return comp.OCIArtifacts{
+		ChartRef:      cfg.Status.Versions.Platform.MonitoringChart,
+		PrometheusRef: cfg.Status.Versions.Platform.Prometheus,
+		LokiRef:       cfg.Status.Versions.Platform.Loki,
+		GrafanaRef:    cfg.Status.Versions.Platform.Grafana,
+	}, nil
+}
+
+var _ comp.KubeStatus = Status
+
+func Status(ctx context.Context, kube kclient.Reader, cfg fabapi.Fabricator) (fabapi.ComponentStatus, error) {
+	if !Enabled(cfg) {
+		return fabapi.CompStatusSkipped, nil
+	}
+
+	statuses := []comp.KubeStatus{}
+	for _, depl := range []struct {
+		name, container, ref string
+		version              meta.Version
+	}{
+		{PrometheusService, "prometheus", PrometheusRef, cfg.Status.Versions.Platform.Prometheus},
+		{LokiService, "loki", LokiRef, cfg.Status.Versions.Platform.Loki},
+		{GrafanaService, "grafana", GrafanaRef, cfg.Status.Versions.Platform.Grafana},
+	} {
+		ref, err := comp.ImageURL(cfg, depl.ref)
+		if err != nil {
+			return fabapi.CompStatusUnknown, fmt.Errorf("getting image URL for %q: %w", depl.ref, err)
+		}
+
+		statuses = append(statuses, comp.GetDeploymentStatus(depl.name, depl.container, ref+":"+string(depl.version)))
+	}
+
+	return comp.MergeKubeStatuses(ctx, kube, cfg, statuses...)
+}
@@ -128,7 +128,7 @@ func Install(cfg fabapi.Fabricator) ([]kclient.Object, error) {
 		comp.NewCertificate("registry", comp.CertificateSpec{
 			DNSNames:    []string{fmt.Sprintf("%s.%s.svc.%s", ServiceName, comp.FabNamespace, comp.ClusterDomain)},
 			IPAddresses: []string{controlVIP.Addr().String()},
-			IssuerRef:   comp.NewIssuerRef(comp.FabCAIssuer),
+			IssuerRef:   comp.FabIssuerRef(cfg),
 			SecretName:  TLSSecret,
 		}),
 		comp.NewSecret(UpstreamSecret, comp.SecretTypeOpaque, map[string]string{
@@ -0,0 +1,80 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package fab_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
+	"k8s.io/utils/ptr"
+)
+
+func TestDefaultControlObservability(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		defaults fabapi.ObservabilityDefaults
+		in       *fabapi.ControlObservability
+		check    func(t *testing.T, o11y *fabapi.ControlObservability)
+	}{
+		{
+			name: "unset",
+			check: func(t *testing.T, o11y *fabapi.ControlObservability) {
+				t.Helper()
+				require.Equal(t, ptr.To(true), o11y.KubePodLogs)
+				require.Equal(t, ptr.To(true), o11y.ContainerdLogs)
+				require.Equal(t, ptr.To(true), o11y.Unix.Metrics)
+				require.Equal(t, ptr.To(true), o11y.APIServer.Metrics)
+				require.Equal(t, uint(60), o11y.APIServer.MetricsInterval)
+			},
+		},
+		{
+			name: "explicit-false",
+			in: &fabapi.ControlObservability{
+				KubePodLogs: ptr.To(false),
+				KubeEvents:  ptr.To(false),
+				Unix: fabapi.ControlObservabilityUnix{
+					Metrics: ptr.To(false),
+				},
+				APIServer: fabapi.ControlObservabilityMetrics{
+					Metrics:         ptr.To(false),
+					MetricsInterval: 30,
+				},
+			},
+			check: func(t *testing.T, o11y *fabapi.ControlObservability) {
+				t.Helper()
+				require.Equal(t, ptr.To(false), o11y.KubePodLogs)
+				require.Equal(t, ptr.To(false), o11y.KubeEvents)
+				require.Equal(t, ptr.To(false), o11y.Unix.Metrics)
+				require.Equal(t, ptr.To(false), o11y.APIServer.Metrics)
+				require.Equal(t, uint(30), o11y.APIServer.MetricsInterval)
+				require.Equal(t, ptr.To(true), o11y.K3sLogs)
+				require.Equal(t, ptr.To(true), o11y.Etcd.Metrics)
+			},
+		},
+		{
+			name:     "none-explicit-true",
+			defaults: fabapi.ObservabilityDefaultsNone,
+			in: &fabapi.ControlObservability{
+				KubePodLogs: ptr.To(true),
+			},
+			check: func(t *testing.T, o11y *fabapi.ControlObservability) {
+				t.Helper()
+				require.Equal(t, ptr.To(true), o11y.KubePodLogs)
+				require.Equal(t, ptr.To(false), o11y.KubeEvents)
+				require.Equal(t, ptr.To(false), o11y.Fabric.Metrics)
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			f := fabapi.Fabricator{}
+			f.Spec.Config.Observability.Defaults = test.defaults
+			f.Spec.Config.Control.Observability = test.in
+			f.Default()
+
+			require.NotNil(t, f.Spec.Config.Control.Observability)
+			test.check(t, f.Spec.Config.Control.Observability)
+		})
+	}
+}
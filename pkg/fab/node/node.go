@@ -67,25 +67,13 @@ func enforceK3sConfigs(ctx context.Context) error {
 
 	restart := false
 
-	changed, err := enforceFile(certmanager.FabCAPath, []byte(ca), 0o644)
-	if err != nil {
+	// no restart required
+	// TODO validate that in case of changing CA in-place k3s would still not require restart
+	if err := EnforceFabCA(ctx, ca); err != nil {
 		return fmt.Errorf("enforcing CA: %w", err)
 	}
 
-	if changed {
-		slog.Info("FabCA updated", "path", certmanager.FabCAPath)
-
-		if err := updateCACertificates(ctx); err != nil {
-			return fmt.Errorf("updating CA certificates: %w", err)
-		}
-
-		// no restart required
-		// TODO validate that in case of changing CA in-place k3s would still not require restart
-	} else {
-		slog.Info("FabCA is up to date", "path", certmanager.FabCAPath)
-	}
-
-	changed, err = enforceFile(k3s.KubeRegistriesPath, []byte(registries), 0o600)
+	changed, err := enforceFile(k3s.KubeRegistriesPath, []byte(registries), 0o600)
 	if err != nil {
 		return fmt.Errorf("enforcing registries.yaml: %w", err)
 	}
@@ -130,6 +118,47 @@ func enforceK3sConfigs(ctx context.Context) error {
 	return nil
 }
 
+// EnforceFabCA makes sure that the active fab CA (first one in the bundle) and the rest of the trusted CAs (e.g.
+// previous one during the rotation) are installed into the system trust store
+func EnforceFabCA(ctx context.Context, bundle string) error {
+	cas, err := certmanager.SplitCABundle(bundle)
+	if err != nil {
+		return fmt.Errorf("splitting CA bundle: %w", err)
+	}
+	if len(cas) == 0 {
+		return fmt.Errorf("empty CA bundle") //nolint:goerr113
+	}
+
+	changed, err := enforceFile(certmanager.FabCAPath, []byte(cas[0]), 0o644)
+	if err != nil {
+		return fmt.Errorf("enforcing active CA: %w", err)
+	}
+
+	if len(cas) > 1 {
+		trustedChanged, err := enforceFile(certmanager.FabCATrustedPath, []byte(strings.Join(cas[1:], "")), 0o644)
+		if err != nil {
+			return fmt.Errorf("enforcing trusted CAs: %w", err)
+		}
+		changed = changed || trustedChanged
+	} else if err := os.Remove(certmanager.FabCATrustedPath); err == nil {
+		changed = true
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("removing trusted CAs: %w", err)
+	}
+
+	if changed {
+		slog.Info("FabCA updated", "path", certmanager.FabCAPath, "trusted", len(cas))
+
+		if err := updateCACertificates(ctx); err != nil {
+			return fmt.Errorf("updating CA certificates: %w", err)
+		}
+	} else {
+		slog.Info("FabCA is up to date", "path", certmanager.FabCAPath)
+	}
+
+	return nil
+}
+
 func enforceFile(path string, expectedContent []byte, mode os.FileMode) (bool, error) {
 	actualContent, err := os.ReadFile(path)
 	if err != nil && !os.IsNotExist(err) {
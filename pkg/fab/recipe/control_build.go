@@ -26,6 +26,7 @@ import (
 	"go.githedgehog.com/fabricator/pkg/fab/comp/gateway"
 	"go.githedgehog.com/fabricator/pkg/fab/comp/k3s"
 	"go.githedgehog.com/fabricator/pkg/fab/comp/k9s"
+	"go.githedgehog.com/fabricator/pkg/fab/comp/monitoring"
 	"go.githedgehog.com/fabricator/pkg/fab/comp/ntp"
 	"go.githedgehog.com/fabricator/pkg/fab/comp/reloader"
 	"go.githedgehog.com/fabricator/pkg/fab/comp/zot"
@@ -60,6 +61,7 @@ var AirgapArtifactsBase = []comp.ListOCIArtifacts{
 	controlproxy.Artifacts,
 	f8r.Artifacts,
 	alloy.Artifacts,
+	monitoring.Artifacts, // empty unless the on-prem observability stack is enabled
 }
 
 var AirgapArtifactsGateway = []comp.ListOCIArtifacts{
@@ -29,6 +29,7 @@ import (
 	"go.githedgehog.com/fabricator/pkg/fab/comp/certmanager"
 	"go.githedgehog.com/fabricator/pkg/fab/comp/k3s"
 	"go.githedgehog.com/fabricator/pkg/fab/comp/zot"
+	"go.githedgehog.com/fabricator/pkg/fab/node"
 	"go.githedgehog.com/fabricator/pkg/util/apiutil"
 	appsapi "k8s.io/api/apps/v1"
 	coreapi "k8s.io/api/core/v1"
@@ -275,70 +276,109 @@ func (c *ControlInstall) installFabCA(ctx context.Context, kube kclient.Client)
 	// Reuse the existing CA to avoid invalidating Zot's cert on retry.
 	// Rotating the CA invalidates all leaf certs until cert-manager reissues them,
 	// which takes longer than the install timeout.
-	var ca *certmanager.CA
-	existingSecret := &coreapi.Secret{}
-	if err := kube.Get(ctx, kclient.ObjectKey{Name: comp.FabCASecret, Namespace: comp.FabNamespace}, existingSecret); err == nil {
-		crt := string(existingSecret.Data["tls.crt"])
-		key := string(existingSecret.Data["tls.key"])
-		if crt != "" && key != "" {
-			if certPair, err := tls.X509KeyPair([]byte(crt), []byte(key)); err != nil {
-				slog.Warn("Existing fab-ca has invalid cert/key, regenerating", "err", err)
-			} else if len(certPair.Certificate) == 0 {
-				slog.Warn("Existing fab-ca has no certificate, regenerating")
-			} else if parsed, err := x509.ParseCertificate(certPair.Certificate[0]); err != nil {
-				slog.Warn("Existing fab-ca certificate unparsable, regenerating", "err", err)
-			} else if !parsed.IsCA || !parsed.BasicConstraintsValid || parsed.KeyUsage&x509.KeyUsageCertSign == 0 {
-				slog.Warn("Existing fab-ca certificate is not a usable CA, regenerating")
-			} else {
-				slog.Debug("Reusing existing fab-ca")
-				ca = &certmanager.CA{Crt: crt, Key: key}
+	ca, err := certmanager.ConfiguredFabCA(ctx, kube, c.Fab)
+	if err != nil {
+		return "", fmt.Errorf("getting external fab-ca: %w", err)
+	}
+	if ca != nil {
+		slog.Info("Using external fab-ca")
+
+		// don't keep the key in the fabricator config stored in the cluster
+		if fabCA := c.Fab.Spec.Config.Control.FabCA; fabCA.Key != "" {
+			if err := comp.EnforceKubeInstall(ctx, kube, c.Fab, certmanager.InstallFabCAKeySecret(ca)); err != nil {
+				return "", fmt.Errorf("enforcing fab-ca key secret: %w", err)
+			}
+
+			fabCA.Key = ""
+			fabCA.KeySecret = comp.FabCAExternalSecret
+		}
+	} else {
+		existingSecret := &coreapi.Secret{}
+		if err := kube.Get(ctx, kclient.ObjectKey{Name: comp.FabCASecret, Namespace: comp.FabNamespace}, existingSecret); err == nil {
+			crt := string(existingSecret.Data["tls.crt"])
+			key := string(existingSecret.Data["tls.key"])
+			if crt != "" && key != "" {
+				if certPair, err := tls.X509KeyPair([]byte(crt), []byte(key)); err != nil {
+					slog.Warn("Existing fab-ca has invalid cert/key, regenerating", "err", err)
+				} else if len(certPair.Certificate) == 0 {
+					slog.Warn("Existing fab-ca has no certificate, regenerating")
+				} else if parsed, err := x509.ParseCertificate(certPair.Certificate[0]); err != nil {
+					slog.Warn("Existing fab-ca certificate unparsable, regenerating", "err", err)
+				} else if !parsed.IsCA || !parsed.BasicConstraintsValid || parsed.KeyUsage&x509.KeyUsageCertSign == 0 {
+					slog.Warn("Existing fab-ca certificate is not a usable CA, regenerating")
+				} else {
+					slog.Debug("Reusing existing fab-ca")
+					ca = &certmanager.CA{Crt: crt, Key: key}
+				}
 			}
+		} else if !kapierrors.IsNotFound(err) {
+			return "", fmt.Errorf("checking for existing fab-ca: %w", err)
 		}
-	} else if !kapierrors.IsNotFound(err) {
-		return "", fmt.Errorf("checking for existing fab-ca: %w", err)
 	}
 
 	if ca == nil {
-		var err error
 		ca, err = certmanager.NewFabCA()
 		if err != nil {
 			return "", fmt.Errorf("creating fab-ca: %w", err)
 		}
 	}
 
-	if err := comp.EnforceKubeInstall(ctx, kube, c.Fab, certmanager.InstallFabCA(ca)); err != nil {
-		return "", fmt.Errorf("enforcing fab-ca install: %w", err)
+	trusted, err := certmanager.ConfiguredTrustedCerts(c.Fab)
+	if err != nil {
+		return "", fmt.Errorf("getting trusted certs: %w", err)
 	}
 
-	if err := os.WriteFile(certmanager.FabCAPath, []byte(ca.Crt), 0o644); err != nil { //nolint:gosec
-		return "", fmt.Errorf("writing fab-ca cert: %w", err)
+	if err := comp.EnforceKubeInstall(ctx, kube, c.Fab, certmanager.InstallFabCA(ca, trusted...)); err != nil {
+		return "", fmt.Errorf("enforcing fab-ca install: %w", err)
 	}
 
-	cmd := exec.CommandContext(ctx, "update-ca-certificates")
-	cmd.Dir = c.WorkDir
-	cmd.Stdout = logutil.NewSink(ctx, slog.Debug, "update-ca: ")
-	cmd.Stderr = logutil.NewSink(ctx, slog.Debug, "update-ca: ")
+	crts, err := certmanager.SplitCABundle(ca.Crt)
+	if err != nil {
+		return "", fmt.Errorf("splitting fab-ca cert: %w", err)
+	}
+	bundle := certmanager.NewCABundle(append(crts, trusted...)...)
 
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("running update-ca-certificates: %w", err)
+	if err := node.EnforceFabCA(ctx, bundle); err != nil {
+		return "", fmt.Errorf("installing fab-ca cert: %w", err)
 	}
 
 	slog.Debug("Waiting for fab-ca ready")
 
 	if err := waitKube(ctx, kube, comp.FabCAIssuer, comp.FabNamespace,
 		&comp.Issuer{}, func(obj *comp.Issuer) (bool, error) {
-			for _, cond := range obj.Status.Conditions {
-				if cond.Type == comp.IssuerConditionReady && cond.Status == comp.CMConditionTrue {
-					return true, nil
-				}
-			}
-
-			return false, nil
+			return isIssuerReady(obj.Status.Conditions), nil
 		}); err != nil {
 		return "", fmt.Errorf("waiting for fab-ca issuer ready: %w", err)
 	}
 
-	return ca.Crt, nil
+	if ref := comp.FabIssuerRef(c.Fab); ref.Name != comp.FabCAIssuer {
+		slog.Info("Waiting for external issuer ready, it should be created now if it doesn't exist yet", "kind", ref.Kind, "name", ref.Name)
+
+		if ref.Kind == fabapi.FabCAIssuerKindClusterIssuer {
+			err = waitKube(ctx, kube, ref.Name, "", &comp.ClusterIssuer{}, func(obj *comp.ClusterIssuer) (bool, error) {
+				return isIssuerReady(obj.Status.Conditions), nil
+			})
+		} else {
+			err = waitKube(ctx, kube, ref.Name, comp.FabNamespace, &comp.Issuer{}, func(obj *comp.Issuer) (bool, error) {
+				return isIssuerReady(obj.Status.Conditions), nil
+			})
+		}
+		if err != nil {
+			return "", fmt.Errorf("waiting for external issuer ready: %w", err)
+		}
+	}
+
+	return bundle, nil
+}
+
+func isIssuerReady(conds []comp.IssuerCondition) bool {
+	for _, cond := range conds {
+		if cond.Type == comp.IssuerConditionReady && cond.Status == comp.CMConditionTrue {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (c *ControlInstall) installZot(ctx context.Context, kube kclient.Client, ca string) error {
@@ -567,6 +567,28 @@ func (c *ControlUpgrade) installSSHConfig() error {
 	return nil
 }
 
+// upgradeK8sConfig updates k3s server config (e.g. new options) and returns true if k3s restart is needed to apply it
+func (c *ControlUpgrade) upgradeK8sConfig() (bool, error) {
+	k3sCfg, err := k3s.ServerConfig(c.Fab, c.Control)
+	if err != nil {
+		return false, fmt.Errorf("k3s config: %w", err)
+	}
+
+	existing, err := os.ReadFile(k3s.ConfigPath)
+	if err != nil {
+		return false, fmt.Errorf("reading file %q: %w", k3s.ConfigPath, err)
+	}
+	if string(existing) == k3sCfg {
+		return false, nil
+	}
+
+	if err := os.WriteFile(k3s.ConfigPath, []byte(k3sCfg), 0o644); err != nil { //nolint:gosec
+		return false, fmt.Errorf("writing file %q: %w", k3s.ConfigPath, err)
+	}
+
+	return true, nil
+}
+
 func (c *ControlUpgrade) upgradeK8s(ctx context.Context, kube kclient.Reader) error {
 	ctx, cancel := context.WithTimeout(ctx, 12*time.Minute)
 	defer cancel()
@@ -578,26 +600,35 @@ func (c *ControlUpgrade) upgradeK8s(ctx context.Context, kube kclient.Reader) er
 		return fmt.Errorf("getting control node: %w", err)
 	}
 
+	cfgChanged, err := c.upgradeK8sConfig()
+	if err != nil {
+		return err
+	}
+
 	actual := node.Status.NodeInfo.KubeletVersion
 	desired := k3s.KubeVersion(c.Fab)
-	if actual == desired {
+	if actual == desired && !cfgChanged {
 		slog.Info("System already running desired K8s version", "version", desired)
 
 		return nil
 	}
 
-	slog.Info("Upgrading K8s", "from", actual, "to", desired)
+	if actual != desired {
+		slog.Info("Upgrading K8s", "from", actual, "to", desired)
 
-	if err := copyFile(k3s.BinName, filepath.Join(k3s.BinDir, k3s.BinName), 0o755); err != nil {
-		return fmt.Errorf("copying k3s bin: %w", err)
-	}
+		if err := copyFile(k3s.BinName, filepath.Join(k3s.BinDir, k3s.BinName), 0o755); err != nil {
+			return fmt.Errorf("copying k3s bin: %w", err)
+		}
 
-	if err := os.MkdirAll(k3s.ImagesDir, 0o755); err != nil {
-		return fmt.Errorf("creating k3s images dir %q: %w", k3s.ImagesDir, err)
-	}
+		if err := os.MkdirAll(k3s.ImagesDir, 0o755); err != nil {
+			return fmt.Errorf("creating k3s images dir %q: %w", k3s.ImagesDir, err)
+		}
 
-	if err := copyFile(k3s.AirgapName, filepath.Join(k3s.ImagesDir, k3s.AirgapName), 0o644); err != nil {
-		return fmt.Errorf("copying k3s airgap: %w", err)
+		if err := copyFile(k3s.AirgapName, filepath.Join(k3s.ImagesDir, k3s.AirgapName), 0o644); err != nil {
+			return fmt.Errorf("copying k3s airgap: %w", err)
+		}
+	} else {
+		slog.Info("Restarting K8s to apply updated config", "version", desired)
 	}
 
 	slog.Debug("Restarting K3s")
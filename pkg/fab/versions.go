@@ -65,6 +65,10 @@ var Versions = fabapi.Versions{
 		ControlProxyChart: FabricatorVersion,
 		BashCompletion:    "v2.16.0",
 		HostBGPContainer:  "v0.4.1",
+		Prometheus:        "v3.5.0",
+		Loki:              "3.5.3",
+		Grafana:           "12.1.1",
+		MonitoringChart:   FabricatorVersion,
 	},
 	Fabricator: fabapi.FabricatorVersions{
 		API:            FabricatorVersion,
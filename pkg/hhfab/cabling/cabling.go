@@ -0,0 +1,431 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package cabling
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
+	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RackAnnotation could be set on switches, servers and gateway nodes to include rack into the cabling plan
+const RackAnnotation = "hhfab.githedgehog.com/rack"
+
+const (
+	ItemCable = "cable"
+	ItemOptic = "optic"
+
+	TypeBreakout = "breakout"
+	SpeedUnknown = "unknown"
+)
+
+// Cable is a single cable run between two ports, B side is empty for the externals as the remote end isn't known
+type Cable struct {
+	Connection string
+	Type       string
+	Speed      string
+	ARack      string
+	ADevice    string
+	APort      string
+	ANOSPort   string
+	ABreakout  string
+	BRack      string
+	BDevice    string
+	BPort      string
+	BNOSPort   string
+	BBreakout  string
+}
+
+// BOMItem is a summary line of the bill of materials, e.g. number of 100G fabric cables
+type BOMItem struct {
+	Item  string
+	Type  string
+	Speed string
+	Count int
+}
+
+type Plan struct {
+	Cables []Cable
+	BOM    []BOMItem
+}
+
+type switchInfo struct {
+	sw      *wiringapi.Switch
+	profile *wiringapi.SwitchProfile
+}
+
+// Build generates the cabling plan from the wiring available through the reader
+func Build(ctx context.Context, kube kclient.Reader) (*Plan, error) {
+	racks := map[string]string{}
+
+	switches := &wiringapi.SwitchList{}
+	if err := kube.List(ctx, switches); err != nil {
+		return nil, fmt.Errorf("listing switches: %w", err)
+	}
+
+	profiles := &wiringapi.SwitchProfileList{}
+	if err := kube.List(ctx, profiles); err != nil {
+		return nil, fmt.Errorf("listing switch profiles: %w", err)
+	}
+	profileMap := map[string]*wiringapi.SwitchProfile{}
+	for idx := range profiles.Items {
+		profileMap[profiles.Items[idx].Name] = &profiles.Items[idx]
+	}
+
+	switchMap := map[string]*switchInfo{}
+	for idx := range switches.Items {
+		sw := &switches.Items[idx]
+		racks[sw.Name] = sw.Annotations[RackAnnotation]
+
+		info := &switchInfo{sw: sw}
+		if profile, ok := profileMap[sw.Spec.Profile]; ok {
+			info.profile = profile
+		} else {
+			slog.Warn("Switch profile not found, NOS port names and speeds will be missing", "switch", sw.Name, "profile", sw.Spec.Profile)
+		}
+
+		switchMap[sw.Name] = info
+	}
+
+	servers := &wiringapi.ServerList{}
+	if err := kube.List(ctx, servers); err != nil {
+		return nil, fmt.Errorf("listing servers: %w", err)
+	}
+	for _, server := range servers.Items {
+		racks[server.Name] = server.Annotations[RackAnnotation]
+	}
+
+	nodes := &fabapi.FabNodeList{}
+	if err := kube.List(ctx, nodes); err != nil {
+		return nil, fmt.Errorf("listing nodes: %w", err)
+	}
+	for _, node := range nodes.Items {
+		racks[node.Name] = node.Annotations[RackAnnotation]
+	}
+
+	conns := &wiringapi.ConnectionList{}
+	if err := kube.List(ctx, conns); err != nil {
+		return nil, fmt.Errorf("listing connections: %w", err)
+	}
+
+	plan := &Plan{}
+	for _, conn := range conns.Items {
+		_, _, ports, links, err := conn.Spec.Endpoints()
+		if err != nil {
+			return nil, fmt.Errorf("getting endpoints for connection %q: %w", conn.Name, err)
+		}
+
+		connType := conn.Spec.Type()
+
+		ends := make([][2]string, 0, max(len(links), len(ports)))
+		for a, b := range links {
+			ends = append(ends, [2]string{a, b})
+		}
+		// externals only have the switch side defined
+		if len(links) == 0 {
+			for _, port := range ports {
+				ends = append(ends, [2]string{port, ""})
+			}
+		}
+
+		for _, end := range ends {
+			cable := Cable{
+				Connection: conn.Name,
+				Type:       connType,
+			}
+
+			cable.ADevice, cable.APort = splitPort(end[0])
+			cable.ARack = racks[cable.ADevice]
+			cable.ANOSPort, cable.ABreakout, cable.Speed = portInfo(switchMap[cable.ADevice], cable.APort)
+
+			if end[1] != "" {
+				bSpeed := ""
+				cable.BDevice, cable.BPort = splitPort(end[1])
+				cable.BRack = racks[cable.BDevice]
+				cable.BNOSPort, cable.BBreakout, bSpeed = portInfo(switchMap[cable.BDevice], cable.BPort)
+				if cable.Speed == "" {
+					cable.Speed = bSpeed
+				} else if bSpeed != "" && bSpeed != cable.Speed {
+					slog.Warn("Port speed mismatch between cable ends", "connection", conn.Name,
+						"a", end[0], "aSpeed", cable.Speed, "b", end[1], "bSpeed", bSpeed)
+				}
+			}
+
+			plan.Cables = append(plan.Cables, cable)
+		}
+	}
+
+	sort.Slice(plan.Cables, func(i, j int) bool {
+		a, b := plan.Cables[i], plan.Cables[j]
+		if a.ADevice != b.ADevice {
+			return a.ADevice < b.ADevice
+		}
+		if a.APort != b.APort {
+			return portLess(a.APort, b.APort)
+		}
+
+		return a.Connection < b.Connection
+	})
+
+	plan.BOM = buildBOM(plan.Cables)
+
+	return plan, nil
+}
+
+func splitPort(name string) (string, string) {
+	parts := wiringapi.SplitPortName(name)
+	if len(parts) != 2 {
+		return name, ""
+	}
+
+	return parts[0], parts[1]
+}
+
+// basePort returns the physical port for the breakout ports, e.g. E1/55 for E1/55/2
+func basePort(port string) string {
+	if parts := strings.Split(port, "/"); len(parts) > 2 {
+		return strings.Join(parts[:2], "/")
+	}
+
+	return port
+}
+
+// parseBreakout returns number of lanes and per-lane speed from the breakout mode such as 4x25G
+func parseBreakout(mode string) (int, string) {
+	lanes, speed, ok := strings.Cut(mode, "x")
+	if !ok {
+		return 0, ""
+	}
+	n, err := strconv.Atoi(lanes)
+	if err != nil {
+		return 0, ""
+	}
+
+	return n, speed
+}
+
+// portInfo returns NOS port name, breakout mode (if port is part of the multi-lane breakout) and speed for the
+// switch port, it returns empty values for non-switch devices
+func portInfo(info *switchInfo, port string) (string, string, string) {
+	if info == nil {
+		return "", "", ""
+	}
+
+	return PortNOSName(info.sw, info.profile, port), PortBreakout(info.sw, info.profile, port), PortSpeed(info.sw, info.profile, port)
+}
+
+// PortNOSName returns the NOS name for the switch port (e.g. E1/1 without switch name), breakout ports get the
+// per-lane names if breakout is configured and the base port NOS name otherwise
+func PortNOSName(sw *wiringapi.Switch, profile *wiringapi.SwitchProfile, port string) string {
+	if sw == nil || profile == nil {
+		return ""
+	}
+
+	if nosPorts, err := profile.Spec.GetAPI2NOSPortsFor(&sw.Spec); err != nil {
+		slog.Debug("Failed to get NOS port names", "switch", sw.Name, "err", err)
+	} else if nosPort, ok := nosPorts[port]; ok {
+		return nosPort
+	}
+
+	portSpec, ok := profile.Spec.Ports[basePort(port)]
+	if !ok {
+		if portSpec, ok = profile.Spec.Ports[port]; !ok {
+			return ""
+		}
+	}
+
+	if portSpec.BaseNOSName != "" {
+		return portSpec.BaseNOSName
+	}
+
+	return portSpec.NOSName
+}
+
+func portBreakoutMode(sw *wiringapi.Switch, profile *wiringapi.SwitchProfile, port string) string {
+	if sw == nil || profile == nil {
+		return ""
+	}
+
+	breakouts, err := profile.Spec.GetBreakoutDefaults(&sw.Spec)
+	if err != nil {
+		slog.Debug("Failed to get port breakouts", "switch", sw.Name, "err", err)
+
+		return ""
+	}
+
+	return breakouts[basePort(port)]
+}
+
+// PortBreakout returns the breakout mode (e.g. 4x25G) if the switch port is part of the multi-lane breakout
+func PortBreakout(sw *wiringapi.Switch, profile *wiringapi.SwitchProfile, port string) string {
+	mode := portBreakoutMode(sw, profile, port)
+	if lanes, _ := parseBreakout(mode); lanes > 1 {
+		return mode
+	}
+
+	return ""
+}
+
+// PortSpeed returns the speed of the switch port (e.g. E1/1 without switch name) taking into account breakouts,
+// port and port group speeds configured on the switch and the switch profile defaults
+func PortSpeed(sw *wiringapi.Switch, profile *wiringapi.SwitchProfile, port string) string {
+	if sw == nil {
+		return ""
+	}
+
+	base := basePort(port)
+
+	if _, speed := parseBreakout(portBreakoutMode(sw, profile, port)); speed != "" {
+		return speed
+	}
+
+	if speed := sw.Spec.PortSpeeds[base]; speed != "" {
+		return speed
+	}
+
+	if profile == nil {
+		return ""
+	}
+
+	profilePort := profile.Spec.Ports[base]
+	profileName := profilePort.Profile
+	if profilePort.Group != "" {
+		if speed := sw.Spec.PortGroupSpeeds[profilePort.Group]; speed != "" {
+			return speed
+		}
+		profileName = profile.Spec.PortGroups[profilePort.Group].Profile
+	}
+
+	if portProfile, ok := profile.Spec.PortProfiles[profileName]; ok && portProfile.Speed != nil {
+		return portProfile.Speed.Default
+	}
+
+	return ""
+}
+
+// portLess compares port names taking numbers into account, so E1/2 goes before E1/10
+func portLess(a, b string) bool {
+	as := strings.FieldsFunc(a, isPortSeparator)
+	bs := strings.FieldsFunc(b, isPortSeparator)
+	for idx := 0; idx < len(as) && idx < len(bs); idx++ {
+		if as[idx] == bs[idx] {
+			continue
+		}
+
+		an, aErr := strconv.Atoi(strings.TrimLeftFunc(as[idx], isNotDigit))
+		bn, bErr := strconv.Atoi(strings.TrimLeftFunc(bs[idx], isNotDigit))
+		if aErr == nil && bErr == nil && an != bn {
+			return an < bn
+		}
+
+		return as[idx] < bs[idx]
+	}
+
+	return len(as) < len(bs)
+}
+
+func isPortSeparator(r rune) bool {
+	return r == '/'
+}
+
+func isNotDigit(r rune) bool {
+	return r < '0' || r > '9'
+}
+
+// buildBOM counts cables and optics, breakout sub-ports sharing a physical port are served by a single breakout
+// cable and optic and every cabled port (including server side) is assumed to need a pluggable optic
+func buildBOM(cables []Cable) []BOMItem {
+	type bomKey struct {
+		item, typ, speed string
+	}
+	counts := map[bomKey]int{}
+	breakoutPorts := map[string]bool{}
+
+	speedOrUnknown := func(speed string) string {
+		if speed == "" {
+			return SpeedUnknown
+		}
+
+		return speed
+	}
+
+	addOptic := func(device, port, breakout, speed string) {
+		if device == "" {
+			return
+		}
+
+		if breakout == "" {
+			counts[bomKey{ItemOptic, "", speedOrUnknown(speed)}]++
+
+			return
+		}
+
+		key := device + "/" + basePort(port)
+		if breakoutPorts[key] {
+			return
+		}
+		breakoutPorts[key] = true
+
+		lanes, laneSpeed := parseBreakout(breakout)
+		totalSpeed := SpeedUnknown
+		if num, unit := splitSpeed(laneSpeed); num > 0 {
+			totalSpeed = fmt.Sprintf("%d%s", num*lanes, unit)
+		}
+		counts[bomKey{ItemOptic, "", totalSpeed}]++
+		counts[bomKey{ItemCable, TypeBreakout, breakout}]++
+	}
+
+	for _, cable := range cables {
+		if cable.ABreakout == "" && cable.BBreakout == "" {
+			counts[bomKey{ItemCable, cable.Type, speedOrUnknown(cable.Speed)}]++
+		}
+
+		addOptic(cable.ADevice, cable.APort, cable.ABreakout, cable.Speed)
+		addOptic(cable.BDevice, cable.BPort, cable.BBreakout, cable.Speed)
+	}
+
+	bom := make([]BOMItem, 0, len(counts))
+	for key, count := range counts {
+		bom = append(bom, BOMItem{
+			Item:  key.item,
+			Type:  key.typ,
+			Speed: key.speed,
+			Count: count,
+		})
+	}
+	sort.Slice(bom, func(i, j int) bool {
+		if bom[i].Item != bom[j].Item {
+			return bom[i].Item < bom[j].Item
+		}
+		if bom[i].Type != bom[j].Type {
+			return bom[i].Type < bom[j].Type
+		}
+
+		return bom[i].Speed < bom[j].Speed
+	})
+
+	return bom
+}
+
+// splitSpeed splits speed such as 25G into number and unit
+func splitSpeed(speed string) (int, string) {
+	idx := strings.IndexFunc(speed, isNotDigit)
+	if idx <= 0 {
+		return 0, ""
+	}
+
+	num, err := strconv.Atoi(speed[:idx])
+	if err != nil {
+		return 0, ""
+	}
+
+	return num, speed[idx:]
+}
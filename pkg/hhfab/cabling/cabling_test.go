@@ -0,0 +1,193 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package cabling
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
+	"go.githedgehog.com/fabricator/pkg/util/apiutil"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func testWiring(t *testing.T) *apiutil.Loader {
+	t.Helper()
+
+	ctx := context.Background()
+	l := apiutil.NewLoader()
+
+	meta := func(name string, anns map[string]string) kmetav1.ObjectMeta {
+		return kmetav1.ObjectMeta{Name: name, Namespace: kmetav1.NamespaceDefault, Annotations: anns}
+	}
+
+	profile := &wiringapi.SwitchProfile{
+		ObjectMeta: meta("test", nil),
+		Spec: wiringapi.SwitchProfileSpec{
+			Ports: map[string]wiringapi.SwitchProfilePort{
+				"E1/1": {NOSName: "Ethernet0", Group: "1"},
+				"E1/2": {NOSName: "1/2", BaseNOSName: "Ethernet4", Profile: "QSFP28-100G"},
+			},
+			PortGroups: map[string]wiringapi.SwitchProfilePortGroup{
+				"1": {Profile: "SFP28-25G"},
+			},
+			PortProfiles: map[string]wiringapi.SwitchProfilePortProfile{
+				"SFP28-25G": {Speed: &wiringapi.SwitchProfilePortProfileSpeed{Default: "25G"}},
+				"QSFP28-100G": {Breakout: &wiringapi.SwitchProfilePortProfileBreakout{
+					Default: "1x100G",
+					Supported: map[string]wiringapi.SwitchProfilePortProfileBreakoutMode{
+						"1x100G": {Offsets: []string{"0"}},
+						"4x25G":  {Offsets: []string{"0", "1", "2", "3"}},
+					},
+				}},
+			},
+		},
+	}
+
+	objs := []kclient.Object{
+		profile,
+		&wiringapi.Switch{
+			ObjectMeta: meta("spine-01", map[string]string{RackAnnotation: "rack-1"}),
+			Spec: wiringapi.SwitchSpec{
+				Profile:         "test",
+				PortGroupSpeeds: map[string]string{"1": "10G"},
+			},
+		},
+		&wiringapi.Switch{
+			ObjectMeta: meta("leaf-01", map[string]string{RackAnnotation: "rack-2"}),
+			Spec: wiringapi.SwitchSpec{
+				Profile:         "test",
+				PortGroupSpeeds: map[string]string{"1": "10G"},
+				PortBreakouts:   map[string]string{"E1/2": "4x25G"},
+			},
+		},
+		&wiringapi.Server{ObjectMeta: meta("server-01", map[string]string{RackAnnotation: "rack-2"})},
+		&wiringapi.Server{ObjectMeta: meta("server-02", nil)},
+		&wiringapi.Connection{
+			ObjectMeta: meta("spine-01--fabric--leaf-01", nil),
+			Spec: wiringapi.ConnectionSpec{Fabric: &wiringapi.ConnFabric{Links: []wiringapi.FabricLink{{
+				Spine: wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: "spine-01/E1/1"}},
+				Leaf:  wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: "leaf-01/E1/1"}},
+			}}}},
+		},
+		&wiringapi.Connection{
+			ObjectMeta: meta("server-01--unbundled--leaf-01", nil),
+			Spec: wiringapi.ConnectionSpec{Unbundled: &wiringapi.ConnUnbundled{Link: wiringapi.ServerToSwitchLink{
+				Switch: wiringapi.BasePortName{Port: "leaf-01/E1/2/1"},
+				Server: wiringapi.BasePortName{Port: "server-01/enp2s1"},
+			}}},
+		},
+		&wiringapi.Connection{
+			ObjectMeta: meta("server-02--unbundled--leaf-01", nil),
+			Spec: wiringapi.ConnectionSpec{Unbundled: &wiringapi.ConnUnbundled{Link: wiringapi.ServerToSwitchLink{
+				Switch: wiringapi.BasePortName{Port: "leaf-01/E1/2/2"},
+				Server: wiringapi.BasePortName{Port: "server-02/enp2s1"},
+			}}},
+		},
+	}
+	require.NoError(t, l.Add(ctx, objs...))
+
+	return l
+}
+
+func TestBuild(t *testing.T) {
+	plan, err := Build(context.Background(), testWiring(t).GetClient())
+	require.NoError(t, err)
+
+	require.Equal(t, []Cable{
+		{
+			Connection: "server-01--unbundled--leaf-01", Type: wiringapi.ConnectionTypeUnbundled, Speed: "25G",
+			ARack: "rack-2", ADevice: "leaf-01", APort: "E1/2/1", ANOSPort: "Ethernet4", ABreakout: "4x25G",
+			BRack: "rack-2", BDevice: "server-01", BPort: "enp2s1",
+		},
+		{
+			Connection: "server-02--unbundled--leaf-01", Type: wiringapi.ConnectionTypeUnbundled, Speed: "25G",
+			ARack: "rack-2", ADevice: "leaf-01", APort: "E1/2/2", ANOSPort: "Ethernet5", ABreakout: "4x25G",
+			BDevice: "server-02", BPort: "enp2s1",
+		},
+		{
+			Connection: "spine-01--fabric--leaf-01", Type: wiringapi.ConnectionTypeFabric, Speed: "10G",
+			ARack: "rack-1", ADevice: "spine-01", APort: "E1/1", ANOSPort: "Ethernet0",
+			BRack: "rack-2", BDevice: "leaf-01", BPort: "E1/1", BNOSPort: "Ethernet0",
+		},
+	}, plan.Cables)
+
+	require.Equal(t, []BOMItem{
+		{Item: ItemCable, Type: TypeBreakout, Speed: "4x25G", Count: 1},
+		{Item: ItemCable, Type: wiringapi.ConnectionTypeFabric, Speed: "10G", Count: 1},
+		{Item: ItemOptic, Speed: "100G", Count: 1},
+		{Item: ItemOptic, Speed: "10G", Count: 2},
+		{Item: ItemOptic, Speed: "25G", Count: 2},
+	}, plan.BOM)
+}
+
+func TestPortInfo(t *testing.T) {
+	profile := &wiringapi.SwitchProfile{}
+	require.NoError(t, testWiring(t).GetClient().Get(context.Background(), kclient.ObjectKey{Name: "test", Namespace: kmetav1.NamespaceDefault}, profile))
+
+	sw := &wiringapi.Switch{Spec: wiringapi.SwitchSpec{Profile: "test"}}
+	require.Equal(t, "Ethernet0", PortNOSName(sw, profile, "E1/1"))
+	require.Equal(t, "25G", PortSpeed(sw, profile, "E1/1"), "port group profile default")
+	require.Equal(t, "Ethernet4", PortNOSName(sw, profile, "E1/2"))
+	require.Equal(t, "100G", PortSpeed(sw, profile, "E1/2"), "breakout default")
+	require.Empty(t, PortBreakout(sw, profile, "E1/2"), "single lane isn't a breakout")
+
+	sw.Spec.PortGroupSpeeds = map[string]string{"1": "10G"}
+	sw.Spec.PortBreakouts = map[string]string{"E1/2": "4x25G"}
+	require.Equal(t, "10G", PortSpeed(sw, profile, "E1/1"))
+	require.Equal(t, "Ethernet6", PortNOSName(sw, profile, "E1/2/3"))
+	require.Equal(t, "25G", PortSpeed(sw, profile, "E1/2/3"))
+	require.Equal(t, "4x25G", PortBreakout(sw, profile, "E1/2/3"))
+
+	require.Empty(t, PortNOSName(sw, profile, "E1/99"))
+	require.Empty(t, PortNOSName(sw, nil, "E1/1"))
+	require.Empty(t, PortSpeed(nil, profile, "E1/1"))
+}
+
+func TestWrite(t *testing.T) {
+	plan, err := Build(context.Background(), testWiring(t).GetClient())
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+
+	files, err := Write(plan, FormatCSV, filepath.Join(dir, "cabling.csv"))
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "cabling.csv"), filepath.Join(dir, "cabling-bom.csv")}, files)
+	data, err := os.ReadFile(files[1])
+	require.NoError(t, err)
+	require.Equal(t, "Item,Type,Speed,Count\ncable,breakout,4x25G,1\ncable,fabric,10G,1\noptic,,100G,1\noptic,,10G,2\noptic,,25G,2\n", string(data))
+
+	files, err = Write(plan, FormatMarkdown, filepath.Join(dir, "cabling.md"))
+	require.NoError(t, err)
+	data, err = os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.Contains(t, string(data), "| rack-1 | spine-01 | E1/1 | Ethernet0 |  | rack-2 | leaf-01 | E1/1 | Ethernet0 |  | 10G | fabric | spine-01--fabric--leaf-01 |\n")
+
+	files, err = Write(plan, FormatXLSX, filepath.Join(dir, "cabling.xlsx"))
+	require.NoError(t, err)
+	data, err = os.ReadFile(files[0])
+	require.NoError(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	names := []string{}
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	require.Contains(t, names, "xl/worksheets/sheet2.xml")
+	require.True(t, strings.HasPrefix(names[0], "[Content_Types]"))
+}
+
+func TestXLSXColumn(t *testing.T) {
+	require.Equal(t, "A", xlsxColumn(0))
+	require.Equal(t, "Z", xlsxColumn(25))
+	require.Equal(t, "AA", xlsxColumn(26))
+	require.Equal(t, "AZ", xlsxColumn(51))
+}
@@ -0,0 +1,295 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package cabling
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatCSV      Format = "csv"
+	FormatXLSX     Format = "xlsx"
+	FormatMarkdown Format = "md"
+)
+
+var Formats = []Format{
+	FormatCSV,
+	FormatXLSX,
+	FormatMarkdown,
+}
+
+// Filename is the default output file name without extension
+const Filename = "cabling"
+
+var (
+	cableHeader = []string{
+		"Rack A", "Device A", "Port A", "NOS Port A", "Breakout A",
+		"Rack B", "Device B", "Port B", "NOS Port B", "Breakout B",
+		"Speed", "Type", "Connection",
+	}
+	bomHeader = []string{"Item", "Type", "Speed", "Count"}
+)
+
+func (c Cable) row() []string {
+	return []string{
+		c.ARack, c.ADevice, c.APort, c.ANOSPort, c.ABreakout,
+		c.BRack, c.BDevice, c.BPort, c.BNOSPort, c.BBreakout,
+		c.Speed, c.Type, c.Connection,
+	}
+}
+
+func (b BOMItem) row() []string {
+	return []string{b.Item, b.Type, b.Speed, strconv.Itoa(b.Count)}
+}
+
+func (p *Plan) cableRows() [][]string {
+	rows := [][]string{cableHeader}
+	for _, cable := range p.Cables {
+		rows = append(rows, cable.row())
+	}
+
+	return rows
+}
+
+func (p *Plan) bomRows() [][]string {
+	rows := [][]string{bomHeader}
+	for _, item := range p.BOM {
+		rows = append(rows, item.row())
+	}
+
+	return rows
+}
+
+// Write writes the cabling plan in the specified format and returns list of the files written, CSV produces a
+// separate file for the BOM while other formats keep it together with the cables (as a second sheet or table)
+func Write(plan *Plan, format Format, outputPath string) ([]string, error) {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return nil, fmt.Errorf("creating output directory: %w", err)
+	}
+
+	switch format {
+	case FormatCSV:
+		bomPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + "-bom" + filepath.Ext(outputPath)
+
+		if err := WriteCSV(outputPath, plan.cableRows()); err != nil {
+			return nil, fmt.Errorf("writing cables: %w", err)
+		}
+		if err := WriteCSV(bomPath, plan.bomRows()); err != nil {
+			return nil, fmt.Errorf("writing BOM: %w", err)
+		}
+
+		return []string{outputPath, bomPath}, nil
+	case FormatXLSX:
+		data, err := renderXLSX(plan)
+		if err != nil {
+			return nil, fmt.Errorf("rendering xlsx: %w", err)
+		}
+		if err := os.WriteFile(outputPath, data, 0o600); err != nil {
+			return nil, fmt.Errorf("writing xlsx: %w", err)
+		}
+
+		return []string{outputPath}, nil
+	case FormatMarkdown:
+		if err := os.WriteFile(outputPath, []byte(renderMarkdown(plan)), 0o600); err != nil {
+			return nil, fmt.Errorf("writing markdown: %w", err)
+		}
+
+		return []string{outputPath}, nil
+	default:
+		return nil, fmt.Errorf("unsupported cabling format: %s", format) //nolint:goerr113
+	}
+}
+
+// WriteCSV writes rows (including header) into the CSV file at the given path
+func WriteCSV(path string, rows [][]string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if err := w.WriteAll(rows); err != nil {
+		return fmt.Errorf("writing csv: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing file: %w", err)
+	}
+
+	return nil
+}
+
+func renderMarkdown(plan *Plan) string {
+	b := &strings.Builder{}
+
+	b.WriteString("# Cabling plan\n\n")
+	writeMarkdownTable(b, plan.cableRows())
+
+	b.WriteString("\n## Bill of materials\n\n")
+	writeMarkdownTable(b, plan.bomRows())
+
+	return b.String()
+}
+
+func writeMarkdownTable(b *strings.Builder, rows [][]string) {
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, cell := range cells {
+			b.WriteString(" ")
+			b.WriteString(strings.ReplaceAll(cell, "|", "\\|"))
+			b.WriteString(" |")
+		}
+		b.WriteString("\n")
+	}
+
+	for idx, row := range rows {
+		writeRow(row)
+		if idx == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", len(row)) + "\n")
+		}
+	}
+}
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/worksheets/sheet2.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets>
+<sheet name="Cables" sheetId="1" r:id="rId1"/>
+<sheet name="BOM" sheetId="2" r:id="rId2"/>
+</sheets>
+</workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet2.xml"/>
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+	// second cell format (s="1") is bold used for the header row
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>
+</styleSheet>`
+)
+
+// renderXLSX builds a minimal Office Open XML workbook with the cables and BOM sheets
+func renderXLSX(plan *Plan) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+
+	files := []struct {
+		name string
+		data func(w io.Writer) error
+	}{
+		{"[Content_Types].xml", writeString(xlsxContentTypes)},
+		{"_rels/.rels", writeString(xlsxRels)},
+		{"xl/workbook.xml", writeString(xlsxWorkbook)},
+		{"xl/_rels/workbook.xml.rels", writeString(xlsxWorkbookRels)},
+		{"xl/styles.xml", writeString(xlsxStyles)},
+		{"xl/worksheets/sheet1.xml", writeSheet(plan.cableRows(), 0)},
+		{"xl/worksheets/sheet2.xml", writeSheet(plan.bomRows(), len(bomHeader)-1)},
+	}
+
+	for _, file := range files {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			return nil, fmt.Errorf("creating %s: %w", file.name, err)
+		}
+		if err := file.data(w); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", file.name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("closing archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeString(s string) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.WriteString(w, s)
+
+		return err //nolint:wrapcheck
+	}
+}
+
+// writeSheet writes rows as inline strings, except for the numeric column (if > 0) in the non-header rows
+func writeSheet(rows [][]string, numericCol int) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		b := &strings.Builder{}
+		b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+		b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+		b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+		b.WriteString(`<sheetData>`)
+		for rowIdx, row := range rows {
+			fmt.Fprintf(b, `<row r="%d">`, rowIdx+1)
+			for colIdx, cell := range row {
+				ref := xlsxColumn(colIdx) + strconv.Itoa(rowIdx+1)
+				style := ""
+				if rowIdx == 0 {
+					style = ` s="1"`
+				}
+
+				if rowIdx > 0 && numericCol > 0 && colIdx == numericCol {
+					fmt.Fprintf(b, `<c r="%s"><v>%s</v></c>`, ref, cell)
+
+					continue
+				}
+
+				fmt.Fprintf(b, `<c r="%s" t="inlineStr"%s><is><t>`, ref, style)
+				if err := xml.EscapeText(b, []byte(cell)); err != nil {
+					return fmt.Errorf("escaping cell: %w", err)
+				}
+				b.WriteString(`</t></is></c>`)
+			}
+			b.WriteString(`</row>`)
+		}
+		b.WriteString(`</sheetData></worksheet>`)
+
+		_, err := io.WriteString(w, b.String())
+
+		return err //nolint:wrapcheck
+	}
+}
+
+// xlsxColumn returns spreadsheet column name for the zero-based index, e.g. A, Z, AA
+func xlsxColumn(idx int) string {
+	name := ""
+	for idx >= 0 {
+		name = string(rune('A'+idx%26)) + name
+		idx = idx/26 - 1
+	}
+
+	return name
+}
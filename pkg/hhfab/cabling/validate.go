@@ -0,0 +1,187 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package cabling
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
+	"go.githedgehog.com/fabric/pkg/util/apiutil"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type MismatchType string
+
+const (
+	MismatchMiswired   MismatchType = "miswired"
+	MismatchMissing    MismatchType = "missing"
+	MismatchUnexpected MismatchType = "unexpected"
+)
+
+// Mismatch is a single switch port where the LLDP neighbor reported by the agent doesn't match the wiring, Expected
+// and Actual are neighbor device/port pairs
+type Mismatch struct {
+	Type       MismatchType
+	Switch     string
+	Port       string
+	Connection string
+	Expected   string
+	Actual     string
+	Fix        string
+}
+
+type ValidateOpts struct {
+	Switches []string // only validate specified switches, all if empty
+	Server   bool     // validate server links, servers may not run LLDP
+}
+
+// neighborsFunc returns expected and actual LLDP neighbors for the switch ports, it's a variable to be replaced in
+// tests as agent status isn't available outside of the live environment
+var neighborsFunc = apiutil.GetLLDPNeighbors
+
+// Validate compares every wiring connection against the LLDP neighbors reported by the agents, external links are
+// skipped as the remote side isn't known
+func Validate(ctx context.Context, kube kclient.Reader, opts ValidateOpts) ([]Mismatch, error) {
+	switches := &wiringapi.SwitchList{}
+	if err := kube.List(ctx, switches); err != nil {
+		return nil, fmt.Errorf("listing switches: %w", err)
+	}
+
+	conns := &wiringapi.ConnectionList{}
+	if err := kube.List(ctx, conns); err != nil {
+		return nil, fmt.Errorf("listing connections: %w", err)
+	}
+	usedPorts := map[string]bool{}
+	for _, conn := range conns.Items {
+		_, _, ports, _, err := conn.Spec.Endpoints()
+		if err != nil {
+			return nil, fmt.Errorf("getting endpoints for connection %q: %w", conn.Name, err)
+		}
+		for _, port := range ports {
+			usedPorts[port] = true
+		}
+	}
+
+	// expected is "device/port" of the neighbor -> "switch/port" where it should be connected
+	expected := map[string]string{}
+	all := map[string]map[string]apiutil.LLDPNeighborStatus{}
+	for idx := range switches.Items {
+		sw := &switches.Items[idx]
+		if len(opts.Switches) > 0 && !slices.Contains(opts.Switches, sw.Name) {
+			continue
+		}
+
+		neighbors, err := neighborsFunc(ctx, kube, sw)
+		if err != nil {
+			return nil, fmt.Errorf("getting LLDP neighbors for %s: %w", sw.Name, err)
+		}
+		all[sw.Name] = neighbors
+
+		for port, n := range neighbors {
+			if n.Expected.Name != "" {
+				expected[n.Expected.Name+"/"+n.Expected.Port] = sw.Name + "/" + port
+			}
+		}
+	}
+
+	for _, sw := range opts.Switches {
+		if _, ok := all[sw]; !ok {
+			return nil, fmt.Errorf("switch %s not found", sw) //nolint:goerr113
+		}
+	}
+
+	mismatches := []Mismatch{}
+	for swName, neighbors := range all {
+		for port, n := range neighbors {
+			if strings.HasPrefix(port, wiringapi.ManagementPortPrefix) || n.Type == apiutil.LLDPNeighborTypeExternal {
+				continue
+			}
+			if !opts.Server && n.Type == apiutil.LLDPNeighborTypeServer {
+				continue
+			}
+
+			local := swName + "/" + port
+			m := Mismatch{
+				Switch:     swName,
+				Port:       port,
+				Connection: n.ConnectionName,
+			}
+
+			if n.Expected.Name == "" {
+				// neighbor on the port that isn't part of any connection or used by the external
+				if len(n.Actual) == 0 || usedPorts[local] {
+					continue
+				}
+
+				m.Type = MismatchUnexpected
+				m.Actual = n.Actual[0].Name + "/" + n.Actual[0].Port
+				if target, ok := expected[m.Actual]; ok {
+					m.Fix = fmt.Sprintf("move cable from %s to %s", local, target)
+				} else {
+					m.Fix = fmt.Sprintf("add connection for %s <-> %s to the wiring or remove the cable", local, m.Actual)
+				}
+				mismatches = append(mismatches, m)
+
+				continue
+			}
+
+			m.Expected = n.Expected.Name + "/" + n.Expected.Port
+
+			if len(n.Actual) == 0 {
+				m.Type = MismatchMissing
+				m.Fix = fmt.Sprintf("check cable and optics between %s and %s, make sure both ports are up", local, m.Expected)
+				mismatches = append(mismatches, m)
+
+				continue
+			}
+
+			found := false
+			for _, actual := range n.Actual {
+				if actual.Name == n.Expected.Name && actual.Port == n.Expected.Port {
+					found = true
+
+					break
+				}
+			}
+			if found {
+				continue
+			}
+
+			actual := n.Actual[0]
+			for _, a := range n.Actual {
+				if a.Name == n.Expected.Name {
+					actual = a
+
+					break
+				}
+			}
+
+			m.Type = MismatchMiswired
+			m.Actual = actual.Name + "/" + actual.Port
+			switch {
+			case actual.Name == n.Expected.Name:
+				m.Fix = fmt.Sprintf("move cable on %s from port %s to %s", actual.Name, actual.Port, n.Expected.Port)
+			case expected[m.Actual] != "":
+				m.Fix = fmt.Sprintf("cable from %s belongs to %s, swap cables or update connection %s", m.Actual, expected[m.Actual], n.ConnectionName)
+			default:
+				m.Fix = fmt.Sprintf("connect %s to %s or update connection %s", local, m.Expected, n.ConnectionName)
+			}
+			mismatches = append(mismatches, m)
+		}
+	}
+
+	sort.Slice(mismatches, func(i, j int) bool {
+		if mismatches[i].Switch != mismatches[j].Switch {
+			return mismatches[i].Switch < mismatches[j].Switch
+		}
+
+		return portLess(mismatches[i].Port, mismatches[j].Port)
+	})
+
+	return mismatches, nil
+}
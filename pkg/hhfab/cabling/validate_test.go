@@ -0,0 +1,89 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package cabling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
+	"go.githedgehog.com/fabric/pkg/util/apiutil"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestValidate(t *testing.T) {
+	neighbors := map[string]map[string]apiutil.LLDPNeighborStatus{
+		"spine-01": {
+			"E1/1": {
+				ConnectionName: "spine-01--fabric--leaf-01",
+				Type:           apiutil.LLDPNeighborTypeFabric,
+				Expected:       apiutil.LLDPNeighbor{Name: "leaf-01", Port: "E1/1"},
+				Actual:         []apiutil.LLDPNeighbor{{Name: "leaf-01", Port: "E1/3"}},
+			},
+			"E1/5": {
+				Actual: []apiutil.LLDPNeighbor{{Name: "server-02", Port: "enp2s1"}},
+			},
+		},
+		"leaf-01": {
+			"E1/1": {
+				ConnectionName: "spine-01--fabric--leaf-01",
+				Type:           apiutil.LLDPNeighborTypeFabric,
+				Expected:       apiutil.LLDPNeighbor{Name: "spine-01", Port: "E1/1"},
+			},
+			"E1/2/1": {
+				ConnectionName: "server-01--unbundled--leaf-01",
+				Type:           apiutil.LLDPNeighborTypeServer,
+				Expected:       apiutil.LLDPNeighbor{Name: "server-01", Port: "enp2s1"},
+				Actual:         []apiutil.LLDPNeighbor{{Name: "server-01", Port: "enp2s1"}},
+			},
+			"E1/2/2": {
+				ConnectionName: "server-02--unbundled--leaf-01",
+				Type:           apiutil.LLDPNeighborTypeServer,
+				Expected:       apiutil.LLDPNeighbor{Name: "server-02", Port: "enp2s1"},
+			},
+		},
+	}
+
+	orig := neighborsFunc
+	t.Cleanup(func() { neighborsFunc = orig })
+	neighborsFunc = func(_ context.Context, _ kclient.Reader, sw *wiringapi.Switch) (map[string]apiutil.LLDPNeighborStatus, error) {
+		return neighbors[sw.Name], nil
+	}
+
+	kube := testWiring(t).GetClient()
+
+	mismatches, err := Validate(context.Background(), kube, ValidateOpts{Server: true})
+	require.NoError(t, err)
+	require.Equal(t, []Mismatch{
+		{
+			Type: MismatchMissing, Switch: "leaf-01", Port: "E1/1", Connection: "spine-01--fabric--leaf-01",
+			Expected: "spine-01/E1/1",
+			Fix:      "check cable and optics between leaf-01/E1/1 and spine-01/E1/1, make sure both ports are up",
+		},
+		{
+			Type: MismatchMissing, Switch: "leaf-01", Port: "E1/2/2", Connection: "server-02--unbundled--leaf-01",
+			Expected: "server-02/enp2s1",
+			Fix:      "check cable and optics between leaf-01/E1/2/2 and server-02/enp2s1, make sure both ports are up",
+		},
+		{
+			Type: MismatchMiswired, Switch: "spine-01", Port: "E1/1", Connection: "spine-01--fabric--leaf-01",
+			Expected: "leaf-01/E1/1", Actual: "leaf-01/E1/3",
+			Fix: "move cable on leaf-01 from port E1/3 to E1/1",
+		},
+		{
+			Type: MismatchUnexpected, Switch: "spine-01", Port: "E1/5",
+			Actual: "server-02/enp2s1",
+			Fix:    "move cable from spine-01/E1/5 to leaf-01/E1/2/2",
+		},
+	}, mismatches)
+
+	mismatches, err = Validate(context.Background(), kube, ValidateOpts{Switches: []string{"leaf-01"}})
+	require.NoError(t, err)
+	require.Len(t, mismatches, 1)
+	require.Equal(t, MismatchMissing, mismatches[0].Type)
+
+	_, err = Validate(context.Background(), kube, ValidateOpts{Switches: []string{"leaf-02"}})
+	require.Error(t, err)
+}
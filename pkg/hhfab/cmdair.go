@@ -7,11 +7,11 @@ import (
 	"context"
 )
 
-func AirGenerate(ctx context.Context, workDir, cacheDir string, hMode HydrateMode) error {
+func AirGenerate(ctx context.Context, workDir, cacheDir string, hMode HydrateMode, opts AirOpts) error {
 	cfg, err := load(ctx, workDir, cacheDir, nil, true, hMode, "")
 	if err != nil {
 		return err
 	}
 
-	return cfg.AirGenerate(ctx)
+	return cfg.AirGenerate(ctx, opts)
 }
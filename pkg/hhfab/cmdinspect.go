@@ -0,0 +1,64 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"go.githedgehog.com/fabric/pkg/hhfctl/inspect"
+	"go.githedgehog.com/fabric/pkg/util/kubeutil"
+	"go.githedgehog.com/fabricator/pkg/hhfab/cabling"
+)
+
+type InspectCablingOpts struct {
+	Kubeconfig string // defaults to the VLAB kubeconfig in the working dir
+	Switches   []string
+	Server     bool
+	Strict     bool // fail if any mismatch found
+}
+
+var ErrCablingMismatch = fmt.Errorf("cabling mismatch found")
+
+func InspectCabling(ctx context.Context, workDir string, opts InspectCablingOpts) error {
+	if opts.Kubeconfig == "" {
+		opts.Kubeconfig = filepath.Join(workDir, VLABDir, VLABKubeConfig)
+	}
+
+	cacheCancel, kube, err := kubeutil.NewClientWithCache(ctx, opts.Kubeconfig, schemeBuilders...)
+	if err != nil {
+		return fmt.Errorf("creating kube client: %w", err)
+	}
+	defer cacheCancel()
+
+	mismatches, err := cabling.Validate(ctx, kube, cabling.ValidateOpts{
+		Switches: opts.Switches,
+		Server:   opts.Server,
+	})
+	if err != nil {
+		return fmt.Errorf("validating cabling: %w", err)
+	}
+
+	if len(mismatches) == 0 {
+		slog.Info("Cabling matches the wiring")
+
+		return nil
+	}
+
+	rows := [][]string{}
+	for _, m := range mismatches {
+		rows = append(rows, []string{string(m.Type), m.Switch, m.Port, m.Connection, m.Expected, m.Actual, m.Fix})
+	}
+	fmt.Println(inspect.RenderTable([]string{"Type", "Switch", "Port", "Connection", "Expected", "Actual", "Suggested fix"}, rows))
+
+	slog.Warn("Cabling doesn't match the wiring", "mismatches", len(mismatches))
+
+	if opts.Strict {
+		return ErrCablingMismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"go.githedgehog.com/fabricator/pkg/util/apiutil"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type ReachabilityOpts struct {
+	WhatIf []string // YAML files with objects to add or replace before checking
+}
+
+type ReachabilityExplainOpts struct {
+	ReachabilityOpts
+	From string // server name
+	To   string // server or external name
+}
+
+type ReachabilityMatrixOpts struct {
+	ReachabilityOpts
+	Format ReachabilityFormat
+	Output string
+}
+
+// offlineReachability holds the connectivity matrix built the same way as for the release tests, servers and
+// externals are the matrix endpoints to look its entries up
+type offlineReachability struct {
+	kube           kclient.Client
+	matrix         *ConnectivityMatrix
+	servers        []*Endpoint
+	externals      []*Endpoint
+	gatewayEnabled bool
+}
+
+func loadOfflineReachability(ctx context.Context, workDir, cacheDir string, opts ReachabilityOpts) (*offlineReachability, error) {
+	c, err := load(ctx, workDir, cacheDir, nil, true, HydrateModeIfNotPresent, "")
+	if err != nil {
+		return nil, err
+	}
+
+	extra := []kclient.Object{}
+	for _, path := range opts.WhatIf {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading what-if file %q: %w", path, err)
+		}
+		objs, err := apiutil.NewLoader().Load(apiutil.FabricGatewayGVKs, data)
+		if err != nil {
+			return nil, fmt.Errorf("loading what-if file %q: %w", path, err)
+		}
+		extra = append(extra, objs...)
+	}
+
+	return newOfflineReachability(ctx, c.Client, extra, c.Fab.Spec.Config.Gateway.Enable)
+}
+
+func newOfflineReachability(ctx context.Context, kube kclient.Reader, extra []kclient.Object, gatewayEnabled bool) (*offlineReachability, error) {
+	offline, err := newOfflineReachabilityClient(ctx, kube, extra)
+	if err != nil {
+		return nil, fmt.Errorf("preparing offline client: %w", err)
+	}
+
+	servers, err := collectOfflineServerEndpoints(ctx, offline, nil)
+	if err != nil {
+		return nil, fmt.Errorf("collecting server endpoints: %w", err)
+	}
+
+	matrix, err := buildConnectivityMatrix(ctx, offline, servers, nil, gatewayEnabled)
+	if err != nil {
+		return nil, err
+	}
+
+	externals := []*Endpoint{}
+	for _, ep := range matrix.AllEndpoints {
+		if ep.External != nil {
+			externals = append(externals, ep)
+		}
+	}
+
+	return &offlineReachability{
+		kube:           offline,
+		matrix:         matrix,
+		servers:        servers,
+		externals:      externals,
+		gatewayEnabled: gatewayEnabled,
+	}, nil
+}
+
+func (r *offlineReachability) endpoints(name string) []*Endpoint {
+	out := []*Endpoint{}
+	for _, ep := range slices.Concat(r.servers, r.externals) {
+		if ep.Server != nil && ep.Server.Name == name || ep.External != nil && ep.External.ExternalName == name {
+			out = append(out, ep)
+		}
+	}
+
+	return out
+}
+
+func (r *offlineReachability) explain(ctx context.Context, w io.Writer, from, to string) error {
+	sources := r.endpoints(from)
+	if len(sources) == 0 || sources[0].Server == nil {
+		return fmt.Errorf("source %q is not a server attached to any VPC", from) //nolint:goerr113
+	}
+	destinations := r.endpoints(to)
+	if len(destinations) == 0 {
+		return fmt.Errorf("destination %q is neither a server attached to any VPC nor an external", to) //nolint:goerr113
+	}
+	if from == to {
+		return fmt.Errorf("source and destination are the same") //nolint:goerr113
+	}
+
+	explanations, err := explainAllReachability(ctx, r.kube, r.matrix, sources, destinations, r.gatewayEnabled)
+	if err != nil {
+		return err
+	}
+
+	for idx, e := range explanations {
+		if idx > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		printReachabilityExplanation(w, e)
+	}
+
+	return nil
+}
+
+func ReachabilityExplain(ctx context.Context, workDir, cacheDir string, opts ReachabilityExplainOpts) error {
+	r, err := loadOfflineReachability(ctx, workDir, cacheDir, opts.ReachabilityOpts)
+	if err != nil {
+		return err
+	}
+
+	return r.explain(ctx, os.Stdout, opts.From, opts.To)
+}
+
+func ReachabilityMatrix(ctx context.Context, workDir, cacheDir string, opts ReachabilityMatrixOpts) error {
+	if !slices.Contains(ReachabilityFormats, opts.Format) {
+		return fmt.Errorf("unsupported reachability matrix format: %s", opts.Format) //nolint:goerr113
+	}
+
+	r, err := loadOfflineReachability(ctx, workDir, cacheDir, opts.ReachabilityOpts)
+	if err != nil {
+		return err
+	}
+
+	explanations, err := explainAllReachability(ctx, r.kube, r.matrix, r.servers, slices.Concat(r.servers, r.externals), r.gatewayEnabled)
+	if err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	switch opts.Format {
+	case ReachabilityFormatCSV:
+		err = writeReachabilityCSV(buf, explanations)
+	case ReachabilityFormatHTML:
+		err = writeReachabilityHTML(buf, explanations)
+	}
+	if err != nil {
+		return fmt.Errorf("writing reachability matrix: %w", err)
+	}
+
+	output := opts.Output
+	if output == "" {
+		output = filepath.Join(workDir, ResultDir, ReachabilityFilename+"."+string(opts.Format))
+		if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+			return fmt.Errorf("creating result directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(output, buf.Bytes(), 0o644); err != nil { //nolint:gosec
+		return fmt.Errorf("writing %q: %w", output, err)
+	}
+
+	allowed := 0
+	for _, e := range explanations {
+		if e.Verdict == VerdictAllow {
+			allowed++
+		}
+	}
+	if rel, err := filepath.Rel(workDir, output); err == nil {
+		output = rel
+	}
+	slog.Info("Reachability matrix written", "file", output, "pairs", len(explanations), "allowed", allowed)
+
+	return nil
+}
@@ -6,10 +6,12 @@ package hhfab
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
 	"go.githedgehog.com/fabricator/pkg/fab/recipe"
@@ -68,6 +70,8 @@ type VLABUpOpts struct {
 	SetJoinToken         string
 	ObservabilityTargets string
 	VMSizesOverrides     VMSizes
+	ServerMode           VLABServerMode
+	SkipPreflight        bool
 	VLABRunOpts
 }
 
@@ -283,3 +287,88 @@ func DoSwitchReinstall(ctx context.Context, workDir, cacheDir string, opts Switc
 
 	return c.VLABSwitchReinstall(ctx, opts)
 }
+
+func DoVLABSnapshotSave(ctx context.Context, workDir, cacheDir, name string) error {
+	c, vlab, err := loadVLABForHelpers(ctx, workDir, cacheDir)
+	if err != nil {
+		return err
+	}
+
+	return c.VLABSnapshotSave(ctx, vlab, name)
+}
+
+func DoVLABSnapshotRestore(ctx context.Context, workDir, cacheDir, name string, opts VLABSnapshotOpts) error {
+	c, vlab, err := loadVLABForHelpers(ctx, workDir, cacheDir)
+	if err != nil {
+		return err
+	}
+
+	return c.VLABSnapshotRestore(ctx, vlab, name, opts)
+}
+
+func DoVLABSnapshotDelete(ctx context.Context, workDir, cacheDir, name string) error {
+	c, vlab, err := loadVLABForHelpers(ctx, workDir, cacheDir)
+	if err != nil {
+		return err
+	}
+
+	return c.VLABSnapshotDelete(ctx, vlab, name)
+}
+
+func DoVLABSnapshotList(workDir string, w io.Writer) error {
+	snaps, err := ListVLABSnapshots(workDir)
+	if err != nil {
+		return err
+	}
+
+	for _, snap := range snaps {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%d VMs\t%d VPCs\n", snap.Name, snap.Created.Local().Format(time.DateTime), len(snap.VMs), snap.VPCs); err != nil {
+			return fmt.Errorf("writing: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func DoVLABPlan(ctx context.Context, workDir, cacheDir string, opts VLABUpOpts, w io.Writer) error {
+	c, err := load(ctx, workDir, cacheDir, nil, true, HydrateModeIfNotPresent, "")
+	if err != nil {
+		return err
+	}
+
+	opts.SkipPreflight = true
+	vlab, err := c.PrepareVLAB(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("preparing VLAB: %w", err)
+	}
+
+	host, err := GetVLABHost(ctx, c.WorkDir)
+	if err != nil {
+		return fmt.Errorf("getting host resources: %w", err)
+	}
+
+	plan := PlanVLAB(vlab, host, opts.KillStale)
+	if err := plan.Print(w); err != nil {
+		return err
+	}
+
+	if plan.Failed() {
+		return fmt.Errorf("VLAB doesn't fit the host") //nolint:goerr113
+	}
+
+	return nil
+}
+
+func DoVLABChaos(ctx context.Context, workDir, cacheDir, schedulePath string, opts ChaosOpts) error {
+	schedule, err := LoadChaosSchedule(schedulePath)
+	if err != nil {
+		return err
+	}
+
+	c, vlab, err := loadVLABForHelpers(ctx, workDir, cacheDir)
+	if err != nil {
+		return err
+	}
+
+	return c.VLABChaos(ctx, vlab, schedule, opts)
+}
@@ -0,0 +1,201 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.githedgehog.com/fabric/pkg/hhfctl/inspect"
+	"go.githedgehog.com/fabricator/pkg/hhfab/cabling"
+	"go.githedgehog.com/fabricator/pkg/hhfab/diagram"
+	"go.githedgehog.com/fabricator/pkg/hhfab/netbox"
+	"go.githedgehog.com/fabricator/pkg/hhfab/wiringdiff"
+	"go.githedgehog.com/fabricator/pkg/util/apiutil"
+)
+
+func WiringCabling(ctx context.Context, workDir, cacheDir string, format cabling.Format, outputPath string) error {
+	if !slices.Contains(cabling.Formats, format) {
+		return fmt.Errorf("unsupported cabling format: %s", format) //nolint:goerr113
+	}
+
+	c, err := load(ctx, workDir, cacheDir, nil, true, HydrateModeIfNotPresent, "")
+	if err != nil {
+		return err
+	}
+
+	plan, err := cabling.Build(ctx, c.Client)
+	if err != nil {
+		return fmt.Errorf("building cabling plan: %w", err)
+	}
+
+	if outputPath == "" {
+		outputPath = filepath.Join(workDir, ResultDir, cabling.Filename+"."+string(format))
+	}
+
+	files, err := cabling.Write(plan, format, outputPath)
+	if err != nil {
+		return fmt.Errorf("writing cabling plan: %w", err)
+	}
+
+	for _, file := range files {
+		if rel, err := filepath.Rel(workDir, file); err == nil {
+			file = rel
+		}
+		slog.Info("Cabling plan written", "file", file, "cables", len(plan.Cables), "bomItems", len(plan.BOM))
+	}
+
+	return nil
+}
+
+func WiringDiff(ctx context.Context, workDir, oldPath, newPath string, format diagram.Format, style diagram.StyleType, outputPath string) error {
+	if format != "" && format != diagram.FormatDrawio && format != diagram.FormatMermaid {
+		return fmt.Errorf("unsupported diff diagram format: %s", format) //nolint:goerr113
+	}
+
+	oldWiring, err := wiringdiff.LoadDir(ctx, oldPath)
+	if err != nil {
+		return fmt.Errorf("loading old wiring: %w", err)
+	}
+	newWiring, err := wiringdiff.LoadDir(ctx, newPath)
+	if err != nil {
+		return fmt.Errorf("loading new wiring: %w", err)
+	}
+
+	report, err := wiringdiff.Compare(ctx, oldWiring.GetClient(), newWiring.GetClient())
+	if err != nil {
+		return fmt.Errorf("comparing wiring: %w", err)
+	}
+
+	if report.Empty() {
+		slog.Info("No wiring changes found")
+	} else {
+		changes := [][]string{}
+		for _, change := range report.Changes {
+			changes = append(changes, []string{
+				string(change.Op), change.Kind, change.Name,
+				strings.Join(change.Fields, ", "), strings.Join(change.Switches, ", "),
+			})
+		}
+		fmt.Println(inspect.RenderTable([]string{"Op", "Kind", "Name", "Fields", "Switches"}, changes))
+
+		switches := [][]string{}
+		for _, sw := range report.Switches {
+			switches = append(switches, []string{sw.Name, strings.Join(sw.Reasons, ", ")})
+		}
+		if len(switches) > 0 {
+			fmt.Println("Switches that need agent config update:")
+			fmt.Println(inspect.RenderTable([]string{"Switch", "Changes"}, switches))
+		}
+
+		slog.Info("Wiring changes found", "changes", len(report.Changes), "switches", len(report.Switches))
+	}
+
+	if format == "" {
+		return nil
+	}
+
+	topo, err := wiringdiff.Topology(ctx, oldWiring.GetClient(), newWiring.GetClient(), report)
+	if err != nil {
+		return fmt.Errorf("building diff topology: %w", err)
+	}
+
+	resultDir := filepath.Join(workDir, ResultDir)
+	switch format {
+	case diagram.FormatDrawio:
+		if outputPath == "" {
+			outputPath = filepath.Join(resultDir, "diff-"+diagram.DrawioFilename)
+		}
+		err = diagram.GenerateDrawio(resultDir, topo, style, outputPath)
+	case diagram.FormatMermaid:
+		if outputPath == "" {
+			outputPath = filepath.Join(resultDir, "diff-"+diagram.MermaidFilename)
+		}
+		err = diagram.GenerateMermaid(resultDir, topo, outputPath)
+	}
+	if err != nil {
+		return fmt.Errorf("generating diff diagram: %w", err)
+	}
+
+	slog.Info("Diff diagram written", "file", outputPath)
+
+	return nil
+}
+
+func WiringNetBoxExport(ctx context.Context, workDir, cacheDir string, format netbox.Format, site, outputPath string) error {
+	if !slices.Contains(netbox.Formats, format) {
+		return fmt.Errorf("unsupported netbox format: %s", format) //nolint:goerr113
+	}
+
+	c, err := load(ctx, workDir, cacheDir, nil, true, HydrateModeIfNotPresent, "")
+	if err != nil {
+		return err
+	}
+
+	data, err := netbox.Export(ctx, c.Client, netbox.ExportOpts{Site: site})
+	if err != nil {
+		return fmt.Errorf("exporting to netbox: %w", err)
+	}
+
+	if outputPath == "" {
+		outputPath = filepath.Join(workDir, ResultDir, netbox.Filename)
+		if format == netbox.FormatJSON {
+			outputPath += ".json"
+		}
+	}
+
+	files, err := netbox.Write(data, format, outputPath)
+	if err != nil {
+		return fmt.Errorf("writing netbox data: %w", err)
+	}
+
+	for _, file := range files {
+		if rel, err := filepath.Rel(workDir, file); err == nil {
+			file = rel
+		}
+		slog.Info("NetBox data written", "file", file, "devices", len(data.Devices), "interfaces", len(data.Interfaces), "cables", len(data.Cables))
+	}
+
+	return nil
+}
+
+func WiringNetBoxImport(ctx context.Context, inputPath, outputPath string, opts netbox.ImportOpts) error {
+	data, err := netbox.Read(inputPath)
+	if err != nil {
+		return fmt.Errorf("reading netbox data: %w", err)
+	}
+
+	objs, err := netbox.Import(data, opts)
+	if err != nil {
+		return fmt.Errorf("importing from netbox: %w", err)
+	}
+
+	l := apiutil.NewLoader()
+	if err := l.Add(ctx, objs...); err != nil {
+		return fmt.Errorf("adding imported objects: %w", err)
+	}
+
+	if outputPath == "" {
+		outputPath = netbox.Filename + ".yaml"
+	}
+
+	f, err := os.OpenFile(outputPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("creating wiring file: %w", err)
+	}
+	defer f.Close()
+
+	if err := apiutil.PrintInclude(ctx, l.GetClient(), f); err != nil {
+		return fmt.Errorf("writing wiring file: %w", err)
+	}
+
+	slog.Info("Wiring imported from NetBox", "file", outputPath, "objects", len(objs))
+
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package diagram
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// PropChange could be set on nodes and links to highlight them as changed, e.g. when rendering a wiring diff
+	PropChange = "change"
+	// PropCabling is set on links which cabling doesn't match the LLDP neighbors reported by the agents
+	PropCabling = "cabling"
+)
+
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+const cablingColor = "#D50000"
+
+var changeColors = map[string]string{
+	ChangeAdded:   "#2E7D32",
+	ChangeRemoved: "#C62828",
+	ChangeChanged: "#EF6C00",
+}
+
+// highlightDrawioChanges updates styles of the node cells for the nodes marked with PropChange
+func highlightDrawioChanges(model *MxGraphModel, topo Topology) {
+	nodeChanges := map[string]string{}
+	for _, node := range topo.Nodes {
+		if change := node.Properties[PropChange]; change != "" {
+			nodeChanges[node.ID] = change
+		}
+	}
+
+	if len(nodeChanges) == 0 {
+		return
+	}
+
+	for idx := range model.Root.MxCell {
+		cell := &model.Root.MxCell[idx]
+		if cell.Vertex != "1" {
+			continue
+		}
+
+		if change := nodeChanges[cell.ID]; change != "" {
+			cell.Style = strings.TrimSuffix(cell.Style, ";") + ";" + drawioChangeStyle(change)
+		}
+	}
+}
+
+// drawioLinkHighlightStyle returns extra style for the links marked with PropChange or PropCabling, cabling
+// mismatches take precedence as they're only available for the live topology
+func drawioLinkHighlightStyle(link Link) string {
+	if mismatch := link.Properties[PropCabling]; mismatch != "" {
+		return fmt.Sprintf("strokeColor=%s;strokeWidth=4;dashed=1;", cablingColor)
+	}
+	if change := link.Properties[PropChange]; change != "" {
+		return drawioChangeStyle(change)
+	}
+
+	return ""
+}
+
+func drawioChangeStyle(change string) string {
+	style := fmt.Sprintf("strokeColor=%s;strokeWidth=4;", changeColors[change])
+	if change == ChangeRemoved {
+		style += "dashed=1;opacity=60;"
+	}
+
+	return style
+}
+
+// mermaidChangeStyles returns style statements for the nodes marked with PropChange and the links marked with
+// PropChange or PropCabling, links are addressed by index in Mermaid so edgeKeys should have the mermaidEdgeKey of
+// each rendered link and an aggregated edge is highlighted if any of its links is marked
+func mermaidChangeStyles(topo Topology, edgeKeys []string) string {
+	lines := []string{}
+	for _, node := range topo.Nodes {
+		change := node.Properties[PropChange]
+		if change == "" {
+			continue
+		}
+
+		line := fmt.Sprintf("style %s stroke:%s,stroke-width:4px", cleanID(node.ID), changeColors[change])
+		if change == ChangeRemoved {
+			line += ",stroke-dasharray:5 5"
+		}
+		lines = append(lines, line+"\n")
+	}
+	sort.Strings(lines)
+
+	edgeStyles := map[string]string{}
+	for _, link := range topo.Links {
+		style := ""
+		if link.Properties[PropCabling] != "" {
+			style = fmt.Sprintf("stroke:%s,stroke-width:4px,stroke-dasharray:5 5", cablingColor)
+		} else if change := link.Properties[PropChange]; change != "" {
+			style = fmt.Sprintf("stroke:%s,stroke-width:4px", changeColors[change])
+			if change == ChangeRemoved {
+				style += ",stroke-dasharray:5 5"
+			}
+		}
+		if style == "" {
+			continue
+		}
+
+		key := mermaidEdgeKey(cleanID(link.Source), cleanID(link.Target), mermaidConnType(link.Type))
+		// cabling mismatches take precedence same as for draw.io
+		if existing, ok := edgeStyles[key]; !ok || (link.Properties[PropCabling] != "" && !strings.Contains(existing, cablingColor)) {
+			edgeStyles[key] = style
+		}
+	}
+
+	styleIndices := map[string][]string{}
+	for idx, key := range edgeKeys {
+		if style, ok := edgeStyles[key]; ok {
+			styleIndices[style] = append(styleIndices[style], strconv.Itoa(idx))
+		}
+	}
+	styles := make([]string, 0, len(styleIndices))
+	for style := range styleIndices {
+		styles = append(styles, style)
+	}
+	sort.Strings(styles)
+	for _, style := range styles {
+		lines = append(lines, fmt.Sprintf("linkStyle %s %s\n", strings.Join(styleIndices[style], ","), style))
+	}
+
+	return strings.Join(lines, "")
+}
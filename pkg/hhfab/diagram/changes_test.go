@@ -0,0 +1,95 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package diagram
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func changesTestTopology() Topology {
+	ports := func(src, tgt string) map[string]string {
+		return map[string]string{PropSourcePort: src, PropTargetPort: tgt}
+	}
+	withProp := func(props map[string]string, key, value string) map[string]string {
+		props[key] = value
+
+		return props
+	}
+
+	return Topology{
+		Nodes: []Node{
+			{ID: "spine-01", Type: NodeTypeSwitch, Properties: map[string]string{PropRole: SwitchRoleSpine}},
+			{ID: "leaf-01", Type: NodeTypeSwitch, Properties: map[string]string{PropRole: SwitchRoleLeaf}},
+			{ID: "leaf-02", Type: NodeTypeSwitch, Properties: map[string]string{PropRole: SwitchRoleLeaf, PropChange: ChangeAdded}},
+			{ID: "server-01", Type: NodeTypeServer},
+		},
+		Links: []Link{
+			{Source: "spine-01", Target: "leaf-01", Type: EdgeTypeFabric, Properties: ports("spine-01/E1/1", "leaf-01/E1/1")},
+			{Source: "spine-01", Target: "leaf-01", Type: EdgeTypeFabric, Properties: withProp(ports("spine-01/E1/2", "leaf-01/E1/2"), PropChange, ChangeRemoved)},
+			{Source: "spine-01", Target: "leaf-02", Type: EdgeTypeFabric, Properties: withProp(ports("spine-01/E1/3", "leaf-02/E1/1"), PropChange, ChangeAdded)},
+			{Source: "server-01", Target: "leaf-01", Type: EdgeTypeUnbundled, Properties: withProp(ports("server-01/enp2s1", "leaf-01/E1/5"), PropCabling, "unexpected neighbor")},
+		},
+	}
+}
+
+func TestMermaidChangeStyles(t *testing.T) {
+	topo := changesTestTopology()
+	res := generateMermaid(topo)
+
+	linkIdx := map[string]int{}
+	idx := 0
+	for _, line := range strings.Split(res, "\n") {
+		// legend links are rendered as " --- |" and go after all other links
+		if !strings.Contains(line, " ---|") {
+			continue
+		}
+		src, rest, _ := strings.Cut(line, " ---|")
+		_, tgt, _ := strings.Cut(rest, "| ")
+		linkIdx[mermaidEdgeKey(src, tgt, "")] = idx
+		idx++
+	}
+	require.Len(t, linkIdx, 3)
+
+	spineLeaf1 := linkIdx[mermaidEdgeKey(cleanID("spine-01"), cleanID("leaf-01"), "")]
+	spineLeaf2 := linkIdx[mermaidEdgeKey(cleanID("spine-01"), cleanID("leaf-02"), "")]
+	leafServer := linkIdx[mermaidEdgeKey(cleanID("leaf-01"), cleanID("server-01"), "")]
+
+	require.Contains(t, res, "style "+cleanID("leaf-02")+" stroke:"+changeColors[ChangeAdded]+",stroke-width:4px\n")
+	require.Contains(t, res, "linkStyle "+strconv.Itoa(spineLeaf1)+" stroke:"+changeColors[ChangeRemoved]+",stroke-width:4px,stroke-dasharray:5 5\n")
+	require.Contains(t, res, "linkStyle "+strconv.Itoa(spineLeaf2)+" stroke:"+changeColors[ChangeAdded]+",stroke-width:4px\n")
+	require.Contains(t, res, "linkStyle "+strconv.Itoa(leafServer)+" stroke:"+cablingColor+",stroke-width:4px,stroke-dasharray:5 5\n")
+
+	// change styles should go last to override the per-type link styles
+	require.Greater(t, strings.LastIndex(res, changeColors[ChangeAdded]), strings.LastIndex(res, "linkStyle default"))
+}
+
+func TestDrawioChangesParallelLinks(t *testing.T) {
+	topo := changesTestTopology()
+	model := createDrawioModel(topo, GetStyle(StyleTypeDefault))
+	highlightDrawioChanges(model, topo)
+
+	spineLeafEdges := 0
+	removed := 0
+	for _, cell := range model.Root.MxCell {
+		if cell.Edge != "1" || cell.Source != "spine-01" || cell.Target != "leaf-01" {
+			continue
+		}
+		spineLeafEdges++
+		if strings.Contains(cell.Style, "strokeColor="+changeColors[ChangeRemoved]) {
+			removed++
+		}
+	}
+	require.Equal(t, 2, spineLeafEdges)
+	require.Equal(t, 1, removed, "only the removed one of the parallel links should be highlighted")
+
+	for _, cell := range model.Root.MxCell {
+		if cell.Vertex == "1" && cell.ID == "leaf-02" {
+			require.Contains(t, cell.Style, "strokeColor="+changeColors[ChangeAdded])
+		}
+	}
+}
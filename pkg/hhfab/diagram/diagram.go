@@ -19,12 +19,16 @@ const (
 	FormatDrawio  Format = "drawio"
 	FormatDot     Format = "dot"
 	FormatMermaid Format = "mermaid"
+	FormatSVG     Format = "svg"
+	FormatPNG     Format = "png"
 )
 
 var Formats = []Format{
 	FormatDrawio,
 	FormatDot,
 	FormatMermaid,
+	FormatSVG,
+	FormatPNG,
 }
 
 func getDisplayPath(workDir, filePath string) string {
@@ -103,6 +107,28 @@ func Generate(ctx context.Context, workDir, resultDir string, client kclient.Rea
 		fmt.Printf("To render this diagram with Mermaid:\n")
 		fmt.Printf("1. Visit https://mermaid.live/ or use a Markdown editor with Mermaid support\n")
 		fmt.Printf("2. Copy the contents of %s into the editor\n", displayPath)
+	case FormatSVG:
+		if err := GenerateSVG(resultDir, topo, style, outputPath); err != nil {
+			return fmt.Errorf("generating SVG diagram: %w", err)
+		}
+		if outputPath != "" {
+			filePath = outputPath
+		} else {
+			filePath = filepath.Join(resultDir, SVGFilename)
+		}
+
+		slog.Info("Generated SVG diagram", "file", getDisplayPath(workDir, filePath), "style", style)
+	case FormatPNG:
+		if err := GeneratePNG(resultDir, topo, style, outputPath); err != nil {
+			return fmt.Errorf("generating PNG diagram: %w", err)
+		}
+		if outputPath != "" {
+			filePath = outputPath
+		} else {
+			filePath = filepath.Join(resultDir, PNGFilename)
+		}
+
+		slog.Info("Generated PNG diagram", "file", getDisplayPath(workDir, filePath), "style", style)
 	default:
 		return fmt.Errorf("unsupported diagram format: %s", format) //nolint:goerr113
 	}
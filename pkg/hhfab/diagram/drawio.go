@@ -137,6 +137,7 @@ func GenerateDrawio(workDir string, topo Topology, styleType StyleType, outputPa
 	generateLinkSpeedLayer = topo.HasAgentData // Only generate link speed layer when agent data available
 
 	model := createDrawioModel(topo, style)
+	highlightDrawioChanges(model, topo)
 	outputXML, err := xml.MarshalIndent(model, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshaling XML: %w", err)
@@ -932,7 +933,7 @@ func createParallelEdges(model *MxGraphModel, group LinkGroup, cellMap map[strin
 		edgeID := fmt.Sprintf("e%d_%d", edgeGroupID, i)
 
 		// Create edge style
-		edgeStyle := GetLinkStyleFromTheme(link, style) +
+		edgeStyle := GetLinkStyleFromTheme(link, style) + drawioLinkHighlightStyle(link) +
 			fmt.Sprintf("exitX=%.3f;exitY=%.3f;exitDx=0;exitDy=0;entryX=%.3f;entryY=%.3f;entryDx=0;entryDy=0;",
 				relSrcX, relSrcY, relTgtX, relTgtY)
 
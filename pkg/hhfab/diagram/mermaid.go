@@ -260,28 +260,7 @@ func generateMermaid(topo Topology) string {
 				connectionMap[key] = make(map[string][]string)
 			}
 
-			var connType string
-			switch link.Type {
-			case EdgeTypeFabric:
-				connType = EdgeTypeFabric
-			case EdgeTypeMesh:
-				connType = EdgeTypeMesh
-			case EdgeTypeBundled:
-				connType = EdgeTypeBundled
-			case EdgeTypeUnbundled:
-				connType = EdgeTypeUnbundled
-			case EdgeTypeESLAG:
-				connType = EdgeTypeESLAG
-			case EdgeTypeGateway:
-				connType = EdgeTypeGateway
-			case EdgeTypeExternal:
-				connType = EdgeTypeExternal
-			case EdgeTypeStaticExternal:
-				connType = EdgeTypeStaticExternal
-			default:
-				connType = "other"
-			}
-
+			connType := mermaidConnType(link.Type)
 			connectionMap[key][connType] = append(connectionMap[key][connType], portLabel)
 		}
 	}
@@ -306,6 +285,8 @@ func generateMermaid(topo Topology) string {
 	staticExternalLinks := []int{}
 
 	linkIndex := 0
+	// edgeKeys are the mermaidEdgeKey for each of the links by index, used to highlight changed links
+	edgeKeys := []string{}
 
 	b.WriteString("%% Connections\n\n")
 
@@ -370,6 +351,7 @@ func generateMermaid(topo Topology) string {
 						connection := fmt.Sprintf("%s ---|%q| %s", finalSourceID, portLabel, finalTargetID)
 						b.WriteString(connection + "\n")
 						gatewayLinks = append(gatewayLinks, linkIndex)
+						edgeKeys = append(edgeKeys, mermaidEdgeKey(finalSourceID, finalTargetID, connType))
 						linkIndex++
 					}
 				}
@@ -383,6 +365,7 @@ func generateMermaid(topo Topology) string {
 
 	// Group spine-leaf connections by spine
 	spineLeafMapBySpine := make(map[string][]string)
+	spineLeafKeys := make(map[string]string)
 
 	for key, connTypes := range connectionMap {
 		parts := strings.Split(key, "->")
@@ -407,9 +390,10 @@ func generateMermaid(topo Topology) string {
 		}
 
 		if isSpineLeaf {
-			for _, ports := range connTypes {
+			for connType, ports := range connTypes {
 				portLabel := strings.Join(ports, "<br>")
 				connection := fmt.Sprintf("%s ---|%q| %s", sourceID, portLabel, targetID)
+				spineLeafKeys[connection] = mermaidEdgeKey(sourceID, targetID, connType)
 
 				if spineLeafMapBySpine[spineID] == nil {
 					spineLeafMapBySpine[spineID] = []string{}
@@ -432,6 +416,7 @@ func generateMermaid(topo Topology) string {
 		for _, conn := range spineLeafMapBySpine[spineID] {
 			b.WriteString(conn + "\n")
 			spineLeafLinks = append(spineLeafLinks, linkIndex)
+			edgeKeys = append(edgeKeys, spineLeafKeys[conn])
 			linkIndex++
 		}
 		b.WriteString("\n")
@@ -542,6 +527,7 @@ func generateMermaid(topo Topology) string {
 				unbundledLinks = append(unbundledLinks, linkIndex)
 			}
 
+			edgeKeys = append(edgeKeys, mermaidEdgeKey(sourceID, targetID, connType))
 			linkIndex++
 		}
 		b.WriteString("\n")
@@ -689,6 +675,9 @@ func generateMermaid(topo Topology) string {
 	for _, connection := range meshConnections {
 		b.WriteString(connection + "\n")
 		meshLinks = append(meshLinks, linkIndex)
+		sourceID, rest, _ := strings.Cut(connection, " ---|")
+		_, targetID, _ := strings.Cut(rest, "| ")
+		edgeKeys = append(edgeKeys, mermaidEdgeKey(sourceID, targetID, EdgeTypeMesh))
 		linkIndex++
 	}
 	b.WriteString("\n")
@@ -732,6 +721,7 @@ func generateMermaid(topo Topology) string {
 					} else {
 						externalLinks = append(externalLinks, linkIndex)
 					}
+					edgeKeys = append(edgeKeys, mermaidEdgeKey(sourceID, targetID, connType))
 					linkIndex++
 				}
 			}
@@ -965,6 +955,8 @@ func generateMermaid(topo Topology) string {
 		}
 	}
 
+	b.WriteString(mermaidChangeStyles(topo, edgeKeys))
+
 	return b.String()
 }
 
@@ -1020,6 +1012,26 @@ func formatLabel(label string) string {
 	return strings.ReplaceAll(label, "\n", "<br>")
 }
 
+// mermaidConnType returns the connection type used to aggregate links between the same nodes into a single edge
+func mermaidConnType(linkType string) string {
+	switch linkType {
+	case EdgeTypeFabric, EdgeTypeMesh, EdgeTypeBundled, EdgeTypeUnbundled, EdgeTypeESLAG,
+		EdgeTypeGateway, EdgeTypeExternal, EdgeTypeStaticExternal:
+		return linkType
+	default:
+		return "other"
+	}
+}
+
+// mermaidEdgeKey identifies the aggregated edge between two nodes (cleaned IDs) regardless of its direction
+func mermaidEdgeKey(sourceID, targetID, connType string) string {
+	if sourceID > targetID {
+		sourceID, targetID = targetID, sourceID
+	}
+
+	return sourceID + "|" + targetID + "|" + connType
+}
+
 func cleanID(id string) string {
 	result := strings.ReplaceAll(id, "-", "_")
 
@@ -0,0 +1,181 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package diagram
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	pngScale      = 2 // pixels per layout unit
+	pngTextScale  = 2 // pixels per font pixel
+	pngLineHeight = (glyphHeight + 3) * pngTextScale
+	pngDashOn     = 12
+	pngDashOff    = 8
+)
+
+func GeneratePNG(workDir string, topo Topology, styleType StyleType, outputPath string) error {
+	var finalOutputPath string
+	if outputPath != "" {
+		finalOutputPath = outputPath
+	} else {
+		finalOutputPath = filepath.Join(workDir, PNGFilename)
+	}
+
+	data, err := generatePNG(buildRenderLayout(topo, GetStyle(styleType)))
+	if err != nil {
+		return fmt.Errorf("rendering PNG: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(finalOutputPath), 0o755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	if err := os.WriteFile(finalOutputPath, data, 0o600); err != nil {
+		return fmt.Errorf("writing PNG file: %w", err)
+	}
+
+	return nil
+}
+
+func generatePNG(layout renderLayout) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, layout.Width*pngScale, layout.Height*pngScale))
+	c := &pngCanvas{img: img}
+
+	bg := parseHexColor(layout.Background)
+	c.fillRect(0, 0, img.Bounds().Dx(), img.Bounds().Dy(), bg)
+
+	for _, line := range layout.Lines {
+		c.line(line.X1*pngScale, line.Y1*pngScale, line.X2*pngScale, line.Y2*pngScale,
+			line.Width*pngScale, line.Dashed, parseHexColor(line.Color))
+	}
+
+	for _, box := range layout.Boxes {
+		x, y, w, h := box.X*pngScale, box.Y*pngScale, box.W*pngScale, box.H*pngScale
+		c.fillRect(x, y, w, h, parseHexColor(box.Stroke))
+		border := 2 * pngScale
+		c.fillRect(x+border, y+border, w-2*border, h-2*border, parseHexColor(box.Fill))
+
+		textY := y + h/2 - len(box.Labels)*pngLineHeight/2 + (pngLineHeight-glyphHeight*pngTextScale)/2
+		for idx, label := range box.Labels {
+			c.textCentered(x+w/2, textY+idx*pngLineHeight, label, idx == 0, parseHexColor(box.Font))
+		}
+	}
+
+	// link labels are drawn on top of the nodes as they're usually in between the tiers anyway
+	for _, line := range layout.Lines {
+		if line.Label == "" {
+			continue
+		}
+		cx, cy := (line.X1+line.X2)/2*pngScale, (line.Y1+line.Y2)/2*pngScale
+		tw := textWidth(line.Label)
+		c.fillRect(cx-tw/2-pngTextScale*2, cy-glyphHeight*pngTextScale/2-pngTextScale*2,
+			tw+pngTextScale*4, glyphHeight*pngTextScale+pngTextScale*4, bg)
+		c.textCentered(cx, cy-glyphHeight*pngTextScale/2, line.Label, false, parseHexColor(line.Color))
+	}
+
+	if len(layout.Legend) > 0 {
+		top := layout.legendTop()
+		for idx, item := range layout.Legend {
+			y := (top + idx*renderLegendHeight + renderLegendHeight/2) * pngScale
+			c.line(renderMargin*pngScale, y, (renderMargin+40)*pngScale, y, item.Width*pngScale, item.Dashed, parseHexColor(item.Color))
+			c.text((renderMargin+50)*pngScale, y-glyphHeight*pngTextScale/2, item.Label, false, parseHexColor(renderDefaultText))
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		return nil, fmt.Errorf("encoding PNG: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// parseHexColor parses #rgb and #rrggbb colors and falls back to black for anything else
+func parseHexColor(s string) color.RGBA {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if len(s) != 6 || err != nil {
+		return color.RGBA{A: 0xff}
+	}
+
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff} //nolint:gosec
+}
+
+func textWidth(s string) int {
+	if s == "" {
+		return 0
+	}
+
+	return (len([]rune(s))*glyphAdvance - 1) * pngTextScale
+}
+
+type pngCanvas struct {
+	img *image.RGBA
+}
+
+func (c *pngCanvas) fillRect(x, y, w, h int, col color.RGBA) {
+	r := image.Rect(x, y, x+w, y+h).Intersect(c.img.Bounds())
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		for px := r.Min.X; px < r.Max.X; px++ {
+			c.img.SetRGBA(px, py, col)
+		}
+	}
+}
+
+// line draws a straight line of the given width by stamping squares along it
+func (c *pngCanvas) line(x1, y1, x2, y2, width int, dashed bool, col color.RGBA) {
+	dx, dy := float64(x2-x1), float64(y2-y1)
+	length := math.Hypot(dx, dy)
+	steps := int(math.Ceil(length))
+	half := width / 2
+
+	for step := 0; step <= steps; step++ {
+		if dashed && step%(pngDashOn+pngDashOff) >= pngDashOn {
+			continue
+		}
+
+		t := 0.0
+		if steps > 0 {
+			t = float64(step) / float64(steps)
+		}
+		px := x1 + int(math.Round(dx*t))
+		py := y1 + int(math.Round(dy*t))
+		c.fillRect(px-half, py-half, max(width, 1), max(width, 1), col)
+	}
+}
+
+func (c *pngCanvas) text(x, y int, s string, bold bool, col color.RGBA) {
+	for _, r := range s {
+		rows := glyph(r)
+		for gy, row := range rows {
+			for gx := 0; gx < glyphWidth; gx++ {
+				if row&(1<<(glyphWidth-1-gx)) == 0 {
+					continue
+				}
+				c.fillRect(x+gx*pngTextScale, y+gy*pngTextScale, pngTextScale, pngTextScale, col)
+				if bold {
+					c.fillRect(x+gx*pngTextScale+1, y+gy*pngTextScale, pngTextScale, pngTextScale, col)
+				}
+			}
+		}
+		x += glyphAdvance * pngTextScale
+	}
+}
+
+func (c *pngCanvas) textCentered(cx, y int, s string, bold bool, col color.RGBA) {
+	c.text(cx-textWidth(s)/2, y, s, bold, col)
+}
@@ -0,0 +1,264 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package diagram
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	renderMargin       = 40
+	renderNodeGap      = 40
+	renderTierGap      = 120
+	renderLegendHeight = 24
+	renderDefaultFill  = "#ffffff"
+	renderDefaultLine  = "#000000"
+	renderDefaultText  = "#000000"
+)
+
+// renderLayout is a fully positioned diagram used by the native SVG and PNG renderers
+type renderLayout struct {
+	Width      int
+	Height     int
+	Background string
+	Boxes      []renderBox
+	Lines      []renderLine
+	Legend     []renderLegendItem
+}
+
+type renderBox struct {
+	Node   Node
+	X, Y   int
+	W, H   int
+	Fill   string
+	Stroke string
+	Font   string
+	Round  bool
+	Labels []string
+}
+
+type renderLine struct {
+	X1, Y1 int
+	X2, Y2 int
+	Color  string
+	Width  int
+	Dashed bool
+	Label  string
+}
+
+type renderLegendItem struct {
+	Label  string
+	Color  string
+	Width  int
+	Dashed bool
+}
+
+// styleValue returns the value of the key from the draw.io style string, e.g. "strokeColor"
+func styleValue(style, key string) string {
+	for _, part := range strings.Split(style, ";") {
+		k, v, ok := strings.Cut(part, "=")
+		if ok && k == key {
+			return v
+		}
+	}
+
+	return ""
+}
+
+func styleColor(style, key, def string) string {
+	if v := styleValue(style, key); strings.HasPrefix(v, "#") {
+		return v
+	}
+
+	return def
+}
+
+func styleWidth(style string, def int) int {
+	if v, err := strconv.Atoi(styleValue(style, "strokeWidth")); err == nil && v > 0 {
+		return v
+	}
+
+	return def
+}
+
+func linkLegendLabel(linkType string) string {
+	switch linkType {
+	case EdgeTypeFabric:
+		return "Fabric"
+	case EdgeTypeMesh:
+		return "Mesh"
+	case EdgeTypeBundled:
+		return "Bundled"
+	case EdgeTypeUnbundled:
+		return "Unbundled"
+	case EdgeTypeESLAG:
+		return "ESLAG"
+	case EdgeTypeGateway:
+		return "Gateway"
+	case EdgeTypeExternal:
+		return "External"
+	case EdgeTypeStaticExternal:
+		return "Static External"
+	default:
+		return linkType
+	}
+}
+
+// buildRenderLayout places nodes in horizontal tiers (gateways and externals, spines, leaves, servers and
+// unused switches) using the same tiering as the other formats and connects them with one line per node pair
+func buildRenderLayout(topo Topology, style Style) renderLayout {
+	layers := sortNodes(topo.Nodes, topo.Links)
+
+	tiers := [][]Node{
+		append(append([]Node{}, layers.Gateway...), layers.External...),
+		layers.Spine,
+		layers.Leaf,
+		layers.Server,
+		layers.Unused,
+	}
+
+	layout := renderLayout{
+		Background: style.BackgroundColor,
+	}
+	if layout.Background == "" {
+		layout.Background = renderDefaultFill
+	}
+
+	maxTierWidth := 0
+	for _, tier := range tiers {
+		width := 0
+		for idx, node := range tier {
+			w, _ := GetNodeDimensions(node)
+			if idx > 0 {
+				width += renderNodeGap
+			}
+			width += w
+		}
+		maxTierWidth = max(maxTierWidth, width)
+	}
+
+	boxes := map[string]*renderBox{}
+	y := renderMargin
+	for _, tier := range tiers {
+		if len(tier) == 0 {
+			continue
+		}
+
+		width, height := 0, 0
+		for idx, node := range tier {
+			w, h := GetNodeDimensions(node)
+			if idx > 0 {
+				width += renderNodeGap
+			}
+			width += w
+			height = max(height, h)
+		}
+
+		x := renderMargin + (maxTierWidth-width)/2
+		for _, node := range tier {
+			w, h := GetNodeDimensions(node)
+			nodeStyle := GetNodeStyleFromTheme(node, style)
+			label := node.Label
+			if label == "" {
+				label = node.ID
+			}
+			layout.Boxes = append(layout.Boxes, renderBox{
+				Node:   node,
+				X:      x,
+				Y:      y + (height-h)/2,
+				W:      w,
+				H:      h,
+				Fill:   styleColor(nodeStyle, "fillColor", renderDefaultFill),
+				Stroke: styleColor(nodeStyle, "strokeColor", renderDefaultLine),
+				Font:   styleColor(nodeStyle, "fontColor", renderDefaultText),
+				Round:  node.Type != NodeTypeServer,
+				Labels: strings.Split(label, "\n"),
+			})
+			x += w + renderNodeGap
+		}
+
+		y += height + renderTierGap
+	}
+	for idx := range layout.Boxes {
+		boxes[layout.Boxes[idx].Node.ID] = &layout.Boxes[idx]
+	}
+
+	groups := groupLinks(topo.Links)
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Source != groups[j].Source {
+			return groups[i].Source < groups[j].Source
+		}
+
+		return groups[i].Target < groups[j].Target
+	})
+
+	legend := map[string]renderLegendItem{}
+	for _, group := range groups {
+		src, srcOk := boxes[group.Source]
+		tgt, tgtOk := boxes[group.Target]
+		if !srcOk || !tgtOk || len(group.Links) == 0 {
+			continue
+		}
+
+		link := group.Links[0]
+		linkStyle := GetLinkStyleFromTheme(link, style)
+		line := renderLine{
+			X1:     src.X + src.W/2,
+			Y1:     src.Y + src.H/2,
+			X2:     tgt.X + tgt.W/2,
+			Y2:     tgt.Y + tgt.H/2,
+			Color:  styleColor(linkStyle, "strokeColor", renderDefaultLine),
+			Width:  styleWidth(linkStyle, 2),
+			Dashed: styleValue(linkStyle, "dashed") == "1",
+		}
+		if len(group.Links) > 1 {
+			line.Label = fmt.Sprintf("x%d", len(group.Links))
+		}
+
+		if _, exists := legend[link.Type]; !exists && link.Type != "" {
+			legend[link.Type] = renderLegendItem{
+				Label:  linkLegendLabel(link.Type),
+				Color:  line.Color,
+				Width:  line.Width,
+				Dashed: line.Dashed,
+			}
+		}
+
+		for _, l := range group.Links {
+			if l.Properties[PropCabling] != "" {
+				line.Color, line.Width, line.Dashed = cablingColor, 4, true
+				legend[PropCabling] = renderLegendItem{Label: "Cabling Mismatch", Color: cablingColor, Width: 4, Dashed: true}
+
+				break
+			}
+		}
+		layout.Lines = append(layout.Lines, line)
+	}
+
+	legendTypes := make([]string, 0, len(legend))
+	for linkType := range legend {
+		legendTypes = append(legendTypes, linkType)
+	}
+	sort.Strings(legendTypes)
+	for _, linkType := range legendTypes {
+		layout.Legend = append(layout.Legend, legend[linkType])
+	}
+
+	layout.Width = maxTierWidth + 2*renderMargin
+	layout.Height = y - renderTierGap + renderMargin
+	if len(layout.Legend) > 0 {
+		layout.Height += renderLegendHeight * len(layout.Legend)
+	}
+	layout.Width = max(layout.Width, 300)
+	layout.Height = max(layout.Height, 2*renderMargin)
+
+	return layout
+}
+
+func (l renderLayout) legendTop() int {
+	return l.Height - renderMargin/2 - renderLegendHeight*len(l.Legend)
+}
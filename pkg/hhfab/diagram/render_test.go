@@ -0,0 +1,98 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package diagram
+
+import (
+	"bytes"
+	"flag"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+func renderTestTopology() Topology {
+	return Topology{
+		Nodes: []Node{
+			{ID: "spine-01", Type: NodeTypeSwitch, Label: "spine-01\nspine", Properties: map[string]string{PropRole: SwitchRoleSpine}},
+			{ID: "leaf-01", Type: NodeTypeSwitch, Label: "leaf-01\nserver-leaf", Properties: map[string]string{PropRole: SwitchRoleLeaf}},
+			{ID: "leaf-02", Type: NodeTypeSwitch, Label: "leaf-02\nserver-leaf", Properties: map[string]string{PropRole: SwitchRoleLeaf}},
+			{ID: "server-01", Type: NodeTypeServer, Label: "server-01", Description: "server <01> & co"},
+			{ID: "server-02", Type: NodeTypeServer, Label: "server-02"},
+			{ID: "gateway-1", Type: NodeTypeGateway, Label: "gateway-1"},
+			{ID: "external-1", Type: NodeTypeExternal, Label: "external-1"},
+		},
+		Links: []Link{
+			{Source: "spine-01", Target: "leaf-01", Type: EdgeTypeFabric},
+			{Source: "spine-01", Target: "leaf-01", Type: EdgeTypeFabric},
+			{Source: "spine-01", Target: "leaf-02", Type: EdgeTypeFabric, Properties: map[string]string{PropCabling: "E1/1 is connected to spine-02/E1/2"}},
+			{Source: "gateway-1", Target: "spine-01", Type: EdgeTypeGateway},
+			{Source: "leaf-01", Target: "external-1", Type: EdgeTypeExternal},
+			{Source: "server-01", Target: "leaf-01", Type: EdgeTypeBundled},
+			{Source: "server-02", Target: "leaf-01", Type: EdgeTypeESLAG},
+			{Source: "server-02", Target: "leaf-02", Type: EdgeTypeESLAG},
+		},
+	}
+}
+
+func checkGolden(t *testing.T, name string, actual []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll("testdata", 0o755))
+		require.NoError(t, os.WriteFile(path, actual, 0o644)) //nolint:gosec
+
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err, "golden file missing, run with -update to create it")
+	require.Equal(t, string(expected), string(actual), "output differs from %s, run with -update if it's expected", path)
+}
+
+func TestGenerateSVGGolden(t *testing.T) {
+	for _, style := range StyleTypes {
+		t.Run(string(style), func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), SVGFilename)
+			require.NoError(t, GenerateSVG("", renderTestTopology(), style, out))
+
+			data, err := os.ReadFile(out)
+			require.NoError(t, err)
+			checkGolden(t, "diagram-"+string(style)+".svg", data)
+		})
+	}
+}
+
+func TestGeneratePNGGolden(t *testing.T) {
+	for _, style := range StyleTypes {
+		t.Run(string(style), func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), PNGFilename)
+			require.NoError(t, GeneratePNG("", renderTestTopology(), style, out))
+
+			data, err := os.ReadFile(out)
+			require.NoError(t, err)
+
+			img, err := png.Decode(bytes.NewReader(data))
+			require.NoError(t, err)
+			layout := buildRenderLayout(renderTestTopology(), GetStyle(style))
+			require.Equal(t, layout.Width*pngScale, img.Bounds().Dx())
+			require.Equal(t, layout.Height*pngScale, img.Bounds().Dy())
+
+			checkGolden(t, "diagram-"+string(style)+".png", data)
+		})
+	}
+}
+
+func TestRenderLayoutDeterministic(t *testing.T) {
+	style := GetStyle(StyleTypeDefault)
+	first := generateSVG(buildRenderLayout(renderTestTopology(), style))
+	for range 10 {
+		require.Equal(t, first, generateSVG(buildRenderLayout(renderTestTopology(), style)))
+	}
+}
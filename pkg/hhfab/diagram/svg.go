@@ -0,0 +1,110 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package diagram
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	svgFontFamily = "Arial, Helvetica, sans-serif"
+	svgFontSize   = 11
+	svgLineHeight = 14
+)
+
+func GenerateSVG(workDir string, topo Topology, styleType StyleType, outputPath string) error {
+	var finalOutputPath string
+	if outputPath != "" {
+		finalOutputPath = outputPath
+	} else {
+		finalOutputPath = filepath.Join(workDir, SVGFilename)
+	}
+
+	svg := generateSVG(buildRenderLayout(topo, GetStyle(styleType)))
+
+	if err := os.MkdirAll(filepath.Dir(finalOutputPath), 0o755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	if err := os.WriteFile(finalOutputPath, []byte(svg), 0o600); err != nil {
+		return fmt.Errorf("writing SVG file: %w", err)
+	}
+
+	return nil
+}
+
+func generateSVG(layout renderLayout) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" font-family=\"%s\" font-size=\"%d\">\n",
+		layout.Width, layout.Height, layout.Width, layout.Height, svgFontFamily, svgFontSize)
+	fmt.Fprintf(&b, "  <rect x=\"0\" y=\"0\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", layout.Width, layout.Height, layout.Background)
+
+	b.WriteString("  <g id=\"links\">\n")
+	for _, line := range layout.Lines {
+		dash := ""
+		if line.Dashed {
+			dash = " stroke-dasharray=\"6,4\""
+		}
+		fmt.Fprintf(&b, "    <line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"%s\" stroke-width=\"%d\"%s/>\n",
+			line.X1, line.Y1, line.X2, line.Y2, line.Color, line.Width, dash)
+		if line.Label != "" {
+			fmt.Fprintf(&b, "    <text x=\"%d\" y=\"%d\" text-anchor=\"middle\" fill=\"%s\" stroke=\"%s\" stroke-width=\"3\" paint-order=\"stroke\">%s</text>\n",
+				(line.X1+line.X2)/2, (line.Y1+line.Y2)/2+svgFontSize/2-1, line.Color, layout.Background, html.EscapeString(line.Label))
+		}
+	}
+	b.WriteString("  </g>\n")
+
+	b.WriteString("  <g id=\"nodes\">\n")
+	for _, box := range layout.Boxes {
+		rx := 0
+		if box.Round {
+			rx = 8
+		}
+		fmt.Fprintf(&b, "    <g id=\"%s\">\n", html.EscapeString(box.Node.ID))
+		if box.Node.Description != "" {
+			fmt.Fprintf(&b, "      <title>%s</title>\n", html.EscapeString(box.Node.Description))
+		}
+		fmt.Fprintf(&b, "      <rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" rx=\"%d\" fill=\"%s\" stroke=\"%s\" stroke-width=\"2\"/>\n",
+			box.X, box.Y, box.W, box.H, rx, box.Fill, box.Stroke)
+
+		textY := box.Y + box.H/2 - (len(box.Labels)-1)*svgLineHeight/2 + svgFontSize/2 - 1
+		for idx, label := range box.Labels {
+			weight := ""
+			if idx == 0 {
+				weight = " font-weight=\"bold\""
+			}
+			fmt.Fprintf(&b, "      <text x=\"%d\" y=\"%d\" text-anchor=\"middle\" fill=\"%s\"%s>%s</text>\n",
+				box.X+box.W/2, textY+idx*svgLineHeight, box.Font, weight, html.EscapeString(label))
+		}
+		b.WriteString("    </g>\n")
+	}
+	b.WriteString("  </g>\n")
+
+	if len(layout.Legend) > 0 {
+		b.WriteString("  <g id=\"legend\">\n")
+		top := layout.legendTop()
+		for idx, item := range layout.Legend {
+			y := top + idx*renderLegendHeight + renderLegendHeight/2
+			dash := ""
+			if item.Dashed {
+				dash = " stroke-dasharray=\"6,4\""
+			}
+			fmt.Fprintf(&b, "    <line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"%s\" stroke-width=\"%d\"%s/>\n",
+				renderMargin, y, renderMargin+40, y, item.Color, item.Width, dash)
+			fmt.Fprintf(&b, "    <text x=\"%d\" y=\"%d\" fill=\"%s\">%s</text>\n",
+				renderMargin+50, y+svgFontSize/2-1, renderDefaultText, html.EscapeString(item.Label))
+		}
+		b.WriteString("  </g>\n")
+	}
+
+	b.WriteString("</svg>\n")
+
+	return b.String()
+}
@@ -6,6 +6,7 @@ package diagram
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"sort"
 	"strings"
 
@@ -15,6 +16,7 @@ import (
 	vpcapi "go.githedgehog.com/fabric/api/vpc/v1beta1"
 	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
 	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
+	"go.githedgehog.com/fabricator/pkg/hhfab/cabling"
 	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -978,6 +980,11 @@ func GetTopologyFor(ctx context.Context, client kclient.Reader) (Topology, error
 	// Set flag indicating whether agent runtime data is available
 	topo.HasAgentData = hasAgentData
 
+	// Mark links that don't match the LLDP neighbors reported by the agents (live mode only)
+	if hasAgentData && len(agents.Items) > 0 {
+		markCablingMismatches(ctx, client, &topo)
+	}
+
 	// Enrich gateway nodes with underlay data (ASN, ProtocolIP, VTEPIP).
 	// Done after all passes so connection-discovered gateways are included.
 	gateways := &gwapi.GatewayList{}
@@ -1320,32 +1327,14 @@ func parseVPCSubnetRef(ref string) (string, string, bool) {
 	return parts[0], parts[1], true
 }
 
-// getPortSpeed returns the speed of a port based on switch port group configuration
+// getPortSpeed returns the configured speed of a port (e.g. "s5248-04/E1/3")
 func getPortSpeed(portName string, sw *wiringapi.Switch, profile *wiringapi.SwitchProfile) string {
-	if profile == nil || sw == nil {
-		return ""
-	}
-
-	// Extract just the port name (e.g., "E1/3" from "s5248-04/E1/3")
 	portParts := wiringapi.SplitPortName(portName)
 	if len(portParts) < 2 {
 		return ""
 	}
-	port := portParts[1]
-
-	// Find port in profile to get its group
-	portSpec, ok := profile.Spec.Ports[port]
-	if !ok {
-		return ""
-	}
 
-	// Get speed from switch's port group speeds
-	speed, ok := sw.Spec.PortGroupSpeeds[portSpec.Group]
-	if !ok {
-		return ""
-	}
-
-	return speed
+	return cabling.PortSpeed(sw, profile, portParts[1])
 }
 
 // getSpeedFromAgent gets interface speed from agent for a specific port
@@ -1451,38 +1440,60 @@ func getPortNOSName(portName string, switchMap map[string]*wiringapi.Switch, pro
 		return ""
 	}
 
-	switchName := portParts[0]
-	port := portParts[1]
-
-	sw, ok := switchMap[switchName]
+	sw, ok := switchMap[portParts[0]]
 	if !ok {
 		return ""
 	}
 
-	profile, ok := profileMap[sw.Spec.Profile]
-	if !ok {
-		return ""
+	return cabling.PortNOSName(sw, profileMap[sw.Spec.Profile], portParts[1])
+}
+
+func markCablingMismatches(ctx context.Context, client kclient.Reader, topo *Topology) {
+	mismatches, err := cabling.Validate(ctx, client, cabling.ValidateOpts{Server: true})
+	if err != nil {
+		slog.Warn("Failed to validate cabling against LLDP neighbors", "err", err)
+
+		return
 	}
 
-	// For breakout ports (e.g., E1/5/1), try the base port first (E1/5)
-	basePort := port
-	if parts := strings.Split(port, "/"); len(parts) > 2 {
-		basePort = strings.Join(parts[:2], "/") // E1/5/1 -> E1/5
+	nodes := map[string]bool{}
+	for _, node := range topo.Nodes {
+		nodes[node.ID] = true
 	}
 
-	portSpec, ok := profile.Spec.Ports[basePort]
-	if !ok {
-		// If base port not found, try exact match
-		portSpec, ok = profile.Spec.Ports[port]
-		if !ok {
-			return ""
+	ports := map[string]string{}
+	for _, m := range mismatches {
+		local := m.Switch + "/" + m.Port
+		if m.Type != cabling.MismatchUnexpected {
+			ports[local] = string(m.Type)
+
+			continue
 		}
-	}
 
-	// Use BaseNOSName if available (for breakout ports), otherwise use NOSName
-	if portSpec.BaseNOSName != "" {
-		return portSpec.BaseNOSName
+		// unexpected neighbors are only shown if they're known nodes as there is no connection for them
+		if device := wiringapi.SplitPortName(m.Actual)[0]; nodes[device] {
+			topo.Links = append(topo.Links, Link{
+				Source: m.Switch,
+				Target: device,
+				Properties: map[string]string{
+					PropSourcePort: local,
+					PropTargetPort: m.Actual,
+					PropCabling:    string(m.Type),
+				},
+			})
+		}
 	}
 
-	return portSpec.NOSName
+	for idx, link := range topo.Links {
+		mismatch, ok := ports[link.Properties[PropSourcePort]]
+		if !ok {
+			mismatch, ok = ports[link.Properties[PropTargetPort]]
+		}
+		if ok {
+			if link.Properties == nil {
+				topo.Links[idx].Properties = map[string]string{}
+			}
+			topo.Links[idx].Properties[PropCabling] = mismatch
+		}
+	}
 }
@@ -38,16 +38,21 @@ type discoveredIP struct {
 	prefix netip.Prefix
 }
 
-// discoverServerIPs returns every eligible IPv4 address configured on the
-// server, paired with the interface it lives on. The management interface
-// (enp2s0), the docker bridge (docker0), and the loopback 127.0.0.1/8 entry
-// are skipped; other lo addresses (hostBGP /32 VIPs) are kept.
+// discoverServerIPs returns every eligible IPv4 and IPv6 address configured
+// on the server, paired with the interface it lives on. The management
+// interface (enp2s0), the docker bridge (docker0), the loopback 127.0.0.1/8
+// and ::1/128 entries and IPv6 link-local addresses are skipped; other lo
+// addresses (hostBGP /32 VIPs) are kept.
 func discoverServerIPs(ctx context.Context, sshCfg *sshutil.Config, server string) ([]discoveredIP, error) {
-	stdout, stderr, err := sshCfg.Run(ctx, "ip -o -4 addr show | awk '{print $2, $4}'")
+	stdout, stderr, err := sshCfg.Run(ctx, "ip -o addr show | awk '{print $2, $4}'")
 	if err != nil {
 		return nil, fmt.Errorf("running ip addr show on %s: %w: %s", server, err, stderr)
 	}
 
+	return parseServerIPs(stdout, server)
+}
+
+func parseServerIPs(stdout, server string) ([]discoveredIP, error) {
 	lines := strings.Split(strings.TrimSpace(stdout), "\n")
 	out := make([]discoveredIP, 0, len(lines))
 	for _, line := range lines {
@@ -62,6 +67,9 @@ func discoverServerIPs(ctx context.Context, sshCfg *sshutil.Config, server strin
 		if err != nil {
 			return nil, fmt.Errorf("parsing %q on %s: %w", fields[1], server, err)
 		}
+		if prefix.Addr().Is6() && (prefix.Addr().IsLinkLocalUnicast() || prefix.Addr().IsLoopback()) {
+			continue
+		}
 		out = append(out, discoveredIP{iface: fields[0], prefix: prefix})
 	}
 
@@ -98,93 +106,9 @@ type serverAttachment struct {
 // *Endpoint per (server, vpc, subnet) attachment for each server in the
 // `servers` filter (nil → all servers attached to at least one VPC).
 func CollectServerEndpoints(ctx context.Context, kube kclient.Client, ssh SSHResolver, servers []string) ([]*Endpoint, []DroppedEndpoint, error) {
-	attaches := &vpcapi.VPCAttachmentList{}
-	if err := kube.List(ctx, attaches); err != nil {
-		return nil, nil, fmt.Errorf("listing VPCAttachments: %w", err)
-	}
-
-	connCache := map[string]*wiringapi.Connection{}
-	getConn := func(name string) (*wiringapi.Connection, error) {
-		if c, ok := connCache[name]; ok {
-			return c, nil
-		}
-		c := &wiringapi.Connection{}
-		if err := kube.Get(ctx, kclient.ObjectKey{Name: name, Namespace: kmetav1.NamespaceDefault}, c); err != nil {
-			return nil, fmt.Errorf("getting connection %q: %w", name, err)
-		}
-		connCache[name] = c
-
-		return c, nil
-	}
-
-	vpcCache := map[string]*vpcapi.VPC{}
-	getVPC := func(name string) (*vpcapi.VPC, error) {
-		if v, ok := vpcCache[name]; ok {
-			return v, nil
-		}
-		v := &vpcapi.VPC{}
-		if err := kube.Get(ctx, kclient.ObjectKey{Name: name, Namespace: kmetav1.NamespaceDefault}, v); err != nil {
-			return nil, fmt.Errorf("getting VPC %q: %w", name, err)
-		}
-		vpcCache[name] = v
-
-		return v, nil
-	}
-
-	want := map[string]bool{}
-	for _, s := range servers {
-		want[s] = true
-	}
-
-	serverAttachments := map[string][]serverAttachment{}
-	for _, attach := range attaches.Items {
-		conn, err := getConn(attach.Spec.Connection)
-		if err != nil {
-			return nil, nil, fmt.Errorf("resolving attachment %q: %w", attach.Name, err)
-		}
-		_, srvs, _, _, err := conn.Spec.Endpoints()
-		if err != nil {
-			return nil, nil, fmt.Errorf("getting endpoints of connection %q: %w", conn.Name, err)
-		}
-		if len(srvs) != 1 {
-			continue
-		}
-		serverName := srvs[0]
-		if len(want) > 0 && !want[serverName] {
-			continue
-		}
-
-		vpc, err := getVPC(attach.Spec.VPCName())
-		if err != nil {
-			return nil, nil, fmt.Errorf("resolving attachment %q: %w", attach.Name, err)
-		}
-		subnetName := attach.Spec.SubnetName()
-		subnet, ok := vpc.Spec.Subnets[subnetName]
-		if !ok {
-			return nil, nil, fmt.Errorf("attachment %q references missing subnet %s/%s", attach.Name, vpc.Name, subnetName) //nolint:goerr113
-		}
-		cidr, err := netip.ParsePrefix(subnet.Subnet)
-		if err != nil {
-			return nil, nil, fmt.Errorf("parsing VPC %s/%s subnet CIDR %q: %w", vpc.Name, subnetName, subnet.Subnet, err)
-		}
-
-		// A multihomed hostBGP server has several VPCAttachments pointing at the
-		// same (vpc, subnet) — one per connection — but a single /32 VIP. Collapse
-		// them to one candidate attachment so the server yields exactly one
-		// endpoint instead of dropping the duplicates with misleading warnings.
-		if subnet.HostBGP && slices.ContainsFunc(serverAttachments[serverName], func(a serverAttachment) bool {
-			return a.vpcName == vpc.Name && a.subnetName == subnetName
-		}) {
-			continue
-		}
-
-		serverAttachments[serverName] = append(serverAttachments[serverName], serverAttachment{
-			vpcName:    vpc.Name,
-			subnetName: subnetName,
-			subnetCIDR: cidr,
-			hostBGP:    subnet.HostBGP,
-			attachName: attach.Name,
-		})
+	serverAttachments, err := collectServerAttachments(ctx, kube, servers)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	type collected struct {
@@ -248,6 +172,14 @@ func CollectServerEndpoints(ctx context.Context, kube kclient.Client, ssh SSHRes
 					bestIdx = i
 				}
 			}
+			if bestIdx < 0 && !slices.ContainsFunc(atts, func(att serverAttachment) bool {
+				return att.subnetCIDR.Addr().Is4() == ip.prefix.Addr().Is4()
+			}) {
+				// e.g. SLAAC IPv6 addresses on a server with only IPv4 subnets attached, not an endpoint to test
+				slog.Debug("Server IP family does not match any attachment subnet, ignoring", "server", p.serverName, "iface", ip.iface, "addr", ip.prefix.String())
+
+				continue
+			}
 			if bestIdx < 0 {
 				slog.Warn("Server IP does not match any attachment subnet", "server", p.serverName, "iface", ip.iface, "addr", ip.prefix.String())
 				dropped = append(dropped, DroppedEndpoint{
@@ -294,6 +226,100 @@ func CollectServerEndpoints(ctx context.Context, kube kclient.Client, ssh SSHRes
 	return out, dropped, nil
 }
 
+// collectServerAttachments returns the VPC attachments of the servers in the `servers` filter (nil → all servers)
+func collectServerAttachments(ctx context.Context, kube kclient.Reader, servers []string) (map[string][]serverAttachment, error) {
+	attaches := &vpcapi.VPCAttachmentList{}
+	if err := kube.List(ctx, attaches); err != nil {
+		return nil, fmt.Errorf("listing VPCAttachments: %w", err)
+	}
+
+	connCache := map[string]*wiringapi.Connection{}
+	getConn := func(name string) (*wiringapi.Connection, error) {
+		if c, ok := connCache[name]; ok {
+			return c, nil
+		}
+		c := &wiringapi.Connection{}
+		if err := kube.Get(ctx, kclient.ObjectKey{Name: name, Namespace: kmetav1.NamespaceDefault}, c); err != nil {
+			return nil, fmt.Errorf("getting connection %q: %w", name, err)
+		}
+		connCache[name] = c
+
+		return c, nil
+	}
+
+	vpcCache := map[string]*vpcapi.VPC{}
+	getVPC := func(name string) (*vpcapi.VPC, error) {
+		if v, ok := vpcCache[name]; ok {
+			return v, nil
+		}
+		v := &vpcapi.VPC{}
+		if err := kube.Get(ctx, kclient.ObjectKey{Name: name, Namespace: kmetav1.NamespaceDefault}, v); err != nil {
+			return nil, fmt.Errorf("getting VPC %q: %w", name, err)
+		}
+		vpcCache[name] = v
+
+		return v, nil
+	}
+
+	want := map[string]bool{}
+	for _, s := range servers {
+		want[s] = true
+	}
+
+	serverAttachments := map[string][]serverAttachment{}
+	for _, attach := range attaches.Items {
+		conn, err := getConn(attach.Spec.Connection)
+		if err != nil {
+			return nil, fmt.Errorf("resolving attachment %q: %w", attach.Name, err)
+		}
+		_, srvs, _, _, err := conn.Spec.Endpoints()
+		if err != nil {
+			return nil, fmt.Errorf("getting endpoints of connection %q: %w", conn.Name, err)
+		}
+		if len(srvs) != 1 {
+			continue
+		}
+		serverName := srvs[0]
+		if len(want) > 0 && !want[serverName] {
+			continue
+		}
+
+		vpc, err := getVPC(attach.Spec.VPCName())
+		if err != nil {
+			return nil, fmt.Errorf("resolving attachment %q: %w", attach.Name, err)
+		}
+		subnetName := attach.Spec.SubnetName()
+		subnet, ok := vpc.Spec.Subnets[subnetName]
+		if !ok {
+			return nil, fmt.Errorf("attachment %q references missing subnet %s/%s", attach.Name, vpc.Name, subnetName) //nolint:goerr113
+		}
+		cidr, err := netip.ParsePrefix(subnet.Subnet)
+		if err != nil {
+			return nil, fmt.Errorf("parsing VPC %s/%s subnet CIDR %q: %w", vpc.Name, subnetName, subnet.Subnet, err)
+		}
+
+		// A multihomed hostBGP server has several VPCAttachments pointing at the
+		// same (vpc, subnet) — one per connection — but a single /32 VIP. Collapse
+		// them to one candidate attachment so the server yields exactly one
+		// endpoint instead of dropping the duplicates with misleading warnings.
+		if subnet.HostBGP && slices.ContainsFunc(serverAttachments[serverName], func(a serverAttachment) bool {
+			return a.vpcName == vpc.Name && a.subnetName == subnetName
+		}) {
+			continue
+		}
+
+		serverAttachments[serverName] = append(serverAttachments[serverName], serverAttachment{
+			vpcName:    vpc.Name,
+			subnetName: subnetName,
+			subnetCIDR: cidr,
+			hostBGP:    subnet.HostBGP,
+			attachName: attach.Name,
+		})
+	}
+
+	return serverAttachments, nil
+}
+
 // ReplaceServerEndpoints reconciles the matrix's endpoints for one
 // server against newEPs:
 //   - Existing endpoints whose (vpc, subnet) match an entry in newEPs
@@ -327,3 +327,26 @@ func TestReplaceServerEndpoints_PreservesHostBGP(t *testing.T) {
 	require.Same(t, ep, m.AllEndpoints[0])
 	require.True(t, ep.Server.HostBGP, "HostBGP should be copied across on in-place update")
 }
+
+func TestParseServerIPs(t *testing.T) {
+	out := `lo 127.0.0.1/8
+lo 10.0.5.10/32
+lo ::1/128
+enp2s0 172.30.0.10/21
+enp2s0 fe80::5054:ff:fe00:1/64
+bond0.1001 10.0.1.10/24
+bond0.1001 fd00:0:0:1::10/64
+bond0.1001 fe80::5054:ff:fe00:2/64
+docker0 172.17.0.1/16`
+
+	ips, err := parseServerIPs(out, "server-01")
+	require.NoError(t, err)
+	require.Equal(t, []discoveredIP{
+		{iface: "lo", prefix: netip.MustParsePrefix("10.0.5.10/32")},
+		{iface: "bond0.1001", prefix: netip.MustParsePrefix("10.0.1.10/24")},
+		{iface: "bond0.1001", prefix: netip.MustParsePrefix("fd00:0:0:1::10/64")},
+	}, ips)
+
+	_, err = parseServerIPs("bond0 foo/24", "server-01")
+	require.Error(t, err)
+}
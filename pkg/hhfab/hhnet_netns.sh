@@ -0,0 +1,53 @@
+
+# Overrides for servers running in network namespaces (hhfab vlab up --server-mode netns): there is no
+# systemd-networkd inside, so DHCP client is started directly and tracked by the pidfiles per namespace.
+
+HHNET_RUN_DIR=/run/hhfab-netns
+
+function restart_networkd() {
+    for pidfile in "$HHNET_RUN_DIR/$HHNET_NETNS"-*.pid; do
+        [ -f "$pidfile" ] || continue
+        sudo kill "$(cat "$pidfile")" 2> /dev/null || true
+        sudo rm -f "$pidfile"
+    done
+}
+
+function start_dhcp() {
+    local iface_name=$1
+
+    if ! command -v dhclient > /dev/null; then
+        echo "dhclient is required for netns servers" >&2
+        exit 1
+    fi
+
+    sudo mkdir -p "$HHNET_RUN_DIR"
+    sudo dhclient -4 -nw \
+        -pf "$HHNET_RUN_DIR/$HHNET_NETNS-$iface_name.pid" \
+        -lf "$HHNET_RUN_DIR/$HHNET_NETNS-$iface_name.leases" \
+        "$iface_name"
+}
+
+function get_ip() {
+    local iface_name=$1
+    local ip=""
+    local max_attempts=300 # 5 minutes
+    local attempt=0
+
+    ip=$(ip a s "$iface_name" | awk '/inet / {print $2}')
+    if [ -z "$ip" ]; then
+        start_dhcp "$iface_name"
+    fi
+
+    while [ -z "$ip" ]; do
+        attempt=$((attempt + 1))
+        ip=$(ip a s "$iface_name" | awk '/inet / {print $2}')
+        [ "$attempt" -ge "$max_attempts" ] && break
+        sleep 1
+    done
+    if [ -z "$ip" ]; then
+        echo "Failed to get IP address for $iface_name" >&2
+        exit 1
+    fi
+    echo "$ip"
+}
+
@@ -94,7 +94,7 @@ type ServerEndpoint struct {
 	VPC     string // e.g. "vpc-01"
 	Subnet  string // e.g. "default"
 	HostBGP bool
-	IP      netip.Addr
+	IP      netip.Addr // IPv4 or IPv6, same family as the attachment subnet
 }
 
 type ExternalEndpoint struct {
@@ -201,6 +201,16 @@ func OverlayMatrixNAT(
 }
 
 func BuildConnectivityMatrix(ctx context.Context, kube kclient.Client, serverEndpoints []*Endpoint, dropped []DroppedEndpoint) (*ConnectivityMatrix, error) {
+	f, _, _, err := fab.GetFabAndNodes(ctx, kube, fab.GetFabAndNodesOpts{AllowNotHydrated: true})
+	if err != nil {
+		return nil, fmt.Errorf("getting fab for connectivity matrix: %w", err)
+	}
+
+	return buildConnectivityMatrix(ctx, kube, serverEndpoints, dropped, f.Spec.Config.Gateway.Enable)
+}
+
+// buildConnectivityMatrix is BuildConnectivityMatrix for the clients without Fabricator, e.g. the offline one
+func buildConnectivityMatrix(ctx context.Context, kube kclient.Reader, serverEndpoints []*Endpoint, dropped []DroppedEndpoint, gatewayEnabled bool) (*ConnectivityMatrix, error) {
 	matrix := NewConnectivityMatrix()
 	matrix.AllEndpoints = append(matrix.AllEndpoints, serverEndpoints...)
 	matrix.dropped = append(matrix.dropped, dropped...)
@@ -211,8 +221,8 @@ func BuildConnectivityMatrix(ctx context.Context, kube kclient.Client, serverEnd
 	}
 	matrix.AllEndpoints = append(matrix.AllEndpoints, buildExternalEndpoints(externalList.Items)...)
 
-	if err := matrix.Repopulate(ctx, kube); err != nil {
-		return nil, err
+	if err := populateConnectivityMatrix(ctx, kube, matrix, gatewayEnabled); err != nil {
+		return nil, fmt.Errorf("populating connectivity matrix: %w", err)
 	}
 
 	return matrix, nil
@@ -439,6 +449,12 @@ func reachabilityFromExpectation(e ConnectivityExpectation) Reachability {
 	}
 }
 
+// crossAddrFamily reports a source server and target of different IP families: servers attached to subnets of both
+// families have an endpoint per family and only the same-family pairs are tested
+func crossAddrFamily(src *Endpoint, toIP netip.Addr) bool {
+	return src.Server != nil && src.Server.IP.IsValid() && toIP.IsValid() && src.Server.IP.Is4() != toIP.Is4()
+}
+
 func IsSameEndpointNode(a, b *Endpoint) bool {
 	if a == nil || b == nil {
 		return false
@@ -500,6 +516,9 @@ func runMatrixServerServerPhase(ctx context.Context, opts TestConnectivityOpts,
 			if !toIP.IsValid() {
 				return fmt.Errorf("matrix entry %s→%s (vpc %s/%s) has no valid target IP", src.Server.Name, dst.Server.Name, dst.Server.VPC, dst.Server.Subnet) //nolint:goerr113
 			}
+			if crossAddrFamily(src, toIP) {
+				continue
+			}
 
 			expected := reachabilityFromExpectation(entry)
 			bidir := false
@@ -747,6 +766,9 @@ func runMatrixProtoPortPhase(ctx context.Context, opts TestConnectivityOpts, mat
 
 					continue
 				}
+				if crossAddrFamily(src, toIP) {
+					continue
+				}
 
 				switch pp.Protocol {
 				case "icmp":
@@ -906,7 +928,7 @@ func (c *Config) TestConnectivityWithMatrix(ctx context.Context, vlab *VLAB, opt
 }
 
 func runMatrixIperfPortForward(ctx context.Context, opts TestConnectivityOpts, iperfs *semaphore.Weighted, from string, ssh *sshutil.Config, toIP netip.Addr, toPort uint16, expected Reachability) *IperfError {
-	target := fmt.Sprintf("%s:%d", toIP.String(), toPort)
+	target := netip.AddrPortFrom(toIP, toPort).String()
 	why := expectationWhy(expected)
 	logArgs := []any{"from", from, "target", target, "expected", expected.Reachable}
 	if expected.Reason != "" {
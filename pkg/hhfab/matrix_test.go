@@ -4,6 +4,7 @@
 package hhfab
 
 import (
+	"net/netip"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -138,3 +139,34 @@ func TestSetVPCToVPCProtoVerdict_AccumulatesScopesAndPreservesPeering(t *testing
 	// Not applied to a server outside the destination VPC.
 	require.False(t, m.HasProtoPortEntries(a1, c1), "vpc-3 destination untouched")
 }
+
+func TestCrossAddrFamily(t *testing.T) {
+	v4 := netip.MustParseAddr("10.0.1.10")
+	v6 := netip.MustParseAddr("fd00:0:0:1::10")
+
+	// dual-stack server has an endpoint per family
+	srcV4 := &Endpoint{Server: &ServerEndpoint{Name: "server-01", VPC: "vpc-01", Subnet: "subnet-01", IP: v4}}
+	srcV6 := &Endpoint{Server: &ServerEndpoint{Name: "server-01", VPC: "vpc-01", Subnet: "subnet-02", IP: v6}}
+	dstV4 := netip.MustParseAddr("10.0.2.10")
+	dstV6 := netip.MustParseAddr("fd00:0:0:2::10")
+
+	for _, tt := range []struct {
+		name string
+		src  *Endpoint
+		to   netip.Addr
+		want bool
+	}{
+		{name: "v4 to v4", src: srcV4, to: dstV4},
+		{name: "v6 to v6", src: srcV6, to: dstV6},
+		{name: "v4 to v6", src: srcV4, to: dstV6, want: true},
+		{name: "v6 to v4", src: srcV6, to: dstV4, want: true},
+		{name: "v4-mapped v6 is v6", src: srcV4, to: netip.MustParseAddr("::ffff:10.0.2.10"), want: true},
+		{name: "no source IP", src: &Endpoint{Server: &ServerEndpoint{Name: "server-02"}}, to: dstV6},
+		{name: "no target IP", src: srcV6, to: netip.Addr{}},
+		{name: "external source", src: &Endpoint{External: &ExternalEndpoint{ExternalName: "ext-01"}}, to: dstV6},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, crossAddrFamily(tt.src, tt.to))
+		})
+	}
+}
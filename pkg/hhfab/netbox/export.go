@@ -0,0 +1,281 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
+	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
+	"go.githedgehog.com/fabricator/pkg/hhfab/cabling"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type ExportOpts struct {
+	Site string
+}
+
+// Export converts switches, servers, gateway nodes and connections into NetBox devices, interfaces and cables,
+// external links only produce the switch interface as the remote device isn't part of the wiring so they can't be
+// imported back
+func Export(ctx context.Context, kube kclient.Reader, opts ExportOpts) (*Data, error) {
+	if opts.Site == "" {
+		opts.Site = DefaultSite
+	}
+
+	data := &Data{
+		Devices:    []Device{},
+		Interfaces: []Interface{},
+		Cables:     []Cable{},
+	}
+
+	profiles := &wiringapi.SwitchProfileList{}
+	if err := kube.List(ctx, profiles); err != nil {
+		return nil, fmt.Errorf("listing switch profiles: %w", err)
+	}
+	manufacturers := map[string]string{}
+	for _, profile := range profiles.Items {
+		manufacturers[profile.Name] = manufacturer(profile.Spec.DisplayName)
+	}
+
+	switches := &wiringapi.SwitchList{}
+	if err := kube.List(ctx, switches); err != nil {
+		return nil, fmt.Errorf("listing switches: %w", err)
+	}
+	for _, sw := range switches.Items {
+		dev := Device{
+			Name:         sw.Name,
+			Role:         string(sw.Spec.Role),
+			Manufacturer: manufacturers[sw.Spec.Profile],
+			DeviceType:   sw.Spec.Profile,
+			Site:         opts.Site,
+			Rack:         sw.Annotations[cabling.RackAnnotation],
+			Status:       StatusActive,
+			Description:  sw.Spec.Description,
+			Tags:         sw.Spec.Groups,
+		}
+		if dev.Manufacturer == "" {
+			dev.Manufacturer = DefaultManufacturer
+		}
+		if sw.Spec.Redundancy.Group != "" {
+			dev.CustomFields = map[string]string{
+				FieldRedundancyGroup: sw.Spec.Redundancy.Group,
+				FieldRedundancyType:  string(sw.Spec.Redundancy.Type),
+			}
+		}
+		data.Devices = append(data.Devices, dev)
+	}
+
+	servers := &wiringapi.ServerList{}
+	if err := kube.List(ctx, servers); err != nil {
+		return nil, fmt.Errorf("listing servers: %w", err)
+	}
+	for _, server := range servers.Items {
+		deviceType := server.Spec.Profile
+		if deviceType == "" {
+			deviceType = DeviceTypeServer
+		}
+		data.Devices = append(data.Devices, Device{
+			Name:         server.Name,
+			Role:         RoleServer,
+			Manufacturer: DefaultManufacturer,
+			DeviceType:   deviceType,
+			Site:         opts.Site,
+			Rack:         server.Annotations[cabling.RackAnnotation],
+			Status:       StatusActive,
+			Description:  server.Spec.Description,
+		})
+	}
+
+	nodes := &fabapi.FabNodeList{}
+	if err := kube.List(ctx, nodes); err != nil {
+		return nil, fmt.Errorf("listing nodes: %w", err)
+	}
+	for _, node := range nodes.Items {
+		if !slices.Contains(node.Spec.Roles, fabapi.NodeRoleGateway) {
+			continue
+		}
+		data.Devices = append(data.Devices, Device{
+			Name:         node.Name,
+			Role:         RoleGateway,
+			Manufacturer: DefaultManufacturer,
+			DeviceType:   DeviceTypeGateway,
+			Site:         opts.Site,
+			Rack:         node.Annotations[cabling.RackAnnotation],
+			Status:       StatusActive,
+		})
+	}
+
+	devices := map[string]bool{}
+	for _, dev := range data.Devices {
+		devices[dev.Name] = true
+	}
+
+	plan, err := cabling.Build(ctx, kube)
+	if err != nil {
+		return nil, fmt.Errorf("building cabling plan: %w", err)
+	}
+
+	ifaces := map[string]bool{}
+	addIface := func(device, port, nosPort, speed, description string) {
+		if ifaces[device+"/"+port] {
+			return
+		}
+		ifaces[device+"/"+port] = true
+
+		ifaceType := interfaceTypes[speed]
+		if ifaceType == "" {
+			ifaceType = InterfaceTypeOther
+		}
+		data.Interfaces = append(data.Interfaces, Interface{
+			Device:      device,
+			Name:        port,
+			Type:        ifaceType,
+			Speed:       speedKbps(speed),
+			Label:       nosPort,
+			Description: description,
+		})
+	}
+
+	for _, cable := range plan.Cables {
+		if cable.BDevice == "" || !devices[cable.BDevice] {
+			slog.Warn("Exporting only switch interface for connection without remote device, it won't be imported back", "connection", cable.Connection, "port", cable.ADevice+"/"+cable.APort)
+			addIface(cable.ADevice, cable.APort, cable.ANOSPort, cable.Speed, cable.Type+" connection "+cable.Connection)
+
+			continue
+		}
+
+		addIface(cable.ADevice, cable.APort, cable.ANOSPort, cable.Speed, "")
+		addIface(cable.BDevice, cable.BPort, cable.BNOSPort, cable.Speed, "")
+		data.Cables = append(data.Cables, Cable{
+			SideADevice: cable.ADevice,
+			SideAType:   TerminationIface,
+			SideAName:   cable.APort,
+			SideBDevice: cable.BDevice,
+			SideBType:   TerminationIface,
+			SideBName:   cable.BPort,
+			Status:      StatusActive,
+			Label:       cable.Connection,
+		})
+	}
+
+	sort.Slice(data.Devices, func(i, j int) bool {
+		return data.Devices[i].Name < data.Devices[j].Name
+	})
+
+	return data, nil
+}
+
+// speedKbps converts speed such as 25G or 100M into Kbps used by NetBox, 0 if unknown
+func speedKbps(speed string) int {
+	multipliers := map[string]int{"M": 1_000, "G": 1_000_000}
+	for suffix, mult := range multipliers {
+		if num, ok := strings.CutSuffix(speed, suffix); ok {
+			if n, err := strconv.Atoi(num); err == nil {
+				return n * mult
+			}
+		}
+	}
+
+	return 0
+}
+
+// Write writes NetBox data as a single JSON file or as a directory with CSV files for devices, interfaces and
+// cables and returns the list of files written
+func Write(data *Data, format Format, outputPath string) ([]string, error) {
+	switch format {
+	case FormatJSON:
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+			return nil, fmt.Errorf("creating output directory: %w", err)
+		}
+
+		out, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("marshaling json: %w", err)
+		}
+		if err := os.WriteFile(outputPath, append(out, '\n'), 0o600); err != nil {
+			return nil, fmt.Errorf("writing json: %w", err)
+		}
+
+		return []string{outputPath}, nil
+	case FormatCSV:
+		if err := os.MkdirAll(outputPath, 0o755); err != nil {
+			return nil, fmt.Errorf("creating output directory: %w", err)
+		}
+
+		files := []string{}
+		for name, rows := range map[string][][]string{
+			DevicesFile:    deviceRows(data.Devices),
+			InterfacesFile: interfaceRows(data.Interfaces),
+			CablesFile:     cableRows(data.Cables),
+		} {
+			path := filepath.Join(outputPath, name)
+			if err := cabling.WriteCSV(path, rows); err != nil {
+				return nil, fmt.Errorf("writing %s: %w", name, err)
+			}
+			files = append(files, path)
+		}
+		sort.Strings(files)
+
+		return files, nil
+	default:
+		return nil, fmt.Errorf("unsupported netbox format: %s", format) //nolint:goerr113
+	}
+}
+
+var (
+	deviceHeader = []string{
+		"name", "role", "manufacturer", "device_type", "site", "rack", "status", "description", "tags",
+		"cf_" + FieldRedundancyGroup, "cf_" + FieldRedundancyType,
+	}
+	interfaceHeader = []string{"device", "name", "type", "speed", "label", "description"}
+	cableHeader     = []string{
+		"side_a_device", "side_a_type", "side_a_name", "side_b_device", "side_b_type", "side_b_name", "status", "label",
+	}
+)
+
+func deviceRows(devices []Device) [][]string {
+	rows := [][]string{deviceHeader}
+	for _, dev := range devices {
+		rows = append(rows, []string{
+			dev.Name, dev.Role, dev.Manufacturer, dev.DeviceType, dev.Site, dev.Rack, dev.Status, dev.Description,
+			strings.Join(dev.Tags, ","), dev.CustomFields[FieldRedundancyGroup], dev.CustomFields[FieldRedundancyType],
+		})
+	}
+
+	return rows
+}
+
+func interfaceRows(ifaces []Interface) [][]string {
+	rows := [][]string{interfaceHeader}
+	for _, iface := range ifaces {
+		speed := ""
+		if iface.Speed > 0 {
+			speed = strconv.Itoa(iface.Speed)
+		}
+		rows = append(rows, []string{iface.Device, iface.Name, iface.Type, speed, iface.Label, iface.Description})
+	}
+
+	return rows
+}
+
+func cableRows(cables []Cable) [][]string {
+	rows := [][]string{cableHeader}
+	for _, c := range cables {
+		rows = append(rows, []string{
+			c.SideADevice, c.SideAType, c.SideAName, c.SideBDevice, c.SideBType, c.SideBName, c.Status, c.Label,
+		})
+	}
+
+	return rows
+}
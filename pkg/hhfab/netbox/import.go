@@ -0,0 +1,507 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package netbox
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.githedgehog.com/fabric/api/meta"
+	vpcapi "go.githedgehog.com/fabric/api/vpc/v1beta1"
+	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
+	"go.githedgehog.com/fabricator/pkg/hhfab/cabling"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// roleLeaf is a generic leaf role commonly used in NetBox, it's imported as server-leaf
+const roleLeaf = "leaf"
+
+const DefaultIPv4Subnet = "10.0.0.0/16"
+
+// connectionTypes are all known connection types, used to detect the type from the connection name
+var connectionTypes = []string{
+	wiringapi.ConnectionTypeUnbundled,
+	wiringapi.ConnectionTypeBundled,
+	wiringapi.ConnectionTypeMCLAG,
+	wiringapi.ConnectionTypeMCLAGDomain,
+	wiringapi.ConnectionTypeESLAG,
+	wiringapi.ConnectionTypeFabric,
+	wiringapi.ConnectionTypeMesh,
+	wiringapi.ConnectionTypeGateway,
+	wiringapi.ConnectionTypeVPCLoopback,
+	wiringapi.ConnectionTypeExternal,
+	wiringapi.ConnectionTypeStaticExternal,
+}
+
+// DefaultVLANRange is the same as generated for VLAB
+var DefaultVLANRange = meta.VLANRange{From: 1000, To: 2999}
+
+type ImportOpts struct {
+	// Profiles maps NetBox device types to the switch profiles, device type is used as is if not mapped
+	Profiles map[string]string
+	// VLANRanges are used for the default VLAN namespace, DefaultVLANRange if empty
+	VLANRanges []meta.VLANRange
+	// IPv4Subnets are used for the default IPv4 namespace, DefaultIPv4Subnet if empty
+	IPv4Subnets []string
+}
+
+// ParseVLANRange parses VLAN range such as 1000-2999 or a single VLAN such as 1000
+func ParseVLANRange(in string) (meta.VLANRange, error) {
+	fromStr, toStr, ok := strings.Cut(in, "-")
+	if !ok {
+		toStr = fromStr
+	}
+
+	from, err := strconv.ParseUint(strings.TrimSpace(fromStr), 10, 16)
+	if err != nil {
+		return meta.VLANRange{}, fmt.Errorf("parsing VLAN range %q start: %w", in, err)
+	}
+	to, err := strconv.ParseUint(strings.TrimSpace(toStr), 10, 16)
+	if err != nil {
+		return meta.VLANRange{}, fmt.Errorf("parsing VLAN range %q end: %w", in, err)
+	}
+	if from > to {
+		return meta.VLANRange{}, fmt.Errorf("invalid VLAN range %q: start is greater than end", in) //nolint:goerr113
+	}
+
+	return meta.VLANRange{From: uint16(from), To: uint16(to)}, nil
+}
+
+// Read reads NetBox data from a JSON file or a directory with devices, interfaces and cables CSV files
+func Read(path string) (*Data, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("checking input: %w", err)
+	}
+
+	if !stat.IsDir() {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading input: %w", err)
+		}
+
+		data := &Data{}
+		if err := json.Unmarshal(raw, data); err != nil {
+			return nil, fmt.Errorf("unmarshaling json: %w", err)
+		}
+
+		return data, nil
+	}
+
+	data := &Data{}
+
+	devices, err := readCSV(filepath.Join(path, DevicesFile))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", DevicesFile, err)
+	}
+	for _, row := range devices {
+		dev := Device{
+			Name:         row["name"],
+			Role:         row["role"],
+			Manufacturer: row["manufacturer"],
+			DeviceType:   row["device_type"],
+			Site:         row["site"],
+			Rack:         row["rack"],
+			Status:       row["status"],
+			Description:  row["description"],
+		}
+		for _, tag := range strings.Split(row["tags"], ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				dev.Tags = append(dev.Tags, tag)
+			}
+		}
+		for key, value := range row {
+			if field, ok := strings.CutPrefix(key, "cf_"); ok && value != "" {
+				if dev.CustomFields == nil {
+					dev.CustomFields = map[string]string{}
+				}
+				dev.CustomFields[field] = value
+			}
+		}
+		data.Devices = append(data.Devices, dev)
+	}
+
+	// interfaces aren't needed for the wiring, but they're still parsed if available
+	if _, err := os.Stat(filepath.Join(path, InterfacesFile)); err == nil {
+		ifaces, err := readCSV(filepath.Join(path, InterfacesFile))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", InterfacesFile, err)
+		}
+		for _, row := range ifaces {
+			speed, _ := strconv.Atoi(row["speed"])
+			data.Interfaces = append(data.Interfaces, Interface{
+				Device:      row["device"],
+				Name:        row["name"],
+				Type:        row["type"],
+				Speed:       speed,
+				Label:       row["label"],
+				Description: row["description"],
+			})
+		}
+	}
+
+	cables, err := readCSV(filepath.Join(path, CablesFile))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", CablesFile, err)
+	}
+	for _, row := range cables {
+		data.Cables = append(data.Cables, Cable{
+			SideADevice: row["side_a_device"],
+			SideAType:   row["side_a_type"],
+			SideAName:   row["side_a_name"],
+			SideBDevice: row["side_b_device"],
+			SideBType:   row["side_b_type"],
+			SideBName:   row["side_b_name"],
+			Status:      row["status"],
+			Label:       row["label"],
+		})
+	}
+
+	return data, nil
+}
+
+func readCSV(path string) ([]map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parsing csv: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	rows := []map[string]string{}
+	for _, record := range records[1:] {
+		row := map[string]string{}
+		for idx, key := range records[0] {
+			if idx < len(record) {
+				row[strings.TrimSpace(key)] = strings.TrimSpace(record[idx])
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// Import generates switches, switch groups, servers and connections from the NetBox data. Devices with the switch
+// roles become switches (device type mapped to a switch profile), devices with the server role become servers,
+// devices with the gateway role are only used for the gateway connections as gateway nodes are part of the
+// fabricator config and everything else is skipped. Cables are grouped into connections by label, connection type
+// is taken from the label (e.g. server-01--eslag--leaf-01--leaf-02) or derived from the devices roles.
+func Import(data *Data, opts ImportOpts) ([]kclient.Object, error) {
+	vlanRanges := opts.VLANRanges
+	if len(vlanRanges) == 0 {
+		vlanRanges = []meta.VLANRange{DefaultVLANRange}
+	}
+	ipv4Subnets := opts.IPv4Subnets
+	if len(ipv4Subnets) == 0 {
+		ipv4Subnets = []string{DefaultIPv4Subnet}
+	}
+	for _, subnet := range ipv4Subnets {
+		if prefix, err := netip.ParsePrefix(subnet); err != nil || !prefix.Addr().Is4() {
+			return nil, fmt.Errorf("invalid IPv4 subnet %q", subnet) //nolint:goerr113
+		}
+	}
+
+	// default namespaces aren't part of NetBox data
+	objs := []kclient.Object{
+		&wiringapi.VLANNamespace{
+			ObjectMeta: objectMeta("default", nil),
+			Spec: wiringapi.VLANNamespaceSpec{
+				Ranges: vlanRanges,
+			},
+		},
+		&vpcapi.IPv4Namespace{
+			ObjectMeta: objectMeta("default", nil),
+			Spec: vpcapi.IPv4NamespaceSpec{
+				Subnets: ipv4Subnets,
+			},
+		},
+	}
+
+	switches := map[string]wiringapi.SwitchRole{}
+	servers := map[string]bool{}
+	gateways := map[string]bool{}
+	groups := map[string]bool{}
+
+	for _, dev := range data.Devices {
+		if dev.Name == "" {
+			return nil, fmt.Errorf("device without name") //nolint:goerr113
+		}
+
+		anns := map[string]string{}
+		if dev.Rack != "" {
+			anns[cabling.RackAnnotation] = dev.Rack
+		}
+
+		role := wiringapi.SwitchRole(dev.Role)
+		if dev.Role == roleLeaf {
+			role = wiringapi.SwitchRoleServerLeaf
+		}
+
+		switch {
+		case slices.Contains(wiringapi.SwitchRoles, role):
+			profile := dev.DeviceType
+			if mapped, ok := opts.Profiles[dev.DeviceType]; ok {
+				profile = mapped
+			}
+			if len(validation.IsDNS1123Subdomain(profile)) != 0 {
+				return nil, fmt.Errorf("switch %q: device type %q isn't a valid switch profile name, it should be mapped to a switch profile", dev.Name, dev.DeviceType) //nolint:goerr113
+			}
+
+			sw := &wiringapi.Switch{
+				ObjectMeta: objectMeta(dev.Name, anns),
+				Spec: wiringapi.SwitchSpec{
+					Role:        role,
+					Description: dev.Description,
+					Profile:     profile,
+					Groups:      dev.Tags,
+					Redundancy: wiringapi.SwitchRedundancy{
+						Group: dev.CustomFields[FieldRedundancyGroup],
+						Type:  meta.RedundancyType(dev.CustomFields[FieldRedundancyType]),
+					},
+				},
+			}
+			objs = append(objs, sw)
+			switches[dev.Name] = role
+
+			for _, group := range dev.Tags {
+				groups[group] = true
+			}
+		case dev.Role == RoleServer:
+			server := &wiringapi.Server{
+				ObjectMeta: objectMeta(dev.Name, anns),
+				Spec: wiringapi.ServerSpec{
+					Description: dev.Description,
+				},
+			}
+			if dev.DeviceType != DeviceTypeServer {
+				server.Spec.Profile = dev.DeviceType
+			}
+			objs = append(objs, server)
+			servers[dev.Name] = true
+		case dev.Role == RoleGateway:
+			gateways[dev.Name] = true
+		default:
+			slog.Warn("Skipping device with unsupported role", "device", dev.Name, "role", dev.Role)
+		}
+	}
+
+	for _, group := range slices.Sorted(maps.Keys(groups)) {
+		objs = append(objs, &wiringapi.SwitchGroup{ObjectMeta: objectMeta(group, nil)})
+	}
+
+	cables := map[string][]Cable{}
+	for _, cable := range data.Cables {
+		if cable.SideAType != "" && cable.SideAType != TerminationIface ||
+			cable.SideBType != "" && cable.SideBType != TerminationIface {
+			slog.Warn("Skipping cable with unsupported termination", "label", cable.Label, "a", cable.SideAType, "b", cable.SideBType)
+
+			continue
+		}
+
+		known := func(name string) bool { return switches[name] != "" || servers[name] || gateways[name] }
+		if !known(cable.SideADevice) || !known(cable.SideBDevice) {
+			slog.Warn("Skipping cable with unknown device", "label", cable.Label, "a", cable.SideADevice, "b", cable.SideBDevice)
+
+			continue
+		}
+
+		key := cable.Label
+		if key == "" {
+			pair := []string{cable.SideADevice, cable.SideBDevice}
+			sort.Strings(pair)
+			key = strings.Join(pair, "|")
+		}
+		cables[key] = append(cables[key], cable)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(cables)) {
+		conn, err := buildConnection(key, cables[key], switches, gateways)
+		if err != nil {
+			return nil, fmt.Errorf("building connection %q: %w", key, err)
+		}
+		objs = append(objs, conn)
+	}
+
+	return objs, nil
+}
+
+func buildConnection(key string, cables []Cable, switches map[string]wiringapi.SwitchRole, gateways map[string]bool) (*wiringapi.Connection, error) {
+	// labels are only used as names if they're valid object names, e.g. not a cable inventory number
+	name := ""
+	if cables[0].Label != "" && len(validation.IsDNS1123Subdomain(key)) == 0 {
+		name = key
+	}
+
+	// connection names are <left>--<type>--<right> or <left>--<type> for the single switch connections
+	connType := ""
+	if parts := strings.Split(name, "--"); len(parts) >= 2 && slices.Contains(connectionTypes, parts[1]) {
+		connType = parts[1]
+	}
+
+	swLinks := [][2]string{}
+	gwLinks := []wiringapi.GatewayLink{}
+	serverLinks := []wiringapi.ServerToSwitchLink{}
+	serverSwitches := map[string]bool{}
+	for _, cable := range cables {
+		a := cable.SideADevice + "/" + cable.SideAName
+		b := cable.SideBDevice + "/" + cable.SideBName
+		aSwitch, bSwitch := switches[cable.SideADevice] != "", switches[cable.SideBDevice] != ""
+		aGateway, bGateway := gateways[cable.SideADevice], gateways[cable.SideBDevice]
+
+		switch {
+		case aGateway && bSwitch:
+			gwLinks = append(gwLinks, gatewayLink(b, a))
+		case aSwitch && bGateway:
+			gwLinks = append(gwLinks, gatewayLink(a, b))
+		case aGateway || bGateway:
+			return nil, fmt.Errorf("gateway cable %s to %s should be connected to a switch", a, b) //nolint:goerr113
+		case aSwitch && bSwitch:
+			// keep spine first for fabric connections
+			if switches[cable.SideBDevice] == wiringapi.SwitchRoleSpine {
+				a, b = b, a
+			}
+			swLinks = append(swLinks, [2]string{a, b})
+		case aSwitch:
+			serverLinks = append(serverLinks, serverLink(b, a))
+			serverSwitches[cable.SideADevice] = true
+		case bSwitch:
+			serverLinks = append(serverLinks, serverLink(a, b))
+			serverSwitches[cable.SideBDevice] = true
+		default:
+			return nil, fmt.Errorf("cable between two servers %s and %s", a, b) //nolint:goerr113
+		}
+	}
+
+	if min(len(swLinks), 1)+min(len(gwLinks), 1)+min(len(serverLinks), 1) > 1 {
+		return nil, fmt.Errorf("mixed switch, gateway and server cables") //nolint:goerr113
+	}
+
+	if connType == "" {
+		switch {
+		case len(gwLinks) > 0:
+			connType = wiringapi.ConnectionTypeGateway
+		case len(swLinks) > 0 && wiringapi.SplitPortName(swLinks[0][0])[0] == wiringapi.SplitPortName(swLinks[0][1])[0]:
+			connType = wiringapi.ConnectionTypeVPCLoopback
+		case len(swLinks) > 0 && switches[wiringapi.SplitPortName(swLinks[0][0])[0]] == wiringapi.SwitchRoleSpine:
+			connType = wiringapi.ConnectionTypeFabric
+		case len(swLinks) > 0:
+			connType = wiringapi.ConnectionTypeMesh
+		case len(serverSwitches) > 1:
+			connType = wiringapi.ConnectionTypeESLAG
+		case len(serverLinks) > 1:
+			connType = wiringapi.ConnectionTypeBundled
+		default:
+			connType = wiringapi.ConnectionTypeUnbundled
+		}
+	}
+
+	conn := &wiringapi.Connection{}
+	switch connType {
+	case wiringapi.ConnectionTypeFabric:
+		fabric := &wiringapi.ConnFabric{}
+		for _, link := range swLinks {
+			fabric.Links = append(fabric.Links, wiringapi.FabricLink{
+				Spine: wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: link[0]}},
+				Leaf:  wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: link[1]}},
+			})
+		}
+		conn.Spec.Fabric = fabric
+	case wiringapi.ConnectionTypeMesh:
+		mesh := &wiringapi.ConnMesh{}
+		for _, link := range swLinks {
+			mesh.Links = append(mesh.Links, wiringapi.MeshLink{
+				Leaf1: wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: link[0]}},
+				Leaf2: wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: link[1]}},
+			})
+		}
+		conn.Spec.Mesh = mesh
+	case wiringapi.ConnectionTypeVPCLoopback:
+		loopback := &wiringapi.ConnVPCLoopback{}
+		for _, link := range swLinks {
+			loopback.Links = append(loopback.Links, wiringapi.SwitchToSwitchLink{
+				Switch1: wiringapi.BasePortName{Port: link[0]},
+				Switch2: wiringapi.BasePortName{Port: link[1]},
+			})
+		}
+		conn.Spec.VPCLoopback = loopback
+	case wiringapi.ConnectionTypeGateway:
+		conn.Spec.Gateway = &wiringapi.ConnGateway{Links: gwLinks}
+	case wiringapi.ConnectionTypeUnbundled:
+		if len(serverLinks) != 1 {
+			return nil, fmt.Errorf("unbundled connection should have exactly one server cable, got %d", len(serverLinks)) //nolint:goerr113
+		}
+		conn.Spec.Unbundled = &wiringapi.ConnUnbundled{Link: serverLinks[0]}
+	case wiringapi.ConnectionTypeBundled:
+		conn.Spec.Bundled = &wiringapi.ConnBundled{Links: serverLinks}
+	case wiringapi.ConnectionTypeESLAG:
+		conn.Spec.ESLAG = &wiringapi.ConnESLAG{Links: serverLinks}
+	default:
+		// deprecated MCLAG isn't exported and externals don't have cables as remote side isn't part of the wiring
+		return nil, fmt.Errorf("unsupported connection type %q", connType) //nolint:goerr113
+	}
+
+	switch connType {
+	case wiringapi.ConnectionTypeFabric, wiringapi.ConnectionTypeMesh, wiringapi.ConnectionTypeVPCLoopback:
+		if len(swLinks) == 0 {
+			return nil, fmt.Errorf("connection type %q doesn't match cabled devices", connType) //nolint:goerr113
+		}
+	case wiringapi.ConnectionTypeGateway:
+		if len(gwLinks) == 0 {
+			return nil, fmt.Errorf("connection type %q doesn't match cabled devices", connType) //nolint:goerr113
+		}
+	default:
+		if len(serverLinks) == 0 {
+			return nil, fmt.Errorf("connection type %q doesn't match cabled devices", connType) //nolint:goerr113
+		}
+	}
+
+	if name == "" {
+		name = conn.Spec.GenerateName()
+	}
+	conn.ObjectMeta = objectMeta(name, nil)
+
+	return conn, nil
+}
+
+func serverLink(server, sw string) wiringapi.ServerToSwitchLink {
+	return wiringapi.ServerToSwitchLink{
+		Server: wiringapi.BasePortName{Port: server},
+		Switch: wiringapi.BasePortName{Port: sw},
+	}
+}
+
+func gatewayLink(sw, gw string) wiringapi.GatewayLink {
+	return wiringapi.GatewayLink{
+		Switch:  wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: sw}},
+		Gateway: wiringapi.ConnGatewayLinkGateway{BasePortName: wiringapi.BasePortName{Port: gw}},
+	}
+}
+
+func objectMeta(name string, anns map[string]string) kmetav1.ObjectMeta {
+	if len(anns) == 0 {
+		anns = nil
+	}
+
+	return kmetav1.ObjectMeta{Name: name, Namespace: kmetav1.NamespaceDefault, Annotations: anns}
+}
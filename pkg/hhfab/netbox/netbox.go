@@ -0,0 +1,107 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+// Package netbox converts fabric wiring to and from the NetBox bulk import data (devices, interfaces and cables).
+// Objects use the same field names as the NetBox CSV/JSON bulk import so every list could be imported as is, switch
+// groups are represented as device tags and wiring connection names as cable labels.
+package netbox
+
+import (
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+)
+
+var Formats = []Format{
+	FormatJSON,
+	FormatCSV,
+}
+
+const (
+	// Filename is the default file (or directory for CSV) name without extension
+	Filename = "netbox"
+
+	DevicesFile    = "devices.csv"
+	InterfacesFile = "interfaces.csv"
+	CablesFile     = "cables.csv"
+
+	DefaultSite         = "default"
+	DefaultManufacturer = "Generic"
+	StatusActive        = "active"
+	TerminationIface    = "dcim.interface"
+	RoleServer          = "server"
+	RoleGateway         = "gateway"
+	DeviceTypeServer    = "server"
+	DeviceTypeGateway   = "gateway"
+	InterfaceTypeOther  = "other"
+
+	// custom fields should be created in NetBox before importing devices with them
+	FieldRedundancyGroup = "hhfab_redundancy_group"
+	FieldRedundancyType  = "hhfab_redundancy_type"
+)
+
+type Data struct {
+	Devices    []Device    `json:"devices"`
+	Interfaces []Interface `json:"interfaces"`
+	Cables     []Cable     `json:"cables"`
+}
+
+type Device struct {
+	Name         string            `json:"name"`
+	Role         string            `json:"role"`
+	Manufacturer string            `json:"manufacturer"`
+	DeviceType   string            `json:"device_type"`
+	Site         string            `json:"site"`
+	Rack         string            `json:"rack,omitempty"`
+	Status       string            `json:"status"`
+	Description  string            `json:"description,omitempty"`
+	Tags         []string          `json:"tags,omitempty"`
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
+}
+
+type Interface struct {
+	Device      string `json:"device"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Speed       int    `json:"speed,omitempty"` // in Kbps
+	Label       string `json:"label,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type Cable struct {
+	SideADevice string `json:"side_a_device"`
+	SideAType   string `json:"side_a_type"`
+	SideAName   string `json:"side_a_name"`
+	SideBDevice string `json:"side_b_device"`
+	SideBType   string `json:"side_b_type"`
+	SideBName   string `json:"side_b_name"`
+	Status      string `json:"status"`
+	Label       string `json:"label,omitempty"`
+}
+
+// interfaceTypes maps port speed to the NetBox interface type
+var interfaceTypes = map[string]string{
+	"1G":   "1000base-t",
+	"10G":  "10gbase-x-sfpp",
+	"25G":  "25gbase-x-sfp28",
+	"40G":  "40gbase-x-qsfpp",
+	"50G":  "50gbase-x-sfp56",
+	"100G": "100gbase-x-qsfp28",
+	"200G": "200gbase-x-qsfp56",
+	"400G": "400gbase-x-qsfpdd",
+	"800G": "800gbase-x-osfp",
+}
+
+// manufacturer guesses manufacturer from the switch profile display name, e.g. "Dell S5248F-ON"
+func manufacturer(displayName string) string {
+	if name, _, ok := strings.Cut(strings.TrimSpace(displayName), " "); ok && name != "" {
+		return name
+	}
+
+	return DefaultManufacturer
+}
@@ -0,0 +1,201 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.githedgehog.com/fabric/api/meta"
+	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
+	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
+	"go.githedgehog.com/fabricator/pkg/hhfab/cabling"
+	"go.githedgehog.com/fabricator/pkg/util/apiutil"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	l := apiutil.NewLoader()
+
+	objs := []kclient.Object{
+		&wiringapi.SwitchProfile{
+			ObjectMeta: objectMeta("test", nil),
+			Spec: wiringapi.SwitchProfileSpec{
+				DisplayName: "Dell Test",
+				Ports: map[string]wiringapi.SwitchProfilePort{
+					"E1/1": {NOSName: "Ethernet0", Profile: "SFP28-25G"},
+					"E1/2": {NOSName: "Ethernet1", Profile: "SFP28-25G"},
+					"E1/3": {NOSName: "Ethernet2", Profile: "SFP28-25G"},
+					"E1/4": {NOSName: "Ethernet3", Profile: "SFP28-25G"},
+				},
+				PortProfiles: map[string]wiringapi.SwitchProfilePortProfile{
+					"SFP28-25G": {Speed: &wiringapi.SwitchProfilePortProfileSpeed{Default: "25G"}},
+				},
+			},
+		},
+		&wiringapi.SwitchGroup{ObjectMeta: objectMeta("eslag-1", nil)},
+		&wiringapi.Switch{
+			ObjectMeta: objectMeta("spine-01", map[string]string{cabling.RackAnnotation: "rack-1"}),
+			Spec:       wiringapi.SwitchSpec{Role: wiringapi.SwitchRoleSpine, Profile: "test", Description: "spine"},
+		},
+		&wiringapi.Switch{
+			ObjectMeta: objectMeta("leaf-01", nil),
+			Spec: wiringapi.SwitchSpec{
+				Role: wiringapi.SwitchRoleServerLeaf, Profile: "test", Groups: []string{"eslag-1"},
+				Redundancy: wiringapi.SwitchRedundancy{Group: "eslag-1", Type: meta.RedundancyTypeESLAG},
+			},
+		},
+		&wiringapi.Switch{
+			ObjectMeta: objectMeta("leaf-02", nil),
+			Spec: wiringapi.SwitchSpec{
+				Role: wiringapi.SwitchRoleServerLeaf, Profile: "test", Groups: []string{"eslag-1"},
+				Redundancy: wiringapi.SwitchRedundancy{Group: "eslag-1", Type: meta.RedundancyTypeESLAG},
+			},
+		},
+		&wiringapi.Server{ObjectMeta: objectMeta("server-01", nil)},
+		&fabapi.FabNode{
+			ObjectMeta: objectMeta("gateway-1", nil),
+			Spec:       fabapi.FabNodeSpec{Roles: []fabapi.FabNodeRole{fabapi.NodeRoleGateway}},
+		},
+		&wiringapi.Connection{
+			ObjectMeta: objectMeta("spine-01--fabric--leaf-01", nil),
+			Spec: wiringapi.ConnectionSpec{Fabric: &wiringapi.ConnFabric{Links: []wiringapi.FabricLink{{
+				Spine: wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: "spine-01/E1/1"}},
+				Leaf:  wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: "leaf-01/E1/1"}},
+			}}}},
+		},
+		&wiringapi.Connection{
+			ObjectMeta: objectMeta("server-01--eslag--leaf-01--leaf-02", nil),
+			Spec: wiringapi.ConnectionSpec{ESLAG: &wiringapi.ConnESLAG{Links: []wiringapi.ServerToSwitchLink{
+				serverLink("server-01/enp2s1", "leaf-01/E1/2"),
+				serverLink("server-01/enp2s2", "leaf-02/E1/2"),
+			}}},
+		},
+		&wiringapi.Connection{
+			ObjectMeta: objectMeta("spine-01--gateway--gateway-1", nil),
+			Spec: wiringapi.ConnectionSpec{Gateway: &wiringapi.ConnGateway{Links: []wiringapi.GatewayLink{
+				gatewayLink("spine-01/E1/2", "gateway-1/enp2s1"),
+			}}},
+		},
+		&wiringapi.Connection{
+			ObjectMeta: objectMeta("leaf-01--vpc-loopback", nil),
+			Spec: wiringapi.ConnectionSpec{VPCLoopback: &wiringapi.ConnVPCLoopback{Links: []wiringapi.SwitchToSwitchLink{{
+				Switch1: wiringapi.BasePortName{Port: "leaf-01/E1/3"},
+				Switch2: wiringapi.BasePortName{Port: "leaf-01/E1/4"},
+			}}}},
+		},
+	}
+	require.NoError(t, l.Add(ctx, objs...))
+
+	data, err := Export(ctx, l.GetClient(), ExportOpts{Site: "dc-1"})
+	require.NoError(t, err)
+	require.Len(t, data.Devices, 5)
+	require.Equal(t, Device{
+		Name: "spine-01", Role: "spine", Manufacturer: "Dell", DeviceType: "test", Site: "dc-1", Rack: "rack-1",
+		Status: StatusActive, Description: "spine",
+	}, data.Devices[4])
+	require.Equal(t, RoleGateway, data.Devices[0].Role)
+	require.Len(t, data.Cables, 5)
+	require.Contains(t, data.Interfaces, Interface{
+		Device: "spine-01", Name: "E1/1", Type: "25gbase-x-sfp28", Speed: 25_000_000, Label: "Ethernet0",
+	})
+
+	for _, format := range Formats {
+		path := filepath.Join(t.TempDir(), Filename)
+		files, err := Write(data, format, path)
+		require.NoError(t, err)
+		for _, file := range files {
+			require.FileExists(t, file)
+		}
+
+		read, err := Read(path)
+		require.NoError(t, err)
+
+		imported, err := Import(read, ImportOpts{})
+		require.NoError(t, err)
+
+		names := map[string]kclient.Object{}
+		for _, obj := range imported {
+			names[obj.GetName()] = obj
+		}
+		require.Len(t, imported, 11, format)
+		require.Contains(t, names, "eslag-1")
+
+		leaf, ok := names["leaf-02"].(*wiringapi.Switch)
+		require.True(t, ok)
+		require.Equal(t, meta.RedundancyTypeESLAG, leaf.Spec.Redundancy.Type)
+		require.Equal(t, []string{"eslag-1"}, leaf.Spec.Groups)
+
+		conn, ok := names["server-01--eslag--leaf-01--leaf-02"].(*wiringapi.Connection)
+		require.True(t, ok)
+		require.NotNil(t, conn.Spec.ESLAG)
+		require.Len(t, conn.Spec.ESLAG.Links, 2)
+
+		conn, ok = names["spine-01--fabric--leaf-01"].(*wiringapi.Connection)
+		require.True(t, ok)
+		require.Equal(t, "spine-01/E1/1", conn.Spec.Fabric.Links[0].Spine.Port)
+
+		conn, ok = names["spine-01--gateway--gateway-1"].(*wiringapi.Connection)
+		require.True(t, ok)
+		require.NotNil(t, conn.Spec.Gateway)
+		require.Equal(t, "gateway-1/enp2s1", conn.Spec.Gateway.Links[0].Gateway.Port)
+
+		conn, ok = names["leaf-01--vpc-loopback"].(*wiringapi.Connection)
+		require.True(t, ok)
+		require.NotNil(t, conn.Spec.VPCLoopback)
+
+		require.NoError(t, apiutil.NewLoader().Add(ctx, imported...))
+	}
+
+	// connection type is derived from the roles if the label isn't a connection name
+	for idx, cable := range data.Cables {
+		data.Cables[idx].Label = fmt.Sprintf("CBL-%04d", idx)
+		if cable.SideADevice == "leaf-02" || cable.SideBDevice == "leaf-02" {
+			data.Cables[idx].Label = ""
+		}
+	}
+	vlanRange, err := ParseVLANRange("100-200")
+	require.NoError(t, err)
+	imported, err := Import(data, ImportOpts{
+		Profiles:    map[string]string{"test": "dell-s5248f-on"},
+		VLANRanges:  []meta.VLANRange{vlanRange},
+		IPv4Subnets: []string{"10.10.0.0/16"},
+	})
+	require.NoError(t, err)
+	names := map[string]kclient.Object{}
+	for _, obj := range imported {
+		names[obj.GetName()] = obj
+	}
+	require.Contains(t, names, "server-01--unbundled--leaf-02")
+	require.Contains(t, names, "spine-01--gateway--gateway-1")
+	require.Contains(t, names, "leaf-01--vpc-loopback")
+	require.Contains(t, names, "spine-01--fabric--leaf-01")
+	sw, ok := names["spine-01"].(*wiringapi.Switch)
+	require.True(t, ok)
+	require.Equal(t, "dell-s5248f-on", sw.Spec.Profile)
+	vlanNS, ok := imported[0].(*wiringapi.VLANNamespace)
+	require.True(t, ok)
+	require.Equal(t, []meta.VLANRange{{From: 100, To: 200}}, vlanNS.Spec.Ranges)
+
+	// device types that aren't valid profile names should be mapped explicitly
+	data.Devices[4].DeviceType = "Dell S5248F-ON"
+	_, err = Import(data, ImportOpts{})
+	require.ErrorContains(t, err, "should be mapped to a switch profile")
+	data.Devices[4].DeviceType = "test"
+
+	// deprecated or unsupported connections fail the import instead of being skipped
+	data.Cables[0].Label = "leaf-01--mclag-domain--leaf-02"
+	_, err = Import(data, ImportOpts{})
+	require.ErrorContains(t, err, "unsupported connection type")
+
+	_, err = ParseVLANRange("200-100")
+	require.Error(t, err)
+
+	_, err = Read(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
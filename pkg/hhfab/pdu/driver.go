@@ -0,0 +1,114 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package pdu
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Driver controls power of a single device or PDU outlet
+type Driver interface {
+	Power(ctx context.Context, action Action) error
+}
+
+type Credentials struct {
+	Username string
+	Password string
+}
+
+const (
+	SchemeNetio       = "http" // NetIO JSON API, http://<pdu>/outlet/<id>
+	SchemeNetioTLS    = "https"
+	SchemeRedfish     = "redfish"
+	SchemeIPMI        = "ipmi"
+	SchemeSNMPAPC     = "snmp-apc"
+	SchemeSNMPRaritan = "snmp-raritan"
+)
+
+// NewDriver creates power driver based on the URL from the switch power annotation (hhfab.cfg/power/<psu>):
+//
+//   - http[s]://<pdu>/outlet/<id> - NetIO PDU outlet using JSON API
+//   - redfish://<bmc>[:port][/<system-id>] - Redfish BMC, first system is used if not specified
+//   - ipmi://<bmc>[:port] - IPMI over LAN using ipmitool
+//   - snmp-apc://<pdu>[:port]/<outlet> - APC PDU outlet using SNMPv2c
+//   - snmp-raritan://<pdu>[:port]/<outlet> - Raritan PDU outlet using SNMPv2c
+//
+// Credentials aren't allowed in the URL as it's stored in the wiring, the provided ones are used instead and the
+// password is used as SNMP community ("private" if not set).
+func NewDriver(rawURL string, creds Credentials) (Driver, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// url errors include the whole URL, so it's not wrapped to avoid leaking credentials
+		return nil, fmt.Errorf("parsing power URL: invalid URL") //nolint:goerr113
+	}
+	if u.User != nil {
+		// username could be an SNMP community so the whole user info is dropped from the error
+		u.User = nil
+
+		return nil, fmt.Errorf("power URL %q: credentials in the URL aren't supported, use PDU username and password instead", u.String()) //nolint:goerr113
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("power URL %q: missing host", rawURL) //nolint:goerr113
+	}
+
+	path := strings.Trim(u.Path, "/")
+
+	switch u.Scheme {
+	case SchemeNetio, SchemeNetioTLS:
+		outletID, err := ExtractOutletID(path)
+		if err != nil {
+			return nil, fmt.Errorf("power URL %q: %w", rawURL, err)
+		}
+		if creds.Username == "" || creds.Password == "" {
+			return nil, fmt.Errorf("power URL %q: PDU credentials required", rawURL) //nolint:goerr113
+		}
+
+		return &netioDriver{baseURL: u.Scheme + "://" + u.Host, outletID: outletID, creds: creds}, nil
+	case SchemeRedfish:
+		if creds.Username == "" || creds.Password == "" {
+			return nil, fmt.Errorf("power URL %q: BMC credentials required", rawURL) //nolint:goerr113
+		}
+
+		return &redfishDriver{baseURL: "https://" + u.Host, systemID: path, creds: creds}, nil
+	case SchemeIPMI:
+		if creds.Username == "" || creds.Password == "" {
+			return nil, fmt.Errorf("power URL %q: BMC credentials required", rawURL) //nolint:goerr113
+		}
+
+		return &ipmiDriver{host: u.Hostname(), port: u.Port(), creds: creds}, nil
+	case SchemeSNMPAPC, SchemeSNMPRaritan:
+		outletID, err := ExtractOutletID(path)
+		if err != nil {
+			return nil, fmt.Errorf("power URL %q: %w", rawURL, err)
+		}
+
+		community := "private"
+		if creds.Password != "" {
+			community = creds.Password
+		}
+
+		addr := u.Host
+		if u.Port() == "" {
+			addr += ":161"
+		}
+
+		oid, values := apcOutletCtl, apcActions
+		if u.Scheme == SchemeSNMPRaritan {
+			oid, values = raritanOutletSwitchingOperation, raritanActions
+		}
+
+		return &snmpDriver{
+			addr:      addr,
+			community: community,
+			oid:       append(slices.Clone(oid), uint32(outletID)), //nolint:gosec
+			values:    values,
+		}, nil
+	default:
+		return nil, fmt.Errorf("power URL %q: unsupported scheme %q", rawURL, u.Scheme) //nolint:goerr113
+	}
+}
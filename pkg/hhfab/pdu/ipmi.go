@@ -0,0 +1,63 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package pdu
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+var IPMIToolBin = "ipmitool"
+
+var ipmiActions = map[Action]string{
+	ActionOn:    "on",
+	ActionOff:   "off",
+	ActionCycle: "cycle",
+}
+
+type ipmiDriver struct {
+	host  string
+	port  string
+	creds Credentials
+}
+
+var _ Driver = (*ipmiDriver)(nil)
+
+func (d *ipmiDriver) args(action Action) ([]string, error) {
+	ipmiAction, ok := ipmiActions[action]
+	if !ok {
+		return nil, fmt.Errorf("unsupported action: %s", action) //nolint:goerr113
+	}
+
+	args := []string{"-I", "lanplus", "-H", d.host}
+	if d.port != "" {
+		args = append(args, "-p", d.port)
+	}
+
+	// password is passed through the IPMI_PASSWORD env variable to not expose it in the process list
+	return append(args, "-U", d.creds.Username, "-E", "chassis", "power", ipmiAction), nil
+}
+
+func (d *ipmiDriver) Power(ctx context.Context, action Action) error {
+	args, err := d.args(action)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, IPMIToolBin, args...)
+	cmd.Env = append(os.Environ(), "IPMI_PASSWORD="+d.creds.Password)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("running ipmitool: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
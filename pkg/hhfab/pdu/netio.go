@@ -5,6 +5,7 @@ package pdu
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,6 +15,13 @@ import (
 	"time"
 )
 
+// netioClient is used for both http and https NetIO API, PDUs are usually using self-signed certificates
+var netioClient = &http.Client{
+	Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+	},
+}
+
 // NetioOutlet defines the structure for each outlet.
 type Outlet struct {
 	ID      int     `json:"ID"`
@@ -36,8 +44,8 @@ type AgentResponse struct {
 	Agent Agent `json:"Agent"`
 }
 
-func GetStatus(pduIP, username, password string) (*Response, error) {
-	url := fmt.Sprintf("http://%s/netio.json", pduIP)
+func GetStatus(baseURL, username, password string) (*Response, error) {
+	url := baseURL + "/netio.json"
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -49,8 +57,7 @@ func GetStatus(pduIP, username, password string) (*Response, error) {
 
 	req.SetBasicAuth(username, password)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := netioClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -93,8 +100,8 @@ var ActionMap = map[Action]int{
 	ActionCycle: 2,
 }
 
-func ControlOutlet(ctx context.Context, pduIP, username, password string, outletID int, action Action) error {
-	url := fmt.Sprintf("http://%s/netio.json", pduIP)
+func ControlOutlet(ctx context.Context, baseURL, username, password string, outletID int, action Action) error {
+	url := baseURL + "/netio.json"
 	data := fmt.Sprintf(`{"Outputs":[{"ID":%d,"Action":%d}]}`, outletID, ActionMap[action])
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(data))
@@ -105,8 +112,7 @@ func ControlOutlet(ctx context.Context, pduIP, username, password string, outlet
 	req.Header.Set("Content-Type", "application/json")
 	req.SetBasicAuth(username, password)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := netioClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -120,15 +126,15 @@ func ControlOutlet(ctx context.Context, pduIP, username, password string, outlet
 }
 
 // GetPDUName queries the PDU for its name.
-func GetPDUName(ctx context.Context, pduIP, username, password string) (string, error) {
-	url := fmt.Sprintf("http://%s/netio.json", pduIP)
+func GetPDUName(ctx context.Context, baseURL, username, password string) (string, error) {
+	url := baseURL + "/netio.json"
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.SetBasicAuth(username, password)
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := netioClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to query PDU: %w", err)
 	}
@@ -150,3 +156,15 @@ func GetPDUName(ctx context.Context, pduIP, username, password string) (string,
 
 	return AgentResponse.Agent.DeviceName, nil
 }
+
+type netioDriver struct {
+	baseURL  string
+	outletID int
+	creds    Credentials
+}
+
+var _ Driver = (*netioDriver)(nil)
+
+func (d *netioDriver) Power(ctx context.Context, action Action) error {
+	return ControlOutlet(ctx, d.baseURL, d.creds.Username, d.creds.Password, d.outletID, action)
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package pdu
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+)
+
+var redfishResetTypes = map[Action]string{
+	ActionOn:    "On",
+	ActionOff:   "ForceOff",
+	ActionCycle: "PowerCycle",
+}
+
+type redfishDriver struct {
+	baseURL  string
+	systemID string
+	creds    Credentials
+}
+
+var _ Driver = (*redfishDriver)(nil)
+
+type redfishCollection struct {
+	Members []struct {
+		ID string `json:"@odata.id"`
+	} `json:"Members"`
+}
+
+func (d *redfishDriver) Power(ctx context.Context, action Action) error {
+	resetType, ok := redfishResetTypes[action]
+	if !ok {
+		return fmt.Errorf("unsupported action: %s", action) //nolint:goerr113
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	system := "/redfish/v1/Systems/" + d.systemID
+	if d.systemID == "" {
+		systems := &redfishCollection{}
+		if err := d.do(ctx, http.MethodGet, "/redfish/v1/Systems", nil, systems); err != nil {
+			return fmt.Errorf("listing systems: %w", err)
+		}
+		if len(systems.Members) == 0 {
+			return fmt.Errorf("no systems found") //nolint:goerr113
+		}
+		system = systems.Members[0].ID
+	}
+
+	body := map[string]string{"ResetType": resetType}
+	if err := d.do(ctx, http.MethodPost, path.Join(system, "Actions", "ComputerSystem.Reset"), body, nil); err != nil {
+		return fmt.Errorf("resetting system %s: %w", system, err)
+	}
+
+	return nil
+}
+
+func (d *redfishDriver) do(ctx context.Context, method, urlPath string, in, out any) error {
+	var reqBody io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("marshaling request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, d.baseURL+urlPath, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.SetBasicAuth(d.creds.Username, d.creds.Password)
+
+	// BMCs are almost always using self-signed certificates
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+
+		return fmt.Errorf("unexpected response status: %s: %s", resp.Status, string(body)) //nolint:goerr113
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
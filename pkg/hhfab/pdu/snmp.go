@@ -0,0 +1,243 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package pdu
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+var (
+	// PowerNet-MIB::rPDUOutletControlOutletCommand
+	apcOutletCtl = []uint32{1, 3, 6, 1, 4, 1, 318, 1, 1, 12, 3, 3, 1, 1, 4}
+	apcActions   = map[Action]int{
+		ActionOn:    1,
+		ActionOff:   2,
+		ActionCycle: 3,
+	}
+
+	// PDU2-MIB::switchingOperation for the first PDU in the chain
+	raritanOutletSwitchingOperation = []uint32{1, 3, 6, 1, 4, 1, 13742, 6, 4, 1, 2, 1, 2, 1, 1}
+	raritanActions                  = map[Action]int{
+		ActionOff:   0,
+		ActionOn:    1,
+		ActionCycle: 2,
+	}
+)
+
+const (
+	snmpVersion2c = 1
+
+	berInteger     = 0x02
+	berOctetString = 0x04
+	berOID         = 0x06
+	berSequence    = 0x30
+	snmpGetResp    = 0xA2
+	snmpSetReq     = 0xA3
+
+	snmpTimeout = 5 * time.Second
+	snmpRetries = 3
+)
+
+var errSNMPMalformed = errors.New("malformed SNMP message")
+
+type snmpDriver struct {
+	addr      string
+	community string
+	oid       []uint32
+	values    map[Action]int
+}
+
+var _ Driver = (*snmpDriver)(nil)
+
+func (d *snmpDriver) Power(ctx context.Context, action Action) error {
+	value, ok := d.values[action]
+	if !ok {
+		return fmt.Errorf("unsupported action: %s", action) //nolint:goerr113
+	}
+
+	reqIDBuf := make([]byte, 4)
+	if _, err := rand.Read(reqIDBuf); err != nil {
+		return fmt.Errorf("generating request ID: %w", err)
+	}
+	reqID := int(binary.BigEndian.Uint32(reqIDBuf) & 0x7fffffff)
+
+	req := snmpSetRequest(d.community, reqID, d.oid, value)
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "udp", d.addr)
+	if err != nil {
+		return fmt.Errorf("dialing %s: %w", d.addr, err)
+	}
+	defer conn.Close()
+
+	var lastErr error
+	for range snmpRetries {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("waiting for response: %w", err)
+		}
+
+		if err := conn.SetDeadline(time.Now().Add(snmpTimeout)); err != nil {
+			return fmt.Errorf("setting deadline: %w", err)
+		}
+		if _, err := conn.Write(req); err != nil {
+			return fmt.Errorf("sending request: %w", err)
+		}
+
+		buf := make([]byte, 65535)
+		n, err := conn.Read(buf)
+		if err != nil {
+			lastErr = err
+
+			continue
+		}
+
+		respID, errStatus, err := parseSNMPResponse(buf[:n])
+		if err != nil {
+			return fmt.Errorf("parsing response: %w", err)
+		}
+		if respID != reqID {
+			lastErr = fmt.Errorf("unexpected request ID in response: %d", respID) //nolint:goerr113
+
+			continue
+		}
+		if errStatus != 0 {
+			return fmt.Errorf("SNMP set failed with error status %d", errStatus) //nolint:goerr113
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("no valid response from %s: %w", d.addr, lastErr)
+}
+
+func snmpSetRequest(community string, reqID int, oid []uint32, value int) []byte {
+	varBind := berTLV(berSequence, append(berTLV(berOID, berEncodeOID(oid)), berTLV(berInteger, berEncodeInt(value))...))
+
+	pdu := berTLV(berInteger, berEncodeInt(reqID))
+	pdu = append(pdu, berTLV(berInteger, berEncodeInt(0))...) // error status
+	pdu = append(pdu, berTLV(berInteger, berEncodeInt(0))...) // error index
+	pdu = append(pdu, berTLV(berSequence, varBind)...)
+
+	msg := berTLV(berInteger, berEncodeInt(snmpVersion2c))
+	msg = append(msg, berTLV(berOctetString, []byte(community))...)
+	msg = append(msg, berTLV(snmpSetReq, pdu)...)
+
+	return berTLV(berSequence, msg)
+}
+
+// parseSNMPResponse returns request ID and error status from the GetResponse PDU
+func parseSNMPResponse(data []byte) (int, int, error) {
+	tag, msg, _, err := berParse(data)
+	if err != nil || tag != berSequence {
+		return 0, 0, errSNMPMalformed
+	}
+
+	// version and community
+	for range 2 {
+		if _, _, msg, err = berParse(msg); err != nil {
+			return 0, 0, errSNMPMalformed
+		}
+	}
+
+	tag, pdu, _, err := berParse(msg)
+	if err != nil || tag != snmpGetResp {
+		return 0, 0, errSNMPMalformed
+	}
+
+	values := [2]int{}
+	for idx := range values {
+		var val []byte
+		tag, val, pdu, err = berParse(pdu)
+		if err != nil || tag != berInteger {
+			return 0, 0, errSNMPMalformed
+		}
+		values[idx] = berDecodeInt(val)
+	}
+
+	return values[0], values[1], nil
+}
+
+func berTLV(tag byte, value []byte) []byte {
+	out := []byte{tag}
+	if l := len(value); l < 0x80 {
+		out = append(out, byte(l))
+	} else {
+		lenBytes := []byte{}
+		for ; l > 0; l >>= 8 {
+			lenBytes = append([]byte{byte(l & 0xff)}, lenBytes...)
+		}
+		out = append(out, 0x80|byte(len(lenBytes)))
+		out = append(out, lenBytes...)
+	}
+
+	return append(out, value...)
+}
+
+func berParse(data []byte) (byte, []byte, []byte, error) {
+	if len(data) < 2 {
+		return 0, nil, nil, errSNMPMalformed
+	}
+
+	tag, l, data := data[0], int(data[1]), data[2:]
+	if l&0x80 != 0 {
+		n := l & 0x7f
+		if n == 0 || n > 4 || len(data) < n {
+			return 0, nil, nil, errSNMPMalformed
+		}
+		l = 0
+		for _, b := range data[:n] {
+			l = l<<8 | int(b)
+		}
+		data = data[n:]
+	}
+	if l > len(data) {
+		return 0, nil, nil, errSNMPMalformed
+	}
+
+	return tag, data[:l], data[l:], nil
+}
+
+func berEncodeInt(v int) []byte {
+	out := []byte{byte(v)}
+	for v > 0x7f || v < -0x80 {
+		v >>= 8
+		out = append([]byte{byte(v)}, out...)
+	}
+
+	return out
+}
+
+func berDecodeInt(data []byte) int {
+	v := 0
+	if len(data) > 0 && data[0]&0x80 != 0 {
+		v = -1
+	}
+	for _, b := range data {
+		v = v<<8 | int(b)
+	}
+
+	return v
+}
+
+func berEncodeOID(oid []uint32) []byte {
+	if len(oid) < 2 {
+		return nil
+	}
+
+	out := []byte{byte(oid[0]*40 + oid[1])} //nolint:gosec
+	for _, arc := range oid[2:] {
+		enc := []byte{byte(arc & 0x7f)}
+		for arc >>= 7; arc > 0; arc >>= 7 {
+			enc = append([]byte{0x80 | byte(arc&0x7f)}, enc...)
+		}
+		out = append(out, enc...)
+	}
+
+	return out
+}
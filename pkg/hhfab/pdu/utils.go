@@ -60,14 +60,3 @@ func ExtractOutletID(url string) (int, error) {
 
 	return id, nil
 }
-
-func GetPDUIPFromURL(url string) (string, error) {
-	parts := strings.Split(url, "/")
-
-	// URL must have at least 3 parts: http://PDUIP/outlet/PORT
-	if len(parts) < 3 {
-		return "", fmt.Errorf("invalid URL format: expected at least 3 parts, got %d", len(parts)) //nolint:goerr113
-	}
-
-	return parts[2], nil
-}
@@ -0,0 +1,568 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+
+	gwapi "go.githedgehog.com/fabric/api/gateway/v1alpha1"
+	vpcapi "go.githedgehog.com/fabric/api/vpc/v1beta1"
+	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
+	"go.githedgehog.com/fabricator/pkg/util/apiutil"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type ReachabilityFormat string
+
+const (
+	ReachabilityFormatCSV  ReachabilityFormat = "csv"
+	ReachabilityFormatHTML ReachabilityFormat = "html"
+)
+
+var ReachabilityFormats = []ReachabilityFormat{
+	ReachabilityFormatCSV,
+	ReachabilityFormatHTML,
+}
+
+const (
+	ReachabilityFilename = "reachability"
+
+	reachabilityExternalSubnet = "external"
+)
+
+// reachabilityLists are the objects the reachability checks need, copied into the offline client with defaults applied
+var reachabilityLists = []kclient.ObjectList{
+	&wiringapi.ServerList{},
+	&wiringapi.ConnectionList{},
+	&vpcapi.VPCList{},
+	&vpcapi.VPCAttachmentList{},
+	&vpcapi.VPCPeeringList{},
+	&vpcapi.ExternalList{},
+	&vpcapi.ExternalAttachmentList{},
+	&vpcapi.ExternalPeeringList{},
+	&gwapi.VPCInfoList{},
+	&gwapi.GatewayPeeringList{},
+}
+
+type defaulter interface {
+	Default()
+}
+
+// newOfflineReachabilityClient builds a client with everything the reachability checks expect to find in a running
+// controller: defaulted objects (so the list labels are set) and VPCInfos for all VPCs and externals. Extra objects
+// (what-if) are added on top and replace the ones with the same kind and name.
+func newOfflineReachabilityClient(ctx context.Context, kube kclient.Reader, extra []kclient.Object) (kclient.Client, error) {
+	l := apiutil.NewLoader()
+	out := l.GetClient()
+
+	key := func(obj kclient.Object) string {
+		return fmt.Sprintf("%T/%s", obj, obj.GetName())
+	}
+
+	overridden := map[string]bool{}
+	for _, obj := range extra {
+		overridden[key(obj)] = true
+	}
+
+	objs := []kclient.Object{}
+	for _, objList := range reachabilityLists {
+		if err := kube.List(ctx, objList); err != nil {
+			return nil, fmt.Errorf("listing %T: %w", objList, err)
+		}
+		for _, obj := range apiutil.KubeListItems(objList) {
+			if overridden[key(obj)] {
+				continue
+			}
+			objs = append(objs, obj)
+		}
+	}
+	objs = append(objs, extra...)
+
+	for _, obj := range objs {
+		if d, ok := obj.(defaulter); ok {
+			d.Default()
+		}
+	}
+	if err := l.Add(ctx, objs...); err != nil {
+		return nil, fmt.Errorf("adding objects: %w", err)
+	}
+
+	vpcs := &vpcapi.VPCList{}
+	if err := out.List(ctx, vpcs); err != nil {
+		return nil, fmt.Errorf("listing VPCs: %w", err)
+	}
+	for _, vpc := range vpcs.Items {
+		info := &gwapi.VPCInfo{Spec: gwapi.VPCInfoSpec{Subnets: map[string]*gwapi.VPCInfoSubnet{}}}
+		for subnetName, subnet := range vpc.Spec.Subnets {
+			info.Spec.Subnets[subnetName] = &gwapi.VPCInfoSubnet{CIDR: subnet.Subnet}
+		}
+		if err := ensureVPCInfo(ctx, out, vpc.Name, info); err != nil {
+			return nil, err
+		}
+	}
+
+	externals := &vpcapi.ExternalList{}
+	if err := out.List(ctx, externals); err != nil {
+		return nil, fmt.Errorf("listing externals: %w", err)
+	}
+	for _, ext := range externals.Items {
+		info := &gwapi.VPCInfo{Spec: gwapi.VPCInfoSpec{Subnets: map[string]*gwapi.VPCInfoSubnet{
+			reachabilityExternalSubnet: {CIDR: "0.0.0.0/0"},
+		}}}
+		if err := ensureVPCInfo(ctx, out, gwapi.VPCInfoExtPrefix+ext.Name, info); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+func ensureVPCInfo(ctx context.Context, kube kclient.Client, name string, info *gwapi.VPCInfo) error {
+	if err := kube.Get(ctx, kclient.ObjectKey{Name: name, Namespace: kmetav1.NamespaceDefault}, &gwapi.VPCInfo{}); err == nil {
+		return nil
+	} else if kclient.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("getting VPCInfo %q: %w", name, err)
+	}
+
+	info.Name = name
+	info.Namespace = kmetav1.NamespaceDefault
+	info.Default()
+	if err := kube.Create(ctx, info); err != nil {
+		return fmt.Errorf("creating VPCInfo %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// collectOfflineServerEndpoints returns one endpoint per server attachment without IPs as nothing is running
+func collectOfflineServerEndpoints(ctx context.Context, kube kclient.Reader, servers []string) ([]*Endpoint, error) {
+	serverAttachments, err := collectServerAttachments(ctx, kube, servers)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []*Endpoint{}
+	for serverName, attaches := range serverAttachments {
+		for _, attach := range attaches {
+			out = append(out, &Endpoint{Server: &ServerEndpoint{
+				Name:    serverName,
+				VPC:     attach.vpcName,
+				Subnet:  attach.subnetName,
+				HostBGP: attach.hostBGP,
+			}})
+		}
+	}
+	slices.SortFunc(out, func(a, b *Endpoint) int {
+		return strings.Compare(endpointLabel(a), endpointLabel(b))
+	})
+
+	return out, nil
+}
+
+// ReachabilityExplanation is the expected verdict for a pair of endpoints together with the chain of objects behind it
+type ReachabilityExplanation struct {
+	Source      *Endpoint
+	Destination *Endpoint
+	Verdict     ConnectivityVerdict
+	Reason      ReachabilityReason
+	Peering     string
+	Detail      string
+	Chain       []string
+	Translation []string
+}
+
+// explainReachability layers the chain of objects on top of the matrix entry for the pair, so the verdict is always
+// the one the release test would expect
+func explainReachability(ctx context.Context, kube kclient.Reader, m *ConnectivityMatrix, src, dst *Endpoint, gatewayEnabled bool) (*ReachabilityExplanation, error) {
+	if src.Server == nil {
+		return nil, fmt.Errorf("source %s is not a server", endpointLabel(src)) //nolint:goerr113
+	}
+
+	exp := m.Lookup(src, dst, ProtoPort{})
+	e := &ReachabilityExplanation{
+		Source:      src,
+		Destination: dst,
+		Verdict:     exp.Verdict,
+		Reason:      exp.Reason,
+		Peering:     exp.Peering,
+		Detail:      exp.Detail,
+	}
+
+	srcCIDR, err := vpcSubnetCIDR(ctx, kube, src.Server.VPC, src.Server.Subnet)
+	if err != nil {
+		return nil, err
+	}
+	e.Chain = append(e.Chain, fmt.Sprintf("source %s in %s/%s (%s)", src.Server.Name, src.Server.VPC, src.Server.Subnet, srcCIDR))
+
+	var dstStep, dstVPC string
+	switch {
+	case dst.Server != nil:
+		dstCIDR, err := vpcSubnetCIDR(ctx, kube, dst.Server.VPC, dst.Server.Subnet)
+		if err != nil {
+			return nil, err
+		}
+		dstStep = fmt.Sprintf("destination %s in %s/%s (%s)", dst.Server.Name, dst.Server.VPC, dst.Server.Subnet, dstCIDR)
+		dstVPC = dst.Server.VPC
+	case dst.External != nil:
+		dstStep = fmt.Sprintf("destination external %s (%s)", dst.External.ExternalName, joinPrefixes(dst.External.Prefixes))
+		dstVPC = gwapi.VPCInfoExtPrefix + dst.External.ExternalName
+	default:
+		return nil, fmt.Errorf("destination %s is empty", endpointLabel(dst)) //nolint:goerr113
+	}
+
+	reachable := e.Verdict == VerdictAllow
+	switch {
+	case e.Reason == ReachabilityReasonIntraVPC && reachable:
+		e.Chain = append(e.Chain, fmt.Sprintf("same VPC %s", src.Server.VPC))
+	case e.Reason == ReachabilityReasonIntraVPC:
+		e.Detail = fmt.Sprintf("subnets are isolated or restricted in VPC %s", src.Server.VPC)
+	case e.Reason == ReachabilityReasonSwitchPeering && dst.External != nil:
+		if e.Peering == "" {
+			peerings, err := externalPeeringsFor(ctx, kube, src.Server.VPC, src.Server.Subnet, dst.External.ExternalName)
+			if err != nil {
+				return nil, err
+			}
+			e.Peering = strings.Join(peerings, ", ")
+		}
+		e.Chain = append(e.Chain, fmt.Sprintf("external peering %s (switches)", e.Peering))
+	case e.Reason == ReachabilityReasonSwitchPeering:
+		e.Chain = append(e.Chain, fmt.Sprintf("VPC peering %s (switches)", e.Peering))
+	case e.Reason == ReachabilityReasonGatewayPeering:
+		e.Chain = append(e.Chain, fmt.Sprintf("gateway peering %s", e.Peering))
+	case e.Verdict == VerdictDeny:
+		e.Detail = "no VPC, external or gateway peering permits the traffic"
+		if !gatewayEnabled {
+			e.Detail += " (gateway is disabled)"
+		}
+	}
+
+	if e.Verdict == VerdictUnknown || e.Reason == ReachabilityReasonGatewayPeering {
+		peerings, translation, err := describeGatewayTranslation(ctx, kube, src.Server.VPC, dstVPC, e.Peering)
+		if err != nil {
+			return nil, err
+		}
+		if e.Verdict == VerdictUnknown {
+			for _, peering := range peerings {
+				e.Chain = append(e.Chain, fmt.Sprintf("gateway peering %s", peering))
+			}
+		}
+		e.Translation = translation
+	}
+
+	e.Chain = append(e.Chain, dstStep)
+
+	return e, nil
+}
+
+func vpcSubnetCIDR(ctx context.Context, kube kclient.Reader, vpcName, subnetName string) (string, error) {
+	vpc := &vpcapi.VPC{}
+	if err := kube.Get(ctx, kclient.ObjectKey{Name: vpcName, Namespace: kmetav1.NamespaceDefault}, vpc); err != nil {
+		return "", fmt.Errorf("getting VPC %q: %w", vpcName, err)
+	}
+	subnet, ok := vpc.Spec.Subnets[subnetName]
+	if !ok {
+		return "", fmt.Errorf("subnet %s not found in VPC %s", subnetName, vpcName) //nolint:goerr113
+	}
+
+	return subnet.Subnet, nil
+}
+
+func joinPrefixes(prefixes []netip.Prefix) string {
+	out := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		out = append(out, prefix.String())
+	}
+
+	return strings.Join(out, ", ")
+}
+
+// externalPeeringsFor lists the switch external peerings permitting the VPC subnet to the external as the matrix
+// entries don't carry the name of the external peering
+func externalPeeringsFor(ctx context.Context, kube kclient.Reader, vpc, subnet, ext string) ([]string, error) {
+	extPeerings := &vpcapi.ExternalPeeringList{}
+	if err := kube.List(ctx, extPeerings,
+		kclient.InNamespace(kmetav1.NamespaceDefault),
+		kclient.MatchingLabels{
+			vpcapi.LabelVPC: vpc,
+		},
+	); err != nil {
+		return nil, fmt.Errorf("listing external peerings: %w", err)
+	}
+
+	names := []string{}
+	for _, extPeering := range extPeerings.Items {
+		permit := extPeering.Spec.Permit
+		if permit.External.Name == ext && slices.Contains(permit.VPC.Subnets, subnet) {
+			names = append(names, extPeering.Name)
+		}
+	}
+
+	return names, nil
+}
+
+// describeGatewayTranslation lists the gateway peerings between the VPCs (or only the given one) and describes the
+// address translation each side of them is exposed with
+func describeGatewayTranslation(ctx context.Context, kube kclient.Reader, vpc1, vpc2, only string) ([]string, []string, error) {
+	peerings := &gwapi.GatewayPeeringList{}
+	if err := kube.List(ctx, peerings, kclient.InNamespace(kmetav1.NamespaceDefault)); err != nil {
+		return nil, nil, fmt.Errorf("listing gateway peerings: %w", err)
+	}
+
+	names, translation := []string{}, []string{}
+	for _, peering := range peerings.Items {
+		if only != "" && peering.Name != only {
+			continue
+		}
+		if peering.Spec.Peering[vpc1] == nil || peering.Spec.Peering[vpc2] == nil {
+			continue
+		}
+
+		names = append(names, peering.Name)
+		for _, vpc := range []string{vpc1, vpc2} {
+			for _, expose := range peering.Spec.Peering[vpc].Expose {
+				if desc := describeExpose(expose); desc != "" {
+					translation = append(translation, fmt.Sprintf("%s: %s", strings.TrimPrefix(vpc, gwapi.VPCInfoExtPrefix), desc))
+				}
+			}
+		}
+	}
+
+	return names, translation, nil
+}
+
+func describeExpose(expose gwapi.PeeringEntryExpose) string {
+	if expose.DefaultDestination {
+		return "default destination"
+	}
+	if len(expose.As) == 0 && expose.NAT == nil {
+		return ""
+	}
+
+	ips := []string{}
+	for _, ip := range expose.IPs {
+		switch {
+		case ip.VPCSubnet != "":
+			ips = append(ips, ip.VPCSubnet)
+		case ip.CIDR != "":
+			ips = append(ips, ip.CIDR)
+		case ip.Not != "":
+			ips = append(ips, "not "+ip.Not)
+		}
+	}
+	as := []string{}
+	for _, a := range expose.As {
+		if a.CIDR != "" {
+			as = append(as, a.CIDR)
+		} else if a.Not != "" {
+			as = append(as, "not "+a.Not)
+		}
+	}
+
+	mode := "static NAT"
+	if expose.NAT != nil {
+		switch {
+		case expose.NAT.Masquerade != nil:
+			mode = "masquerade"
+		case expose.NAT.PortForward != nil:
+			ports := []string{}
+			for _, port := range expose.NAT.PortForward.Ports {
+				proto := string(port.Protocol)
+				if proto == "" {
+					proto = "any"
+				}
+				ports = append(ports, fmt.Sprintf("%s/%s->%s", proto, port.Port, port.As))
+			}
+			mode = "port forward " + strings.Join(ports, ", ")
+		}
+	}
+
+	return fmt.Sprintf("%s exposed as %s (%s)", strings.Join(ips, ", "), strings.Join(as, ", "), mode)
+}
+
+// explainAllReachability explains all pairs of endpoints, servers are only considered as sources
+func explainAllReachability(ctx context.Context, kube kclient.Reader, m *ConnectivityMatrix, sources, destinations []*Endpoint, gatewayEnabled bool) ([]*ReachabilityExplanation, error) {
+	out := []*ReachabilityExplanation{}
+	for _, src := range sources {
+		if src.Server == nil {
+			continue
+		}
+		for _, dst := range destinations {
+			if dst.Server != nil && dst.Server.Name == src.Server.Name {
+				continue
+			}
+
+			e, err := explainReachability(ctx, kube, m, src, dst, gatewayEnabled)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, e)
+		}
+	}
+
+	return out, nil
+}
+
+func printReachabilityExplanation(w io.Writer, e *ReachabilityExplanation) {
+	_, _ = fmt.Fprintf(w, "%s -> %s: %s\n", endpointLabel(e.Source), endpointLabel(e.Destination), e.Verdict)
+	for _, step := range e.Chain {
+		_, _ = fmt.Fprintf(w, "  %s\n", step)
+	}
+	if e.Detail != "" {
+		_, _ = fmt.Fprintf(w, "  reason: %s\n", e.Detail)
+	} else if e.Reason != "" {
+		_, _ = fmt.Fprintf(w, "  reason: %s\n", e.Reason)
+	}
+	for _, t := range e.Translation {
+		_, _ = fmt.Fprintf(w, "  translation: %s\n", t)
+	}
+}
+
+func endpointColumns(ep *Endpoint) (string, string, string) {
+	if ep.Server != nil {
+		return ep.Server.Name, ep.Server.VPC, ep.Server.Subnet
+	}
+
+	return "external:" + ep.External.ExternalName, "", ""
+}
+
+func writeReachabilityCSV(w io.Writer, explanations []*ReachabilityExplanation) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{
+		"source", "source_vpc", "source_subnet",
+		"destination", "destination_vpc", "destination_subnet",
+		"verdict", "reason", "peering", "detail", "translation",
+	}); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	for _, e := range explanations {
+		srcName, srcVPC, srcSubnet := endpointColumns(e.Source)
+		dstName, dstVPC, dstSubnet := endpointColumns(e.Destination)
+		if err := out.Write([]string{
+			srcName, srcVPC, srcSubnet,
+			dstName, dstVPC, dstSubnet,
+			string(e.Verdict), string(e.Reason), e.Peering, e.Detail, strings.Join(e.Translation, "; "),
+		}); err != nil {
+			return fmt.Errorf("writing row: %w", err)
+		}
+	}
+
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return fmt.Errorf("flushing: %w", err)
+	}
+
+	return nil
+}
+
+var reachabilityHTMLTmpl = template.Must(template.New("reachability").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Expected reachability</title>
+<style>
+body { font-family: sans-serif; font-size: 12px; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 2px 4px; text-align: center; }
+th.dst { writing-mode: vertical-rl; transform: rotate(180deg); }
+td.allow { background: #b7e1b0; }
+td.deny { background: #eee; color: #999; }
+td.unknown { background: #f7e29c; }
+</style>
+</head>
+<body>
+<h1>Expected reachability</h1>
+<p>Rows are sources, columns are destinations. Hover a cell for the reason, peering and translation.</p>
+<table>
+<tr><th></th>{{ range .Destinations }}<th class="dst">{{ . }}</th>{{ end }}</tr>
+{{ range .Rows }}<tr><th>{{ .Source }}</th>{{ range .Cells }}<td class="{{ .Verdict }}" title="{{ .Title }}">{{ .Short }}</td>{{ end }}</tr>
+{{ end }}</table>
+</body>
+</html>
+`))
+
+type reachabilityHTMLCell struct {
+	Verdict string
+	Short   string
+	Title   string
+}
+
+type reachabilityHTMLRow struct {
+	Source string
+	Cells  []reachabilityHTMLCell
+}
+
+func writeReachabilityHTML(w io.Writer, explanations []*ReachabilityExplanation) error {
+	sources, destinations := []string{}, []string{}
+	cells := map[string]map[string]*ReachabilityExplanation{}
+	for _, e := range explanations {
+		src, dst := endpointLabel(e.Source), endpointLabel(e.Destination)
+		if cells[src] == nil {
+			cells[src] = map[string]*ReachabilityExplanation{}
+			sources = append(sources, src)
+		}
+		if !slices.Contains(destinations, dst) {
+			destinations = append(destinations, dst)
+		}
+		cells[src][dst] = e
+	}
+	sort.SliceStable(destinations, func(i, j int) bool {
+		iExt, jExt := strings.HasPrefix(destinations[i], "external:"), strings.HasPrefix(destinations[j], "external:")
+		if iExt != jExt {
+			return jExt
+		}
+
+		return destinations[i] < destinations[j]
+	})
+
+	rows := []reachabilityHTMLRow{}
+	for _, src := range sources {
+		row := reachabilityHTMLRow{Source: src}
+		for _, dst := range destinations {
+			e, ok := cells[src][dst]
+			if !ok {
+				row.Cells = append(row.Cells, reachabilityHTMLCell{})
+
+				continue
+			}
+
+			title := []string{string(e.Verdict)}
+			if e.Reason != "" {
+				title = append(title, string(e.Reason))
+			}
+			if e.Peering != "" {
+				title = append(title, "peering "+e.Peering)
+			}
+			if e.Detail != "" {
+				title = append(title, e.Detail)
+			}
+			title = append(title, e.Translation...)
+
+			row.Cells = append(row.Cells, reachabilityHTMLCell{
+				Verdict: string(e.Verdict),
+				Short:   strings.ToUpper(string(e.Verdict)[:1]),
+				Title:   strings.Join(title, "\n"),
+			})
+		}
+		rows = append(rows, row)
+	}
+
+	if err := reachabilityHTMLTmpl.Execute(w, map[string]any{
+		"Destinations": destinations,
+		"Rows":         rows,
+	}); err != nil {
+		return fmt.Errorf("executing template: %w", err)
+	}
+
+	return nil
+}
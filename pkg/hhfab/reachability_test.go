@@ -0,0 +1,211 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.githedgehog.com/fabricator/pkg/util/apiutil"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const reachabilityServerTmpl = `---
+apiVersion: wiring.githedgehog.com/v1beta1
+kind: Server
+metadata:
+  name: server-0%[1]d
+---
+apiVersion: wiring.githedgehog.com/v1beta1
+kind: Connection
+metadata:
+  name: server-0%[1]d--unbundled--leaf-01
+spec:
+  unbundled:
+    link:
+      server:
+        port: server-0%[1]d/enp2s1
+      switch:
+        port: leaf-01/E1/%[1]d
+---
+apiVersion: vpc.githedgehog.com/v1beta1
+kind: VPC
+metadata:
+  name: vpc-0%[1]d
+spec:
+  subnets:
+    subnet-01:
+      subnet: 10.0.%[1]d.0/24
+      vlan: 100%[1]d
+---
+apiVersion: vpc.githedgehog.com/v1beta1
+kind: VPCAttachment
+metadata:
+  name: server-0%[1]d--vpc-0%[1]d--subnet-01
+spec:
+  connection: server-0%[1]d--unbundled--leaf-01
+  subnet: vpc-0%[1]d/subnet-01
+`
+
+const reachabilityPeerings = `---
+apiVersion: vpc.githedgehog.com/v1beta1
+kind: VPCPeering
+metadata:
+  name: vpc-01--vpc-02
+spec:
+  permit:
+    - vpc-01: {}
+      vpc-02: {}
+---
+apiVersion: vpc.githedgehog.com/v1beta1
+kind: External
+metadata:
+  name: ext-01
+spec:
+  ipv4Namespace: default
+  inboundCommunity: 65102:5000
+  outboundCommunity: 5000:65102
+---
+apiVersion: vpc.githedgehog.com/v1beta1
+kind: ExternalAttachment
+metadata:
+  name: ext-01--leaf-01
+spec:
+  external: ext-01
+  connection: leaf-01--external--ext
+  switch:
+    vlan: 100
+    ip: 100.100.0.1/24
+  neighbor:
+    asn: 64102
+    ip: 100.100.0.6
+---
+apiVersion: vpc.githedgehog.com/v1beta1
+kind: ExternalPeering
+metadata:
+  name: vpc-02--ext-01
+spec:
+  permit:
+    vpc:
+      name: vpc-02
+      subnets: [subnet-01]
+    external:
+      name: ext-01
+      prefixes:
+        - prefix: 0.0.0.0/0
+---
+apiVersion: gateway.githedgehog.com/v1alpha1
+kind: GatewayPeering
+metadata:
+  name: vpc-01--vpc-03
+spec:
+  peering:
+    vpc-01:
+      expose:
+        - ips:
+            - cidr: 10.0.1.0/24
+    vpc-03:
+      expose:
+        - ips:
+            - cidr: 10.0.3.0/24
+          as:
+            - cidr: 192.168.3.0/24
+          nat:
+            static: {}
+`
+
+func newTestOfflineReachability(t *testing.T, extra string) *offlineReachability {
+	t.Helper()
+
+	ctx := context.Background()
+	data := reachabilityPeerings
+	for idx := 1; idx <= 3; idx++ {
+		data += fmt.Sprintf(reachabilityServerTmpl, idx)
+	}
+
+	l := apiutil.NewLoader()
+	require.NoError(t, l.LoadAdd(ctx, apiutil.FabricGatewayGVKs, []byte(data)))
+
+	extraObjs := []kclient.Object{}
+	if extra != "" {
+		objs, err := apiutil.NewLoader().Load(apiutil.FabricGatewayGVKs, []byte(extra))
+		require.NoError(t, err)
+		extraObjs = objs
+	}
+
+	r, err := newOfflineReachability(ctx, l.GetClient(), extraObjs, true)
+	require.NoError(t, err)
+
+	return r
+}
+
+func TestOfflineReachabilityExplain(t *testing.T) {
+	ctx := context.Background()
+	r := newTestOfflineReachability(t, "")
+	require.Len(t, r.servers, 3)
+	require.Len(t, r.externals, 1)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, r.explain(ctx, out, "server-01", "server-02"))
+	require.Contains(t, out.String(), "server-01(vpc-01/subnet-01) -> server-02(vpc-02/subnet-01): allow")
+	require.Contains(t, out.String(), "VPC peering vpc-01--vpc-02 (switches)")
+
+	out.Reset()
+	require.NoError(t, r.explain(ctx, out, "server-01", "server-03"))
+	require.Contains(t, out.String(), ": unknown")
+	require.Contains(t, out.String(), "gateway peering vpc-01--vpc-03")
+	require.Contains(t, out.String(), "translation: vpc-03: 10.0.3.0/24 exposed as 192.168.3.0/24 (static NAT)")
+
+	out.Reset()
+	require.NoError(t, r.explain(ctx, out, "server-02", "ext-01"))
+	require.Contains(t, out.String(), "server-02(vpc-02/subnet-01) -> external:ext-01: allow")
+	require.Contains(t, out.String(), "external peering vpc-02--ext-01 (switches)")
+
+	out.Reset()
+	require.NoError(t, r.explain(ctx, out, "server-02", "server-03"))
+	require.Contains(t, out.String(), ": deny")
+	require.Contains(t, out.String(), "reason: no VPC, external or gateway peering permits the traffic")
+
+	require.Error(t, r.explain(ctx, out, "ext-01", "server-01"))
+	require.Error(t, r.explain(ctx, out, "server-01", "server-09"))
+}
+
+func TestOfflineReachabilityWhatIf(t *testing.T) {
+	r := newTestOfflineReachability(t, `
+apiVersion: vpc.githedgehog.com/v1beta1
+kind: VPCPeering
+metadata:
+  name: vpc-01--vpc-02
+spec:
+  permit:
+    - vpc-01: {}
+      vpc-03: {}
+`)
+
+	explanations, err := explainAllReachability(context.Background(), r.kube, r.matrix, r.servers, append(r.servers, r.externals...), r.gatewayEnabled)
+	require.NoError(t, err)
+	require.Len(t, explanations, 9)
+
+	verdicts := map[string]ConnectivityVerdict{}
+	for _, e := range explanations {
+		verdicts[endpointLabel(e.Source)+" "+endpointLabel(e.Destination)] = e.Verdict
+	}
+	require.Equal(t, VerdictDeny, verdicts["server-01(vpc-01/subnet-01) server-02(vpc-02/subnet-01)"])
+	require.Equal(t, VerdictAllow, verdicts["server-01(vpc-01/subnet-01) server-03(vpc-03/subnet-01)"])
+	require.Equal(t, VerdictAllow, verdicts["server-02(vpc-02/subnet-01) external:ext-01"])
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeReachabilityCSV(buf, explanations))
+	require.Equal(t, 10, strings.Count(buf.String(), "\n"))
+	require.Contains(t, buf.String(), "server-01,vpc-01,subnet-01,server-03,vpc-03,subnet-01,allow,switch-peering,vpc-01--vpc-02,,\n")
+
+	buf.Reset()
+	require.NoError(t, writeReachabilityHTML(buf, explanations))
+	require.Contains(t, buf.String(), `<td class="allow"`)
+	require.Contains(t, buf.String(), "external:ext-01")
+}
@@ -96,6 +96,7 @@ func makeTestCtx(ctx context.Context, kube kclient.Client, setupOpts SetupVPCsOp
 		IPerfsMinSpeed:    rtOpts.IPerfsMinSpeed,
 		CurlsCount:        1,
 		RequireAllServers: setupOpts.VPCMode == vpcapi.VPCModeL2VNI, // L3VNI will skip eslag servers
+		IPerfsStats:       &IPerfStats{},
 	}
 	testCtx.wrOpts = WaitReadyOpts{
 		AppliedFor: waitAppliedFor,
@@ -273,18 +274,18 @@ type JUnitTestSuite struct {
 }
 
 type SkipFlags struct {
-	VirtualSwitch     bool `xml:"-"` // skip if there's any virtual switch in the vlab
-	NoBGPExternals    bool `xml:"-"` // skip if there are no viable BGP externals
-	NoStaticExternals bool `xml:"-"` // skip if there are no viable static externals
-	ExtendedOnly      bool `xml:"-"` // skip if extended tests are not enabled
-	RoCE              bool `xml:"-"` // skip if RoCE is not supported by any of the leaf switches
-	SubInterfaces     bool `xml:"-"` // skip if subinterfaces are not supported by some of the switches
-	NoFabricLink      bool `xml:"-"` // skip if there's no fabric (i.e. spine-leaf) link between the switches
-	NoMeshLink        bool `xml:"-"` // skip if there's no mesh (i.e. leaf-leaf) link between the switches
-	NoGateway         bool `xml:"-"` // skip if gateway is not enabled or no gateways available
-	NoLoki            bool `xml:"-"` // skip if Loki is not configured or available
-	NoProm            bool `xml:"-"` // skip if Prometheus is not configured or available
-	NoServers         bool `xml:"-"` // skip if there are no servers in the fabric
+	VirtualSwitch     bool `xml:"-" json:"virtualSwitch,omitempty"`     // skip if there's any virtual switch in the vlab
+	NoBGPExternals    bool `xml:"-" json:"noBGPExternals,omitempty"`    // skip if there are no viable BGP externals
+	NoStaticExternals bool `xml:"-" json:"noStaticExternals,omitempty"` // skip if there are no viable static externals
+	ExtendedOnly      bool `xml:"-" json:"extendedOnly,omitempty"`      // skip if extended tests are not enabled
+	RoCE              bool `xml:"-" json:"roce,omitempty"`              // skip if RoCE is not supported by any of the leaf switches
+	SubInterfaces     bool `xml:"-" json:"subInterfaces,omitempty"`     // skip if subinterfaces are not supported by some of the switches
+	NoFabricLink      bool `xml:"-" json:"noFabricLink,omitempty"`      // skip if there's no fabric (i.e. spine-leaf) link between the switches
+	NoMeshLink        bool `xml:"-" json:"noMeshLink,omitempty"`        // skip if there's no mesh (i.e. leaf-leaf) link between the switches
+	NoGateway         bool `xml:"-" json:"noGateway,omitempty"`         // skip if gateway is not enabled or no gateways available
+	NoLoki            bool `xml:"-" json:"noLoki,omitempty"`            // skip if Loki is not configured or available
+	NoProm            bool `xml:"-" json:"noProm,omitempty"`            // skip if Prometheus is not configured or available
+	NoServers         bool `xml:"-" json:"noServers,omitempty"`         // skip if there are no servers in the fabric
 
 	/* Note about subinterfaces; they are required in the following cases:
 	 * 1. when using VPC loopback workaround - it's applied when we have a pair of vpcs or vpc and external both attached on a switch with peering between them
@@ -347,6 +348,11 @@ type JUnitTestCase struct {
 	Skipped   *Skipped  `xml:"skipped,omitempty"`
 	F         TestFunc  `xml:"-"` // function to run
 	SkipFlags SkipFlags `xml:"-"` // flags to determine whether to skip the test
+
+	// iperf3 stats for the release test history
+	IPerfMinSpeed *float64 `xml:"-"`
+	IPerfAvgSpeed *float64 `xml:"-"`
+	IPerfMargin   *float64 `xml:"-"`
 }
 
 type Failure struct {
@@ -358,11 +364,15 @@ type Failure struct {
 type Skipped struct {
 	XMLName xml.Name `xml:"skipped"`
 	Message string   `xml:"message,attr,omitempty"`
+	Type    string   `xml:"type,attr,omitempty"` // SkippedTypeShard for tests left to the other shards
 }
 
 func printTestSuite(ts *JUnitTestSuite) {
 	slog.Info("*** Test suite", "suite", ts.Name, "tests", ts.Tests)
 	for _, test := range ts.TestCases {
+		if isShardSkipped(&test) {
+			continue
+		}
 		slog.Info("* Test", "name", test.Name, "skipFlags", test.SkipFlags.PrettyPrint())
 	}
 }
@@ -504,8 +514,10 @@ func doRunSuite(ctx context.Context, testCtx *VPCPeeringTestCtx, ts *JUnitTestSu
 		}
 		prevRevertsFailed = false
 		testStart := time.Now()
+		testCtx.tcOpts.IPerfsStats.Reset()
 		skip, reverts, err := test.F(ctx, testCtx, matrix)
 		ts.TestCases[i].Time = time.Since(testStart).Seconds()
+		ts.TestCases[i].IPerfMinSpeed, ts.TestCases[i].IPerfAvgSpeed, ts.TestCases[i].IPerfMargin = testCtx.tcOpts.IPerfsStats.summary()
 		ranSomeTests = true
 		// logic is getting complex, so let's make a recap:
 		// - if skip is true, we mark the test as skipped, use the error as the skip message, and nullify it
@@ -602,6 +614,9 @@ func regexpSelection(regexes []*regexp.Regexp, invertRegex bool, suite *JUnitTes
 	}
 
 	for i, test := range suite.TestCases {
+		if test.Skipped != nil {
+			continue
+		}
 		matched := false
 		for _, regex := range regexes {
 			if regex.MatchString(test.Name) {
@@ -772,7 +787,7 @@ func selectAndRunSuite(ctx context.Context, testCtx *VPCPeeringTestCtx, suite *J
 	return suite, nil
 }
 
-func recapAndReport(results []JUnitTestSuite, rtOpts ReleaseTestOpts) error {
+func recapAndReport(start time.Time, results []JUnitTestSuite, rtOpts ReleaseTestOpts) error {
 	slog.Info("*** Recap of the test results ***")
 	for _, suite := range results {
 		printSuiteResults(&suite)
@@ -791,6 +806,13 @@ func recapAndReport(results []JUnitTestSuite, rtOpts ReleaseTestOpts) error {
 		}
 	}
 
+	if rtOpts.HistoryDir != "" {
+		// history is best effort and shouldn't fail the run
+		if err := saveReleaseTestRun(rtOpts.HistoryDir, newReleaseTestRun(start, rtOpts, results)); err != nil {
+			slog.Warn("Failed to save release test run to history", "dir", rtOpts.HistoryDir, "err", err)
+		}
+	}
+
 	return nil
 }
 
@@ -810,6 +832,23 @@ func RunReleaseTestSuites(ctx context.Context, vlabCfg *Config, vlab *VLAB, rtOp
 		suites = []*JUnitTestSuite{noVpcSuite, singleVpcSuite, multiVPCMultiSubnetSuite, multiVPCSingleSubnetSuite}
 	}
 
+	testFiles, err := LoadReleaseTestFiles(rtOpts.TestFiles)
+	if err != nil {
+		return fmt.Errorf("loading release test files: %w", err)
+	}
+	fileSuites := make([]*JUnitTestSuite, 0, len(testFiles))
+	for _, testFile := range testFiles {
+		fileSuites = append(fileSuites, testFile.suite())
+	}
+	if !rtOpts.OnReadyTest {
+		suites = append(suites, fileSuites...)
+	}
+
+	if rtOpts.Shard.Enabled() {
+		slog.Info("Running only tests from the shard", "shard", rtOpts.Shard.String())
+		shardSelection(rtOpts.Shard, suites)
+	}
+
 	if rtOpts.ListTests {
 		for _, suite := range suites {
 			printTestSuite(suite)
@@ -818,6 +857,10 @@ func RunReleaseTestSuites(ctx context.Context, vlabCfg *Config, vlab *VLAB, rtOp
 		return nil
 	}
 
+	if rtOpts.Shard.Enabled() && rtOpts.HistoryDir != "" && rtOpts.RunID == "" {
+		return fmt.Errorf("run ID is required to record the history of the sharded run") //nolint:goerr113
+	}
+
 	cacheCancel, kube, err := getKubeClientWithCache(ctx, vlabCfg.WorkDir)
 	if err != nil {
 		return err
@@ -1027,7 +1070,7 @@ func RunReleaseTestSuites(ctx context.Context, vlabCfg *Config, vlab *VLAB, rtOp
 			return fmt.Errorf("running on-ready test suite: %w", err)
 		}
 		results = append(results, *ortResults)
-		if err := recapAndReport(results, rtOpts); err != nil {
+		if err := recapAndReport(testStart, results, rtOpts); err != nil {
 			return fmt.Errorf("recapping and reporting results: %w", err)
 		}
 		slog.Info("OnReady Test Suite completed", "duration", time.Since(testStart).String())
@@ -1066,13 +1109,28 @@ func RunReleaseTestSuites(ctx context.Context, vlabCfg *Config, vlab *VLAB, rtOp
 	}
 	results = append(results, *basicResults)
 
-	if err := recapAndReport(results, rtOpts); err != nil {
+	fileFailures := 0
+	for idx, testFile := range testFiles {
+		slog.Info("Running release test file", "suite", testFile.Suite, "path", testFile.path)
+
+		testCtx.setupOpts.SubnetsPerVPC = max(testFile.Setup.SubnetsPerVPC, 1)
+		testCtx.setupOpts.ServersPerSubnet = max(testFile.Setup.ServersPerSubnet, 1)
+		testCtx.wipeBetweenTests = !testFile.Setup.KeepBetweenTests
+		fileResults, err := selectAndRunSuite(ctx, testCtx, fileSuites[idx], regexesCompiled, rtOpts.InvertRegex, skipFlags)
+		if err != nil && rtOpts.FailFast {
+			return fmt.Errorf("running %s suite: %w", testFile.Suite, err)
+		}
+		results = append(results, *fileResults)
+		fileFailures += fileResults.Failures
+	}
+
+	if err := recapAndReport(testStart, results, rtOpts); err != nil {
 		return fmt.Errorf("recapping and reporting results: %w", err)
 	}
 
 	slog.Info("All tests completed", "duration", time.Since(testStart).String())
-	if singleVpcResults.Failures > 0 || multiVpcResults.Failures > 0 || basicResults.Failures > 0 || noVpcResults.Failures > 0 {
-		return fmt.Errorf("some tests failed: singleVpc=%d, multiVpc=%d, basic=%d, noVpc=%d", singleVpcResults.Failures, multiVpcResults.Failures, basicResults.Failures, noVpcResults.Failures) //nolint:goerr113
+	if singleVpcResults.Failures > 0 || multiVpcResults.Failures > 0 || basicResults.Failures > 0 || noVpcResults.Failures > 0 || fileFailures > 0 {
+		return fmt.Errorf("some tests failed: singleVpc=%d, multiVpc=%d, basic=%d, noVpc=%d, files=%d", singleVpcResults.Failures, multiVpcResults.Failures, basicResults.Failures, noVpcResults.Failures, fileFailures) //nolint:goerr113
 	}
 
 	return nil
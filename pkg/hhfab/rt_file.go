@@ -0,0 +1,875 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	gwapi "go.githedgehog.com/fabric/api/gateway/v1alpha1"
+	vpcapi "go.githedgehog.com/fabric/api/vpc/v1beta1"
+	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// Declarative release tests loaded from YAML files, see rt_file.md for the format and an example
+
+const (
+	// placeholders for the externals picked automatically by the release test (see findExternals)
+	ReleaseTestFileBGPExternal    = "$bgp"
+	ReleaseTestFileStaticExternal = "$static"
+)
+
+// VPCs and subnets are created by the release test setup (see SetupVPCs) so only the generated names are valid
+var (
+	releaseTestFileVPCName    = regexp.MustCompile(`^vpc-(\d{2,})$`)
+	releaseTestFileSubnetName = regexp.MustCompile(`^subnet-(\d{2,})$`)
+)
+
+type ReleaseTestFile struct {
+	Suite string                `json:"suite"`
+	Setup ReleaseTestFileSetup  `json:"setup,omitempty"`
+	Tests []ReleaseTestFileTest `json:"tests"`
+	path  string
+}
+
+type ReleaseTestFileSetup struct {
+	SubnetsPerVPC    int  `json:"subnetsPerVPC,omitempty"`    // 1 by default
+	ServersPerSubnet int  `json:"serversPerSubnet,omitempty"` // 1 by default
+	KeepBetweenTests bool `json:"keepBetweenTests,omitempty"` // don't re-create VPCs between tests
+}
+
+type ReleaseTestFileTest struct {
+	Name      string                `json:"name"`
+	SkipFlags SkipFlags             `json:"skipFlags,omitempty"`
+	Steps     []ReleaseTestFileStep `json:"steps"`
+}
+
+// ReleaseTestFileStep is a single step of the test, exactly one of the fields should be set
+type ReleaseTestFileStep struct {
+	// sets the complete list of peerings, all others are removed
+	Peerings *ReleaseTestFilePeerings `json:"peerings,omitempty"`
+	// injects a fault that is reverted after the test
+	Fault *ReleaseTestFileFault `json:"fault,omitempty"`
+	// tests connectivity using the matrix built from the current peerings with the overrides applied
+	Connectivity *ReleaseTestFileConnectivity `json:"connectivity,omitempty"`
+	Wait         *kmetav1.Duration            `json:"wait,omitempty"`
+}
+
+type ReleaseTestFilePeerings struct {
+	VPC             []ReleaseTestFileVPCPeering   `json:"vpc,omitempty"`
+	External        []ReleaseTestFileExtPeering   `json:"external,omitempty"`
+	Gateway         []ReleaseTestFileGwPeering    `json:"gateway,omitempty"`
+	GatewayExternal []ReleaseTestFileGwExtPeering `json:"gatewayExternal,omitempty"`
+}
+
+type ReleaseTestFileVPCPeering struct {
+	VPC1        string   `json:"vpc1"`
+	VPC2        string   `json:"vpc2"`
+	VPC1Subnets []string `json:"vpc1Subnets,omitempty"` // subnet names, all if empty
+	VPC2Subnets []string `json:"vpc2Subnets,omitempty"`
+}
+
+type ReleaseTestFileExtPeering struct {
+	VPC      string   `json:"vpc"`
+	External string   `json:"external"`
+	Subnets  []string `json:"subnets,omitempty"`  // subnet names, all if empty
+	Prefixes []string `json:"prefixes,omitempty"` // 0.0.0.0/0 if empty
+}
+
+type ReleaseTestFileGwPeering struct {
+	VPC1        string              `json:"vpc1"`
+	VPC2        string              `json:"vpc2"`
+	VPC1Subnets []string            `json:"vpc1Subnets,omitempty"` // subnet names, all if empty
+	VPC2Subnets []string            `json:"vpc2Subnets,omitempty"`
+	VPC1NAT     *ReleaseTestFileNAT `json:"vpc1NAT,omitempty"`
+	VPC2NAT     *ReleaseTestFileNAT `json:"vpc2NAT,omitempty"`
+}
+
+type ReleaseTestFileGwExtPeering struct {
+	VPC      string              `json:"vpc"`
+	External string              `json:"external"`
+	Subnets  []string            `json:"subnets,omitempty"` // subnet names, all if empty
+	NAT      *ReleaseTestFileNAT `json:"nat,omitempty"`
+}
+
+type ReleaseTestFileNATMode string
+
+const (
+	ReleaseTestFileNATStatic                ReleaseTestFileNATMode = "static"
+	ReleaseTestFileNATMasquerade            ReleaseTestFileNATMode = "masquerade"
+	ReleaseTestFileNATPortForward           ReleaseTestFileNATMode = "port-forward"
+	ReleaseTestFileNATMasqueradePortForward ReleaseTestFileNATMode = "masquerade-port-forward"
+)
+
+var releaseTestFileNATModes = map[ReleaseTestFileNATMode]NATMode{
+	ReleaseTestFileNATStatic:                NATModeStatic,
+	ReleaseTestFileNATMasquerade:            NATModeMasquerade,
+	ReleaseTestFileNATPortForward:           NATModePortForward,
+	ReleaseTestFileNATMasqueradePortForward: NATModeMasqueradePortForward,
+}
+
+type ReleaseTestFileNAT struct {
+	Mode        ReleaseTestFileNATMode             `json:"mode,omitempty"` // static by default
+	CIDRs       []string                           `json:"cidrs"`
+	PortForward []gwapi.PeeringNATPortForwardEntry `json:"portForward,omitempty"`
+}
+
+type ReleaseTestFileFaultType string
+
+const (
+	ReleaseTestFileFaultAgentDown ReleaseTestFileFaultType = "agent-down"
+	ReleaseTestFileFaultPortDown  ReleaseTestFileFaultType = "port-down"
+)
+
+type ReleaseTestFileFault struct {
+	Type   ReleaseTestFileFaultType `json:"type"`
+	Target string                   `json:"target"` // switch name for agent-down, switch/port (wiring name) for port-down
+}
+
+type ReleaseTestFileConnectivity struct {
+	Overrides []ReleaseTestFileOverride `json:"overrides,omitempty"`
+}
+
+// ReleaseTestFileOverride changes the expectations for all servers in the From VPC to either all servers in the To
+// VPC or to the ToExternal external
+type ReleaseTestFileOverride struct {
+	From       string `json:"from"`
+	To         string `json:"to,omitempty"`
+	ToExternal string `json:"toExternal,omitempty"`
+
+	// sets verdict for the default check or for the protocol/port if specified
+	Verdict  ConnectivityVerdict `json:"verdict,omitempty"`
+	Protocol string              `json:"protocol,omitempty"`
+	Port     uint16              `json:"port,omitempty"`
+
+	// expected SNAT pool, externals only
+	SourcePool string `json:"sourcePool,omitempty"`
+
+	// expected static (or port-forward if port is set) DNAT of the To VPC subnet into the pool, VPCs only
+	DestinationPool   string `json:"destinationPool,omitempty"`
+	DestinationSubnet string `json:"destinationSubnet,omitempty"` // subnet-01 by default
+	DestinationPort   uint16 `json:"destinationPort,omitempty"`
+}
+
+// LoadReleaseTestFiles loads release test files, directories are scanned for *.yaml and *.yml files
+func LoadReleaseTestFiles(paths []string) ([]*ReleaseTestFile, error) {
+	files := []string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("checking release test file %q: %w", path, err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading release test dir %q: %w", path, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			if ext := filepath.Ext(entry.Name()); ext == ".yaml" || ext == ".yml" {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	res := []*ReleaseTestFile{}
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading release test file %q: %w", path, err)
+		}
+
+		f, err := ParseReleaseTestFile(data)
+		if err != nil {
+			return nil, fmt.Errorf("release test file %q: %w", path, err)
+		}
+		f.path = path
+
+		res = append(res, f)
+	}
+
+	return res, nil
+}
+
+func ParseReleaseTestFile(data []byte) (*ReleaseTestFile, error) {
+	f := &ReleaseTestFile{}
+	if err := yaml.UnmarshalStrict(data, f); err != nil {
+		return nil, fmt.Errorf("parsing: %w", err)
+	}
+
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f *ReleaseTestFile) validate() error {
+	if f.Suite == "" {
+		return fmt.Errorf("suite name is required") //nolint:goerr113
+	}
+	if len(f.Tests) == 0 {
+		return fmt.Errorf("no tests defined") //nolint:goerr113
+	}
+	if f.Setup.SubnetsPerVPC < 0 || f.Setup.ServersPerSubnet < 0 {
+		return fmt.Errorf("subnets per VPC and servers per subnet should be positive") //nolint:goerr113
+	}
+
+	subnetsPerVPC := max(f.Setup.SubnetsPerVPC, 1)
+
+	names := map[string]bool{}
+	for _, test := range f.Tests {
+		if test.Name == "" {
+			return fmt.Errorf("test name is required") //nolint:goerr113
+		}
+		if names[test.Name] {
+			return fmt.Errorf("duplicate test name %q", test.Name) //nolint:goerr113
+		}
+		names[test.Name] = true
+
+		if len(test.Steps) == 0 {
+			return fmt.Errorf("test %q: no steps defined", test.Name) //nolint:goerr113
+		}
+		for idx, step := range test.Steps {
+			if err := step.validate(subnetsPerVPC); err != nil {
+				return fmt.Errorf("test %q: step %d: %w", test.Name, idx+1, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateReleaseTestVPC(name string) error {
+	m := releaseTestFileVPCName.FindStringSubmatch(name)
+	if m == nil || m[1] == strings.Repeat("0", len(m[1])) {
+		return fmt.Errorf("invalid VPC name %q: should be vpc-01, vpc-02, ... as created by the setup", name) //nolint:goerr113
+	}
+
+	return nil
+}
+
+func validateReleaseTestSubnets(subnetsPerVPC int, names []string) error {
+	for _, name := range names {
+		m := releaseTestFileSubnetName.FindStringSubmatch(name)
+		if m == nil {
+			return fmt.Errorf("invalid subnet name %q: should be subnet-01, subnet-02, ... as created by the setup", name) //nolint:goerr113
+		}
+		if idx, err := strconv.Atoi(m[1]); err != nil || idx < 1 || idx > subnetsPerVPC {
+			return fmt.Errorf("subnet %q doesn't exist with %d subnet(s) per VPC in setup", name, subnetsPerVPC) //nolint:goerr113
+		}
+	}
+
+	return nil
+}
+
+func validateReleaseTestExternal(name string) error {
+	if name == "" {
+		return fmt.Errorf("external is required") //nolint:goerr113
+	}
+	if strings.HasPrefix(name, "$") && name != ReleaseTestFileBGPExternal && name != ReleaseTestFileStaticExternal {
+		return fmt.Errorf("unknown external placeholder %q: should be %s or %s", name, ReleaseTestFileBGPExternal, ReleaseTestFileStaticExternal) //nolint:goerr113
+	}
+
+	return nil
+}
+
+func (p *ReleaseTestFilePeerings) validate(subnetsPerVPC int) error {
+	for _, peering := range p.VPC {
+		if err := validateReleaseTestVPCPair(subnetsPerVPC, peering.VPC1, peering.VPC2, peering.VPC1Subnets, peering.VPC2Subnets); err != nil {
+			return fmt.Errorf("vpc peering %s--%s: %w", peering.VPC1, peering.VPC2, err)
+		}
+	}
+	for _, peering := range p.External {
+		if err := validateReleaseTestVPCExt(subnetsPerVPC, peering.VPC, peering.External, peering.Subnets); err != nil {
+			return fmt.Errorf("external peering %s--%s: %w", peering.VPC, peering.External, err)
+		}
+	}
+	for _, peering := range p.Gateway {
+		if err := validateReleaseTestVPCPair(subnetsPerVPC, peering.VPC1, peering.VPC2, peering.VPC1Subnets, peering.VPC2Subnets); err != nil {
+			return fmt.Errorf("gateway peering %s--%s: %w", peering.VPC1, peering.VPC2, err)
+		}
+		for _, nat := range []*ReleaseTestFileNAT{peering.VPC1NAT, peering.VPC2NAT} {
+			if err := nat.validate(); err != nil {
+				return fmt.Errorf("gateway peering %s--%s: %w", peering.VPC1, peering.VPC2, err)
+			}
+		}
+	}
+	for _, peering := range p.GatewayExternal {
+		if err := validateReleaseTestVPCExt(subnetsPerVPC, peering.VPC, peering.External, peering.Subnets); err != nil {
+			return fmt.Errorf("gateway external peering %s--%s: %w", peering.VPC, peering.External, err)
+		}
+		if err := peering.NAT.validate(); err != nil {
+			return fmt.Errorf("gateway external peering %s--%s: %w", peering.VPC, peering.External, err)
+		}
+	}
+
+	return nil
+}
+
+func validateReleaseTestVPCPair(subnetsPerVPC int, vpc1, vpc2 string, vpc1Subnets, vpc2Subnets []string) error {
+	for _, name := range []string{vpc1, vpc2} {
+		if err := validateReleaseTestVPC(name); err != nil {
+			return err
+		}
+	}
+	if vpc1 == vpc2 {
+		return fmt.Errorf("VPC can't be peered with itself") //nolint:goerr113
+	}
+	for _, subnets := range [][]string{vpc1Subnets, vpc2Subnets} {
+		if err := validateReleaseTestSubnets(subnetsPerVPC, subnets); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateReleaseTestVPCExt(subnetsPerVPC int, vpc, ext string, subnets []string) error {
+	if err := validateReleaseTestVPC(vpc); err != nil {
+		return err
+	}
+	if err := validateReleaseTestExternal(ext); err != nil {
+		return err
+	}
+
+	return validateReleaseTestSubnets(subnetsPerVPC, subnets)
+}
+
+func (s *ReleaseTestFileStep) validate(subnetsPerVPC int) error {
+	set := 0
+	for _, isSet := range []bool{s.Peerings != nil, s.Fault != nil, s.Connectivity != nil, s.Wait != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of peerings, fault, connectivity or wait should be set") //nolint:goerr113
+	}
+
+	if s.Peerings != nil {
+		if err := s.Peerings.validate(subnetsPerVPC); err != nil {
+			return err
+		}
+	}
+
+	if s.Fault != nil {
+		switch s.Fault.Type {
+		case ReleaseTestFileFaultAgentDown:
+			if s.Fault.Target == "" || strings.Contains(s.Fault.Target, "/") {
+				return fmt.Errorf("agent-down fault target should be a switch name") //nolint:goerr113
+			}
+		case ReleaseTestFileFaultPortDown:
+			if !strings.Contains(s.Fault.Target, "/") {
+				return fmt.Errorf("port-down fault target should be a switch port, e.g. leaf-01/E1/1") //nolint:goerr113
+			}
+		default:
+			return fmt.Errorf("unknown fault type %q", s.Fault.Type) //nolint:goerr113
+		}
+	}
+
+	if s.Connectivity != nil {
+		for idx, o := range s.Connectivity.Overrides {
+			if err := o.validate(subnetsPerVPC); err != nil {
+				return fmt.Errorf("override %d: %w", idx+1, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (n *ReleaseTestFileNAT) validate() error {
+	if n == nil {
+		return nil
+	}
+	if n.Mode != "" {
+		if _, ok := releaseTestFileNATModes[n.Mode]; !ok {
+			return fmt.Errorf("unknown NAT mode %q", n.Mode) //nolint:goerr113
+		}
+	}
+	if len(n.CIDRs) == 0 {
+		return fmt.Errorf("NAT CIDRs are required") //nolint:goerr113
+	}
+	for _, cidr := range n.CIDRs {
+		if _, err := netip.ParsePrefix(cidr); err != nil {
+			return fmt.Errorf("NAT CIDR %q: %w", cidr, err)
+		}
+	}
+
+	return nil
+}
+
+func (o *ReleaseTestFileOverride) validate(subnetsPerVPC int) error {
+	if o.From == "" {
+		return fmt.Errorf("from is required") //nolint:goerr113
+	}
+	if (o.To == "") == (o.ToExternal == "") {
+		return fmt.Errorf("exactly one of to or toExternal should be set") //nolint:goerr113
+	}
+	if err := validateReleaseTestVPC(o.From); err != nil {
+		return fmt.Errorf("from: %w", err)
+	}
+	if o.To != "" {
+		if err := validateReleaseTestVPC(o.To); err != nil {
+			return fmt.Errorf("to: %w", err)
+		}
+	} else if err := validateReleaseTestExternal(o.ToExternal); err != nil {
+		return fmt.Errorf("toExternal: %w", err)
+	}
+	if o.Verdict != "" && o.Verdict != VerdictAllow && o.Verdict != VerdictDeny {
+		return fmt.Errorf("verdict should be %s or %s", VerdictAllow, VerdictDeny) //nolint:goerr113
+	}
+	if o.Protocol != "" {
+		if !slices.Contains([]string{"icmp", "tcp", "udp"}, o.Protocol) {
+			return fmt.Errorf("unknown protocol %q", o.Protocol) //nolint:goerr113
+		}
+		if o.Verdict == "" {
+			return fmt.Errorf("verdict is required for protocol override") //nolint:goerr113
+		}
+		if o.To == "" {
+			return fmt.Errorf("protocol overrides are only supported between VPCs") //nolint:goerr113
+		}
+		if o.Protocol != "icmp" && o.Port == 0 {
+			return fmt.Errorf("port is required for %s", o.Protocol) //nolint:goerr113
+		}
+	}
+	if o.SourcePool != "" {
+		if o.ToExternal == "" {
+			return fmt.Errorf("source pool is only supported for externals") //nolint:goerr113
+		}
+		if _, err := netip.ParsePrefix(o.SourcePool); err != nil {
+			return fmt.Errorf("source pool: %w", err)
+		}
+	}
+	if o.DestinationPool != "" {
+		if o.To == "" {
+			return fmt.Errorf("destination pool is only supported between VPCs") //nolint:goerr113
+		}
+		if _, err := netip.ParsePrefix(o.DestinationPool); err != nil {
+			return fmt.Errorf("destination pool: %w", err)
+		}
+		if o.DestinationSubnet != "" {
+			if err := validateReleaseTestSubnets(subnetsPerVPC, []string{o.DestinationSubnet}); err != nil {
+				return fmt.Errorf("destination subnet: %w", err)
+			}
+		}
+	} else if o.DestinationSubnet != "" || o.DestinationPort != 0 {
+		return fmt.Errorf("destination subnet and port require destination pool") //nolint:goerr113
+	}
+	if o.Verdict == "" && o.SourcePool == "" && o.DestinationPool == "" {
+		return fmt.Errorf("override doesn't change anything") //nolint:goerr113
+	}
+
+	return nil
+}
+
+func (f *ReleaseTestFile) suite() *JUnitTestSuite {
+	suite := &JUnitTestSuite{
+		Name: f.Suite,
+	}
+	for _, test := range f.Tests {
+		suite.TestCases = append(suite.TestCases, JUnitTestCase{
+			Name:      test.Name,
+			F:         test.run,
+			SkipFlags: test.SkipFlags,
+		})
+	}
+	suite.Tests = len(suite.TestCases)
+
+	return suite
+}
+
+func (t ReleaseTestFileTest) run(ctx context.Context, testCtx *VPCPeeringTestCtx, matrix *ConnectivityMatrix) (bool, []RevertFunc, error) {
+	reverts := []RevertFunc{}
+	for idx, step := range t.Steps {
+		stepReverts, err := step.run(ctx, testCtx, matrix)
+		reverts = append(reverts, stepReverts...)
+		if err != nil {
+			// names are validated on load, so only VPCs above the count created by the setup and externals that
+			// couldn't be picked automatically are missing because of the environment, anything else is a failure
+			skip := errors.Is(err, errNoExternals) || errors.Is(err, errNotEnoughVPCs)
+
+			return skip, reverts, fmt.Errorf("step %d: %w", idx+1, err)
+		}
+	}
+
+	return false, reverts, nil
+}
+
+func (s *ReleaseTestFileStep) run(ctx context.Context, testCtx *VPCPeeringTestCtx, matrix *ConnectivityMatrix) ([]RevertFunc, error) {
+	switch {
+	case s.Peerings != nil:
+		return nil, s.Peerings.apply(ctx, testCtx)
+	case s.Fault != nil:
+		return s.Fault.inject(ctx, testCtx)
+	case s.Connectivity != nil:
+		return nil, s.Connectivity.test(ctx, testCtx, matrix)
+	case s.Wait != nil:
+		slog.Debug("Waiting", "duration", s.Wait.Duration)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting: %w", ctx.Err())
+		case <-time.After(s.Wait.Duration):
+		}
+	}
+
+	return nil, nil
+}
+
+// resolveExternal returns the external picked by the release test for the placeholders or checks that the named one
+// exists, only unresolved placeholders are reported as missing externals to skip the test
+func (testCtx *VPCPeeringTestCtx) resolveExternal(ctx context.Context, name string) (string, error) {
+	switch name {
+	case ReleaseTestFileBGPExternal:
+		name = testCtx.extName
+	case ReleaseTestFileStaticExternal:
+		name = testCtx.staticExtName
+	default:
+		ext := &vpcapi.External{}
+		if err := testCtx.kube.Get(ctx, kclient.ObjectKey{Namespace: kmetav1.NamespaceDefault, Name: name}, ext); err != nil {
+			if kapierrors.IsNotFound(err) {
+				return "", fmt.Errorf("external %s not found", name) //nolint:goerr113
+			}
+
+			return "", fmt.Errorf("getting external %s: %w", name, err)
+		}
+	}
+	if name == "" {
+		return "", errNoExternals
+	}
+
+	return name, nil
+}
+
+func getVPCsByName(ctx context.Context, kube kclient.Client) (map[string]*vpcapi.VPC, error) {
+	vpcList := &vpcapi.VPCList{}
+	if err := kube.List(ctx, vpcList); err != nil {
+		return nil, fmt.Errorf("listing VPCs: %w", err)
+	}
+
+	vpcs := map[string]*vpcapi.VPC{}
+	for idx := range vpcList.Items {
+		vpcs[vpcList.Items[idx].Name] = &vpcList.Items[idx]
+	}
+
+	return vpcs, nil
+}
+
+func getVPC(vpcs map[string]*vpcapi.VPC, name string) (*vpcapi.VPC, error) {
+	vpc, ok := vpcs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errNotEnoughVPCs, name)
+	}
+
+	return vpc, nil
+}
+
+// subnetCIDRs returns CIDRs of the named VPC subnets, empty list means all subnets for the gateway peerings
+func subnetCIDRs(vpc *vpcapi.VPC, names []string) ([]string, error) {
+	cidrs := []string{}
+	for _, name := range names {
+		subnet, ok := vpc.Spec.Subnets[name]
+		if !ok || subnet == nil {
+			return nil, fmt.Errorf("subnet %s not found in VPC %s", name, vpc.Name) //nolint:goerr113
+		}
+		cidrs = append(cidrs, subnet.Subnet)
+	}
+
+	return cidrs, nil
+}
+
+func (n *ReleaseTestFileNAT) opts() (NATMode, []string, []gwapi.PeeringNATPortForwardEntry) {
+	if n == nil {
+		return NATModeStatic, nil, nil
+	}
+
+	mode := NATModeStatic
+	if n.Mode != "" {
+		mode = releaseTestFileNATModes[n.Mode]
+	}
+
+	return mode, n.CIDRs, n.PortForward
+}
+
+func (p *ReleaseTestFilePeerings) apply(ctx context.Context, testCtx *VPCPeeringTestCtx) error {
+	vpcs, err := getVPCsByName(ctx, testCtx.kube)
+	if err != nil {
+		return err
+	}
+
+	vpcPeerings := map[string]*vpcapi.VPCPeeringSpec{}
+	for _, peering := range p.VPC {
+		vpc1, err := getVPC(vpcs, peering.VPC1)
+		if err != nil {
+			return err
+		}
+		vpc2, err := getVPC(vpcs, peering.VPC2)
+		if err != nil {
+			return err
+		}
+		if _, err := subnetCIDRs(vpc1, peering.VPC1Subnets); err != nil {
+			return err
+		}
+		if _, err := subnetCIDRs(vpc2, peering.VPC2Subnets); err != nil {
+			return err
+		}
+		appendVpcPeeringSpecByName(vpcPeerings, peering.VPC1, peering.VPC2, peering.VPC1Subnets, peering.VPC2Subnets)
+	}
+
+	extPeerings := map[string]*vpcapi.ExternalPeeringSpec{}
+	for _, peering := range p.External {
+		vpc, err := getVPC(vpcs, peering.VPC)
+		if err != nil {
+			return err
+		}
+		if _, err := subnetCIDRs(vpc, peering.Subnets); err != nil {
+			return err
+		}
+		ext, err := testCtx.resolveExternal(ctx, peering.External)
+		if err != nil {
+			return err
+		}
+		prefixes := peering.Prefixes
+		if len(prefixes) == 0 {
+			prefixes = AllZeroPrefix
+		}
+		appendExtPeeringSpecByName(extPeerings, peering.VPC, ext, peering.Subnets, prefixes)
+	}
+
+	gwPeerings := map[string]*gwapi.PeeringSpec{}
+	for _, peering := range p.Gateway {
+		vpc1, err := getVPC(vpcs, peering.VPC1)
+		if err != nil {
+			return err
+		}
+		vpc2, err := getVPC(vpcs, peering.VPC2)
+		if err != nil {
+			return err
+		}
+
+		opts := &GwPeeringOptions{}
+		if opts.VPC1Subnets, err = subnetCIDRs(vpc1, peering.VPC1Subnets); err != nil {
+			return err
+		}
+		if opts.VPC2Subnets, err = subnetCIDRs(vpc2, peering.VPC2Subnets); err != nil {
+			return err
+		}
+		opts.VPC1NATMode, opts.VPC1NATCIDR, opts.VPC1PortForwardRules = peering.VPC1NAT.opts()
+		opts.VPC2NATMode, opts.VPC2NATCIDR, opts.VPC2PortForwardRules = peering.VPC2NAT.opts()
+
+		if err := appendGwPeeringSpec(gwPeerings, vpc1, vpc2, opts); err != nil {
+			return fmt.Errorf("gateway peering %s--%s: %w", vpc1.Name, vpc2.Name, err)
+		}
+	}
+
+	for _, peering := range p.GatewayExternal {
+		vpc, err := getVPC(vpcs, peering.VPC)
+		if err != nil {
+			return err
+		}
+		ext, err := testCtx.resolveExternal(ctx, peering.External)
+		if err != nil {
+			return err
+		}
+
+		opts := &GwExtPeeringOptions{}
+		if opts.VPCSubnets, err = subnetCIDRs(vpc, peering.Subnets); err != nil {
+			return err
+		}
+		opts.VPCNATMode, opts.VPCNATCIDR, opts.VPCPortForwardRules = peering.NAT.opts()
+
+		if err := appendGwExtPeeringSpecWithNAT(gwPeerings, vpc, ext, opts); err != nil {
+			return fmt.Errorf("gateway external peering %s--%s: %w", vpc.Name, ext, err)
+		}
+	}
+
+	if err := DoSetupPeerings(ctx, testCtx.kube, vpcPeerings, extPeerings, gwPeerings, true); err != nil {
+		return fmt.Errorf("setting up peerings: %w", err)
+	}
+
+	return nil
+}
+
+func (f *ReleaseTestFileFault) inject(ctx context.Context, testCtx *VPCPeeringTestCtx) ([]RevertFunc, error) {
+	swName, port, _ := strings.Cut(f.Target, "/")
+
+	swSSH, err := testCtx.getSSH(ctx, swName)
+	if err != nil {
+		return nil, fmt.Errorf("getting ssh config for switch %s: %w", swName, err)
+	}
+
+	var nosPortName string
+	if f.Type == ReleaseTestFileFaultPortDown {
+		if nosPortName, err = testCtx.getNOSPortName(ctx, swName, port); err != nil {
+			return nil, err
+		}
+	}
+
+	// agent is stopped for the port-down as well, otherwise it'll bring the port back up
+	slog.Debug("Stopping agent", "switch", swName)
+	if err := changeAgentStatus(ctx, swSSH, swName, false); err != nil {
+		return nil, fmt.Errorf("disabling HH agent: %w", err)
+	}
+	reverts := []RevertFunc{
+		func(ctx context.Context) error {
+			return changeAgentStatus(ctx, swSSH, swName, true)
+		},
+	}
+
+	if f.Type == ReleaseTestFileFaultPortDown {
+		if err := changeSwitchPortStatus(ctx, swSSH, swName, nosPortName, false); err != nil {
+			return reverts, fmt.Errorf("setting switch port down: %w", err)
+		}
+		reverts = append(reverts, func(ctx context.Context) error {
+			return changeSwitchPortStatus(ctx, swSSH, swName, nosPortName, true)
+		})
+	}
+
+	return reverts, nil
+}
+
+// getNOSPortName returns the sonic-cli port name for the switch port (wiring name, e.g. E1/1)
+func (testCtx *VPCPeeringTestCtx) getNOSPortName(ctx context.Context, swName, port string) (string, error) {
+	sw := &wiringapi.Switch{}
+	if err := testCtx.kube.Get(ctx, kclient.ObjectKey{Namespace: kmetav1.NamespaceDefault, Name: swName}, sw); err != nil {
+		return "", fmt.Errorf("getting switch %s: %w", swName, err)
+	}
+	profile := &wiringapi.SwitchProfile{}
+	if err := testCtx.kube.Get(ctx, kclient.ObjectKey{Namespace: kmetav1.NamespaceDefault, Name: sw.Spec.Profile}, profile); err != nil {
+		return "", fmt.Errorf("getting switch profile %s: %w", sw.Spec.Profile, err)
+	}
+	portMap, err := profile.Spec.GetAPI2NOSPortsFor(&sw.Spec)
+	if err != nil {
+		return "", fmt.Errorf("getting API2NOS ports for switch %s: %w", swName, err)
+	}
+	nosPortName, ok := portMap[port]
+	if !ok {
+		return "", fmt.Errorf("port %s not found in switch profile %s for switch %s", port, profile.Name, swName) //nolint:goerr113
+	}
+
+	return nosPortName, nil
+}
+
+func (c *ReleaseTestFileConnectivity) test(ctx context.Context, testCtx *VPCPeeringTestCtx, matrix *ConnectivityMatrix) error {
+	if err := matrix.Repopulate(ctx, testCtx.kube); err != nil {
+		return fmt.Errorf("refreshing matrix after peerings: %w", err)
+	}
+
+	if len(c.Overrides) > 0 {
+		vpcs, err := getVPCsByName(ctx, testCtx.kube)
+		if err != nil {
+			return err
+		}
+
+		for idx, o := range c.Overrides {
+			if err := o.apply(ctx, testCtx, vpcs, matrix); err != nil {
+				return fmt.Errorf("applying override %d: %w", idx+1, err)
+			}
+		}
+	}
+
+	return DoVLABTestConnectivityWithMatrix(ctx, testCtx.vlabCfg.WorkDir, testCtx.vlabCfg.CacheDir, testCtx.tcOpts, matrix)
+}
+
+func (o *ReleaseTestFileOverride) apply(ctx context.Context, testCtx *VPCPeeringTestCtx, vpcs map[string]*vpcapi.VPC, matrix *ConnectivityMatrix) error {
+	if _, err := getVPC(vpcs, o.From); err != nil {
+		return err
+	}
+
+	if o.ToExternal != "" {
+		ext, err := testCtx.resolveExternal(ctx, o.ToExternal)
+		if err != nil {
+			return err
+		}
+
+		if o.SourcePool != "" {
+			if err := overlayExternalSNAT(matrix, o.From, ext, o.SourcePool); err != nil {
+				return err
+			}
+		}
+		if o.Verdict != "" {
+			overrideVerdict(matrix, ServerInVPC(o.From), ExternalNamed(ext), o.Verdict)
+		}
+
+		return nil
+	}
+
+	toVPC, err := getVPC(vpcs, o.To)
+	if err != nil {
+		return err
+	}
+
+	if o.DestinationPool != "" {
+		subnetName := o.DestinationSubnet
+		if subnetName == "" {
+			subnetName = "subnet-01"
+		}
+		cidrs, err := subnetCIDRs(toVPC, []string{subnetName})
+		if err != nil {
+			return err
+		}
+
+		if o.DestinationPort != 0 {
+			err = overlayVPCToVPCPortForwardDNAT(matrix, o.From, o.To, cidrs[0], o.DestinationPool, o.DestinationPort)
+		} else {
+			err = overlayVPCToVPCStaticDNAT(matrix, o.From, o.To, cidrs[0], o.DestinationPool)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case o.Protocol != "":
+		setVPCToVPCProtoVerdict(matrix, o.From, o.To, ProtoPort{Protocol: o.Protocol, Port: o.Port}, o.Verdict)
+	case o.Verdict != "":
+		overrideVPCToVPCVerdict(matrix, o.From, o.To, o.Verdict)
+	}
+
+	return nil
+}
+
+// overrideVerdict sets the default check verdict for all matching endpoint pairs
+func overrideVerdict(matrix *ConnectivityMatrix, srcPred, dstPred EndpointPredicate, verdict ConnectivityVerdict) {
+	for _, src := range matrix.AllEndpoints {
+		if !srcPred(src) {
+			continue
+		}
+		for _, dst := range matrix.AllEndpoints {
+			if !dstPred(dst) {
+				continue
+			}
+			existing := matrix.Lookup(src, dst, ProtoPort{})
+			matrix.Add(ConnectivityExpectation{
+				Pair:    EndpointPair{Source: src, Destination: dst},
+				Verdict: verdict,
+				Reason:  overlayReason(existing.Reason),
+				Peering: existing.Peering,
+				NAT:     existing.NAT,
+			})
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const releaseTestFileExample = `
+suite: Example
+setup:
+  subnetsPerVPC: 2
+tests:
+  - name: Gateway static NAT
+    skipFlags:
+      noGateway: true
+    steps:
+      - peerings:
+          gateway:
+            - vpc1: vpc-01
+              vpc2: vpc-02
+              vpc2Subnets: [subnet-01]
+              vpc2NAT:
+                cidrs: [192.168.91.0/24]
+          external:
+            - vpc: vpc-01
+              external: $bgp
+      - fault:
+          type: port-down
+          target: leaf-01/E1/1
+      - wait: 30s
+      - connectivity:
+          overrides:
+            - from: vpc-01
+              to: vpc-02
+              destinationPool: 192.168.91.0/24
+            - from: vpc-01
+              toExternal: $bgp
+              verdict: deny
+  - name: Agent down
+    steps:
+      - fault:
+          type: agent-down
+          target: leaf-01
+      - connectivity: {}
+`
+
+func TestParseReleaseTestFile(t *testing.T) {
+	f, err := ParseReleaseTestFile([]byte(releaseTestFileExample))
+	require.NoError(t, err)
+	require.Equal(t, "Example", f.Suite)
+	require.Equal(t, 2, f.Setup.SubnetsPerVPC)
+	require.Len(t, f.Tests, 2)
+	require.True(t, f.Tests[0].SkipFlags.NoGateway)
+	require.Len(t, f.Tests[0].Steps, 4)
+	require.Equal(t, []string{"192.168.91.0/24"}, f.Tests[0].Steps[0].Peerings.Gateway[0].VPC2NAT.CIDRs)
+	require.Equal(t, 30*time.Second, f.Tests[0].Steps[2].Wait.Duration)
+	require.Equal(t, VerdictDeny, f.Tests[0].Steps[3].Connectivity.Overrides[1].Verdict)
+
+	suite := f.suite()
+	require.Equal(t, "Example", suite.Name)
+	require.Equal(t, 2, suite.Tests)
+	require.Equal(t, "Agent down", suite.TestCases[1].Name)
+	require.NotNil(t, suite.TestCases[1].F)
+	require.True(t, suite.TestCases[0].SkipFlags.NoGateway)
+}
+
+func TestParseReleaseTestFileInvalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"unknown field":    "suite: s\ntests: [{name: t, steps: [{wait: 1s}]}]\nfoo: bar\n",
+		"no suite":         "tests: [{name: t, steps: [{wait: 1s}]}]\n",
+		"no tests":         "suite: s\n",
+		"duplicate test":   "suite: s\ntests: [{name: t, steps: [{wait: 1s}]}, {name: t, steps: [{wait: 1s}]}]\n",
+		"no steps":         "suite: s\ntests: [{name: t}]\n",
+		"two in one step":  "suite: s\ntests: [{name: t, steps: [{wait: 1s, connectivity: {}}]}]\n",
+		"bad fault":        "suite: s\ntests: [{name: t, steps: [{fault: {type: reboot, target: leaf-01}}]}]\n",
+		"port without sw":  "suite: s\ntests: [{name: t, steps: [{fault: {type: port-down, target: leaf-01}}]}]\n",
+		"bad NAT mode":     "suite: s\ntests: [{name: t, steps: [{peerings: {gateway: [{vpc1: vpc-01, vpc2: vpc-02, vpc1NAT: {mode: foo, cidrs: [10.0.0.0/24]}}]}}]}]\n",
+		"bad NAT CIDR":     "suite: s\ntests: [{name: t, steps: [{peerings: {gateway: [{vpc1: vpc-01, vpc2: vpc-02, vpc1NAT: {cidrs: [foo]}}]}}]}]\n",
+		"override no-op":   "suite: s\ntests: [{name: t, steps: [{connectivity: {overrides: [{from: vpc-01, to: vpc-02}]}}]}]\n",
+		"override to both": "suite: s\ntests: [{name: t, steps: [{connectivity: {overrides: [{from: vpc-01, to: vpc-02, toExternal: c, verdict: deny}]}}]}]\n",
+		"proto no port":    "suite: s\ntests: [{name: t, steps: [{connectivity: {overrides: [{from: vpc-01, to: vpc-02, protocol: tcp, verdict: deny}]}}]}]\n",
+		"misspelled VPC":   "suite: s\ntests: [{name: t, steps: [{peerings: {vpc: [{vpc1: vpc-1, vpc2: vpc-02}]}}]}]\n",
+		"zero VPC":         "suite: s\ntests: [{name: t, steps: [{peerings: {external: [{vpc: vpc-00, external: $bgp}]}}]}]\n",
+		"self peering":     "suite: s\ntests: [{name: t, steps: [{peerings: {vpc: [{vpc1: vpc-01, vpc2: vpc-01}]}}]}]\n",
+		"vpc subnet":       "suite: s\ntests: [{name: t, steps: [{peerings: {vpc: [{vpc1: vpc-01, vpc2: vpc-02, vpc2Subnets: [subnet-02]}]}}]}]\n",
+		"subnet name":      "suite: s\nsetup: {subnetsPerVPC: 2}\ntests: [{name: t, steps: [{peerings: {gateway: [{vpc1: vpc-01, vpc2: vpc-02, vpc1Subnets: [subnet1]}]}}]}]\n",
+		"ext subnet":       "suite: s\ntests: [{name: t, steps: [{peerings: {gatewayExternal: [{vpc: vpc-01, external: e, subnets: [subnet-03]}]}}]}]\n",
+		"ext placeholder":  "suite: s\ntests: [{name: t, steps: [{peerings: {external: [{vpc: vpc-01, external: $bpg}]}}]}]\n",
+		"no external":      "suite: s\ntests: [{name: t, steps: [{peerings: {external: [{vpc: vpc-01}]}}]}]\n",
+		"override VPC":     "suite: s\ntests: [{name: t, steps: [{connectivity: {overrides: [{from: vcp-01, toExternal: $bgp, verdict: deny}]}}]}]\n",
+		"override subnet":  "suite: s\ntests: [{name: t, steps: [{connectivity: {overrides: [{from: vpc-01, to: vpc-02, destinationPool: 10.0.0.0/24, destinationSubnet: subnet-02}]}}]}]\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseReleaseTestFile([]byte(data))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLoadReleaseTestFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte(releaseTestFileExample), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.yml"), []byte("suite: B\ntests: [{name: t, steps: [{wait: 1s}]}]\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a test"), 0o644))
+
+	files, err := LoadReleaseTestFiles([]string{dir})
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.Equal(t, "Example", files[0].Suite)
+	require.Equal(t, "B", files[1].Suite)
+
+	_, err = LoadReleaseTestFiles([]string{filepath.Join(dir, "missing.yaml")})
+	require.Error(t, err)
+}
@@ -0,0 +1,474 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	// duration regressions smaller than this are ignored to avoid noise from the short tests
+	releaseTestMinDurationRegression = 10 * time.Second
+	// minimum number of previous runs to calculate baseline from
+	releaseTestMinBaselineRuns = 2
+)
+
+// IPerfStats collects iperf3 speeds observed during a single test, safe for concurrent use
+type IPerfStats struct {
+	mu        sync.Mutex
+	count     int
+	minSpeed  float64
+	sumSpeed  float64
+	minMargin float64
+	hasMargin bool
+}
+
+// Record records sent and received speeds (bps) for one direction of the iperf3 run with the min speed (Mbps) used
+// to check it, no-op on nil receiver
+func (s *IPerfStats) Record(sentBps, rcvdBps, minSpeed float64) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	speed := min(sentBps, rcvdBps) / 1_000_000
+	if s.count == 0 || speed < s.minSpeed {
+		s.minSpeed = speed
+	}
+	s.sumSpeed += speed
+	s.count++
+
+	if minSpeed > 0 {
+		margin := speed/minSpeed - 1
+		if !s.hasMargin || margin < s.minMargin {
+			s.minMargin = margin
+		}
+		s.hasMargin = true
+	}
+}
+
+func (s *IPerfStats) Reset() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.count, s.minSpeed, s.sumSpeed, s.minMargin, s.hasMargin = 0, 0, 0, 0, false
+}
+
+// summary returns min and avg speed in Mbps and the smallest margin over the min speed, all nil if nothing recorded
+func (s *IPerfStats) summary() (*float64, *float64, *float64) {
+	if s == nil {
+		return nil, nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count == 0 {
+		return nil, nil, nil
+	}
+
+	minSpeed, avgSpeed := s.minSpeed, s.sumSpeed/float64(s.count)
+	if !s.hasMargin {
+		return &minSpeed, &avgSpeed, nil
+	}
+	minMargin := s.minMargin
+
+	return &minSpeed, &avgSpeed, &minMargin
+}
+
+type ReleaseTestResult string
+
+const (
+	ReleaseTestResultPass ReleaseTestResult = "pass"
+	ReleaseTestResultFail ReleaseTestResult = "fail"
+	ReleaseTestResultSkip ReleaseTestResult = "skip"
+)
+
+// ReleaseTestRun is a record of a single release test run stored in the history dir, each shard of the sharded run
+// stores its own record with the same run ID
+type ReleaseTestRun struct {
+	Start          time.Time              `json:"start"`
+	Duration       float64                `json:"duration"` // seconds
+	RunID          string                 `json:"runID,omitempty"`
+	Shard          string                 `json:"shard,omitempty"`
+	Extended       bool                   `json:"extended,omitempty"`
+	VPCMode        string                 `json:"vpcMode,omitempty"`
+	IPerfsMinSpeed float64                `json:"iperfsMinSpeed,omitempty"` // Mbps
+	Tests          []ReleaseTestRunResult `json:"tests"`
+}
+
+type ReleaseTestRunResult struct {
+	Suite    string            `json:"suite"`
+	Name     string            `json:"name"`
+	Result   ReleaseTestResult `json:"result"`
+	Message  string            `json:"message,omitempty"`
+	Duration float64           `json:"duration"` // seconds
+
+	IPerfMinSpeed *float64 `json:"iperfMinSpeed,omitempty"` // Mbps
+	IPerfAvgSpeed *float64 `json:"iperfAvgSpeed,omitempty"` // Mbps
+	IPerfMargin   *float64 `json:"iperfMargin,omitempty"`   // smallest relative margin over the min speed, 0.1 = 10%
+}
+
+func newReleaseTestRun(start time.Time, rtOpts ReleaseTestOpts, results []JUnitTestSuite) *ReleaseTestRun {
+	run := &ReleaseTestRun{
+		Start:          start.UTC(),
+		Duration:       time.Since(start).Round(time.Second).Seconds(),
+		RunID:          rtOpts.RunID,
+		Extended:       rtOpts.Extended,
+		VPCMode:        string(rtOpts.VPCMode),
+		IPerfsMinSpeed: rtOpts.IPerfsMinSpeed,
+	}
+	if rtOpts.Shard.Enabled() {
+		run.Shard = rtOpts.Shard.String()
+	}
+
+	for _, suite := range results {
+		for _, test := range suite.TestCases {
+			// tests from other shards are recorded by their own runs
+			if isShardSkipped(&test) {
+				continue
+			}
+
+			res := ReleaseTestRunResult{
+				Suite:         suite.Name,
+				Name:          test.Name,
+				Result:        ReleaseTestResultPass,
+				Duration:      test.Time,
+				IPerfMinSpeed: test.IPerfMinSpeed,
+				IPerfAvgSpeed: test.IPerfAvgSpeed,
+				IPerfMargin:   test.IPerfMargin,
+			}
+			if test.Skipped != nil {
+				res.Result = ReleaseTestResultSkip
+				res.Message = test.Skipped.Message
+			} else if test.Failure != nil {
+				res.Result = ReleaseTestResultFail
+				res.Message = test.Failure.Message
+			}
+
+			run.Tests = append(run.Tests, res)
+		}
+	}
+
+	return run
+}
+
+func saveReleaseTestRun(dir string, run *ReleaseTestRun) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating history dir: %w", err)
+	}
+
+	name := run.Start.Format("20060102-150405")
+	if run.Shard != "" {
+		name += "-shard-" + strings.ReplaceAll(run.Shard, "/", "-of-")
+	}
+
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling run: %w", err)
+	}
+
+	path := filepath.Join(dir, name+".json")
+	if err := os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec
+		return fmt.Errorf("writing run: %w", err)
+	}
+
+	slog.Info("Release test run saved to history", "path", path)
+
+	return nil
+}
+
+// LoadReleaseTestRuns loads all release test runs from the history dir sorted by the start time, records of the
+// shards of the same run are combined into a single run
+func LoadReleaseTestRuns(dir string) ([]*ReleaseTestRun, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading history dir: %w", err)
+	}
+
+	runs := []*ReleaseTestRun{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading run %q: %w", entry.Name(), err)
+		}
+
+		run := &ReleaseTestRun{}
+		if err := json.Unmarshal(data, run); err != nil {
+			return nil, fmt.Errorf("parsing run %q: %w", entry.Name(), err)
+		}
+
+		runs = append(runs, run)
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].Start.Before(runs[j].Start)
+	})
+
+	return combineReleaseTestShards(runs), nil
+}
+
+// combineReleaseTestShards merges records with the same run ID into a single run starting with the first shard and
+// lasting until the last one is done, so runs are counted and compared as a whole, runs should be sorted by the start
+func combineReleaseTestShards(runs []*ReleaseTestRun) []*ReleaseTestRun {
+	res := []*ReleaseTestRun{}
+	byID := map[string]*ReleaseTestRun{}
+	for _, run := range runs {
+		if run.RunID == "" {
+			res = append(res, run)
+
+			continue
+		}
+
+		combined, ok := byID[run.RunID]
+		if !ok {
+			combined = &ReleaseTestRun{
+				Start:          run.Start,
+				RunID:          run.RunID,
+				Extended:       run.Extended,
+				VPCMode:        run.VPCMode,
+				IPerfsMinSpeed: run.IPerfsMinSpeed,
+			}
+			byID[run.RunID] = combined
+			res = append(res, combined)
+		}
+
+		combined.Duration = max(combined.Duration, run.Start.Sub(combined.Start).Seconds()+run.Duration)
+		combined.Tests = append(combined.Tests, run.Tests...)
+	}
+
+	return res
+}
+
+type ReleaseTestReportOpts struct {
+	HistoryDir        string
+	Runs              int     // only use last N runs, all if 0
+	DurationThreshold float64 // relative duration increase over baseline to warn about, e.g. 0.25
+	SpeedThreshold    float64 // relative iperf3 speed decrease over baseline to warn about, e.g. 0.1
+	SpeedMargin       float64 // relative iperf3 speed margin over the min speed to warn about, e.g. 0.1
+}
+
+type releaseTestStats struct {
+	Suite    string
+	Name     string
+	History  string // oldest to newest, + pass, x fail, - skip, . not run
+	Passed   int
+	Failed   int
+	Flaky    bool
+	Duration float64 // last passed run, seconds
+	Speed    *float64
+	Margin   *float64
+
+	BaselineDuration float64
+	BaselineSpeed    *float64
+
+	Warnings []string
+}
+
+func (s *releaseTestStats) passRate() string {
+	if s.Passed+s.Failed == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.0f%%", 100*float64(s.Passed)/float64(s.Passed+s.Failed))
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	values = slices.Clone(values)
+	slices.Sort(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+
+	return values[mid]
+}
+
+func analyzeReleaseTestRuns(runs []*ReleaseTestRun, opts ReleaseTestReportOpts) []*releaseTestStats {
+	stats := []*releaseTestStats{}
+	byKey := map[string]*releaseTestStats{}
+	results := map[string][]*ReleaseTestRunResult{}
+
+	for _, run := range runs {
+		for idx := range run.Tests {
+			test := &run.Tests[idx]
+			key := test.Suite + "/" + test.Name
+			if _, ok := byKey[key]; !ok {
+				byKey[key] = &releaseTestStats{Suite: test.Suite, Name: test.Name}
+				stats = append(stats, byKey[key])
+			}
+		}
+	}
+
+	for _, run := range runs {
+		runTests := map[string]*ReleaseTestRunResult{}
+		for idx := range run.Tests {
+			runTests[run.Tests[idx].Suite+"/"+run.Tests[idx].Name] = &run.Tests[idx]
+		}
+
+		for _, st := range stats {
+			key := st.Suite + "/" + st.Name
+			test := runTests[key]
+
+			switch {
+			case test == nil:
+				st.History += "."
+			case test.Result == ReleaseTestResultPass:
+				st.History += "+"
+				st.Passed++
+				results[key] = append(results[key], test)
+			case test.Result == ReleaseTestResultFail:
+				st.History += "x"
+				st.Failed++
+			default:
+				st.History += "-"
+			}
+		}
+	}
+
+	for _, st := range stats {
+		// flaky if the result flips from pass to fail and back (or vice versa), a single flip is a regression or a fix
+		flips, prev := 0, rune(0)
+		for _, r := range st.History {
+			if r != '+' && r != 'x' {
+				continue
+			}
+			if prev != 0 && r != prev {
+				flips++
+			}
+			prev = r
+		}
+		st.Flaky = flips >= 2
+		if st.Flaky {
+			st.Warnings = append(st.Warnings, fmt.Sprintf("flaky: pass rate %s", st.passRate()))
+		}
+		if prev == 'x' {
+			st.Warnings = append(st.Warnings, "failed in the last run")
+		}
+
+		passed := results[st.Suite+"/"+st.Name]
+		if len(passed) == 0 {
+			continue
+		}
+
+		last, prevPassed := passed[len(passed)-1], passed[:len(passed)-1]
+		st.Duration = last.Duration
+		st.Speed = last.IPerfMinSpeed
+		st.Margin = last.IPerfMargin
+
+		if st.Margin != nil && *st.Margin < opts.SpeedMargin {
+			st.Warnings = append(st.Warnings, fmt.Sprintf("iperf3 speed only %.0f%% over the min speed", 100**st.Margin))
+		}
+
+		if len(prevPassed) < releaseTestMinBaselineRuns {
+			continue
+		}
+
+		durations, speeds := []float64{}, []float64{}
+		for _, test := range prevPassed {
+			durations = append(durations, test.Duration)
+			if test.IPerfMinSpeed != nil {
+				speeds = append(speeds, *test.IPerfMinSpeed)
+			}
+		}
+
+		st.BaselineDuration = median(durations)
+		if opts.DurationThreshold > 0 && st.Duration > st.BaselineDuration*(1+opts.DurationThreshold) &&
+			time.Duration((st.Duration-st.BaselineDuration)*float64(time.Second)) > releaseTestMinDurationRegression {
+			st.Warnings = append(st.Warnings, fmt.Sprintf("duration %s regressed from baseline %s",
+				time.Duration(st.Duration*float64(time.Second)).Round(time.Second),
+				time.Duration(st.BaselineDuration*float64(time.Second)).Round(time.Second)))
+		}
+
+		if len(speeds) >= releaseTestMinBaselineRuns {
+			baseline := median(speeds)
+			st.BaselineSpeed = &baseline
+			if opts.SpeedThreshold > 0 && st.Speed != nil && *st.Speed < baseline*(1-opts.SpeedThreshold) {
+				st.Warnings = append(st.Warnings, fmt.Sprintf("iperf3 speed %.0f Mbps regressed from baseline %.0f Mbps", *st.Speed, baseline))
+			}
+		}
+	}
+
+	return stats
+}
+
+// ReleaseTestReport prints pass rates, flaky tests and duration/iperf3 speed regressions based on the history
+func ReleaseTestReport(w io.Writer, opts ReleaseTestReportOpts) error {
+	runs, err := LoadReleaseTestRuns(opts.HistoryDir)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		return fmt.Errorf("no release test runs found in %q", opts.HistoryDir) //nolint:goerr113
+	}
+	if opts.Runs > 0 && len(runs) > opts.Runs {
+		runs = runs[len(runs)-opts.Runs:]
+	}
+
+	slog.Info("Release test history", "runs", len(runs), "from", runs[0].Start.Format(time.DateTime), "to", runs[len(runs)-1].Start.Format(time.DateTime))
+
+	stats := analyzeReleaseTestRuns(runs, opts)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SUITE\tTEST\tPASS RATE\tHISTORY\tDURATION\tBASELINE\tIPERF3 MBPS\tBASELINE")
+	for _, st := range stats {
+		duration, baselineDuration, speed, baselineSpeed := "-", "-", "-", "-"
+		if st.Duration > 0 {
+			duration = time.Duration(st.Duration * float64(time.Second)).Round(time.Second).String()
+		}
+		if st.BaselineDuration > 0 {
+			baselineDuration = time.Duration(st.BaselineDuration * float64(time.Second)).Round(time.Second).String()
+		}
+		if st.Speed != nil {
+			speed = fmt.Sprintf("%.0f", *st.Speed)
+		}
+		if st.BaselineSpeed != nil {
+			baselineSpeed = fmt.Sprintf("%.0f", *st.BaselineSpeed)
+		}
+
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", st.Suite, st.Name, st.passRate(), st.History,
+			duration, baselineDuration, speed, baselineSpeed)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flushing report: %w", err)
+	}
+
+	warnings := 0
+	for _, st := range stats {
+		for _, warning := range st.Warnings {
+			slog.Warn(warning, "suite", st.Suite, "test", st.Name)
+			warnings++
+		}
+	}
+	if warnings == 0 {
+		slog.Info("No flaky tests or regressions found")
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPerfStats(t *testing.T) {
+	var nilStats *IPerfStats
+	nilStats.Record(1, 1, 1)
+	minSpeed, _, _ := nilStats.summary()
+	require.Nil(t, minSpeed)
+
+	stats := &IPerfStats{}
+	stats.Record(9_000_000_000, 8_800_000_000, 8200)
+	stats.Record(9_500_000_000, 9_400_000_000, 8200)
+	minSpeed, avgSpeed, margin := stats.summary()
+	require.InDelta(t, 8800, *minSpeed, 0.001)
+	require.InDelta(t, 9100, *avgSpeed, 0.001)
+	require.InDelta(t, 8800.0/8200-1, *margin, 0.0001)
+
+	stats.Reset()
+	minSpeed, _, _ = stats.summary()
+	require.Nil(t, minSpeed)
+
+	stats.Record(5_000_000_000, 5_000_000_000, 0)
+	_, _, margin = stats.summary()
+	require.Nil(t, margin)
+}
+
+func makeHistoryRun(start time.Time, results map[string]ReleaseTestRunResult) *ReleaseTestRun {
+	run := &ReleaseTestRun{Start: start}
+	for _, name := range []string{"t1", "t2", "t3"} {
+		if res, ok := results[name]; ok {
+			res.Suite, res.Name = "S", name
+			run.Tests = append(run.Tests, res)
+		}
+	}
+
+	return run
+}
+
+func TestAnalyzeReleaseTestRuns(t *testing.T) {
+	speed := func(v float64) *float64 { return &v }
+	pass := func(duration, iperf float64) ReleaseTestRunResult {
+		return ReleaseTestRunResult{Result: ReleaseTestResultPass, Duration: duration, IPerfMinSpeed: speed(iperf), IPerfMargin: speed(iperf/8200 - 1)}
+	}
+	fail := ReleaseTestRunResult{Result: ReleaseTestResultFail}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	runs := []*ReleaseTestRun{
+		makeHistoryRun(start, map[string]ReleaseTestRunResult{"t1": pass(60, 9500), "t2": pass(30, 9500), "t3": pass(30, 9500)}),
+		makeHistoryRun(start.Add(time.Hour), map[string]ReleaseTestRunResult{"t1": fail, "t2": pass(32, 9400), "t3": pass(30, 9500)}),
+		makeHistoryRun(start.Add(2*time.Hour), map[string]ReleaseTestRunResult{"t1": pass(60, 9500), "t2": pass(31, 9450)}),
+		makeHistoryRun(start.Add(3*time.Hour), map[string]ReleaseTestRunResult{"t1": pass(90, 9500), "t2": pass(30, 8300), "t3": fail}),
+	}
+
+	stats := analyzeReleaseTestRuns(runs, ReleaseTestReportOpts{DurationThreshold: 0.25, SpeedThreshold: 0.1, SpeedMargin: 0.05})
+	require.Len(t, stats, 3)
+
+	t1, t2, t3 := stats[0], stats[1], stats[2]
+
+	require.Equal(t, "+x++", t1.History)
+	require.Equal(t, "75%", t1.passRate())
+	require.True(t, t1.Flaky)
+	require.InDelta(t, 60, t1.BaselineDuration, 0.001)
+	require.Len(t, t1.Warnings, 2) // flaky and duration
+
+	require.Equal(t, "++++", t2.History)
+	require.False(t, t2.Flaky)
+	require.InDelta(t, 9450, *t2.BaselineSpeed, 0.001)
+	require.Len(t, t2.Warnings, 2) // margin and speed
+
+	require.Equal(t, "++.x", t3.History)
+	require.False(t, t3.Flaky)
+	require.Equal(t, []string{"failed in the last run"}, t3.Warnings)
+}
+
+func TestReleaseTestHistory(t *testing.T) {
+	dir := t.TempDir()
+
+	shardSkipped := &Skipped{Message: "Not in shard", Type: SkippedTypeShard}
+	shardResults := [][]JUnitTestSuite{
+		{{Name: "S", TestCases: []JUnitTestCase{
+			{Name: "t1", Time: 10},
+			{Name: "t2", Failure: &Failure{Message: "boom"}},
+			{Name: "t3", Skipped: shardSkipped},
+		}}},
+		{{Name: "S", TestCases: []JUnitTestCase{
+			{Name: "t1", Skipped: shardSkipped},
+			{Name: "t2", Skipped: shardSkipped},
+			{Name: "t3", Time: 20},
+		}}},
+	}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for idx := range 3 {
+		for shardIdx, results := range shardResults {
+			rtOpts := ReleaseTestOpts{Shard: ReleaseTestShard{Index: shardIdx + 1, Total: 2}, RunID: fmt.Sprintf("run-%d", idx)}
+			// shards of the same run are started at different times
+			runStart := start.Add(time.Duration(idx)*time.Hour + time.Duration(shardIdx)*time.Minute)
+			run := newReleaseTestRun(runStart, rtOpts, results)
+			require.Equal(t, rtOpts.Shard.String(), run.Shard)
+			require.NoError(t, saveReleaseTestRun(dir, run))
+		}
+	}
+
+	runs, err := LoadReleaseTestRuns(dir)
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	require.True(t, runs[0].Start.Before(runs[2].Start))
+	require.Equal(t, "run-0", runs[0].RunID)
+	require.Len(t, runs[0].Tests, 3)
+	require.Equal(t, ReleaseTestResultFail, runs[0].Tests[1].Result)
+	require.Equal(t, "boom", runs[0].Tests[1].Message)
+	require.Equal(t, "t3", runs[0].Tests[2].Name)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, ReleaseTestReport(out, ReleaseTestReportOpts{HistoryDir: dir, Runs: 2}))
+	require.Contains(t, out.String(), "t2")
+	require.Contains(t, out.String(), "xx")
+	require.NotContains(t, out.String(), ".")
+
+	require.Error(t, ReleaseTestReport(out, ReleaseTestReportOpts{HistoryDir: t.TempDir()}))
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SkippedTypeShard marks tests skipped because they belong to another shard, so they could be replaced by the actual
+// results when merging reports
+const SkippedTypeShard = "shard"
+
+// ReleaseTestShard selects a subset of the release tests to run, Index is 1-based
+type ReleaseTestShard struct {
+	Index int
+	Total int
+}
+
+// ParseReleaseTestShard parses shard in the i/N format, empty string means no sharding
+func ParseReleaseTestShard(in string) (ReleaseTestShard, error) {
+	if in == "" {
+		return ReleaseTestShard{}, nil
+	}
+
+	idxStr, totalStr, ok := strings.Cut(in, "/")
+	if !ok {
+		return ReleaseTestShard{}, fmt.Errorf("invalid shard %q, expected i/N", in) //nolint:goerr113
+	}
+	idx, err := strconv.Atoi(idxStr)
+	if err != nil {
+		return ReleaseTestShard{}, fmt.Errorf("invalid shard index %q: %w", idxStr, err)
+	}
+	total, err := strconv.Atoi(totalStr)
+	if err != nil {
+		return ReleaseTestShard{}, fmt.Errorf("invalid shard total %q: %w", totalStr, err)
+	}
+	if total < 1 || idx < 1 || idx > total {
+		return ReleaseTestShard{}, fmt.Errorf("invalid shard %q, expected 1 <= i <= N", in) //nolint:goerr113
+	}
+
+	return ReleaseTestShard{Index: idx, Total: total}, nil
+}
+
+func (s ReleaseTestShard) Enabled() bool {
+	return s.Total > 1
+}
+
+func (s ReleaseTestShard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Total)
+}
+
+// shardSelection marks tests that don't belong to the shard as skipped. Tests are distributed round-robin in the
+// order of suites and tests, so all shards running the same hhfab and test files get disjoint and balanced subsets.
+func shardSelection(shard ReleaseTestShard, suites []*JUnitTestSuite) {
+	if !shard.Enabled() {
+		return
+	}
+
+	idx := 0
+	for _, suite := range suites {
+		for i := range suite.TestCases {
+			if idx%shard.Total != shard.Index-1 {
+				suite.TestCases[i].Skipped = &Skipped{
+					Message: "Not in shard " + shard.String(),
+					Type:    SkippedTypeShard,
+				}
+				suite.Skipped++
+			}
+			idx++
+		}
+	}
+}
+
+func isShardSkipped(test *JUnitTestCase) bool {
+	return test.Skipped != nil && test.Skipped.Type == SkippedTypeShard
+}
+
+// mergeRank is used to pick the test result when merging shards: skipped by shard < skipped < passed < failed
+func mergeRank(test *JUnitTestCase) int {
+	switch {
+	case isShardSkipped(test):
+		return 0
+	case test.Skipped != nil:
+		return 1
+	case test.Failure == nil:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// MergeJUnitReports combines JUnit reports produced by the release test shards into a single report. Suites and
+// tests are matched by name, the actual result is preferred over the skip caused by the sharding.
+func MergeJUnitReports(inputs []string, output string) error {
+	if len(inputs) == 0 {
+		return fmt.Errorf("no reports to merge") //nolint:goerr113
+	}
+
+	merged := &JUnitReport{}
+	suiteIdx := map[string]int{}
+	testIdx := map[string]map[string]int{}
+	for _, input := range inputs {
+		data, err := os.ReadFile(input)
+		if err != nil {
+			return fmt.Errorf("reading report %q: %w", input, err)
+		}
+
+		report := &JUnitReport{}
+		if err := xml.Unmarshal(data, report); err != nil {
+			return fmt.Errorf("parsing report %q: %w", input, err)
+		}
+
+		for _, suite := range report.Suites {
+			sIdx, ok := suiteIdx[suite.Name]
+			if !ok {
+				sIdx = len(merged.Suites)
+				suiteIdx[suite.Name] = sIdx
+				testIdx[suite.Name] = map[string]int{}
+				merged.Suites = append(merged.Suites, JUnitTestSuite{Name: suite.Name})
+			}
+			mergedSuite := &merged.Suites[sIdx]
+			mergedSuite.Time += suite.Time
+
+			for _, test := range suite.TestCases {
+				tIdx, ok := testIdx[suite.Name][test.Name]
+				if !ok {
+					testIdx[suite.Name][test.Name] = len(mergedSuite.TestCases)
+					mergedSuite.TestCases = append(mergedSuite.TestCases, test)
+
+					continue
+				}
+				if mergeRank(&test) > mergeRank(&mergedSuite.TestCases[tIdx]) {
+					mergedSuite.TestCases[tIdx] = test
+				}
+			}
+		}
+	}
+
+	for sIdx := range merged.Suites {
+		suite := &merged.Suites[sIdx]
+		suite.Tests = len(suite.TestCases)
+		for _, test := range suite.TestCases {
+			if isShardSkipped(&test) {
+				slog.Warn("Test not run by any of the shards", "suite", suite.Name, "test", test.Name)
+			}
+			if test.Skipped != nil {
+				suite.Skipped++
+			} else if test.Failure != nil {
+				suite.Failures++
+			}
+		}
+		slog.Info("Merged suite", "suite", suite.Name, "tests", suite.Tests, "failures", suite.Failures, "skipped", suite.Skipped)
+	}
+
+	out, err := xml.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling XML: %w", err)
+	}
+	if err := os.WriteFile(output, out, 0o600); err != nil {
+		return fmt.Errorf("writing XML file: %w", err)
+	}
+
+	return nil
+}
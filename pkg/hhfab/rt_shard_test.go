@@ -0,0 +1,123 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReleaseTestShard(t *testing.T) {
+	shard, err := ParseReleaseTestShard("")
+	require.NoError(t, err)
+	require.False(t, shard.Enabled())
+
+	shard, err = ParseReleaseTestShard("2/3")
+	require.NoError(t, err)
+	require.Equal(t, ReleaseTestShard{Index: 2, Total: 3}, shard)
+	require.True(t, shard.Enabled())
+
+	for _, in := range []string{"2", "0/3", "4/3", "1/0", "a/2", "1/b"} {
+		_, err := ParseReleaseTestShard(in)
+		require.Error(t, err, in)
+	}
+}
+
+func makeShardTestSuites() []*JUnitTestSuite {
+	return []*JUnitTestSuite{
+		{Name: "A", Tests: 3, TestCases: []JUnitTestCase{{Name: "a1"}, {Name: "a2"}, {Name: "a3"}}},
+		{Name: "B", Tests: 2, TestCases: []JUnitTestCase{{Name: "b1"}, {Name: "b2"}}},
+	}
+}
+
+func TestShardSelection(t *testing.T) {
+	const total = 3
+
+	runBy := map[string]int{}
+	for idx := 1; idx <= total; idx++ {
+		suites := makeShardTestSuites()
+		shardSelection(ReleaseTestShard{Index: idx, Total: total}, suites)
+		for _, suite := range suites {
+			for _, test := range suite.TestCases {
+				if test.Skipped == nil {
+					_, ok := runBy[test.Name]
+					require.False(t, ok, "test %s selected by multiple shards", test.Name)
+					runBy[test.Name] = idx
+				}
+			}
+		}
+	}
+	require.Equal(t, map[string]int{"a1": 1, "a2": 2, "a3": 3, "b1": 1, "b2": 2}, runBy)
+
+	suites := makeShardTestSuites()
+	shardSelection(ReleaseTestShard{Index: 3, Total: total}, suites)
+	require.Equal(t, 2, suites[0].Skipped)
+	require.Equal(t, 2, suites[1].Skipped)
+}
+
+func TestMergeJUnitReports(t *testing.T) {
+	dir := t.TempDir()
+
+	inputs := []string{}
+	for idx := 1; idx <= 2; idx++ {
+		suites := makeShardTestSuites()
+		shardSelection(ReleaseTestShard{Index: idx, Total: 2}, suites)
+		suites[0].Time = 10
+		if idx == 2 {
+			suites[0].TestCases[1].Failure = &Failure{Message: "boom"}
+			suites[1].TestCases[0].Skipped = &Skipped{Message: "Regex selection"}
+		}
+
+		report := JUnitReport{}
+		for _, suite := range suites {
+			report.Suites = append(report.Suites, *suite)
+		}
+		data, err := xml.Marshal(report)
+		require.NoError(t, err)
+
+		path := filepath.Join(dir, fmt.Sprintf("shard-%d.xml", idx))
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		inputs = append(inputs, path)
+	}
+
+	output := filepath.Join(dir, "merged.xml")
+	require.NoError(t, MergeJUnitReports(inputs, output))
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	merged := JUnitReport{}
+	require.NoError(t, xml.Unmarshal(data, &merged))
+
+	require.Len(t, merged.Suites, 2)
+	a, b := merged.Suites[0], merged.Suites[1]
+	require.Equal(t, "A", a.Name)
+	require.Equal(t, 3, a.Tests)
+	require.Equal(t, 1, a.Failures)
+	require.Equal(t, 0, a.Skipped)
+	require.InDelta(t, 20.0, a.Time, 0.001)
+	require.Equal(t, "boom", a.TestCases[1].Failure.Message)
+
+	require.Equal(t, 2, b.Tests)
+	require.Equal(t, 1, b.Skipped)
+	require.Equal(t, "Regex selection", b.TestCases[0].Skipped.Message)
+
+	require.Error(t, MergeJUnitReports(nil, output))
+}
+
+func TestIsShardSkipped(t *testing.T) {
+	require.False(t, isShardSkipped(&JUnitTestCase{}))
+	require.False(t, isShardSkipped(&JUnitTestCase{Skipped: &Skipped{Message: "Not in shard 1/2"}}))
+	require.True(t, isShardSkipped(&JUnitTestCase{Skipped: &Skipped{Message: "anything", Type: SkippedTypeShard}}))
+
+	data, err := xml.Marshal(JUnitTestCase{Name: "t", Skipped: &Skipped{Message: "Not in shard 1/2", Type: SkippedTypeShard}})
+	require.NoError(t, err)
+	test := JUnitTestCase{}
+	require.NoError(t, xml.Unmarshal(data, &test))
+	require.True(t, isShardSkipped(&test))
+}
@@ -1095,10 +1095,15 @@ type DHCPLeaseInfo struct {
 func parseDHCPLease(output string) (*DHCPLeaseInfo, error) {
 	info := &DHCPLeaseInfo{}
 
+	// lifetimes follow the address they belong to, the ones of IPv6 addresses (SLAAC or DHCPv6) are skipped
+	afterInet6 := false
 	lines := strings.Split(output, "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
-		if !strings.Contains(line, "valid_lft") {
+		if strings.HasPrefix(line, "inet") {
+			afterInet6 = strings.HasPrefix(line, "inet6 ")
+		}
+		if afterInet6 || !strings.Contains(line, "valid_lft") {
 			continue
 		}
 
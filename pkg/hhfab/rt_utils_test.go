@@ -0,0 +1,33 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDHCPLease(t *testing.T) {
+	out := `3: enp2s1: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 9036 qdisc fq_codel state UP group default qlen 1000
+    link/ether 0c:20:12:fe:02:01 brd ff:ff:ff:ff:ff:ff
+    inet6 fd00:0:0:1::10/128 scope global dynamic noprefixroute
+       valid_lft 7200sec preferred_lft 4500sec
+    inet 10.0.1.10/24 metric 1024 brd 10.0.1.255 scope global dynamic enp2s1
+       valid_lft 3590sec preferred_lft 3590sec
+`
+
+	info, err := parseDHCPLease(out)
+	require.NoError(t, err)
+	require.True(t, info.HasLease)
+	require.Equal(t, 3590, info.ValidLifetime)
+	require.Equal(t, 3590, info.PreferredLifetime)
+
+	info, err = parseDHCPLease("    inet6 fd00::10/64 scope global\n       valid_lft forever preferred_lft forever\n")
+	require.NoError(t, err)
+	require.False(t, info.HasLease)
+
+	_, err = parseDHCPLease("    inet 10.0.1.10/24 scope global\n       valid_lft forever preferred_lft forever\n")
+	require.Error(t, err)
+}
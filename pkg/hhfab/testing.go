@@ -723,6 +723,10 @@ func (c *Config) SetupVPCs(ctx context.Context, vlab *VLAB, opts SetupVPCsOpts)
 	nextVLAN, stopVLAN := iter.Pull(VLANsFrom(vlanNS.Spec.Ranges...))
 	defer stopVLAN()
 
+	// TODO: VPC subnets are only allocated from the IPv4Namespace as the fabric API has no IPv6 namespace and validates
+	// VPC subnets against the IPv4 one, allocate IPv6 (dual-stack) subnets as well once the fabric API supports them;
+	// endpoint discovery and the connectivity matrix already handle IPv6 and dual-stack servers (see crossAddrFamily).
+	// Tracked as a follow-up to the fabric API.
 	ipNS := &vpcapi.IPv4Namespace{}
 	if err := kube.Get(ctx, client.ObjectKey{Name: opts.IPv4Namespace, Namespace: metav1.NamespaceDefault}, ipNS); err != nil {
 		return nil, nil, fmt.Errorf("getting IPv4 namespace %s: %w", opts.IPv4Namespace, err)
@@ -2284,6 +2288,7 @@ type TestConnectivityOpts struct {
 	Sources           []string
 	Destinations      []string
 	RequireAllServers bool
+	IPerfsStats       *IPerfStats // optional, collects iperf3 speeds (e.g. for release test history)
 }
 
 func (c *Config) prepareConnectivityTest(ctx context.Context, vlab *VLAB, opts *TestConnectivityOpts) (sshConfigs map[string]*sshutil.Config, kube kclient.Client, cleanup func(), err error) {
@@ -2480,9 +2485,10 @@ func (c *Config) TestConnectivity(ctx context.Context, vlab *VLAB, opts TestConn
 			if err != nil {
 				return fmt.Errorf("getting server %q IP: %w", server, err)
 			}
-			// Legacy TestConnectivity assumes one VPC IP per server; the
+			// Legacy TestConnectivity assumes one IPv4 VPC IP per server; the
 			// matrix-driven path (TestConnectivityWithMatrix) is the one
-			// that handles multi-IP servers correctly.
+			// that handles multi-IP and IPv6 servers correctly.
+			found = slices.DeleteFunc(found, func(ip discoveredIP) bool { return !ip.prefix.Addr().Is4() })
 			switch len(found) {
 			case 0:
 				return fmt.Errorf("no IP discovered for server %q", server) //nolint:goerr113
@@ -3332,9 +3338,11 @@ func runIPerf3Test(ctx context.Context, opts TestConnectivityOpts, from, to stri
 
 	fwd.SentSpeed = asMbps(fwdSent.BitsPerSecond)
 	fwd.RcvdSpeed = asMbps(fwdRcvd.BitsPerSecond)
+	opts.IPerfsStats.Record(fwdSent.BitsPerSecond, fwdRcvd.BitsPerSecond, iPerfsMinSpeed)
 	if rev != nil {
 		rev.SentSpeed = asMbps(report.End.SumSentBidirReverse.BitsPerSecond)
 		rev.RcvdSpeed = asMbps(report.End.SumReceivedBidirReverse.BitsPerSecond)
+		opts.IPerfsStats.Record(report.End.SumSentBidirReverse.BitsPerSecond, report.End.SumReceivedBidirReverse.BitsPerSecond, iPerfsMinSpeed)
 	}
 
 	if iPerfsMinSpeed <= 0 {
@@ -3406,7 +3414,7 @@ func parseNCReturnCode(stdout string) (int, bool) {
 // means the path is open (allow), a refused/timed-out connect (nc exit 1) means
 // it is blocked (deny).
 func checkTCPPort(ctx context.Context, sem *semaphore.Weighted, from string, fromSSH *sshutil.Config, toIP netip.Addr, port uint16, expected bool) *IperfError {
-	target := fmt.Sprintf("%s:%d", toIP.String(), port)
+	target := netip.AddrPortFrom(toIP, port).String()
 	ie := &IperfError{Source: from, Destination: target}
 
 	if sem != nil {
@@ -3481,7 +3489,7 @@ const (
 // cannot distinguish "TCP denied + UDP allowed" on the same port (the control
 // channel would be blocked). Callers must avoid that combination.
 func checkUDPPort(ctx context.Context, opts TestConnectivityOpts, sem *semaphore.Weighted, from string, fromSSH *sshutil.Config, toIP netip.Addr, port uint16, expected bool) *IperfError {
-	target := fmt.Sprintf("%s:%d", toIP.String(), port)
+	target := netip.AddrPortFrom(toIP, port).String()
 	ie := &IperfError{Source: from, Destination: target}
 
 	if sem != nil {
@@ -3545,6 +3553,15 @@ func checkUDPPort(ctx context.Context, opts TestConnectivityOpts, sem *semaphore
 	return nil
 }
 
+// urlHost returns the host part of the URL for the IP, IPv6 addresses are enclosed in brackets
+func urlHost(ip string) string {
+	if addr, err := netip.ParseAddr(ip); err == nil && addr.Is6() {
+		return "[" + ip + "]"
+	}
+
+	return ip
+}
+
 func checkCurl(ctx context.Context, opts TestConnectivityOpts, curls *semaphore.Weighted, from string, fromSSH *sshutil.Config, toIP string, expected Reachability) *CurlError {
 	if opts.CurlsCount <= 0 {
 		return nil
@@ -3568,7 +3585,7 @@ func checkCurl(ctx context.Context, opts TestConnectivityOpts, curls *semaphore.
 	slog.Debug("Running curls", "from", from, "to", toIP, "count", opts.CurlsCount)
 
 	for idx := 0; idx < opts.CurlsCount; idx++ {
-		cmd := fmt.Sprintf("timeout -v 5 curl --insecure --connect-timeout 3 --silent http://%s", toIP)
+		cmd := fmt.Sprintf("timeout -v 5 curl --insecure --connect-timeout 3 --silent http://%s", urlHost(toIP))
 		stdout, stderr, err := retrySSHCmd(ctx, fromSSH, cmd, from)
 		ce.Output = stdout
 
@@ -3690,28 +3707,44 @@ func AddrsFrom(prefixes ...netip.Prefix) iter.Seq[netip.Prefix] {
 func SubPrefixesFrom(bits int, prefixes ...netip.Prefix) iter.Seq[netip.Prefix] {
 	return func(yield func(netip.Prefix) bool) {
 		for _, prefix := range prefixes {
-			if bits < prefix.Bits() || !prefix.Addr().Is4() {
+			if bits < prefix.Bits() || bits > prefix.Addr().BitLen() {
 				continue
 			}
 
-			addr := prefix.Masked().Addr()
-			addrBytes := addr.AsSlice()
-			addrUint := binary.BigEndian.Uint32(addrBytes)
-			ok := true
-
-			for ok && prefix.Contains(addr) {
+			for addr, ok := prefix.Masked().Addr(), true; ok && prefix.Contains(addr); addr, ok = nextPrefixAddr(addr, bits) {
 				if !yield(netip.PrefixFrom(addr, bits)) {
 					return
 				}
-
-				addrUint += 1 << uint(32-bits)
-				binary.BigEndian.PutUint32(addrBytes, addrUint)
-				addr, ok = netip.AddrFromSlice(addrBytes)
 			}
 		}
 	}
 }
 
+// nextPrefixAddr returns the address of the next prefix of the given length (IPv4 or IPv6), false on overflow
+func nextPrefixAddr(addr netip.Addr, bits int) (netip.Addr, bool) {
+	if bits <= 0 {
+		return netip.Addr{}, false
+	}
+
+	addrBytes := addr.AsSlice()
+	idx, carry := (bits-1)/8, byte(1)<<(7-(bits-1)%8)
+	for ; idx >= 0; idx-- {
+		prev := addrBytes[idx]
+		addrBytes[idx] += carry
+		if addrBytes[idx] > prev {
+			break
+		}
+		carry = 1
+	}
+	if idx < 0 {
+		return netip.Addr{}, false
+	}
+
+	next, ok := netip.AddrFromSlice(addrBytes)
+
+	return next, ok
+}
+
 func CollectN[E any](n int, seq iter.Seq[E]) []E {
 	res := make([]E, n)
 
@@ -3889,6 +3922,10 @@ type ReleaseTestOpts struct {
 	ShowTechDump   bool
 	IPerfsMinSpeed float64
 	OnReadyTest    bool
+	TestFiles      []string // declarative release test files or directories
+	Shard          ReleaseTestShard
+	HistoryDir     string // dir to store run records in, history isn't recorded if empty
+	RunID          string // shared by all shards of the run to combine their records in the history
 }
 
 func ReleaseTest(ctx context.Context, c *Config, vlab *VLAB, opts ReleaseTestOpts) error {
@@ -109,6 +109,16 @@ func TestAddrsFrom(t *testing.T) {
 				netip.MustParsePrefix("10.0.1.199/31"),
 			},
 		},
+		{
+			name: "ipv6 prefix",
+			prefixes: []netip.Prefix{
+				netip.MustParsePrefix("fd00::1:fe/127"),
+			},
+			expected: []netip.Prefix{
+				netip.MustParsePrefix("fd00::1:fe/127"),
+				netip.MustParsePrefix("fd00::1:ff/127"),
+			},
+		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			got := slices.Collect(AddrsFrom(test.prefixes...))
@@ -227,6 +237,44 @@ func TestSubPrefixesFrom(t *testing.T) {
 				netip.MustParsePrefix("10.0.0.206/31"),
 			},
 		},
+		{
+			name: "ipv6 prefix",
+			bits: 64,
+			prefixes: []netip.Prefix{
+				netip.MustParsePrefix("fd00:0:0:fe::/62"),
+			},
+			expected: []netip.Prefix{
+				netip.MustParsePrefix("fd00:0:0:fc::/64"),
+				netip.MustParsePrefix("fd00:0:0:fd::/64"),
+				netip.MustParsePrefix("fd00:0:0:fe::/64"),
+				netip.MustParsePrefix("fd00:0:0:ff::/64"),
+			},
+		},
+		{
+			name: "ipv6 prefix carry over bytes",
+			bits: 120,
+			prefixes: []netip.Prefix{
+				netip.MustParsePrefix("fd00::fe00/118"),
+			},
+			expected: []netip.Prefix{
+				netip.MustParsePrefix("fd00::fc00/120"),
+				netip.MustParsePrefix("fd00::fd00/120"),
+				netip.MustParsePrefix("fd00::fe00/120"),
+				netip.MustParsePrefix("fd00::ff00/120"),
+			},
+		},
+		{
+			name: "mixed families end of range",
+			bits: 32,
+			prefixes: []netip.Prefix{
+				netip.MustParsePrefix("255.255.255.254/31"),
+				netip.MustParsePrefix("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff/128"),
+			},
+			expected: []netip.Prefix{
+				netip.MustParsePrefix("255.255.255.254/32"),
+				netip.MustParsePrefix("255.255.255.255/32"),
+			},
+		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			got := slices.Collect(SubPrefixesFrom(test.bits, test.prefixes...))
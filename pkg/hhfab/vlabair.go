@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"maps"
 	"net/netip"
 	"os"
 	"path/filepath"
@@ -20,6 +21,7 @@ import (
 	vpcapi "go.githedgehog.com/fabric/api/vpc/v1beta1"
 	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
 	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
+	"go.githedgehog.com/fabricator/pkg/fab/recipe"
 	"go.githedgehog.com/fabricator/pkg/util/apiutil"
 )
 
@@ -29,6 +31,24 @@ var vlabAirTopoTmpl string
 //go:embed vlabair_setup_servers.tmpl.sh
 var vlabAirSetupServersTmpl string
 
+//go:embed vlabair_setup_gateways.tmpl.sh
+var vlabAirSetupGatewaysTmpl string
+
+//go:embed vlabair_setup_externals.tmpl.sh
+var vlabAirSetupExternalsTmpl string
+
+const (
+	AirServerOS   = "generic/ubuntu2404"
+	AirSwitchOS   = "cumulus-vx-5.15.0"
+	AirExternalOS = AirServerOS
+)
+
+type AirOpts struct {
+	// Air image to be used for all gateway nodes, it should be a plain Flatcar image uploaded to Air as gateways are
+	// installed using per node installers the same way as in VLAB manual build mode
+	GatewayOS string
+}
+
 type AirTopoIn struct {
 	Nodes []AirTopoNode
 	Links []AirTopoLink
@@ -65,19 +85,44 @@ type AirServersInServer struct {
 }
 
 type AirServersInServerIface struct {
-	IP string
+	Parent string // set for VLAN subinterfaces
+	VLAN   uint16
+	IP     string
+	DHCP   bool
+}
+
+type AirGatewaysIn struct {
+	Gateways  map[string]AirGatewaysInGateway
+	RecipeBin string
+}
+
+type AirGatewaysInGateway struct {
+	IP        string
+	Ports     map[string]string // wiring port name -> MAC
+	Installer string            // installer archive name in the hhfab result dir
+	Install   string            // installer dir name after extracting the archive
+}
+
+type AirExternalsIn struct {
+	Name string
+	ExternalsCfg
 }
 
 type AirServersInServerRoute struct {
 	NextHops []string
 }
 
-func (c *Config) AirGenerate(ctx context.Context) error {
+func (c *Config) AirGenerate(ctx context.Context, opts AirOpts) error {
 	if len(c.Controls) != 1 {
-		return fmt.Errorf("exactly one control node is required") //nolint:err113
+		return fmt.Errorf("exactly one control node is required as it's running on the oob-mgmt-server") //nolint:err113
 	}
-	if len(c.Nodes) > 0 {
-		return fmt.Errorf("nodes are not supported yet") //nolint:err113
+	for _, node := range c.Nodes {
+		if !slices.Contains(node.Spec.Roles, fabapi.NodeRoleGateway) {
+			return fmt.Errorf("node %s: only gateway nodes are supported", node.Name) //nolint:err113
+		}
+	}
+	if len(c.Nodes) > 0 && opts.GatewayOS == "" {
+		return fmt.Errorf("gateway OS image is required for gateway nodes (upload Flatcar image to Air first)") //nolint:err113
 	}
 	if c.Fab.Spec.Config.Control.ManagementSubnet != "192.168.200.0/24" {
 		return fmt.Errorf("unsupported management subnet: %s", c.Fab.Spec.Config.Control.ManagementSubnet) //nolint:err113
@@ -125,7 +170,7 @@ func (c *Config) AirGenerate(ctx context.Context) error {
 			CPU:     2,
 			Memory:  4096,
 			Storage: 10,
-			OS:      "cumulus-vx-5.15.0",
+			OS:      AirSwitchOS,
 			IP:      ip,
 			MAC:     sw.Spec.Boot.MAC,
 		})
@@ -139,6 +184,40 @@ func (c *Config) AirGenerate(ctx context.Context) error {
 		return mac
 	}
 
+	gatewaysIn := AirGatewaysIn{
+		Gateways:  map[string]AirGatewaysInGateway{},
+		RecipeBin: recipe.RecipeBin,
+	}
+	for idx, node := range c.Nodes {
+		ip, err := node.Spec.Management.IP.Parse()
+		if err != nil {
+			return fmt.Errorf("node %s management IP: %w", node.Name, err)
+		}
+
+		mac := nextMAC()
+		staticIPs[mac] = ip.Addr().String()
+		topoIn.Nodes = append(topoIn.Nodes, AirTopoNode{
+			Name:    node.Name,
+			NIC:     "e1000",
+			CPU:     DefaultSizes.Gateway.CPU,
+			Memory:  DefaultSizes.Gateway.RAM,
+			Storage: DefaultSizes.Gateway.Disk,
+			OS:      opts.GatewayOS,
+			IP:      ip.Addr().String(),
+			MAC:     mac,
+		})
+		fullName := string(recipe.TypeNode) + recipe.Separator + node.Name + recipe.Separator
+		gatewaysIn.Gateways[node.Name] = AirGatewaysInGateway{
+			IP:        ip.Addr().String(),
+			Ports:     map[string]string{},
+			Installer: fullName + recipe.InstallArchiveSuffix,
+			Install:   fullName + recipe.InstallSuffix,
+		}
+
+		// Air names NICs in order, so management one is always the first
+		c.Nodes[idx].Spec.Management.Interface = "eth0"
+	}
+
 	translatePort := func(in string) (string, string) {
 		parts := strings.SplitN(in, "/", 2)
 		if len(parts) != 2 {
@@ -157,6 +236,35 @@ func (c *Config) AirGenerate(ctx context.Context) error {
 		return parts[0], parts[1]
 	}
 
+	addLink := func(l1, l2 string) {
+		d1, p1 := translatePort(l1)
+		d2, p2 := translatePort(l2)
+		m1, m2 := nextMAC(), nextMAC()
+
+		// gateway NICs are renamed to match wiring using MACs as dataplane is configured using them
+		if gw, ok := gatewaysIn.Gateways[d1]; ok {
+			gw.Ports[strings.SplitN(l1, "/", 2)[1]] = m1
+		}
+		if gw, ok := gatewaysIn.Gateways[d2]; ok {
+			gw.Ports[strings.SplitN(l2, "/", 2)[1]] = m2
+		}
+
+		topoIn.Links = append(topoIn.Links, AirTopoLink{
+			Endpoints: []AirTopoLinkEndpoint{
+				{
+					Interface: p1,
+					Node:      d1,
+					MAC:       m1,
+				},
+				{
+					Interface: p2,
+					Node:      d2,
+					MAC:       m2,
+				},
+			},
+		})
+	}
+
 	attaches := &vpcapi.VPCAttachmentList{}
 	if err := c.Client.List(ctx, attaches); err != nil {
 		return fmt.Errorf("listing vpc attachments: %w", err)
@@ -177,6 +285,15 @@ func (c *Config) AirGenerate(ctx context.Context) error {
 		conns[conn.Name] = conn
 	}
 
+	vpcList := &vpcapi.VPCList{}
+	if err := c.Client.List(ctx, vpcList); err != nil {
+		return fmt.Errorf("listing vpcs: %w", err)
+	}
+	vpcs := map[string]vpcapi.VPC{}
+	for _, vpc := range vpcList.Items {
+		vpcs[vpc.Name] = vpc
+	}
+
 	servers := &wiringapi.ServerList{}
 	if err := c.Client.List(ctx, servers); err != nil {
 		return fmt.Errorf("listing servers: %w", err)
@@ -184,8 +301,33 @@ func (c *Config) AirGenerate(ctx context.Context) error {
 	slices.SortFunc(servers.Items, func(a, b wiringapi.Server) int {
 		return strings.Compare(a.Name, b.Name)
 	})
-	for idx, server := range servers.Items {
-		ip := fmt.Sprintf("192.168.200.%d", 100+idx)
+
+	// servers and externals are getting management IPs starting from .100 skipping ones used by switches and nodes
+	usedIPs := map[string]bool{}
+	for _, ip := range staticIPs {
+		usedIPs[ip] = true
+	}
+	if controlIP, err := c.Controls[0].Spec.Management.IP.Parse(); err == nil {
+		usedIPs[controlIP.Addr().String()] = true
+	}
+	nextIPID := 100
+	nextIP := func(name string) (string, error) {
+		for ; nextIPID < 255; nextIPID++ {
+			if ip := fmt.Sprintf("192.168.200.%d", nextIPID); !usedIPs[ip] {
+				usedIPs[ip] = true
+
+				return ip, nil
+			}
+		}
+
+		return "", fmt.Errorf("no free management IP left for %s", name) //nolint:err113
+	}
+
+	for _, server := range servers.Items {
+		ip, err := nextIP(server.Name)
+		if err != nil {
+			return err
+		}
 		mac := nextMAC()
 		staticIPs[mac] = ip
 		topoIn.Nodes = append(topoIn.Nodes, AirTopoNode{
@@ -194,7 +336,7 @@ func (c *Config) AirGenerate(ctx context.Context) error {
 			CPU:     1,
 			Memory:  1024,
 			Storage: 10,
-			OS:      "generic/ubuntu2404",
+			OS:      AirServerOS,
 			IP:      ip,
 			MAC:     mac,
 		})
@@ -221,7 +363,27 @@ func (c *Config) AirGenerate(ctx context.Context) error {
 
 			p2pStr := attach.Annotations[vpcapi.AnnotationVPCAttachmentP2PLink]
 			if p2pStr == "" {
-				return fmt.Errorf("VPCAttachment %s missing p2p annotation", attach.Name) //nolint:err113
+				// regular VPC subnet attachment, server is getting IP from the fabric DHCP
+				vpc, ok := vpcs[attach.Spec.VPCName()]
+				if !ok {
+					return fmt.Errorf("VPC not found for attachment %s", attach.Name) //nolint:err113
+				}
+				subnet, ok := vpc.Spec.Subnets[attach.Spec.SubnetName()]
+				if !ok || subnet == nil {
+					return fmt.Errorf("VPC subnet not found for attachment %s", attach.Name) //nolint:err113
+				}
+
+				if attach.Spec.NativeVLAN {
+					inServer.Ifaces[port] = AirServersInServerIface{DHCP: true}
+				} else {
+					inServer.Ifaces[fmt.Sprintf("%s.%d", port, subnet.VLAN)] = AirServersInServerIface{
+						Parent: port,
+						VLAN:   subnet.VLAN,
+						DHCP:   true,
+					}
+				}
+
+				continue
 			}
 
 			p2p, err := netip.ParsePrefix(p2pStr)
@@ -261,23 +423,61 @@ func (c *Config) AirGenerate(ctx context.Context) error {
 			return fmt.Errorf("parsing connection endpoints: %w", err)
 		}
 		for l1, l2 := range links {
-			d1, p1 := translatePort(l1)
-			d2, p2 := translatePort(l2)
-
-			topoIn.Links = append(topoIn.Links, AirTopoLink{
-				Endpoints: []AirTopoLinkEndpoint{
-					{
-						Interface: p1,
-						Node:      d1,
-						MAC:       nextMAC(),
-					},
-					{
-						Interface: p2,
-						Node:      d2,
-						MAC:       nextMAC(),
-					},
-				},
-			})
+			addLink(l1, l2)
+		}
+	}
+
+	// reusing VLAB config to get the same virtual externals setup as in the local VLAB
+	vlabCfg, err := createVLABConfig(ctx, c.Controls, c.Nodes, c.Client)
+	if err != nil {
+		return fmt.Errorf("creating VLAB config: %w", err)
+	}
+
+	var externalsIn *AirExternalsIn
+	if len(vlabCfg.Externals.NICs) > 0 {
+		externalsIn = &AirExternalsIn{
+			Name: ExternalVMName,
+			ExternalsCfg: ExternalsCfg{
+				NICs: map[string]ExternalNICCfg{},
+				VRFs: map[string]ExternalVRFCfg{},
+			},
+		}
+		for name, nic := range vlabCfg.Externals.NICs {
+			_, port := translatePort(ExternalVMName + "/" + name)
+			externalsIn.NICs[port] = nic
+		}
+		for name, vrf := range vlabCfg.Externals.VRFs {
+			if vrf.IsStatic && vrf.StaticCfg.NICName != "" {
+				_, vrf.StaticCfg.NICName = translatePort(ExternalVMName + "/" + vrf.StaticCfg.NICName)
+			}
+			externalsIn.VRFs[name] = vrf
+		}
+
+		ip, err := nextIP(externalsIn.Name)
+		if err != nil {
+			return err
+		}
+		mac := nextMAC()
+		staticIPs[mac] = ip
+		topoIn.Nodes = append(topoIn.Nodes, AirTopoNode{
+			Name:    externalsIn.Name,
+			NIC:     "e1000",
+			CPU:     2,
+			Memory:  2048,
+			Storage: 10,
+			OS:      AirExternalOS,
+			IP:      ip,
+			MAC:     mac,
+		})
+
+		extVM := vlabCfg.VMs[ExternalVMName]
+		for _, nicName := range slices.Sorted(maps.Keys(extVM.NICs)) {
+			peer, ok := strings.CutPrefix(extVM.NICs[nicName], NICTypeDirect+NICTypeSep)
+			if !ok {
+				continue
+			}
+
+			addLink(peer, ExternalVMName+"/"+nicName)
 		}
 	}
 
@@ -346,6 +546,18 @@ func (c *Config) AirGenerate(ctx context.Context) error {
 		return fmt.Errorf("writing setup servers file: %w", err)
 	}
 
+	if len(gatewaysIn.Gateways) > 0 {
+		if err := airWriteScript(resultDir, "setup_gateways.sh", vlabAirSetupGatewaysTmpl, gatewaysIn); err != nil {
+			return err
+		}
+	}
+
+	if externalsIn != nil {
+		if err := airWriteScript(resultDir, "setup_externals.sh", vlabAirSetupExternalsTmpl, externalsIn); err != nil {
+			return err
+		}
+	}
+
 	includeBuf := &bytes.Buffer{}
 	if err := apiutil.PrintInclude(ctx, c.Client, includeBuf); err != nil {
 		return fmt.Errorf("printing include: %w", err)
@@ -357,3 +569,21 @@ func (c *Config) AirGenerate(ctx context.Context) error {
 
 	return nil
 }
+
+func airWriteScript(resultDir, name, tmplText string, in any) error {
+	tmpl, err := template.New(name).Parse(tmplText)
+	if err != nil {
+		return fmt.Errorf("parsing %s template: %w", name, err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, in); err != nil {
+		return fmt.Errorf("executing %s template: %w", name, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(resultDir, name), buf.Bytes(), 0o755); err != nil { //nolint:gosec
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+
+	return nil
+}
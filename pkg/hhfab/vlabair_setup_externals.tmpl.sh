@@ -0,0 +1,123 @@
+#!/bin/bash
+# Copyright 2025 Hedgehog
+# SPDX-License-Identifier: Apache-2.0
+
+set -euo pipefail
+
+echo "======================="
+echo "Setting up externals..."
+echo "======================="
+echo -e "\n"
+
+SSHPASS='nvidia' sshpass -e ssh-copy-id -o StrictHostKeyChecking=accept-new -i ~/.ssh/id_rsa.pub ubuntu@{{ $.Name }}
+
+# Same setup as the VLAB external VM with eth0 (Air management) used as an upstream instead of enp2s0
+cat <<'EOF' | ssh ubuntu@{{ $.Name }} bash
+set -euo pipefail
+hostname
+
+UPLINK_GW=$(ip -4 route show default dev eth0 | awk '{ print $3; exit }')
+if [ -z "$UPLINK_GW" ]; then
+  echo "No default gateway found on eth0"
+  exit 1
+fi
+
+sudo DEBIAN_FRONTEND=noninteractive apt-get update -y
+sudo DEBIAN_FRONTEND=noninteractive apt-get install -y frr
+
+sudo sysctl -w net.ipv4.ip_forward=1
+sudo sysctl -w net.ipv4.tcp_l3mdev_accept=1
+sudo sysctl -w net.ipv4.udp_l3mdev_accept=1
+sudo iptables -t nat -C POSTROUTING -o eth0 -j MASQUERADE 2>/dev/null || sudo iptables -t nat -A POSTROUTING -o eth0 -j MASQUERADE
+{{ range $vrfKey, $vrf := $.VRFs }}
+sudo ip link add {{ $vrfKey }} type vrf table {{ $vrf.TableID }} || true
+sudo ip link set dev {{ $vrfKey }} up
+{{ end }}
+{{ range $nicKey, $nic := $.NICs }}
+sudo ip link set dev {{ $nicKey }} up
+sudo ip a flush dev {{ $nicKey }}
+{{ if $nic.Untagged }}sudo ip link set dev {{ $nicKey }} master {{ $nic.UntaggedCfg.VRF }}
+sudo ip a a {{ $nic.UntaggedCfg.Prefix }} dev {{ $nicKey }}
+{{ end }}{{ range $attach := $nic.Attachments }}
+sudo ip link add link {{ $nicKey }} name {{ $nicKey }}.{{ $attach.Vlan }} type vlan id {{ $attach.Vlan }} || true
+sudo ip link set dev {{ $nicKey }}.{{ $attach.Vlan }} master {{ $attach.VRF }}
+sudo ip link set dev {{ $nicKey }}.{{ $attach.Vlan }} up
+sudo ip a flush dev {{ $nicKey }}.{{ $attach.Vlan }}
+sudo ip a a {{ $attach.Prefix }} dev {{ $nicKey }}.{{ $attach.Vlan }}
+{{ end }}{{ end }}
+sudo sed -i 's/^bgpd=no/bgpd=yes/' /etc/frr/daemons
+
+cat <<FRR | sudo tee /etc/frr/frr.conf
+hostname {{ $.Name }}
+log syslog informational
+!{{ range $vrfKey, $vrf := $.VRFs }}
+vrf {{ $vrfKey }}
+ {{ if $vrf.IsStatic }}{{ range $prefix := $vrf.StaticCfg.Prefixes }}ip route {{ $prefix }} $UPLINK_GW eth0 nexthop-vrf default{{ end }}
+ {{ if ne $vrf.StaticCfg.SwitchIP "" }}ip route 10.0.0.0/16 {{ $vrf.StaticCfg.SwitchIP }} {{ $vrf.StaticCfg.NICName }}{{ end }}
+ {{ if and (ne $vrf.StaticCfg.NATPoolCIDR "") (ne $vrf.StaticCfg.SwitchIP "") }}ip route {{ $vrf.StaticCfg.NATPoolCIDR }} {{ $vrf.StaticCfg.SwitchIP }} {{ $vrf.StaticCfg.NICName }}{{ end }}
+ {{ else }}ip route 0.0.0.0/0 $UPLINK_GW eth0 nexthop-vrf default{{ end }}
+exit-vrf
+!{{ if $vrf.IsStatic }}{{ range $gwIP := $vrf.StaticCfg.GatewayIPs }}
+ip route {{ $gwIP }} {{ $vrf.StaticCfg.NICName }} nexthop-vrf {{ $vrfKey }}
+ip prefix-list static-ext-routes permit {{ $gwIP }}{{ end }}
+{{ if ne $vrf.StaticCfg.SwitchIP "" }}ip route 10.0.0.0/16 {{ $vrf.StaticCfg.SwitchIP }} {{ $vrf.StaticCfg.NICName }} nexthop-vrf {{ $vrfKey }}{{ end }}
+{{ if and (ne $vrf.StaticCfg.NATPoolCIDR "") (ne $vrf.StaticCfg.SwitchIP "") }}ip route {{ $vrf.StaticCfg.NATPoolCIDR }} {{ $vrf.StaticCfg.SwitchIP }} {{ $vrf.StaticCfg.NICName }} nexthop-vrf {{ $vrfKey }}
+ip prefix-list static-ext-routes permit {{ $vrf.StaticCfg.NATPoolCIDR }}{{ end }}{{ else }}
+bgp community-list standard {{ $vrfKey }}In seq 5 permit {{ $vrf.BGPCfg.InCommunity }}
+bgp community-list standard {{ $vrfKey }}Out seq 5 permit {{ $vrf.BGPCfg.OutCommunity }}
+!
+route-map {{ $vrfKey }}In permit 10
+ match community {{ $vrfKey }}In
+!
+route-map {{ $vrfKey }}Out permit 10
+ set community {{ $vrf.BGPCfg.OutCommunity }}
+!
+route-map {{ $vrfKey }}Out deny 2000
+!{{ end }}{{ end }}{{ range $vrfKey, $vrf := $.VRFs }}{{ if not $vrf.IsStatic }}
+router bgp {{ $vrf.BGPCfg.ASN }} vrf {{ $vrfKey }}
+ bgp log-neighbor-changes
+ bgp bestpath as-path multipath-relax
+ no bgp ebgp-requires-policy
+ no bgp network import-check{{ range $nicKey, $nic := $.NICs }}{{ range $attach := $nic.Attachments }}{{ if eq $attach.VRF $vrfKey }}
+ neighbor {{ $attach.NeighborIP }} remote-as {{ $attach.NeighborASN }}
+ neighbor {{ $attach.NeighborIP }} advertisement-interval 0
+ neighbor {{ $attach.NeighborIP }} timers connect 30{{ end }}{{ end }}{{ if and ($nic.Untagged) (eq $nic.UntaggedCfg.VRF $vrfKey) }}
+ neighbor {{ $nic.UntaggedCfg.NeighborIP }} remote-as {{ $nic.UntaggedCfg.NeighborASN }}
+ neighbor {{ $nic.UntaggedCfg.NeighborIP }} advertisement-interval 0
+ neighbor {{ $nic.UntaggedCfg.NeighborIP }} timers connect 30{{ end }}
+ address-family ipv4 unicast{{ range $attach := $nic.Attachments }}{{ if eq $attach.VRF $vrfKey }}
+  neighbor {{ $attach.NeighborIP }} activate
+  no neighbor {{ $attach.NeighborIP }} send-community large
+  neighbor {{ $attach.NeighborIP }} soft-reconfiguration inbound
+  neighbor {{ $attach.NeighborIP }} route-map {{ $vrfKey }}In in
+  neighbor {{ $attach.NeighborIP }} route-map {{ $vrfKey }}Out out{{ end }}{{ end }}{{ if and ($nic.Untagged) (eq $nic.UntaggedCfg.VRF $vrfKey) }}
+  neighbor {{ $nic.UntaggedCfg.NeighborIP }} activate
+  no neighbor {{ $nic.UntaggedCfg.NeighborIP }} send-community large
+  neighbor {{ $nic.UntaggedCfg.NeighborIP }} route-map {{ $vrfKey }}In in
+  neighbor {{ $nic.UntaggedCfg.NeighborIP }} route-map {{ $vrfKey }}Out out{{ end }}
+  redistribute static
+  import vrf default
+ !
+!{{ end }}{{ end }}{{ end }}
+route-map filter-static-redist deny 10
+ match ip address prefix-list static-ext-routes
+!
+route-map filter-static-redist permit 100
+!
+router bgp 1
+ bgp bestpath as-path multipath-relax
+ address-family ipv4 unicast
+  redistribute static route-map filter-static-redist{{ range $vrfKey, $vrf := $.VRFs }}{{ if not $vrf.IsStatic }}
+  import vrf {{ $vrfKey }}{{ end }}{{ end }}
+ !
+!
+line vty
+FRR
+
+sudo systemctl restart frr
+EOF
+
+echo -e "\n"
+echo "====================="
+echo "All externals set up."
+echo "====================="
@@ -0,0 +1,63 @@
+#!/bin/bash
+# Copyright 2025 Hedgehog
+# SPDX-License-Identifier: Apache-2.0
+
+set -euo pipefail
+
+# Gateways are installed using per node installers built by "hhfab build --mode manual" from the Air config
+RESULT_DIR="${RESULT_DIR:-result}"
+
+echo "======================"
+echo "Setting up gateways..."
+echo "======================"
+echo -e "\n"
+
+{{ range $name, $gw := $.Gateways }}
+if [ ! -f "$RESULT_DIR/{{ $gw.Installer }}" ]; then
+  echo "Installer $RESULT_DIR/{{ $gw.Installer }} not found, run hhfab build --mode manual first"
+  exit 1
+fi
+{{ end }}
+
+{{ range $name, $gw := $.Gateways }}
+
+echo -e "\nSetting up gateway: {{ $name }}"
+
+# Air names NICs in order while gateway dataplane is configured using the wiring port names
+cat <<'EOF' | ssh -o StrictHostKeyChecking=accept-new core@{{ $gw.IP }} bash
+hostname
+{{ range $port, $mac := $gw.Ports }}
+cat <<'LINK' | sudo tee /etc/systemd/network/10-hh-{{ $port }}.link
+[Match]
+MACAddress={{ $mac }}
+
+[Link]
+Name={{ $port }}
+LINK
+{{ end }}
+sudo systemd-run --on-active=5 systemctl reboot
+EOF
+
+{{ end }}
+
+{{ range $name, $gw := $.Gateways }}
+
+echo -e "\nInstalling gateway: {{ $name }}"
+
+# wait for the reboot to start and gateway to get back with the renamed NICs
+sleep 15
+until ssh -o ConnectTimeout=5 core@{{ $gw.IP }} true; do
+  echo "Waiting for gateway {{ $name }} to come back after reboot..."
+  sleep 5
+done
+
+ssh core@{{ $gw.IP }} "rm -rf {{ $gw.Install }}*"
+scp "$RESULT_DIR/{{ $gw.Installer }}" core@{{ $gw.IP }}:{{ $gw.Installer }}
+ssh core@{{ $gw.IP }} "tar xzf {{ $gw.Installer }} && cd {{ $gw.Install }} && sudo ./{{ $.RecipeBin }} install"
+
+{{ end }}
+
+echo -e "\n"
+echo "==================================="
+echo "All gateways installed and joined."
+echo "==================================="
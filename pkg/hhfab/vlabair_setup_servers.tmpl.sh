@@ -20,10 +20,17 @@ SSHPASS='nvidia' sshpass -e ssh-copy-id -o StrictHostKeyChecking=accept-new -i ~
 cat <<'EOF' | ssh ubuntu@{{ $name }} bash
 hostname
 {{ range $ifaceName, $iface := $server.Ifaces }}
-sudo ip link set dev {{ $ifaceName }} up
+{{ if $iface.Parent }}sudo ip link set dev {{ $iface.Parent }} up
+sudo ip link add link {{ $iface.Parent }} name {{ $ifaceName }} type vlan id {{ $iface.VLAN }} || true
+{{ end }}sudo ip link set dev {{ $ifaceName }} up
 sudo ip a flush dev {{ $ifaceName }}
-sudo ip a a {{ $iface.IP }} dev {{ $ifaceName }}
-{{ end }}
+{{ if $iface.DHCP }}if command -v dhcpcd >/dev/null; then
+  sudo dhcpcd -4 -b {{ $ifaceName }}
+else
+  sudo dhclient -4 -nw {{ $ifaceName }}
+fi
+{{ else }}sudo ip a a {{ $iface.IP }} dev {{ $ifaceName }}
+{{ end }}{{ end }}
 {{ range $prefix, $route := $server.Routes }}
 sudo ip r a {{ $prefix }}{{ range $via := $route.NextHops }} nexthop via {{ $via }}{{ end }}
 {{ end }}
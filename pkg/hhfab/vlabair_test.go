@@ -0,0 +1,99 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.githedgehog.com/fabric/api/meta"
+	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
+	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
+	"go.githedgehog.com/fabricator/pkg/util/apiutil"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAirGenerate(t *testing.T) {
+	ctx := context.Background()
+
+	nodes := []fabapi.FabNode{{
+		ObjectMeta: kmetav1.ObjectMeta{Name: "gateway-1", Namespace: "fab"},
+		Spec: fabapi.FabNodeSpec{
+			Roles:      []fabapi.FabNodeRole{fabapi.NodeRoleGateway},
+			Management: fabapi.ControlNodeManagement{IP: "192.168.200.100/24", Interface: "enp2s0"},
+		},
+	}}
+
+	l := apiutil.NewLoader()
+	require.NoError(t, (&VLABBuilderDefault{
+		SpinesCount:        1,
+		FabricLinksCount:   1,
+		OrphanLeafsCount:   1,
+		UnbundledServers:   2,
+		GatewayUplinks:     1,
+		GatewayLogLevel:    "info",
+		ExtBGPCount:        1,
+		ExtOrphanConnCount: 1,
+		VLABBuilderBase:    VLABBuilderBase{DefaultSwitchProfile: meta.SwitchProfileCmlsVX},
+	}).Build(ctx, l, meta.FabricModeSpineLeaf, nodes))
+
+	// switch IPs are normally assigned by hydration, leaf is taking one of the IPs used for servers and externals
+	switchIPs := map[string]string{"spine-01": "192.168.200.10/24", "leaf-01": "192.168.200.101/24"}
+	sws := &wiringapi.SwitchList{}
+	require.NoError(t, l.GetClient().List(ctx, sws))
+	require.Len(t, sws.Items, len(switchIPs))
+	for _, sw := range sws.Items {
+		sw.Spec.IP = switchIPs[sw.Name]
+		require.NoError(t, l.GetClient().Update(ctx, &sw))
+	}
+
+	fab := fabapi.Fabricator{}
+	fab.Spec.Config.Control.ManagementSubnet = "192.168.200.0/24"
+	cfg := &Config{
+		WorkDir: t.TempDir(),
+		Fab:     fab,
+		Controls: []fabapi.ControlNode{{
+			ObjectMeta: kmetav1.ObjectMeta{Name: "control-1", Namespace: "fab"},
+			Spec: fabapi.ControlNodeSpec{
+				Management: fabapi.ControlNodeManagement{IP: "192.168.200.1/24", Interface: "enp2s1"},
+				External:   fabapi.ControlNodeExternal{Interface: "enp2s0"},
+			},
+		}},
+		Nodes:  nodes,
+		Client: l.GetClient(),
+	}
+
+	require.ErrorContains(t, cfg.AirGenerate(ctx, AirOpts{}), "gateway OS image is required")
+	require.NoError(t, cfg.AirGenerate(ctx, AirOpts{GatewayOS: "flatcar"}))
+
+	resultDir := filepath.Join(cfg.WorkDir, ResultDir, "air")
+	for _, name := range []string{"topology.json", "config.yaml", "include.yaml", "setup_servers.sh", "setup_gateways.sh", "setup_externals.sh"} {
+		require.FileExists(t, filepath.Join(resultDir, name))
+	}
+
+	data, err := os.ReadFile(filepath.Join(resultDir, "topology.json"))
+	require.NoError(t, err)
+	require.True(t, json.Valid(data), "topology should be valid json")
+
+	ips := map[string]string{}
+	for mac, ip := range cfg.Fab.Spec.Config.Control.ManagementSubnetStatic {
+		require.NotContains(t, ips, ip, "duplicate management IP for %s", mac)
+		ips[ip] = mac
+	}
+	// .100 and .101 are taken by the gateway and leaf so servers and external are getting the next ones
+	for _, ip := range []string{"10", "100", "101", "102", "103", "104"} {
+		require.Contains(t, ips, "192.168.200."+ip)
+	}
+	require.Len(t, ips, 2+1+2+1)
+
+	gateways, err := os.ReadFile(filepath.Join(resultDir, "setup_gateways.sh"))
+	require.NoError(t, err)
+	require.Contains(t, string(gateways), "scp \"$RESULT_DIR/node--gateway-1--install.tgz\" core@192.168.200.100:node--gateway-1--install.tgz")
+	require.Contains(t, string(gateways), "cd node--gateway-1--install && sudo ./hhfab-recipe install")
+	require.Contains(t, string(gateways), "Name=enp2s1")
+}
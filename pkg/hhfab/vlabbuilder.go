@@ -122,19 +122,9 @@ func (b *VLABBuilderDefault) Build(ctx context.Context, l *apiutil.Loader, fabri
 		}
 	}
 
-	isGw := false
-	gws := []fabapi.FabNode{}
-	gwTags := map[string]gwapi.GatewayLogLevel{}
-	var gwLogLevel gwapi.GatewayLogLevel
-	for _, node := range nodes {
-		if slices.Contains(node.Spec.Roles, fabapi.NodeRoleGateway) {
-			isGw = true
-			gws = append(gws, node)
-		}
-	}
-	slices.SortFunc(gws, func(a, b fabapi.FabNode) int {
-		return strings.Compare(a.Name, b.Name)
-	})
+	gws := gatewayNodes(nodes)
+	isGw := len(gws) > 0
+	gwLogs := gwapi.GatewayLogs{}
 
 	if isGw {
 		if fabricMode != meta.FabricModeSpineLeaf {
@@ -152,17 +142,10 @@ func (b *VLABBuilderDefault) Build(ctx context.Context, l *apiutil.Loader, fabri
 			return fmt.Errorf("unsupported gateway driver %s", b.GatewayDriver) //nolint:goerr113
 		}
 
-		gwLogLevel = gwapi.GatewayLogLevel(b.GatewayLogLevel)
-		if !slices.Contains(gwapi.GatewayLogLevels, gwLogLevel) {
-			return fmt.Errorf("invalid gateway log level %s", b.GatewayLogLevel) //nolint:goerr113
-		}
-
-		for tagTarget, tagLevel := range b.GatewayTags {
-			gwTagLevel := gwapi.GatewayLogLevel(tagLevel)
-			if !slices.Contains(gwapi.GatewayLogLevels, gwTagLevel) {
-				return fmt.Errorf("invalid gateway log level %s for tag target %s", tagLevel, tagTarget) //nolint:goerr113
-			}
-			gwTags[tagTarget] = gwTagLevel
+		var err error
+		gwLogs, err = gatewayLogs(b.GatewayLogLevel, b.GatewayTags)
+		if err != nil {
+			return err
 		}
 
 		totalESLAGLeafs := 0
@@ -274,83 +257,14 @@ func (b *VLABBuilderDefault) Build(ctx context.Context, l *apiutil.Loader, fabri
 	slog.Info(">>>", "externalBGPCount", b.ExtBGPCount, "externalStaticCount", b.ExtStaticCount, "externalStaticProxyCount", b.ExtStaticProxyCount)
 	slog.Info(">>>", "externalEslagConnCount", b.ExtESLAGConnCount, "externalOrphanConnCount", b.ExtOrphanConnCount)
 
-	if err := b.data.Add(ctx, &wiringapi.VLANNamespace{
-		TypeMeta: kmetav1.TypeMeta{
-			Kind:       wiringapi.KindVLANNamespace,
-			APIVersion: wiringapi.GroupVersion.String(),
-		},
-		ObjectMeta: kmetav1.ObjectMeta{
-			Name: "default",
-		},
-		Spec: wiringapi.VLANNamespaceSpec{
-			Ranges: []meta.VLANRange{
-				{From: 1000, To: 2999},
-			},
-		},
-	}); err != nil {
-		return fmt.Errorf("creating VLAN namespace: %w", err) //nolint:goerr113
-	}
-
-	if err := b.data.Add(ctx, &vpcapi.IPv4Namespace{
-		TypeMeta: kmetav1.TypeMeta{
-			Kind:       vpcapi.KindIPv4Namespace,
-			APIVersion: vpcapi.GroupVersion.String(),
-		},
-		ObjectMeta: kmetav1.ObjectMeta{
-			Name: "default",
-		},
-		Spec: vpcapi.IPv4NamespaceSpec{
-			Subnets: []string{
-				"10.0.0.0/16",
-			},
-		},
-	}); err != nil {
-		return fmt.Errorf("creating IPv4 namespace: %w", err) //nolint:goerr113
+	if err := b.createDefaultNamespaces(ctx, 1000, "10.0.0.0/16"); err != nil {
+		return err
 	}
 
 	b.ifaceTracker = map[string]uint8{}
 
-	if isGw {
-		if _, err := b.createGatewayGroup(ctx, gwapi.DefaultGatewayGroup); err != nil {
-			return err
-		}
-	}
-
-	for _, node := range nodes {
-		if slices.Contains(node.Spec.Roles, fabapi.NodeRoleGateway) {
-			gwName := node.Name
-
-			ifaces := map[string]gwapi.GatewayInterface{}
-			for i := uint8(1); i <= b.GatewayUplinks; i++ {
-				switch b.GatewayDriver {
-				case GatewayDriverKernel:
-					ifaces[fmt.Sprintf("enp2s%d", i)] = gwapi.GatewayInterface{}
-					// TODO enable after migrating dataplane to a new interface format
-					// ifaces[fmt.Sprintf("port%d", i)] = gwapi.GatewayInterface{
-					// 	Kernel: fmt.Sprintf("enp2s%d", i),
-					// }
-				case GatewayDriverDPDK:
-					ifaces[fmt.Sprintf("port%d", i)] = gwapi.GatewayInterface{
-						PCI: fmt.Sprintf("0000:02:%02d.0", i),
-					}
-				}
-			}
-
-			if _, err := b.createGateway(ctx, gwName, gwapi.GatewaySpec{
-				Groups: []gwapi.GatewayGroupMembership{
-					{Name: gwapi.DefaultGatewayGroup},
-				},
-				Interfaces: ifaces,
-				Workers:    b.GatewayWorkers,
-				Logs: gwapi.GatewayLogs{
-					Default: gwLogLevel,
-					Tags:    gwTags,
-				},
-				// Neighbors will be later hydrated in based on the gateway connections
-			}); err != nil {
-				return err
-			}
-		}
+	if err := b.createGateways(ctx, gws, b.GatewayUplinks, b.GatewayDriver, b.GatewayWorkers, gwLogs); err != nil {
+		return err
 	}
 
 	if _, err := b.createSwitchGroup(ctx, "empty"); err != nil {
@@ -411,24 +325,7 @@ func (b *VLABBuilderDefault) Build(ctx context.Context, l *apiutil.Loader, fabri
 
 			leafNamesStr := strings.Join(leafNames, " ")
 
-			if _, err := b.createServer(ctx, serverName, wiringapi.ServerSpec{
-				Description: fmt.Sprintf("S-%02d ESLAG %s", serverID, leafNamesStr),
-			}); err != nil {
-				return err
-			}
-
-			links := []wiringapi.ServerToSwitchLink{}
-			for _, leafName := range leafNames {
-				links = append(links, wiringapi.ServerToSwitchLink{
-					Server: wiringapi.BasePortName{Port: b.nextServerPort(serverName)},
-					Switch: wiringapi.BasePortName{Port: b.nextSwitchPort(leafName)},
-				})
-			}
-			if _, err := b.createConnection(ctx, wiringapi.ConnectionSpec{
-				ESLAG: &wiringapi.ConnESLAG{
-					Links: links,
-				},
-			}); err != nil {
+			if err := b.createESLAGServer(ctx, serverName, fmt.Sprintf("S-%02d ESLAG %s", serverID, leafNamesStr), leafNames); err != nil {
 				return err
 			}
 
@@ -438,20 +335,7 @@ func (b *VLABBuilderDefault) Build(ctx context.Context, l *apiutil.Loader, fabri
 		for i := 0; i < int(b.UnbundledServers); i++ {
 			serverName := fmt.Sprintf("server-%02d", serverID)
 
-			if _, err := b.createServer(ctx, serverName, wiringapi.ServerSpec{
-				Description: fmt.Sprintf("S-%02d Unbundled %s", serverID, leafNames[0]),
-			}); err != nil {
-				return err
-			}
-
-			if _, err := b.createConnection(ctx, wiringapi.ConnectionSpec{
-				Unbundled: &wiringapi.ConnUnbundled{
-					Link: wiringapi.ServerToSwitchLink{
-						Server: wiringapi.BasePortName{Port: b.nextServerPort(serverName)},
-						Switch: wiringapi.BasePortName{Port: b.nextSwitchPort(leafNames[0])},
-					},
-				},
-			}); err != nil {
+			if err := b.createUnbundledServer(ctx, serverName, fmt.Sprintf("S-%02d Unbundled %s", serverID, leafNames[0]), leafNames[0]); err != nil {
 				return err
 			}
 
@@ -462,26 +346,7 @@ func (b *VLABBuilderDefault) Build(ctx context.Context, l *apiutil.Loader, fabri
 			for i := 0; i < int(b.BundledServers); i++ {
 				serverName := fmt.Sprintf("server-%02d", serverID)
 
-				if _, err := b.createServer(ctx, serverName, wiringapi.ServerSpec{
-					Description: fmt.Sprintf("S-%02d Bundled %s", serverID, leafNames[1]),
-				}); err != nil {
-					return err
-				}
-
-				if _, err := b.createConnection(ctx, wiringapi.ConnectionSpec{
-					Bundled: &wiringapi.ConnBundled{
-						Links: []wiringapi.ServerToSwitchLink{
-							{
-								Server: wiringapi.BasePortName{Port: b.nextServerPort(serverName)},
-								Switch: wiringapi.BasePortName{Port: b.nextSwitchPort(leafNames[1])},
-							},
-							{
-								Server: wiringapi.BasePortName{Port: b.nextServerPort(serverName)},
-								Switch: wiringapi.BasePortName{Port: b.nextSwitchPort(leafNames[1])},
-							},
-						},
-					},
-				}); err != nil {
+				if err := b.createBundledServer(ctx, serverName, fmt.Sprintf("S-%02d Bundled %s", serverID, leafNames[1]), leafNames[1]); err != nil {
 					return err
 				}
 
@@ -516,20 +381,7 @@ func (b *VLABBuilderDefault) Build(ctx context.Context, l *apiutil.Loader, fabri
 		for i := 0; i < int(b.UnbundledServers); i++ {
 			serverName := fmt.Sprintf("server-%02d", serverID)
 
-			if _, err := b.createServer(ctx, serverName, wiringapi.ServerSpec{
-				Description: fmt.Sprintf("S-%02d Unbundled %s", serverID, leafName),
-			}); err != nil {
-				return err
-			}
-
-			if _, err := b.createConnection(ctx, wiringapi.ConnectionSpec{
-				Unbundled: &wiringapi.ConnUnbundled{
-					Link: wiringapi.ServerToSwitchLink{
-						Server: wiringapi.BasePortName{Port: b.nextServerPort(serverName)},
-						Switch: wiringapi.BasePortName{Port: b.nextSwitchPort(leafName)},
-					},
-				},
-			}); err != nil {
+			if err := b.createUnbundledServer(ctx, serverName, fmt.Sprintf("S-%02d Unbundled %s", serverID, leafName), leafName); err != nil {
 				return err
 			}
 
@@ -539,26 +391,7 @@ func (b *VLABBuilderDefault) Build(ctx context.Context, l *apiutil.Loader, fabri
 		for i := 0; i < int(b.BundledServers); i++ {
 			serverName := fmt.Sprintf("server-%02d", serverID)
 
-			if _, err := b.createServer(ctx, serverName, wiringapi.ServerSpec{
-				Description: fmt.Sprintf("S-%02d Bundled %s", serverID, leafName),
-			}); err != nil {
-				return err
-			}
-
-			if _, err := b.createConnection(ctx, wiringapi.ConnectionSpec{
-				Bundled: &wiringapi.ConnBundled{
-					Links: []wiringapi.ServerToSwitchLink{
-						{
-							Server: wiringapi.BasePortName{Port: b.nextServerPort(serverName)},
-							Switch: wiringapi.BasePortName{Port: b.nextSwitchPort(leafName)},
-						},
-						{
-							Server: wiringapi.BasePortName{Port: b.nextServerPort(serverName)},
-							Switch: wiringapi.BasePortName{Port: b.nextSwitchPort(leafName)},
-						},
-					},
-				},
-			}); err != nil {
+			if err := b.createBundledServer(ctx, serverName, fmt.Sprintf("S-%02d Bundled %s", serverID, leafName), leafName); err != nil {
 				return err
 			}
 
@@ -574,30 +407,7 @@ func (b *VLABBuilderDefault) Build(ctx context.Context, l *apiutil.Loader, fabri
 		leaf2 := mhLeaves[mhIdx]
 		mhIdx = (mhIdx + 1) % len(mhLeaves)
 
-		if _, err := b.createServer(ctx, serverName, wiringapi.ServerSpec{
-			Description: fmt.Sprintf("S-%02d MultiHomed %s + %s", serverID, leaf1, leaf2),
-		}); err != nil {
-			return err
-		}
-
-		if _, err := b.createConnection(ctx, wiringapi.ConnectionSpec{
-			Unbundled: &wiringapi.ConnUnbundled{
-				Link: wiringapi.ServerToSwitchLink{
-					Server: wiringapi.BasePortName{Port: b.nextServerPort(serverName)},
-					Switch: wiringapi.BasePortName{Port: b.nextSwitchPort(leaf1)},
-				},
-			},
-		}); err != nil {
-			return err
-		}
-		if _, err := b.createConnection(ctx, wiringapi.ConnectionSpec{
-			Unbundled: &wiringapi.ConnUnbundled{
-				Link: wiringapi.ServerToSwitchLink{
-					Server: wiringapi.BasePortName{Port: b.nextServerPort(serverName)},
-					Switch: wiringapi.BasePortName{Port: b.nextSwitchPort(leaf2)},
-				},
-			},
-		}); err != nil {
+		if err := b.createMultiHomedServer(ctx, serverName, fmt.Sprintf("S-%02d MultiHomed %s + %s", serverID, leaf1, leaf2), leaf1, leaf2); err != nil {
 			return err
 		}
 
@@ -617,52 +427,14 @@ func (b *VLABBuilderDefault) Build(ctx context.Context, l *apiutil.Loader, fabri
 		switchID++
 
 		for leafID := uint8(1); leafID <= b.OrphanLeafsCount+totalESLAGLeafs; leafID++ {
-			leafName := fmt.Sprintf("leaf-%02d", leafID)
-
-			links := []wiringapi.FabricLink{}
-			for spinePortID := uint8(0); spinePortID < b.FabricLinksCount; spinePortID++ {
-				spinePort := b.nextSwitchPort(spineName)
-				leafPort := b.nextSwitchPort(leafName)
-
-				links = append(links, wiringapi.FabricLink{
-					Spine: wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: spinePort}},
-					Leaf:  wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: leafPort}},
-				})
-			}
-
-			if _, err := b.createConnection(ctx, wiringapi.ConnectionSpec{
-				Fabric: &wiringapi.ConnFabric{
-					Links: links,
-				},
-			}); err != nil {
+			if err := b.createFabricConnection(ctx, spineName, fmt.Sprintf("leaf-%02d", leafID), b.FabricLinksCount); err != nil {
 				return err
 			}
 		}
 
 		if isGw && spineID <= b.GatewayUplinks {
 			for _, gw := range gws {
-				switchPort := b.nextSwitchPort(spineName)
-
-				gwPort := fmt.Sprintf("%s/", gw.Name)
-				switch b.GatewayDriver {
-				case GatewayDriverKernel:
-					gwPort += fmt.Sprintf("enp2s%d", spineID)
-					// TODO enable after migrating dataplane to a new interface format
-					// gwPort += fmt.Sprintf("port%d", spineID)
-				case GatewayDriverDPDK:
-					gwPort += fmt.Sprintf("port%d", spineID)
-				}
-
-				if _, err := b.createConnection(ctx, wiringapi.ConnectionSpec{
-					Gateway: &wiringapi.ConnGateway{
-						Links: []wiringapi.GatewayLink{
-							{
-								Switch:  wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: switchPort}},
-								Gateway: wiringapi.ConnGatewayLinkGateway{BasePortName: wiringapi.BasePortName{Port: gwPort}},
-							},
-						},
-					},
-				}); err != nil {
+				if err := b.createGatewayConnection(ctx, spineName, gw.Name, b.GatewayDriver, spineID); err != nil {
 					return err
 				}
 			}
@@ -674,24 +446,7 @@ func (b *VLABBuilderDefault) Build(ctx context.Context, l *apiutil.Loader, fabri
 			leaf1Name := fmt.Sprintf("leaf-%02d", leaf1ID)
 
 			for leaf2ID := leaf1ID + 1; leaf2ID <= b.OrphanLeafsCount+totalESLAGLeafs; leaf2ID++ {
-				leaf2Name := fmt.Sprintf("leaf-%02d", leaf2ID)
-
-				links := []wiringapi.MeshLink{}
-				for leaf1PortID := uint8(0); leaf1PortID < b.MeshLinksCount; leaf1PortID++ {
-					leaf1Port := b.nextSwitchPort(leaf1Name)
-					leaf2Port := b.nextSwitchPort(leaf2Name)
-
-					links = append(links, wiringapi.MeshLink{
-						Leaf1: wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: leaf1Port}},
-						Leaf2: wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: leaf2Port}},
-					})
-				}
-
-				if _, err := b.createConnection(ctx, wiringapi.ConnectionSpec{
-					Mesh: &wiringapi.ConnMesh{
-						Links: links,
-					},
-				}); err != nil {
+				if err := b.createMeshConnection(ctx, leaf1Name, fmt.Sprintf("leaf-%02d", leaf2ID), b.MeshLinksCount); err != nil {
 					return err
 				}
 			}
@@ -702,29 +457,7 @@ func (b *VLABBuilderDefault) Build(ctx context.Context, l *apiutil.Loader, fabri
 		connectedLeafs := uint8(0)
 		for leafID := uint8(1); leafID <= b.OrphanLeafsCount+totalESLAGLeafs && connectedLeafs < b.GatewayUplinks; leafID++ {
 			for _, gw := range gws {
-				leafName := fmt.Sprintf("leaf-%02d", leafID)
-				switchPort := b.nextSwitchPort(leafName)
-
-				gwPort := fmt.Sprintf("%s/", gw.Name)
-				switch b.GatewayDriver {
-				case GatewayDriverKernel:
-					gwPort += fmt.Sprintf("enp2s%d", connectedLeafs+1)
-					// TODO enable after migrating dataplane to a new interface format
-					// gwPort += fmt.Sprintf("port%d", connectedLeafs+1)
-				case GatewayDriverDPDK:
-					gwPort += fmt.Sprintf("port%d", connectedLeafs+1)
-				}
-
-				if _, err := b.createConnection(ctx, wiringapi.ConnectionSpec{
-					Gateway: &wiringapi.ConnGateway{
-						Links: []wiringapi.GatewayLink{
-							{
-								Switch:  wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: switchPort}},
-								Gateway: wiringapi.ConnGatewayLinkGateway{BasePortName: wiringapi.BasePortName{Port: gwPort}},
-							},
-						},
-					},
-				}); err != nil {
+				if err := b.createGatewayConnection(ctx, fmt.Sprintf("leaf-%02d", leafID), gw.Name, b.GatewayDriver, connectedLeafs+1); err != nil {
 					return err
 				}
 			}
@@ -733,114 +466,7 @@ func (b *VLABBuilderDefault) Build(ctx context.Context, l *apiutil.Loader, fabri
 		}
 	}
 
-	externals := []vpcapi.External{}
-	extAsn := 64102
-
-	// Reserve disjoint /24 ranges per external type to keep auto-generated NAT
-	// pool CIDRs unique and non-overlapping: 192.168.91..99.0/24 for BGP (90+i)
-	// and 192.168.101..109.0/24 for static (100+i). 9 of each is plenty for any
-	// VLAB topology and avoids crossing into the adjacent range.
-	const maxExtBGPForNATPool = 9
-	const maxExtStaticForNATPool = 9
-	if b.ExtBGPCount > maxExtBGPForNATPool {
-		return fmt.Errorf("ExtBGPCount=%d exceeds %d (NAT pool CIDR scheme limit)", b.ExtBGPCount, maxExtBGPForNATPool) //nolint:goerr113
-	}
-	totalStaticExternals := int(b.ExtStaticCount) + int(b.ExtStaticProxyCount)
-	if totalStaticExternals > maxExtStaticForNATPool {
-		return fmt.Errorf("ExtStaticCount+ExtStaticProxyCount=%d exceeds %d (NAT pool CIDR scheme limit)", totalStaticExternals, maxExtStaticForNATPool) //nolint:goerr113
-	}
-
-	if b.ExtBGPCount > 0 {
-		inboundCommPrefix := 65102
-		communityRuleID := 1000
-
-		for i := uint8(1); i <= b.ExtBGPCount; i++ {
-			externalName := fmt.Sprintf("ext-bgp-%02d", i)
-			externalSpec := vpcapi.ExternalSpec{
-				IPv4Namespace:     "default",
-				InboundCommunity:  fmt.Sprintf("%d:%d", inboundCommPrefix, communityRuleID),
-				OutboundCommunity: fmt.Sprintf("%d:%d", extAsn, communityRuleID),
-			}
-			anns := map[string]string{
-				extBGPNATAnnotation: fmt.Sprintf("192.168.%d.0/24", 90+int(i)),
-			}
-			ext, err := b.createExternal(ctx, externalName, externalSpec, anns)
-			if err != nil {
-				return err
-			}
-			externals = append(externals, *ext)
-			communityRuleID += 100
-		}
-	}
-
-	if totalStaticExternals > 0 {
-		var externalName string
-		for i := 1; i <= totalStaticExternals; i++ {
-			var anns map[string]string
-			if i <= int(b.ExtStaticProxyCount) {
-				externalName = fmt.Sprintf("ext-sp-%02d", i)
-				// Proxy static externals have no SwitchIP, so the NAT pool return route
-				// can't be installed on the virtual external. Skip the annotation so NAT
-				// tests skip cleanly rather than running with broken data-plane wiring.
-			} else {
-				externalName = fmt.Sprintf("ext-snp-%02d", i)
-				anns = map[string]string{
-					extStaticNATPoolAnnotation: fmt.Sprintf("192.168.%d.0/24", 100+i),
-				}
-			}
-			externalSpec := vpcapi.ExternalSpec{
-				IPv4Namespace: "default",
-				Static: &vpcapi.ExternalStaticSpec{
-					Prefixes: []string{"0.0.0.0/0"},
-				},
-			}
-			ext, err := b.createExternal(ctx, externalName, externalSpec, anns)
-			if err != nil {
-				return err
-			}
-			externals = append(externals, *ext)
-		}
-	}
-
-	connOctet := uint8(0)
-	for _, conn := range externalConns {
-		connOctet++
-		vlanID := uint16(10)
-		for _, ext := range externals {
-			extAttachName := fmt.Sprintf("%s--%s", conn.Spec.External.Link.Switch.DeviceName(), ext.Name)
-			extAttachSpec := vpcapi.ExternalAttachmentSpec{
-				External:   ext.Name,
-				Connection: conn.Name,
-			}
-			if ext.Spec.Static != nil {
-				staticSpec := &vpcapi.ExternalAttachmentStatic{
-					RemoteIP: fmt.Sprintf("100.%d.%d.1", connOctet, vlanID),
-					VLAN:     vlanID,
-				}
-				if strings.HasPrefix(ext.Name, "ext-sp") {
-					staticSpec.Proxy = true
-				} else {
-					staticSpec.IP = fmt.Sprintf("100.%d.%d.2/24", connOctet, vlanID)
-				}
-				extAttachSpec.Static = staticSpec
-			} else {
-				extAttachSpec.Switch = vpcapi.ExternalAttachmentSwitch{
-					VLAN: vlanID,
-					IP:   fmt.Sprintf("100.%d.%d.1/24", connOctet, vlanID),
-				}
-				extAttachSpec.Neighbor = vpcapi.ExternalAttachmentNeighbor{
-					ASN: uint32(extAsn),
-					IP:  fmt.Sprintf("100.%d.%d.6", connOctet, vlanID),
-				}
-			}
-			vlanID += 10
-			if _, err := b.createExternalAttach(ctx, extAttachName, extAttachSpec); err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
+	return b.createExternals(ctx, b.ExtBGPCount, b.ExtStaticCount, b.ExtStaticProxyCount, externalConns)
 }
 
 type VLABBuilderGPURail struct {
@@ -877,38 +503,8 @@ func (b *VLABBuilderGPURail) Build(ctx context.Context, l *apiutil.Loader, fabri
 	}
 	slog.Info(">>>", "units", b.ScalableUnits, "vpcCount", b.VPCs, "serversPerVPCPerUnit", b.ServersPerVPCPerUnit, "p2p", b.P2P)
 
-	if err := b.data.Add(ctx, &wiringapi.VLANNamespace{
-		TypeMeta: kmetav1.TypeMeta{
-			Kind:       wiringapi.KindVLANNamespace,
-			APIVersion: wiringapi.GroupVersion.String(),
-		},
-		ObjectMeta: kmetav1.ObjectMeta{
-			Name: "default",
-		},
-		Spec: wiringapi.VLANNamespaceSpec{
-			Ranges: []meta.VLANRange{
-				{From: startingVLAN, To: 2999},
-			},
-		},
-	}); err != nil {
-		return fmt.Errorf("creating VLAN namespace: %w", err) //nolint:goerr113
-	}
-
-	if err := b.data.Add(ctx, &vpcapi.IPv4Namespace{
-		TypeMeta: kmetav1.TypeMeta{
-			Kind:       vpcapi.KindIPv4Namespace,
-			APIVersion: vpcapi.GroupVersion.String(),
-		},
-		ObjectMeta: kmetav1.ObjectMeta{
-			Name: "default",
-		},
-		Spec: vpcapi.IPv4NamespaceSpec{
-			Subnets: []string{
-				"10.0.0.0/8",
-			},
-		},
-	}); err != nil {
-		return fmt.Errorf("creating IPv4 namespace: %w", err) //nolint:goerr113
+	if err := b.createDefaultNamespaces(ctx, startingVLAN, "10.0.0.0/8"); err != nil {
+		return err
 	}
 
 	b.ifaceTracker = map[string]uint8{}
@@ -1053,6 +649,394 @@ func (b *VLABBuilderBase) addExternalConnection(ctx context.Context, extConnList
 	return append(extConnList, *extConn), nil
 }
 
+// createDefaultNamespaces creates default VLAN namespace starting from the vlanFrom and default IPv4 namespace
+func (b *VLABBuilderBase) createDefaultNamespaces(ctx context.Context, vlanFrom uint16, subnet string) error {
+	if err := b.data.Add(ctx, &wiringapi.VLANNamespace{
+		TypeMeta: kmetav1.TypeMeta{
+			Kind:       wiringapi.KindVLANNamespace,
+			APIVersion: wiringapi.GroupVersion.String(),
+		},
+		ObjectMeta: kmetav1.ObjectMeta{
+			Name: "default",
+		},
+		Spec: wiringapi.VLANNamespaceSpec{
+			Ranges: []meta.VLANRange{
+				{From: vlanFrom, To: 2999},
+			},
+		},
+	}); err != nil {
+		return fmt.Errorf("creating VLAN namespace: %w", err) //nolint:goerr113
+	}
+
+	if err := b.data.Add(ctx, &vpcapi.IPv4Namespace{
+		TypeMeta: kmetav1.TypeMeta{
+			Kind:       vpcapi.KindIPv4Namespace,
+			APIVersion: vpcapi.GroupVersion.String(),
+		},
+		ObjectMeta: kmetav1.ObjectMeta{
+			Name: "default",
+		},
+		Spec: vpcapi.IPv4NamespaceSpec{
+			Subnets: []string{
+				subnet,
+			},
+		},
+	}); err != nil {
+		return fmt.Errorf("creating IPv4 namespace: %w", err) //nolint:goerr113
+	}
+
+	return nil
+}
+
+// gatewayNodes returns gateway nodes sorted by name
+func gatewayNodes(nodes []fabapi.FabNode) []fabapi.FabNode {
+	gws := []fabapi.FabNode{}
+	for _, node := range nodes {
+		if slices.Contains(node.Spec.Roles, fabapi.NodeRoleGateway) {
+			gws = append(gws, node)
+		}
+	}
+	slices.SortFunc(gws, func(a, b fabapi.FabNode) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return gws
+}
+
+func gatewayLogs(logLevel string, tags map[string]string) (gwapi.GatewayLogs, error) {
+	logs := gwapi.GatewayLogs{
+		Default: gwapi.GatewayLogLevel(logLevel),
+		Tags:    map[string]gwapi.GatewayLogLevel{},
+	}
+	if !slices.Contains(gwapi.GatewayLogLevels, logs.Default) {
+		return logs, fmt.Errorf("invalid gateway log level %s", logLevel) //nolint:goerr113
+	}
+
+	for tagTarget, tagLevel := range tags {
+		gwTagLevel := gwapi.GatewayLogLevel(tagLevel)
+		if !slices.Contains(gwapi.GatewayLogLevels, gwTagLevel) {
+			return logs, fmt.Errorf("invalid gateway log level %s for tag target %s", tagLevel, tagTarget) //nolint:goerr113
+		}
+		logs.Tags[tagTarget] = gwTagLevel
+	}
+
+	return logs, nil
+}
+
+// createGateways creates default gateway group and gateways with the uplinks interfaces, neighbors will be later
+// hydrated in based on the gateway connections
+func (b *VLABBuilderBase) createGateways(ctx context.Context, gws []fabapi.FabNode, uplinks uint8, driver string, workers uint8, logs gwapi.GatewayLogs) error {
+	if len(gws) == 0 {
+		return nil
+	}
+
+	if _, err := b.createGatewayGroup(ctx, gwapi.DefaultGatewayGroup); err != nil {
+		return err
+	}
+
+	for _, gw := range gws {
+		ifaces := map[string]gwapi.GatewayInterface{}
+		for i := uint8(1); i <= uplinks; i++ {
+			switch driver {
+			case GatewayDriverKernel:
+				ifaces[fmt.Sprintf("enp2s%d", i)] = gwapi.GatewayInterface{}
+				// TODO enable after migrating dataplane to a new interface format
+				// ifaces[fmt.Sprintf("port%d", i)] = gwapi.GatewayInterface{
+				// 	Kernel: fmt.Sprintf("enp2s%d", i),
+				// }
+			case GatewayDriverDPDK:
+				ifaces[fmt.Sprintf("port%d", i)] = gwapi.GatewayInterface{
+					PCI: fmt.Sprintf("0000:02:%02d.0", i),
+				}
+			}
+		}
+
+		if _, err := b.createGateway(ctx, gw.Name, gwapi.GatewaySpec{
+			Groups: []gwapi.GatewayGroupMembership{
+				{Name: gwapi.DefaultGatewayGroup},
+			},
+			Interfaces: ifaces,
+			Workers:    workers,
+			Logs:       logs,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createGatewayConnection connects next switch port to the gateway uplink with the specified ID
+func (b *VLABBuilderBase) createGatewayConnection(ctx context.Context, switchName, gwName, driver string, uplinkID uint8) error {
+	switchPort := b.nextSwitchPort(switchName)
+
+	gwPort := fmt.Sprintf("%s/", gwName)
+	switch driver {
+	case GatewayDriverKernel:
+		gwPort += fmt.Sprintf("enp2s%d", uplinkID)
+		// TODO enable after migrating dataplane to a new interface format
+		// gwPort += fmt.Sprintf("port%d", uplinkID)
+	case GatewayDriverDPDK:
+		gwPort += fmt.Sprintf("port%d", uplinkID)
+	}
+
+	_, err := b.createConnection(ctx, wiringapi.ConnectionSpec{
+		Gateway: &wiringapi.ConnGateway{
+			Links: []wiringapi.GatewayLink{
+				{
+					Switch:  wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: switchPort}},
+					Gateway: wiringapi.ConnGatewayLinkGateway{BasePortName: wiringapi.BasePortName{Port: gwPort}},
+				},
+			},
+		},
+	})
+
+	return err
+}
+
+func (b *VLABBuilderBase) createFabricConnection(ctx context.Context, spineName, leafName string, linksCount uint8) error {
+	links := []wiringapi.FabricLink{}
+	for range linksCount {
+		spinePort := b.nextSwitchPort(spineName)
+		leafPort := b.nextSwitchPort(leafName)
+
+		links = append(links, wiringapi.FabricLink{
+			Spine: wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: spinePort}},
+			Leaf:  wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: leafPort}},
+		})
+	}
+
+	_, err := b.createConnection(ctx, wiringapi.ConnectionSpec{
+		Fabric: &wiringapi.ConnFabric{
+			Links: links,
+		},
+	})
+
+	return err
+}
+
+func (b *VLABBuilderBase) createMeshConnection(ctx context.Context, leaf1Name, leaf2Name string, linksCount uint8) error {
+	links := []wiringapi.MeshLink{}
+	for range linksCount {
+		leaf1Port := b.nextSwitchPort(leaf1Name)
+		leaf2Port := b.nextSwitchPort(leaf2Name)
+
+		links = append(links, wiringapi.MeshLink{
+			Leaf1: wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: leaf1Port}},
+			Leaf2: wiringapi.ConnFabricLinkSwitch{BasePortName: wiringapi.BasePortName{Port: leaf2Port}},
+		})
+	}
+
+	_, err := b.createConnection(ctx, wiringapi.ConnectionSpec{
+		Mesh: &wiringapi.ConnMesh{
+			Links: links,
+		},
+	})
+
+	return err
+}
+
+// createESLAGServer creates server with a single ESLAG connection to all of the specified leaves
+func (b *VLABBuilderBase) createESLAGServer(ctx context.Context, serverName, description string, leafNames []string) error {
+	if _, err := b.createServer(ctx, serverName, wiringapi.ServerSpec{
+		Description: description,
+	}); err != nil {
+		return err
+	}
+
+	links := []wiringapi.ServerToSwitchLink{}
+	for _, leafName := range leafNames {
+		links = append(links, wiringapi.ServerToSwitchLink{
+			Server: wiringapi.BasePortName{Port: b.nextServerPort(serverName)},
+			Switch: wiringapi.BasePortName{Port: b.nextSwitchPort(leafName)},
+		})
+	}
+
+	_, err := b.createConnection(ctx, wiringapi.ConnectionSpec{
+		ESLAG: &wiringapi.ConnESLAG{
+			Links: links,
+		},
+	})
+
+	return err
+}
+
+func (b *VLABBuilderBase) createUnbundledServer(ctx context.Context, serverName, description, leafName string) error {
+	if _, err := b.createServer(ctx, serverName, wiringapi.ServerSpec{
+		Description: description,
+	}); err != nil {
+		return err
+	}
+
+	return b.createUnbundledConnection(ctx, serverName, leafName)
+}
+
+// createMultiHomedServer creates server with two unbundled connections to two different leaves
+func (b *VLABBuilderBase) createMultiHomedServer(ctx context.Context, serverName, description, leaf1Name, leaf2Name string) error {
+	if _, err := b.createServer(ctx, serverName, wiringapi.ServerSpec{
+		Description: description,
+	}); err != nil {
+		return err
+	}
+
+	if err := b.createUnbundledConnection(ctx, serverName, leaf1Name); err != nil {
+		return err
+	}
+
+	return b.createUnbundledConnection(ctx, serverName, leaf2Name)
+}
+
+func (b *VLABBuilderBase) createUnbundledConnection(ctx context.Context, serverName, leafName string) error {
+	_, err := b.createConnection(ctx, wiringapi.ConnectionSpec{
+		Unbundled: &wiringapi.ConnUnbundled{
+			Link: wiringapi.ServerToSwitchLink{
+				Server: wiringapi.BasePortName{Port: b.nextServerPort(serverName)},
+				Switch: wiringapi.BasePortName{Port: b.nextSwitchPort(leafName)},
+			},
+		},
+	})
+
+	return err
+}
+
+// createBundledServer creates server with a bundled connection of two links to the same leaf
+func (b *VLABBuilderBase) createBundledServer(ctx context.Context, serverName, description, leafName string) error {
+	if _, err := b.createServer(ctx, serverName, wiringapi.ServerSpec{
+		Description: description,
+	}); err != nil {
+		return err
+	}
+
+	_, err := b.createConnection(ctx, wiringapi.ConnectionSpec{
+		Bundled: &wiringapi.ConnBundled{
+			Links: []wiringapi.ServerToSwitchLink{
+				{
+					Server: wiringapi.BasePortName{Port: b.nextServerPort(serverName)},
+					Switch: wiringapi.BasePortName{Port: b.nextSwitchPort(leafName)},
+				},
+				{
+					Server: wiringapi.BasePortName{Port: b.nextServerPort(serverName)},
+					Switch: wiringapi.BasePortName{Port: b.nextSwitchPort(leafName)},
+				},
+			},
+		},
+	})
+
+	return err
+}
+
+// createExternals creates BGP and static externals and attaches all of them to each of the external connections
+func (b *VLABBuilderBase) createExternals(ctx context.Context, bgpCount, staticCount, staticProxyCount uint8, externalConns []wiringapi.Connection) error {
+	externals := []vpcapi.External{}
+	extAsn := 64102
+
+	// Reserve disjoint /24 ranges per external type to keep auto-generated NAT
+	// pool CIDRs unique and non-overlapping: 192.168.91..99.0/24 for BGP (90+i)
+	// and 192.168.101..109.0/24 for static (100+i). 9 of each is plenty for any
+	// VLAB topology and avoids crossing into the adjacent range.
+	const maxExtBGPForNATPool = 9
+	const maxExtStaticForNATPool = 9
+	if bgpCount > maxExtBGPForNATPool {
+		return fmt.Errorf("ExtBGPCount=%d exceeds %d (NAT pool CIDR scheme limit)", bgpCount, maxExtBGPForNATPool) //nolint:goerr113
+	}
+	totalStaticExternals := int(staticCount) + int(staticProxyCount)
+	if totalStaticExternals > maxExtStaticForNATPool {
+		return fmt.Errorf("ExtStaticCount+ExtStaticProxyCount=%d exceeds %d (NAT pool CIDR scheme limit)", totalStaticExternals, maxExtStaticForNATPool) //nolint:goerr113
+	}
+
+	if bgpCount > 0 {
+		inboundCommPrefix := 65102
+		communityRuleID := 1000
+
+		for i := uint8(1); i <= bgpCount; i++ {
+			externalName := fmt.Sprintf("ext-bgp-%02d", i)
+			externalSpec := vpcapi.ExternalSpec{
+				IPv4Namespace:     "default",
+				InboundCommunity:  fmt.Sprintf("%d:%d", inboundCommPrefix, communityRuleID),
+				OutboundCommunity: fmt.Sprintf("%d:%d", extAsn, communityRuleID),
+			}
+			anns := map[string]string{
+				extBGPNATAnnotation: fmt.Sprintf("192.168.%d.0/24", 90+int(i)),
+			}
+			ext, err := b.createExternal(ctx, externalName, externalSpec, anns)
+			if err != nil {
+				return err
+			}
+			externals = append(externals, *ext)
+			communityRuleID += 100
+		}
+	}
+
+	if totalStaticExternals > 0 {
+		var externalName string
+		for i := 1; i <= totalStaticExternals; i++ {
+			var anns map[string]string
+			if i <= int(staticProxyCount) {
+				externalName = fmt.Sprintf("ext-sp-%02d", i)
+				// Proxy static externals have no SwitchIP, so the NAT pool return route
+				// can't be installed on the virtual external. Skip the annotation so NAT
+				// tests skip cleanly rather than running with broken data-plane wiring.
+			} else {
+				externalName = fmt.Sprintf("ext-snp-%02d", i)
+				anns = map[string]string{
+					extStaticNATPoolAnnotation: fmt.Sprintf("192.168.%d.0/24", 100+i),
+				}
+			}
+			externalSpec := vpcapi.ExternalSpec{
+				IPv4Namespace: "default",
+				Static: &vpcapi.ExternalStaticSpec{
+					Prefixes: []string{"0.0.0.0/0"},
+				},
+			}
+			ext, err := b.createExternal(ctx, externalName, externalSpec, anns)
+			if err != nil {
+				return err
+			}
+			externals = append(externals, *ext)
+		}
+	}
+
+	connOctet := uint8(0)
+	for _, conn := range externalConns {
+		connOctet++
+		vlanID := uint16(10)
+		for _, ext := range externals {
+			extAttachName := fmt.Sprintf("%s--%s", conn.Spec.External.Link.Switch.DeviceName(), ext.Name)
+			extAttachSpec := vpcapi.ExternalAttachmentSpec{
+				External:   ext.Name,
+				Connection: conn.Name,
+			}
+			if ext.Spec.Static != nil {
+				staticSpec := &vpcapi.ExternalAttachmentStatic{
+					RemoteIP: fmt.Sprintf("100.%d.%d.1", connOctet, vlanID),
+					VLAN:     vlanID,
+				}
+				if strings.HasPrefix(ext.Name, "ext-sp") {
+					staticSpec.Proxy = true
+				} else {
+					staticSpec.IP = fmt.Sprintf("100.%d.%d.2/24", connOctet, vlanID)
+				}
+				extAttachSpec.Static = staticSpec
+			} else {
+				extAttachSpec.Switch = vpcapi.ExternalAttachmentSwitch{
+					VLAN: vlanID,
+					IP:   fmt.Sprintf("100.%d.%d.1/24", connOctet, vlanID),
+				}
+				extAttachSpec.Neighbor = vpcapi.ExternalAttachmentNeighbor{
+					ASN: uint32(extAsn),
+					IP:  fmt.Sprintf("100.%d.%d.6", connOctet, vlanID),
+				}
+			}
+			vlanID += 10
+			if _, err := b.createExternalAttach(ctx, extAttachName, extAttachSpec); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 func (b *VLABBuilderBase) nextSwitchPort(switchName string) string {
 	ifaceID := b.ifaceTracker[switchName]
 	portName := fmt.Sprintf("%s/E1/%d", switchName, ifaceID+1)
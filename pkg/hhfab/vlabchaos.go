@@ -0,0 +1,695 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.githedgehog.com/fabricator/pkg/util/sshutil"
+	"golang.org/x/sync/errgroup"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	VLABCmdTC = "tc"
+
+	// chaosLoopback is where qemu UDP sockets of the links between VMs are, netem for them is applied per link
+	// using the prio qdisc band with u32 filters on the link ports, first 3 bands are the default prio ones
+	chaosLoopback       = "lo"
+	chaosPrioBands      = 16
+	chaosNetemFirstBand = 4
+
+	DefaultChaosPingsCount     = 3
+	DefaultChaosCheckInterval  = 5 * time.Second
+	DefaultChaosRecoverTimeout = 10 * time.Minute
+)
+
+type ChaosFaultType string
+
+const (
+	ChaosFaultLink         ChaosFaultType = "link"
+	ChaosFaultNetem        ChaosFaultType = "netem"
+	ChaosFaultVMPause      ChaosFaultType = "vm-pause"
+	ChaosFaultVMReset      ChaosFaultType = "vm-reset"
+	ChaosFaultAgentStop    ChaosFaultType = "agent-stop"
+	ChaosFaultAgentRestart ChaosFaultType = "agent-restart"
+)
+
+var ChaosFaultTypes = []ChaosFaultType{
+	ChaosFaultLink,
+	ChaosFaultNetem,
+	ChaosFaultVMPause,
+	ChaosFaultVMReset,
+	ChaosFaultAgentStop,
+	ChaosFaultAgentRestart,
+}
+
+// ChaosSchedule is a list of faults to inject into the running VLAB while connectivity is continuously checked
+type ChaosSchedule struct {
+	PingsCount     int              `json:"pingsCount,omitempty"`
+	CheckInterval  kmetav1.Duration `json:"checkInterval,omitempty"`
+	RecoverTimeout kmetav1.Duration `json:"recoverTimeout,omitempty"`
+	Faults         []ChaosFault     `json:"faults"`
+}
+
+type ChaosFault struct {
+	Name     string           `json:"name,omitempty"`
+	Type     ChaosFaultType   `json:"type"`
+	Target   string           `json:"target"`             // vm/port for link and netem, vm or switch name for the rest
+	At       kmetav1.Duration `json:"at,omitempty"`       // offset from the chaos start
+	Duration kmetav1.Duration `json:"duration,omitempty"` // how long the fault is active, ignored for instant faults
+	Flaps    int              `json:"flaps,omitempty"`    // link only: number of down/up cycles within duration
+	Delay    kmetav1.Duration `json:"delay,omitempty"`    // netem only
+	Jitter   kmetav1.Duration `json:"jitter,omitempty"`   // netem only
+	Loss     float64          `json:"loss,omitempty"`     // netem only: percent
+
+	band int // netem only: prio band used for the link on loopback
+}
+
+type ChaosOpts struct {
+	ReportFile string
+}
+
+type ChaosFaultResult struct {
+	Name          string         `json:"name"`
+	Type          ChaosFaultType `json:"type"`
+	Target        string         `json:"target"`
+	InjectedAt    time.Time      `json:"injectedAt"`
+	ClearedAt     time.Time      `json:"clearedAt"`
+	Impacted      bool           `json:"impacted"`
+	Recovered     bool           `json:"recovered"`
+	TimeToRecover time.Duration  `json:"timeToRecover"`
+	Error         string         `json:"error,omitempty"`
+}
+
+type chaosCheck struct {
+	Start, End time.Time
+	Passed     bool
+}
+
+func (f ChaosFault) instant() bool {
+	return f.Type == ChaosFaultVMReset || f.Type == ChaosFaultAgentRestart
+}
+
+func LoadChaosSchedule(path string) (*ChaosSchedule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading chaos schedule: %w", err)
+	}
+
+	schedule := &ChaosSchedule{}
+	if err := yaml.UnmarshalStrict(data, schedule); err != nil {
+		return nil, fmt.Errorf("unmarshaling chaos schedule: %w", err)
+	}
+
+	if err := schedule.Validate(); err != nil {
+		return nil, fmt.Errorf("validating chaos schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+func (s *ChaosSchedule) Validate() error {
+	if s.PingsCount == 0 {
+		s.PingsCount = DefaultChaosPingsCount
+	}
+	if s.CheckInterval.Duration == 0 {
+		s.CheckInterval.Duration = DefaultChaosCheckInterval
+	}
+	if s.RecoverTimeout.Duration == 0 {
+		s.RecoverTimeout.Duration = DefaultChaosRecoverTimeout
+	}
+
+	if len(s.Faults) == 0 {
+		return fmt.Errorf("no faults") //nolint:goerr113
+	}
+
+	names := map[string]bool{}
+	band := chaosNetemFirstBand
+	for idx := range s.Faults {
+		f := &s.Faults[idx]
+		if f.Name == "" {
+			f.Name = fmt.Sprintf("%02d-%s-%s", idx+1, f.Type, strings.ReplaceAll(f.Target, "/", "-"))
+		}
+		if names[f.Name] {
+			return fmt.Errorf("duplicate fault name %q", f.Name) //nolint:goerr113
+		}
+		names[f.Name] = true
+
+		if !slices.Contains(ChaosFaultTypes, f.Type) {
+			return fmt.Errorf("fault %q: unsupported type %q", f.Name, f.Type) //nolint:goerr113
+		}
+		if f.Target == "" {
+			return fmt.Errorf("fault %q: target is required", f.Name) //nolint:goerr113
+		}
+		if f.At.Duration < 0 || f.Duration.Duration < 0 {
+			return fmt.Errorf("fault %q: negative at or duration", f.Name) //nolint:goerr113
+		}
+		if !f.instant() && f.Duration.Duration == 0 {
+			return fmt.Errorf("fault %q: duration is required", f.Name) //nolint:goerr113
+		}
+		if (f.Type == ChaosFaultLink || f.Type == ChaosFaultNetem) && !strings.Contains(f.Target, "/") {
+			return fmt.Errorf("fault %q: %s target should be <vm>/<port>", f.Name, f.Type) //nolint:goerr113
+		}
+		if f.Type != ChaosFaultLink && f.Flaps != 0 {
+			return fmt.Errorf("fault %q: flaps are only supported for link faults", f.Name) //nolint:goerr113
+		}
+		if f.Type == ChaosFaultNetem {
+			if f.Delay.Duration == 0 && f.Loss == 0 {
+				return fmt.Errorf("fault %q: netem requires delay or loss", f.Name) //nolint:goerr113
+			}
+			if f.Loss < 0 || f.Loss > 100 {
+				return fmt.Errorf("fault %q: loss should be in 0-100%%", f.Name) //nolint:goerr113
+			}
+			if band >= chaosPrioBands {
+				return fmt.Errorf("fault %q: at most %d netem faults are supported", f.Name, chaosPrioBands-chaosNetemFirstBand) //nolint:goerr113
+			}
+			f.band = band
+			band++
+		} else if f.Delay.Duration != 0 || f.Jitter.Duration != 0 || f.Loss != 0 {
+			return fmt.Errorf("fault %q: delay, jitter and loss are only supported for netem faults", f.Name) //nolint:goerr113
+		}
+	}
+
+	return nil
+}
+
+// vmNICParam returns value of the parameter of the qemu arg (e.g. -netdev or -device) from the VM NIC qemu args
+func vmNICParam(nic, arg, key string) string {
+	fields := strings.Fields(nic)
+	for idx := 0; idx+1 < len(fields); idx++ {
+		if fields[idx] != arg {
+			continue
+		}
+
+		for _, param := range strings.Split(fields[idx+1], ",") {
+			if v, ok := strings.CutPrefix(param, key+"="); ok {
+				return v
+			}
+		}
+	}
+
+	return ""
+}
+
+type vmNIC struct {
+	vm     string
+	netdev string
+}
+
+// chaosVMNIC returns the VM NIC qemu args by the VM port name
+func chaosVMNIC(vlab *VLAB, target string) (string, error) {
+	vmName, port, _ := strings.Cut(target, "/")
+
+	vmIdx := slices.IndexFunc(vlab.VMs, func(vm VM) bool { return vm.Name == vmName })
+	if vmIdx < 0 {
+		return "", fmt.Errorf("vm %q not found", vmName) //nolint:goerr113
+	}
+	vm := vlab.VMs[vmIdx]
+
+	nicID, err := getNICID(port)
+	if err != nil {
+		return "", fmt.Errorf("getting NIC ID: %w", err)
+	}
+	if int(nicID) >= len(vm.NICs) { //nolint:gosec
+		return "", fmt.Errorf("vm %q has no NIC %q", vmName, port) //nolint:goerr113
+	}
+
+	return vm.NICs[nicID], nil
+}
+
+// chaosLinkEnds resolves both ends of the link by the VM port name, peer is found by the direct NIC socket ports
+func chaosLinkEnds(vlab *VLAB, target string) ([]vmNIC, error) {
+	vmName, port, _ := strings.Cut(target, "/")
+
+	nic, err := chaosVMNIC(vlab, target)
+	if err != nil {
+		return nil, err
+	}
+
+	netdev := vmNICParam(nic, "-netdev", "id")
+	if netdev == "" {
+		return nil, fmt.Errorf("vm %q NIC %q has no netdev", vmName, port) //nolint:goerr113
+	}
+
+	ends := []vmNIC{{vm: vmName, netdev: netdev}}
+
+	local := vmNICParam(nic, "-netdev", "localaddr")
+	if local == "" {
+		return ends, nil
+	}
+	for _, other := range vlab.VMs {
+		for _, otherNIC := range other.NICs {
+			if vmNICParam(otherNIC, "-netdev", "udp") == local {
+				ends = append(ends, vmNIC{vm: other.Name, netdev: vmNICParam(otherNIC, "-netdev", "id")})
+			}
+		}
+	}
+
+	return ends, nil
+}
+
+// chaosNetemTarget resolves where netem should be applied for the link by the VM port name, it's the NIC tap
+// for the management NICs and loopback with the UDP ports of both link ends for the links between VMs
+func chaosNetemTarget(vlab *VLAB, target string) (string, []string, error) {
+	nic, err := chaosVMNIC(vlab, target)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if tap := vmNICParam(nic, "-netdev", "ifname"); strings.HasPrefix(tap, VLABTapPrefix) {
+		return tap, nil, nil
+	}
+
+	local, remote := vmNICParam(nic, "-netdev", "localaddr"), vmNICParam(nic, "-netdev", "udp")
+	if local == "" || remote == "" {
+		return "", nil, fmt.Errorf("%s is neither a %s* tap nor a link to another VM", target, VLABTapPrefix) //nolint:goerr113
+	}
+
+	ports := []string{}
+	for _, addr := range []string{local, remote} {
+		_, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return "", nil, fmt.Errorf("parsing %s socket address %q: %w", target, addr, err)
+		}
+		ports = append(ports, port)
+	}
+
+	return chaosLoopback, ports, nil
+}
+
+func (f ChaosFault) netemArgs() []string {
+	args := []string{"netem"}
+	if f.Delay.Duration > 0 {
+		args = append(args, "delay", fmt.Sprintf("%dms", f.Delay.Milliseconds()))
+		if f.Jitter.Duration > 0 {
+			args = append(args, fmt.Sprintf("%dms", f.Jitter.Milliseconds()))
+		}
+	}
+	if f.Loss > 0 {
+		args = append(args, "loss", strconv.FormatFloat(f.Loss, 'f', -1, 64)+"%")
+	}
+
+	return args
+}
+
+func (c *Config) chaosSetNetem(ctx context.Context, vlab *VLAB, f ChaosFault, enable bool) error {
+	dev, ports, err := chaosNetemTarget(vlab, f.Target)
+	if err != nil {
+		return err
+	}
+
+	if len(ports) == 0 {
+		if !enable {
+			return execCmd(ctx, true, "", VLABCmdTC, []string{"qdisc", "del", "dev", dev, "root"}, "dev", dev)
+		}
+
+		return execCmd(ctx, true, "", VLABCmdTC, append([]string{"qdisc", "replace", "dev", dev, "root"}, f.netemArgs()...), "dev", dev)
+	}
+
+	class := fmt.Sprintf("1:%x", f.band)
+	prio := strconv.Itoa(f.band)
+	if !enable {
+		return errors.Join(
+			execCmd(ctx, true, "", VLABCmdTC, []string{"filter", "del", "dev", dev, "parent", "1:", "protocol", "ip", "prio", prio}, "dev", dev),
+			execCmd(ctx, true, "", VLABCmdTC, []string{"qdisc", "del", "dev", dev, "parent", class}, "dev", dev),
+		)
+	}
+
+	args := append([]string{"qdisc", "replace", "dev", dev, "parent", class, "handle", fmt.Sprintf("%x:", 0x100+f.band)}, f.netemArgs()...)
+	if err := execCmd(ctx, true, "", VLABCmdTC, args, "dev", dev); err != nil {
+		return err
+	}
+	// both link ends are matched by the destination port so netem is applied in both directions
+	for _, port := range ports {
+		if err := execCmd(ctx, true, "", VLABCmdTC, []string{
+			"filter", "add", "dev", dev, "parent", "1:", "protocol", "ip", "prio", prio, "u32",
+			"match", "ip", "protocol", "17", "0xff", "match", "ip", "dport", port, "0xffff", "flowid", class,
+		}, "dev", dev, "port", port); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) vmQMP(ctx context.Context, vmName string, cmd string, args any) error {
+	q, err := dialQMP(ctx, filepath.Join(c.WorkDir, VLABDir, VLABVMsDir, vmName), vmName)
+	if err != nil {
+		return err
+	}
+	defer q.Close()
+
+	return q.execute(cmd, args, nil)
+}
+
+func (c *Config) chaosSetLink(ctx context.Context, vlab *VLAB, target string, up bool) error {
+	ends, err := chaosLinkEnds(vlab, target)
+	if err != nil {
+		return err
+	}
+
+	for _, end := range ends {
+		// netns servers have no QMP, switch side going down is enough
+		if slices.ContainsFunc(vlab.VMs, func(vm VM) bool { return vm.Name == end.vm && vm.Netns }) {
+			continue
+		}
+
+		if err := c.vmQMP(ctx, end.vm, "set_link", map[string]any{"name": end.netdev, "up": up}); err != nil {
+			return fmt.Errorf("setting link %s/%s: %w", end.vm, end.netdev, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) chaosInject(ctx context.Context, vlab *VLAB, f ChaosFault) error {
+	switch f.Type {
+	case ChaosFaultLink:
+		if f.Flaps == 0 {
+			return c.chaosSetLink(ctx, vlab, f.Target, false)
+		}
+
+		// flapping is done within the fault duration, link is up when it's cleared
+		step := f.Duration.Duration / time.Duration(2*f.Flaps)
+		for idx := 0; idx < 2*f.Flaps-1; idx++ {
+			if err := c.chaosSetLink(ctx, vlab, f.Target, idx%2 == 1); err != nil {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("flapping: %w", ctx.Err())
+			case <-time.After(step):
+			}
+		}
+
+		return nil
+	case ChaosFaultNetem:
+		return c.chaosSetNetem(ctx, vlab, f, true)
+	case ChaosFaultVMPause:
+		return c.vmQMP(ctx, f.Target, "stop", nil)
+	case ChaosFaultVMReset:
+		// hard reset of the VM (like power cycle) while keeping the qemu process managed by VLAB
+		return c.vmQMP(ctx, f.Target, "system_reset", nil)
+	case ChaosFaultAgentStop, ChaosFaultAgentRestart:
+		ssh, err := c.SSH(ctx, vlab, f.Target)
+		if err != nil {
+			return err
+		}
+
+		if f.Type == ChaosFaultAgentRestart {
+			if _, stderr, err := ssh.Run(ctx, "sudo systemctl restart hedgehog-agent.service"); err != nil {
+				return fmt.Errorf("restarting agent on switch %s: %w: %s", f.Target, err, stderr)
+			}
+
+			return nil
+		}
+
+		return changeAgentStatus(ctx, ssh, f.Target, false)
+	}
+
+	return fmt.Errorf("unsupported fault type %q", f.Type) //nolint:goerr113
+}
+
+func (c *Config) chaosClear(ctx context.Context, vlab *VLAB, f ChaosFault) error {
+	switch f.Type {
+	case ChaosFaultLink:
+		return c.chaosSetLink(ctx, vlab, f.Target, true)
+	case ChaosFaultNetem:
+		return c.chaosSetNetem(ctx, vlab, f, false)
+	case ChaosFaultVMPause:
+		return c.vmQMP(ctx, f.Target, "cont", nil)
+	case ChaosFaultAgentStop:
+		ssh, err := c.SSH(ctx, vlab, f.Target)
+		if err != nil {
+			return err
+		}
+
+		return changeAgentStatus(ctx, ssh, f.Target, true)
+	case ChaosFaultVMReset, ChaosFaultAgentRestart:
+	}
+
+	return nil
+}
+
+func (c *Config) VLABChaos(ctx context.Context, vlab *VLAB, schedule *ChaosSchedule, opts ChaosOpts) error {
+	if err := schedule.Validate(); err != nil {
+		return fmt.Errorf("validating chaos schedule: %w", err)
+	}
+
+	loopbackNetem := false
+	for _, f := range schedule.Faults {
+		var err error
+		switch f.Type {
+		case ChaosFaultLink:
+			_, err = chaosLinkEnds(vlab, f.Target)
+		case ChaosFaultNetem:
+			var dev string
+			dev, _, err = chaosNetemTarget(vlab, f.Target)
+			loopbackNetem = loopbackNetem || dev == chaosLoopback
+		case ChaosFaultVMPause, ChaosFaultVMReset:
+			if !slices.ContainsFunc(vlab.VMs, func(vm VM) bool { return vm.Name == f.Target }) {
+				err = fmt.Errorf("vm %q not found", f.Target) //nolint:goerr113
+			}
+		case ChaosFaultAgentStop, ChaosFaultAgentRestart:
+			_, err = c.SSH(ctx, vlab, f.Target)
+		}
+		if err != nil {
+			return fmt.Errorf("fault %q: %w", f.Name, err)
+		}
+	}
+
+	cacheCancel, kube, err := getKubeClientWithCache(ctx, c.WorkDir)
+	if err != nil {
+		return fmt.Errorf("creating kube client: %w", err)
+	}
+	defer cacheCancel()
+
+	sshConfigs := map[string]*sshutil.Config{}
+	for _, vm := range vlab.VMs {
+		if vm.Type != VMTypeServer {
+			continue
+		}
+
+		if sshConfigs[vm.Name], err = c.SSHVM(ctx, vlab, vm); err != nil {
+			return fmt.Errorf("getting ssh config for vm %q: %w", vm.Name, err)
+		}
+	}
+
+	matrix, err := BuildConnectivityMatrixFromCluster(ctx, kube, SSHResolverFromMap(sshConfigs))
+	if err != nil {
+		return fmt.Errorf("building connectivity matrix: %w", err)
+	}
+
+	testOpts := TestConnectivityOpts{PingsCount: schedule.PingsCount}
+
+	slog.Info("Checking baseline connectivity before injecting faults")
+	if err := c.TestConnectivityWithMatrix(ctx, vlab, testOpts, matrix); err != nil {
+		return fmt.Errorf("baseline connectivity check failed: %w", err)
+	}
+
+	checksMu := sync.Mutex{}
+	checks := []chaosCheck{}
+	checkerCtx, stopChecker := context.WithCancel(ctx)
+	checkerDone := make(chan struct{})
+	go func() {
+		defer close(checkerDone)
+
+		for checkerCtx.Err() == nil {
+			check := chaosCheck{Start: time.Now()}
+			err := c.TestConnectivityWithMatrix(checkerCtx, vlab, testOpts, matrix)
+			if checkerCtx.Err() != nil {
+				return
+			}
+			check.End = time.Now()
+			check.Passed = err == nil
+
+			checksMu.Lock()
+			checks = append(checks, check)
+			checksMu.Unlock()
+
+			select {
+			case <-checkerCtx.Done():
+			case <-time.After(schedule.CheckInterval.Duration):
+			}
+		}
+	}()
+	defer func() {
+		stopChecker()
+		<-checkerDone
+	}()
+
+	if loopbackNetem {
+		if err := execCmd(ctx, true, "", VLABCmdTC, []string{
+			"qdisc", "replace", "dev", chaosLoopback, "root", "handle", "1:", "prio", "bands", strconv.Itoa(chaosPrioBands),
+			"priomap", "1", "2", "2", "2", "1", "2", "0", "0", "1", "1", "1", "1", "1", "1", "1", "1",
+		}, "dev", chaosLoopback); err != nil {
+			return fmt.Errorf("setting up loopback qdisc for netem: %w", err)
+		}
+		defer func() {
+			cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+			defer cancel()
+
+			if err := execCmd(cleanupCtx, true, "", VLABCmdTC, []string{"qdisc", "del", "dev", chaosLoopback, "root"}, "dev", chaosLoopback); err != nil {
+				slog.Warn("Failed to remove loopback qdisc", "err", err)
+			}
+		}()
+	}
+
+	start := time.Now()
+	slog.Info("Starting chaos", "faults", len(schedule.Faults))
+
+	results := make([]ChaosFaultResult, len(schedule.Faults))
+	g := errgroup.Group{}
+	for idx, f := range schedule.Faults {
+		g.Go(func() error {
+			res := &results[idx]
+			res.Name, res.Type, res.Target = f.Name, f.Type, f.Target
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("waiting to inject %q: %w", f.Name, ctx.Err())
+			case <-time.After(time.Until(start.Add(f.At.Duration))):
+			}
+
+			slog.Info("Injecting fault", "name", f.Name, "type", f.Type, "target", f.Target, "duration", f.Duration.Duration)
+			res.InjectedAt = time.Now()
+			if err := c.chaosInject(ctx, vlab, f); err != nil {
+				res.Error = err.Error()
+				slog.Error("Failed to inject fault", "name", f.Name, "err", err)
+			} else if !f.instant() {
+				select {
+				case <-ctx.Done():
+				case <-time.After(time.Until(res.InjectedAt.Add(f.Duration.Duration))):
+				}
+			}
+
+			// always clear the fault, even if cancelled, to not leave VLAB broken
+			clearCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Minute)
+			defer cancel()
+
+			slog.Info("Clearing fault", "name", f.Name)
+			if err := c.chaosClear(clearCtx, vlab, f); err != nil {
+				res.Error = strings.TrimPrefix(res.Error+"; "+err.Error(), "; ")
+				slog.Error("Failed to clear fault", "name", f.Name, "err", err)
+			}
+			res.ClearedAt = time.Now()
+
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	lastCleared := time.Time{}
+	for _, res := range results {
+		if res.ClearedAt.After(lastCleared) {
+			lastCleared = res.ClearedAt
+		}
+	}
+
+	slog.Info("All faults cleared, waiting for recovery", "timeout", schedule.RecoverTimeout.Duration)
+	recoverCtx, cancel := context.WithTimeout(ctx, schedule.RecoverTimeout.Duration)
+	defer cancel()
+recovery:
+	for {
+		checksMu.Lock()
+		recovered := slices.ContainsFunc(checks, func(check chaosCheck) bool {
+			return check.Passed && !check.Start.Before(lastCleared)
+		})
+		checksMu.Unlock()
+
+		if recovered {
+			break
+		}
+
+		select {
+		case <-recoverCtx.Done():
+			slog.Warn("Timed out waiting for recovery")
+
+			break recovery
+		case <-time.After(time.Second):
+		}
+	}
+
+	stopChecker()
+	<-checkerDone
+
+	evalChaosResults(results, checks)
+
+	failed := 0
+	for _, res := range results {
+		if !res.Recovered || res.Error != "" {
+			failed++
+		}
+
+		slog.Info("Fault result", "name", res.Name, "impacted", res.Impacted, "recovered", res.Recovered,
+			"ttr", res.TimeToRecover.Round(time.Second), "err", res.Error)
+	}
+
+	if opts.ReportFile != "" {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling chaos report: %w", err)
+		}
+		if err := os.WriteFile(opts.ReportFile, data, 0o644); err != nil { //nolint:gosec
+			return fmt.Errorf("writing chaos report: %w", err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d faults failed or not recovered", failed, len(results)) //nolint:goerr113
+	}
+
+	slog.Info("Chaos finished", "faults", len(results), "checks", len(checks), "took", time.Since(start))
+
+	return nil
+}
+
+// evalChaosResults calculates per fault impact and time to recover, which is the time from the fault being cleared
+// till the end of the first fully passing connectivity check started after it
+func evalChaosResults(results []ChaosFaultResult, checks []chaosCheck) {
+	for idx := range results {
+		res := &results[idx]
+
+		recoveredAt := time.Time{}
+		for _, check := range checks {
+			if check.Passed && !check.Start.Before(res.ClearedAt) {
+				recoveredAt = check.End
+				res.Recovered = true
+				res.TimeToRecover = check.End.Sub(res.ClearedAt)
+
+				break
+			}
+		}
+
+		for _, check := range checks {
+			if check.Passed || check.End.Before(res.InjectedAt) {
+				continue
+			}
+			if res.Recovered && check.Start.After(recoveredAt) {
+				continue
+			}
+
+			res.Impacted = true
+
+			break
+		}
+	}
+}
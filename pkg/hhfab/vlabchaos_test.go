@@ -0,0 +1,102 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestChaosSchedule(t *testing.T) {
+	dur := func(d time.Duration) kmetav1.Duration { return kmetav1.Duration{Duration: d} }
+
+	s := &ChaosSchedule{Faults: []ChaosFault{
+		{Type: ChaosFaultLink, Target: "leaf-01/E1/1", Duration: dur(time.Minute), Flaps: 2},
+		{Type: ChaosFaultAgentRestart, Target: "spine-01"},
+		{Type: ChaosFaultNetem, Target: "leaf-01/E1/1", Duration: dur(time.Minute), Delay: dur(100 * time.Millisecond)},
+		{Type: ChaosFaultNetem, Target: "leaf-01/E1/2", Duration: dur(time.Minute), Loss: 5},
+	}}
+	require.NoError(t, s.Validate())
+	require.Equal(t, DefaultChaosPingsCount, s.PingsCount)
+	require.Equal(t, "01-link-leaf-01-E1-1", s.Faults[0].Name)
+	require.Equal(t, chaosNetemFirstBand, s.Faults[2].band)
+	require.Equal(t, chaosNetemFirstBand+1, s.Faults[3].band)
+	require.Equal(t, []string{"netem", "delay", "100ms"}, s.Faults[2].netemArgs())
+
+	for _, f := range []ChaosFault{
+		{Type: "unknown", Target: "leaf-01", Duration: dur(time.Minute)},
+		{Type: ChaosFaultVMPause, Target: "leaf-01"},
+		{Type: ChaosFaultLink, Target: "leaf-01", Duration: dur(time.Minute)},
+		{Type: ChaosFaultNetem, Target: "leaf-01/E1/1", Duration: dur(time.Minute)},
+		{Type: ChaosFaultNetem, Target: "leaf-01/E1/1", Duration: dur(time.Minute), Loss: 101},
+		{Type: ChaosFaultNetem, Target: "leaf-01", Duration: dur(time.Minute), Loss: 5},
+		{Type: ChaosFaultVMPause, Target: "leaf-01", Duration: dur(time.Minute), Loss: 5},
+	} {
+		require.Error(t, (&ChaosSchedule{Faults: []ChaosFault{f}}).Validate(), f)
+	}
+}
+
+func TestChaosTargets(t *testing.T) {
+	vlab := &VLAB{VMs: []VM{
+		{Name: "leaf-01", NICs: []string{
+			"-netdev tap,ifname=hhtap1,script=no,downscript=no,id=eth00 -device e1000,netdev=eth00",
+			"-netdev socket,udp=127.0.0.1:21001,localaddr=127.0.0.1:21101,id=eth01 -device e1000,netdev=eth01",
+		}},
+		{Name: "server-01", NICs: []string{
+			"-netdev user,hostname=server-01,id=eth00 -device e1000,netdev=eth00",
+			"-netdev socket,udp=127.0.0.1:21101,localaddr=127.0.0.1:21001,id=eth01 -device e1000,netdev=eth01",
+		}},
+	}}
+
+	ends, err := chaosLinkEnds(vlab, "leaf-01/E1/1")
+	require.NoError(t, err)
+	require.Equal(t, []vmNIC{{vm: "leaf-01", netdev: "eth01"}, {vm: "server-01", netdev: "eth01"}}, ends)
+
+	_, err = chaosLinkEnds(vlab, "leaf-01/E1/5")
+	require.Error(t, err)
+
+	dev, ports, err := chaosNetemTarget(vlab, "leaf-01/M1")
+	require.NoError(t, err)
+	require.Equal(t, "hhtap1", dev)
+	require.Empty(t, ports)
+
+	dev, ports, err = chaosNetemTarget(vlab, "leaf-01/E1/1")
+	require.NoError(t, err)
+	require.Equal(t, chaosLoopback, dev)
+	require.Equal(t, []string{"21101", "21001"}, ports)
+
+	_, _, err = chaosNetemTarget(vlab, "server-01/enp2s0")
+	require.Error(t, err)
+}
+
+func TestEvalChaosResults(t *testing.T) {
+	start := time.Now()
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+
+	checks := []chaosCheck{
+		{Start: at(0), End: at(5), Passed: true},
+		{Start: at(10), End: at(15), Passed: false},
+		{Start: at(20), End: at(25), Passed: false},
+		{Start: at(30), End: at(35), Passed: true},
+	}
+	results := []ChaosFaultResult{
+		{Name: "impacting", InjectedAt: at(8), ClearedAt: at(18)},
+		{Name: "harmless", InjectedAt: at(28), ClearedAt: at(29)},
+		{Name: "unrecovered", InjectedAt: at(33), ClearedAt: at(40)},
+	}
+
+	evalChaosResults(results, checks)
+
+	require.True(t, results[0].Impacted)
+	require.True(t, results[0].Recovered)
+	require.Equal(t, 17*time.Second, results[0].TimeToRecover)
+
+	require.False(t, results[1].Impacted)
+	require.Equal(t, 6*time.Second, results[1].TimeToRecover)
+
+	require.False(t, results[2].Recovered)
+}
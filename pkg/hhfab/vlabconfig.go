@@ -79,6 +79,7 @@ type VM struct {
 	Restricted    bool
 	NICs          []string
 	Size          VMSize
+	Netns         bool // server running as a network namespace on the host instead of the VM
 }
 
 type ExternalAttachCfg struct {
@@ -123,10 +124,11 @@ type ExternalsCfg struct {
 }
 
 type VLABConfig struct {
-	SSHKey    string              `json:"-"`
-	Sizes     VMSizes             `json:"sizes"`
-	VMs       map[string]VMConfig `json:"vms"`
-	Externals ExternalsCfg        `json:"externals"`
+	SSHKey     string              `json:"-"`
+	ServerMode VLABServerMode      `json:"serverMode,omitempty"`
+	Sizes      VMSizes             `json:"sizes"`
+	VMs        map[string]VMConfig `json:"vms"`
+	Externals  ExternalsCfg        `json:"externals"`
 }
 
 type VMSizes struct {
@@ -250,6 +252,9 @@ func (c *Config) PrepareVLAB(ctx context.Context, opts VLABUpOpts) (*VLAB, error
 		if err != nil {
 			return nil, fmt.Errorf("creating VLAB config: %w", err)
 		}
+		if opts.ServerMode != VLABServerModeVM {
+			vlabCfg.ServerMode = opts.ServerMode
+		}
 
 		data, err := kyaml.Marshal(vlabCfg)
 		if err != nil {
@@ -275,6 +280,16 @@ func (c *Config) PrepareVLAB(ctx context.Context, opts VLABUpOpts) (*VLAB, error
 		return nil, fmt.Errorf("unmarshaling VLAB config: %w", err)
 	}
 
+	if vlabCfg.ServerMode == "" {
+		vlabCfg.ServerMode = VLABServerModeVM
+	}
+	if !slices.Contains(VLABServerModes, vlabCfg.ServerMode) {
+		return nil, fmt.Errorf("invalid server mode %q", vlabCfg.ServerMode) //nolint:goerr113
+	}
+	if opts.ServerMode != "" && opts.ServerMode != vlabCfg.ServerMode {
+		return nil, fmt.Errorf("VLAB is created with server mode %q, use --recreate (-f) to switch to %q", vlabCfg.ServerMode, opts.ServerMode) //nolint:goerr113
+	}
+
 	sizes := opts.VMSizesOverrides
 	if err := mergo.Merge(&sizes, vlabCfg.Sizes); err != nil {
 		return nil, fmt.Errorf("merging VLAB sizes from config: %w", err)
@@ -354,6 +369,12 @@ func (c *Config) PrepareVLAB(ctx context.Context, opts VLABUpOpts) (*VLAB, error
 		return nil, fmt.Errorf("creating VLAB: %w", err)
 	}
 
+	if !opts.NoCreate && !opts.SkipPreflight {
+		if err := c.preflightVLAB(ctx, vlab, opts); err != nil {
+			return nil, fmt.Errorf("pre-flight check: %w", err)
+		}
+	}
+
 	return vlab, nil
 }
 
@@ -1083,6 +1104,8 @@ func vlabFromConfig(cfg *VLABConfig, opts VLABRunOpts) (*VLAB, error) {
 			controlID++
 		}
 
+		netns := vm.Type == VMTypeServer && cfg.ServerMode == VLABServerModeNetns
+
 		size := VMSize{}
 		switch vm.Type {
 		case VMTypeSwitch:
@@ -1092,7 +1115,9 @@ func vlabFromConfig(cfg *VLABConfig, opts VLABRunOpts) (*VLAB, error) {
 		case VMTypeGateway:
 			size = cfg.Sizes.Gateway
 		case VMTypeServer:
-			size = cfg.Sizes.Server
+			if !netns {
+				size = cfg.Sizes.Server
+			}
 		case VMTypeExternal:
 			size = cfg.Sizes.External
 		}
@@ -1110,6 +1135,7 @@ func vlabFromConfig(cfg *VLABConfig, opts VLABRunOpts) (*VLAB, error) {
 			SwitchProfile: vm.SwitchProfile,
 			NICs:          paddedNICs,
 			Size:          size,
+			Netns:         netns,
 		})
 	}
 
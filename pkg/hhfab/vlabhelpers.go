@@ -12,6 +12,7 @@ import (
 	"io"
 	"io/fs"
 	"log/slog"
+	"maps"
 	"net/netip"
 	"os"
 	"os/exec"
@@ -454,6 +455,16 @@ func (c *Config) VLABSwitchReinstall(ctx context.Context, opts SwitchReinstallOp
 		}
 	}
 
+	if opts.Mode == ReinstallModeHardReset {
+		// fail early if power drivers or credentials are missing
+		if _, err := c.switchPowerDrivers(ctx, opts.Switches, pdu.Credentials{
+			Username: opts.PDUUsername,
+			Password: opts.PDUPassword,
+		}); err != nil {
+			return fmt.Errorf("checking power drivers: %w", err)
+		}
+	}
+
 	self, err := os.Executable()
 	if err != nil {
 		return fmt.Errorf("getting executable path: %w", err)
@@ -1010,56 +1021,69 @@ func (c *Config) VLABShowTech(ctx context.Context, vlab *VLAB, opts ShowTechOpts
 func (c *Config) VLABSwitchPower(ctx context.Context, opts SwitchPowerOpts) error {
 	slog.Info("Power managing switches", "action", opts.Action, "switches", opts.Switches)
 
-	if opts.PDUUsername == "" || opts.PDUPassword == "" {
-		return errors.New("PDU credentials required") //nolint:goerr113
+	drivers, err := c.switchPowerDrivers(ctx, opts.Switches, pdu.Credentials{
+		Username: opts.PDUUsername,
+		Password: opts.PDUPassword,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, sw := range slices.Sorted(maps.Keys(drivers)) {
+		for _, psuName := range slices.Sorted(maps.Keys(drivers[sw])) {
+			slog.Info("Calling power driver", "switch", sw, "psu", psuName, "action", opts.Action)
+			if err := drivers[sw][psuName].Power(ctx, opts.Action); err != nil {
+				return fmt.Errorf("failed to power %s switch %s %s: %w", opts.Action, sw, psuName, err)
+			}
+		}
 	}
 
+	return nil
+}
+
+// switchPowerDrivers returns power drivers for all PSUs of the specified switches (all if empty) based on the
+// switch power annotations, see pdu.NewDriver for the supported URLs
+func (c *Config) switchPowerDrivers(ctx context.Context, names []string, creds pdu.Credentials) (map[string]map[string]pdu.Driver, error) {
 	switches := wiringapi.SwitchList{}
 	if err := c.Client.List(ctx, &switches); err != nil {
-		return fmt.Errorf("failed to list switches: %w", err)
+		return nil, fmt.Errorf("failed to list switches: %w", err)
 	}
 
-	if len(opts.Switches) > 0 {
+	if len(names) > 0 {
 		sws := map[string]bool{}
 		for _, sw := range switches.Items {
 			sws[sw.Name] = true
 		}
 
-		for _, sw := range opts.Switches {
+		for _, sw := range names {
 			if !sws[sw] {
-				return fmt.Errorf("switch not found: %s", sw) //nolint:goerr113
+				return nil, fmt.Errorf("switch not found: %s", sw) //nolint:goerr113
 			}
 		}
 	}
 
+	drivers := map[string]map[string]pdu.Driver{}
 	for _, sw := range switches.Items {
-		if len(opts.Switches) > 0 && !slices.Contains(opts.Switches, sw.Name) {
+		if len(names) > 0 && !slices.Contains(names, sw.Name) {
 			continue
 		}
 
 		powerInfo := hhfctl.GetPowerInfo(&sw)
 		if len(powerInfo) == 0 {
-			return fmt.Errorf("no power info found for switch: %s", sw.Name) //nolint:goerr113
+			return nil, fmt.Errorf("no power info found for switch: %s", sw.Name) //nolint:goerr113
 		}
 
+		drivers[sw.Name] = map[string]pdu.Driver{}
 		for psuName, url := range powerInfo {
-			outletID, err := pdu.ExtractOutletID(url)
-			if err != nil {
-				return fmt.Errorf("extracting outlet ID from URL %s: %w", url, err)
-			}
-			pduIP, err := pdu.GetPDUIPFromURL(url)
+			drv, err := pdu.NewDriver(url, creds)
 			if err != nil {
-				return fmt.Errorf("extracting PDU IP from URL %s: %w", url, err)
-			}
-
-			slog.Info("Calling PDU API", "switch", sw.Name, "psu", psuName, "pduIP", pduIP, "outletID", outletID, "action", opts.Action)
-			if err := pdu.ControlOutlet(ctx, pduIP, opts.PDUUsername, opts.PDUPassword, outletID, opts.Action); err != nil {
-				return fmt.Errorf("failed to power %s switch %s %s: %w", opts.Action, sw.Name, psuName, err)
+				return nil, fmt.Errorf("switch %s %s: %w", sw.Name, psuName, err)
 			}
+			drivers[sw.Name][psuName] = drv
 		}
 	}
 
-	return nil
+	return drivers, nil
 }
 
 func (c *Config) collectShowTech(ctx context.Context, entryName string, ssh *sshutil.Config, script []byte, outputDir string) error {
@@ -1342,14 +1366,22 @@ func (c *Config) CollectVLABDebug(ctx context.Context, vlab *VLAB, opts VLABRunO
 		}
 	}
 
-	if dump, err := support.Collect(ctx, "vlab", kubeconfig, false); err != nil {
-		slog.Warn("Failed to collect support dump", "err", err)
+	dumpPath := filepath.Join(c.WorkDir, "vlab"+support.ArchiveFileExt)
+	if f, err := os.Create(dumpPath); err != nil {
+		slog.Warn("Failed to create support dump file", "err", err)
 	} else {
-		if data, err := support.Marshal(dump); err != nil {
-			slog.Warn("Failed to marshal support dump", "err", err)
-		} else {
-			if err := os.WriteFile(filepath.Join(c.WorkDir, "vlab.hhs"), data, 0o644); err != nil { //nolint:gosec
-				slog.Warn("Failed to write support dump", "err", err)
+		collectErr := support.CollectArchive(ctx, f, "vlab", support.CollectOpts{KubeconfigPath: kubeconfig})
+		if collectErr != nil {
+			slog.Warn("Failed to collect support dump", "err", collectErr)
+		}
+		closeErr := f.Close()
+		if closeErr != nil {
+			slog.Warn("Failed to write support dump", "err", closeErr)
+		}
+		// don't leave the truncated archive behind as it'd fail to open
+		if collectErr != nil || closeErr != nil {
+			if err := os.Remove(dumpPath); err != nil {
+				slog.Warn("Failed to remove incomplete support dump", "err", err)
 			}
 		}
 	}
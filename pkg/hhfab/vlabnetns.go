@@ -0,0 +1,568 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+	"go.githedgehog.com/fabric/pkg/util/logutil"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/sync/errgroup"
+)
+
+type VLABServerMode string
+
+const (
+	VLABServerModeVM    VLABServerMode = "vm"
+	VLABServerModeNetns VLABServerMode = "netns"
+)
+
+var VLABServerModes = []VLABServerMode{
+	VLABServerModeVM,
+	VLABServerModeNetns,
+}
+
+const (
+	VLABNetnsDir        = "netns"
+	VLABNetnsConfigFile = "servers.json"
+	VLABNetnsBinDir     = "bin"
+	VLABNetnsPrefix     = "hhfab-"
+
+	// netns servers SSH is only reachable from the host itself, the same as the SSH port forwards of the VMs are used
+	VLABNetnsSSHAddr = "127.0.0.1"
+)
+
+// VLABNetnsCmds are host commands required to run servers as network namespaces: ip to manage namespaces and
+// commands run inside of them by hhnet and tests (DHCP client, ping, iperf3 and curl) as there is no server OS image
+var VLABNetnsCmds = []string{"ip", "dhclient", "ping", "iperf3", "curl"}
+
+//go:embed hhnet_netns.sh
+var hhnetNetnsOverrides []byte
+
+const netnsToolboxShim = `#!/bin/bash
+# toolbox shim for netns servers, commands are running directly on the host inside of the server namespace
+[ "$1" == "-q" ] && shift
+exec "$@"
+`
+
+// hhnet usage section marks the end of the function definitions in the hhnet script
+var hhnetUsageMarker = []byte("# Usage:\n")
+
+type NetnsServersConfig struct {
+	AuthorizedKey string        `json:"authorizedKey"`
+	BinDir        string        `json:"binDir"`
+	Servers       []NetnsServer `json:"servers"`
+}
+
+type NetnsServer struct {
+	Name    string     `json:"name"`
+	SSHPort uint       `json:"sshPort"`
+	NICs    []NetnsNIC `json:"nics"`
+}
+
+type NetnsNIC struct {
+	Name   string `json:"name"`
+	MAC    string `json:"mac"`
+	Local  string `json:"local"`
+	Remote string `json:"remote"`
+}
+
+func netnsName(server string) string {
+	return VLABNetnsPrefix + server
+}
+
+// netnsHHNet returns hhnet script with overrides for netns servers inserted right before the commands handling
+func netnsHHNet() ([]byte, error) {
+	idx := bytes.Index(hhnet, hhnetUsageMarker)
+	if idx < 0 {
+		return nil, fmt.Errorf("hhnet usage marker not found") //nolint:goerr113
+	}
+
+	return slices.Concat(hhnet[:idx], hhnetNetnsOverrides, hhnet[idx:]), nil
+}
+
+func netnsServersConfig(vlab *VLAB, binDir string) (*NetnsServersConfig, error) {
+	signer, err := ssh.ParsePrivateKey([]byte(vlab.SSHKey))
+	if err != nil {
+		return nil, fmt.Errorf("parsing VLAB SSH key: %w", err)
+	}
+
+	cfg := &NetnsServersConfig{
+		AuthorizedKey: strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))),
+		BinDir:        binDir,
+	}
+
+	for _, vm := range vlab.VMs {
+		if !vm.Netns {
+			continue
+		}
+
+		srv := NetnsServer{
+			Name:    vm.Name,
+			SSHPort: getSSHPort(vm.ID),
+		}
+		for nicID, nic := range vm.NICs {
+			remote, local := vmNICParam(nic, "-netdev", "udp"), vmNICParam(nic, "-netdev", "localaddr")
+			if remote == "" || local == "" {
+				// only links to switches are needed, management is done through the SSH server
+				continue
+			}
+
+			srv.NICs = append(srv.NICs, NetnsNIC{
+				Name:   srvPrefix + strconv.Itoa(nicID),
+				MAC:    vmNICParam(nic, "-device", "mac"),
+				Local:  local,
+				Remote: remote,
+			})
+		}
+
+		cfg.Servers = append(cfg.Servers, srv)
+	}
+
+	return cfg, nil
+}
+
+// prepareNetnsServers writes config and helper binaries for the netns servers and returns the config path
+func (c *Config) prepareNetnsServers(vlab *VLAB) (string, error) {
+	dir := filepath.Join(c.WorkDir, VLABDir, VLABNetnsDir)
+	binDir := filepath.Join(dir, VLABNetnsBinDir)
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		return "", fmt.Errorf("creating netns bin dir: %w", err)
+	}
+
+	script, err := netnsHHNet()
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(binDir, "hhnet"), script, 0o755); err != nil { //nolint:gosec
+		return "", fmt.Errorf("writing hhnet: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(binDir, "toolbox"), []byte(netnsToolboxShim), 0o755); err != nil { //nolint:gosec
+		return "", fmt.Errorf("writing toolbox shim: %w", err)
+	}
+
+	cfg, err := netnsServersConfig(vlab, binDir)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshaling netns servers config: %w", err)
+	}
+
+	cfgPath := filepath.Join(dir, VLABNetnsConfigFile)
+	if err := os.WriteFile(cfgPath, data, 0o600); err != nil {
+		return "", fmt.Errorf("writing netns servers config: %w", err)
+	}
+
+	return cfgPath, nil
+}
+
+// runNetnsServers runs the helper serving netns servers, it's terminated gracefully to cleanup namespaces
+func (c *Config) runNetnsServers(ctx context.Context, vlab *VLAB) error {
+	cfgPath, err := c.prepareNetnsServers(vlab)
+	if err != nil {
+		return fmt.Errorf("preparing netns servers: %w", err)
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("getting executable path: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, VLABCmdSudo, self, "_helpers", "netns-servers", "--config", cfgPath)
+	cmd.Dir = c.WorkDir
+	cmd.Stdout = logutil.NewSink(ctx, slog.Debug, "netns: ")
+	cmd.Stderr = os.Stderr
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM) //nolint:wrapcheck
+	}
+	cmd.WaitDelay = 30 * time.Second
+
+	if err := cmd.Run(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("running netns servers: %w", err)
+	}
+
+	return nil
+}
+
+// RunNetnsServers creates network namespaces for servers with tap devices relaying frames to the switch ports and
+// runs SSH servers executing commands inside of them, it should be run as root and keeps running until terminated
+func RunNetnsServers(ctx context.Context, cfgPath string) error {
+	ctx, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+
+	cfg := &NetnsServersConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("unmarshaling config: %w", err)
+	}
+
+	authorized, _, _, _, err := ssh.ParseAuthorizedKey([]byte(cfg.AuthorizedKey))
+	if err != nil {
+		return fmt.Errorf("parsing authorized key: %w", err)
+	}
+
+	// host key is per run as VLAB SSH clients don't check host keys and the server is only listening on loopback
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("generating host key: %w", err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		return fmt.Errorf("creating host key signer: %w", err)
+	}
+
+	sshCfg := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if !bytes.Equal(key.Marshal(), authorized.Marshal()) {
+				return nil, fmt.Errorf("unknown public key") //nolint:goerr113
+			}
+
+			return &ssh.Permissions{}, nil
+		},
+	}
+	sshCfg.AddHostKey(hostSigner)
+
+	defer func() {
+		for _, srv := range cfg.Servers {
+			cleanupNetnsServer(srv)
+		}
+	}()
+
+	g, ctx := errgroup.WithContext(ctx)
+	for _, srv := range cfg.Servers {
+		taps, err := setupNetnsServer(srv)
+		if err != nil {
+			return fmt.Errorf("setting up server %s: %w", srv.Name, err)
+		}
+
+		for idx, nic := range srv.NICs {
+			g.Go(func() error {
+				if err := relayNetnsNIC(ctx, taps[idx], nic); err != nil {
+					return fmt.Errorf("relaying server %s NIC %s: %w", srv.Name, nic.Name, err)
+				}
+
+				return nil
+			})
+		}
+
+		g.Go(func() error {
+			if err := serveNetnsSSH(ctx, srv, sshCfg, cfg.BinDir); err != nil {
+				return fmt.Errorf("serving SSH for server %s: %w", srv.Name, err)
+			}
+
+			return nil
+		})
+	}
+
+	slog.Info("Netns servers are running", "count", len(cfg.Servers))
+
+	return g.Wait() //nolint:wrapcheck
+}
+
+func setupNetnsServer(srv NetnsServer) ([]*os.File, error) {
+	name := netnsName(srv.Name)
+	cleanupNetnsServer(srv)
+
+	// ip netns exec bind mounts files from this dir over /etc, so DHCP client doesn't touch host resolv.conf
+	etcDir := filepath.Join("/etc/netns", name)
+	if err := os.MkdirAll(etcDir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", etcDir, err)
+	}
+	if err := os.WriteFile(filepath.Join(etcDir, "resolv.conf"), nil, 0o644); err != nil { //nolint:gosec
+		return nil, fmt.Errorf("creating resolv.conf: %w", err)
+	}
+
+	// namespace switch and tap creation are per OS thread
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	orig, err := netns.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting current netns: %w", err)
+	}
+	defer orig.Close()
+	defer func() {
+		if err := netns.Set(orig); err != nil {
+			slog.Error("Failed to restore netns", "err", err)
+		}
+	}()
+
+	ns, err := netns.NewNamed(name)
+	if err != nil {
+		return nil, fmt.Errorf("creating netns: %w", err)
+	}
+	defer ns.Close()
+
+	lo, err := netlink.LinkByName("lo")
+	if err != nil {
+		return nil, fmt.Errorf("getting lo: %w", err)
+	}
+	if err := netlink.LinkSetUp(lo); err != nil {
+		return nil, fmt.Errorf("setting lo up: %w", err)
+	}
+
+	taps := []*os.File{}
+	for _, nic := range srv.NICs {
+		la := netlink.NewLinkAttrs()
+		la.Name = nic.Name
+		if nic.MAC != "" {
+			if la.HardwareAddr, err = net.ParseMAC(nic.MAC); err != nil {
+				return nil, fmt.Errorf("parsing NIC %s MAC: %w", nic.Name, err)
+			}
+		}
+
+		tap := &netlink.Tuntap{
+			LinkAttrs:  la,
+			Mode:       netlink.TUNTAP_MODE_TAP,
+			Flags:      netlink.TUNTAP_NO_PI,
+			Queues:     1,
+			NonPersist: true,
+		}
+		if err := netlink.LinkAdd(tap); err != nil {
+			return nil, fmt.Errorf("adding tap %s: %w", nic.Name, err)
+		}
+		if err := netlink.LinkSetUp(tap); err != nil {
+			return nil, fmt.Errorf("setting tap %s up: %w", nic.Name, err)
+		}
+
+		taps = append(taps, tap.Fds[0])
+	}
+
+	return taps, nil
+}
+
+func cleanupNetnsServer(srv NetnsServer) {
+	name := netnsName(srv.Name)
+
+	// DHCP clients and anything else left running inside of the namespace
+	if out, err := exec.Command("ip", "netns", "pids", name).Output(); err == nil {
+		for _, pid := range strings.Fields(string(out)) {
+			if pid, err := strconv.Atoi(pid); err == nil {
+				_ = syscall.Kill(pid, syscall.SIGKILL)
+			}
+		}
+	}
+
+	if err := netns.DeleteNamed(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Debug("Deleting netns", "name", name, "err", err)
+	}
+	_ = os.RemoveAll(filepath.Join("/etc/netns", name))
+}
+
+// relayNetnsNIC forwards frames between the tap and qemu socket netdev of the switch port, it's a raw ethernet frame
+// per UDP datagram
+func relayNetnsNIC(ctx context.Context, tap *os.File, nic NetnsNIC) error {
+	local, err := net.ResolveUDPAddr("udp", nic.Local)
+	if err != nil {
+		return fmt.Errorf("resolving local addr: %w", err)
+	}
+	remote, err := net.ResolveUDPAddr("udp", nic.Remote)
+	if err != nil {
+		return fmt.Errorf("resolving remote addr: %w", err)
+	}
+
+	conn, err := net.ListenUDP("udp", local)
+	if err != nil {
+		return fmt.Errorf("listening: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+		_ = tap.Close()
+	}()
+
+	g := errgroup.Group{}
+	g.Go(func() error {
+		buf := make([]byte, 65536)
+		for {
+			n, err := tap.Read(buf)
+			if err != nil {
+				return ignoreClosed(ctx, err)
+			}
+			if _, err := conn.WriteToUDP(buf[:n], remote); err != nil && ctx.Err() != nil {
+				return nil
+			}
+		}
+	})
+	g.Go(func() error {
+		buf := make([]byte, 65536)
+		for {
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return ignoreClosed(ctx, err)
+			}
+			if _, err := tap.Write(buf[:n]); err != nil {
+				return ignoreClosed(ctx, err)
+			}
+		}
+	})
+
+	return g.Wait() //nolint:wrapcheck
+}
+
+func ignoreClosed(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return err
+}
+
+func serveNetnsSSH(ctx context.Context, srv NetnsServer, cfg *ssh.ServerConfig, binDir string) error {
+	lc := net.ListenConfig{}
+	l, err := lc.Listen(ctx, "tcp", net.JoinHostPort(VLABNetnsSSHAddr, strconv.FormatUint(uint64(srv.SSHPort), 10)))
+	if err != nil {
+		return fmt.Errorf("listening: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return ignoreClosed(ctx, err)
+		}
+
+		go func() {
+			sconn, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+			if err != nil {
+				slog.Debug("SSH handshake failed", "server", srv.Name, "err", err)
+
+				return
+			}
+			defer sconn.Close()
+
+			go ssh.DiscardRequests(reqs)
+
+			for newCh := range chans {
+				if newCh.ChannelType() != "session" {
+					_ = newCh.Reject(ssh.UnknownChannelType, "only session channels are supported")
+
+					continue
+				}
+
+				ch, chReqs, err := newCh.Accept()
+				if err != nil {
+					continue
+				}
+
+				go handleNetnsSSHSession(ctx, srv, binDir, ch, chReqs)
+			}
+		}()
+	}
+}
+
+func handleNetnsSSHSession(ctx context.Context, srv NetnsServer, binDir string, ch ssh.Channel, reqs <-chan *ssh.Request) {
+	started := false
+	for req := range reqs {
+		switch req.Type {
+		case "exec", "shell":
+			if started {
+				_ = req.Reply(false, nil)
+
+				continue
+			}
+
+			payload := struct{ Command string }{}
+			if req.Type == "exec" {
+				if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+					_ = req.Reply(false, nil)
+
+					continue
+				}
+			}
+
+			started = true
+			_ = req.Reply(true, nil)
+
+			go func() {
+				code := runNetnsCommand(ctx, srv, binDir, payload.Command, ch)
+				_, _ = ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{code}))
+				_ = ch.Close()
+			}()
+		case "pty-req", "env", "window-change":
+			_ = req.Reply(true, nil)
+		default:
+			_ = req.Reply(false, nil)
+		}
+	}
+}
+
+// runNetnsCommand runs command inside of the server namespace the same way as it would run on the server VM: with
+// server hostname, hhnet and toolbox available in /opt/bin and PATH
+func runNetnsCommand(ctx context.Context, srv NetnsServer, binDir, command string, ch ssh.Channel) uint32 {
+	script := fmt.Sprintf(
+		`hostname %s && mkdir -p /opt && mount -t tmpfs tmpfs /opt && mkdir -p /opt/bin && cp %s/* /opt/bin/ && `+
+			`export PATH=/opt/bin:$PATH HHNET_NETNS=%s HOME=/root && `,
+		srv.Name, binDir, netnsName(srv.Name))
+	args := []string{"netns", "exec", netnsName(srv.Name), "unshare", "--uts", "/bin/bash", "-c"}
+	if command == "" {
+		args = append(args, script+`exec /bin/bash -l`)
+	} else {
+		args = append(args, script+`exec /bin/bash -c "$1"`, "bash", command)
+	}
+
+	cmd := exec.CommandContext(ctx, "ip", args...)
+	cmd.Stdout = ch
+	cmd.Stderr = ch.Stderr()
+
+	// stdin isn't passed directly to not block on waiting for it to be closed by the client
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return 255
+	}
+	if err := cmd.Start(); err != nil {
+		_, _ = fmt.Fprintf(ch.Stderr(), "starting command: %s\n", err)
+
+		return 255
+	}
+	go func() {
+		_, _ = io.Copy(stdin, ch)
+		_ = stdin.Close()
+	}()
+
+	if err := cmd.Wait(); err != nil {
+		exitErr := &exec.ExitError{}
+		if errors.As(err, &exitErr) {
+			return uint32(exitErr.ExitCode()) //nolint:gosec
+		}
+
+		return 255
+	}
+
+	return 0
+}
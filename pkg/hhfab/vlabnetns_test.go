@@ -0,0 +1,64 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestNetnsHHNet(t *testing.T) {
+	script, err := netnsHHNet()
+	require.NoError(t, err)
+
+	overrides := bytes.Index(script, []byte("function start_dhcp()"))
+	usage := bytes.Index(script, hhnetUsageMarker)
+	require.Positive(t, overrides)
+	require.Greater(t, usage, overrides)
+
+	// overrides should go after the original definitions to take effect
+	require.Less(t, bytes.Index(script, []byte("function get_ip()")), overrides)
+}
+
+func TestNetnsServersConfig(t *testing.T) {
+	_, prv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(prv, "")
+	require.NoError(t, err)
+
+	vlab := &VLAB{
+		SSHKey: string(pem.EncodeToMemory(block)),
+		VMs: []VM{
+			{ID: 1, Name: "leaf-01", NICs: []string{
+				"-netdev tap,ifname=hhtap1,script=no,downscript=no,id=eth00 -device e1000,netdev=eth00,mac=0c:20:12:ff:01:00",
+				"-netdev socket,udp=127.0.0.1:21001,localaddr=127.0.0.1:21101,id=eth01 -device e1000,netdev=eth01,mac=0c:20:12:ff:01:01",
+			}},
+			{ID: 2, Name: "server-01", Netns: true, NICs: []string{
+				"-netdev user,hostname=server-01,hostfwd=tcp:0.0.0.0:22002-:22,id=eth00 -device e1000,netdev=eth00,mac=0c:20:12:ff:02:00",
+				"-netdev socket,udp=127.0.0.1:21101,localaddr=127.0.0.1:21001,id=eth01 -device e1000,netdev=eth01,mac=0c:20:12:ff:02:01,bus=pci0,addr=0x1",
+			}},
+		},
+	}
+
+	cfg, err := netnsServersConfig(vlab, "/tmp/bin")
+	require.NoError(t, err)
+	require.Equal(t, "/tmp/bin", cfg.BinDir)
+	require.Contains(t, cfg.AuthorizedKey, "ssh-ed25519 ")
+	require.Equal(t, []NetnsServer{{
+		Name:    "server-01",
+		SSHPort: getSSHPort(2),
+		NICs: []NetnsNIC{{
+			Name:   "enp2s1",
+			MAC:    "0c:20:12:ff:02:01",
+			Local:  "127.0.0.1:21001",
+			Remote: "127.0.0.1:21101",
+		}},
+	}}, cfg.Servers)
+}
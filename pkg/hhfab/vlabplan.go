@@ -0,0 +1,446 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/process"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	VLABMaxTaps = 100
+
+	// memory used by qemu itself on top of the guest RAM
+	VLABPlanVMOverheadRAM = 256
+	// ratio of vCPUs to host CPUs after which VMs are getting too slow to install in time
+	VLABPlanMaxCPUOversubscription = 2
+	// share of the available memory after which host is likely to start swapping
+	VLABPlanRAMWarnRatio = 0.9
+)
+
+// VLABReducedSizes are the smallest VM sizes suggested by the planner that are still known to work
+var VLABReducedSizes = VMSizes{
+	Control: VMSize{CPU: 4, RAM: 4096},
+	Switch:  VMSize{CPU: 4, RAM: 5120},
+	Server:  VMSize{CPU: 1, RAM: 512},
+	Gateway: VMSize{CPU: 4, RAM: 4096},
+}
+
+type VLABPlanStatus string
+
+const (
+	VLABPlanOK   VLABPlanStatus = "ok"
+	VLABPlanWarn VLABPlanStatus = "warn"
+	VLABPlanFail VLABPlanStatus = "fail"
+)
+
+type VLABPlan struct {
+	Usage       []VLABPlanUsage
+	Total       VMSize
+	Netns       int
+	Host        VLABHost
+	Checks      []VLABPlanCheck
+	Suggestions []string
+}
+
+type VLABPlanUsage struct {
+	Type  VMType
+	Count uint
+	Size  VMSize // per VM
+	Total VMSize
+}
+
+type VLABPlanCheck struct {
+	Name    string
+	Status  VLABPlanStatus
+	Message string
+}
+
+// VLABHost is a snapshot of the host resources relevant for running VLAB, memory is in MB and disk in GB
+type VLABHost struct {
+	CPUs         uint
+	RAMTotal     uint
+	RAMAvailable uint
+	RAMStale     uint // used by the stale VLAB VMs and will be freed on start if --kill-stale is set
+	HugePagesRAM uint
+	DiskFree     uint
+	KVM          bool
+	TUN          bool
+	IOMMU        bool
+	PCIDevices   []string
+	ExistingVMs  map[string]bool
+	MissingCmds  []string // host commands required for netns servers that aren't available
+}
+
+// GetVLABHost collects resources of the current host, workDir is used to check free disk space and existing VMs
+func GetVLABHost(ctx context.Context, workDir string) (VLABHost, error) {
+	host := VLABHost{
+		CPUs:        uint(runtime.NumCPU()), //nolint:gosec
+		ExistingVMs: map[string]bool{},
+	}
+
+	vm, err := mem.VirtualMemoryWithContext(ctx)
+	if err != nil {
+		return host, fmt.Errorf("getting memory stats: %w", err)
+	}
+	host.RAMTotal = uint(vm.Total >> 20)
+	host.RAMAvailable = uint(vm.Available >> 20)
+	host.HugePagesRAM = uint(vm.HugePagesTotal * vm.HugePageSize >> 20)
+
+	stale, err := CheckStaleVMs(ctx, false)
+	if err != nil {
+		return host, fmt.Errorf("checking for stale VMs: %w", err)
+	}
+	for _, pid := range stale {
+		pr, err := process.NewProcessWithContext(ctx, pid)
+		if err != nil {
+			continue
+		}
+		if info, err := pr.MemoryInfoWithContext(ctx); err == nil {
+			host.RAMStale += uint(info.RSS >> 20)
+		}
+	}
+
+	vmsDir := filepath.Join(workDir, VLABDir, VLABVMsDir)
+	statDir := workDir
+	if _, err := os.Stat(vmsDir); err == nil {
+		statDir = vmsDir
+
+		entries, err := os.ReadDir(vmsDir)
+		if err != nil {
+			return host, fmt.Errorf("reading VMs dir: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() && isPresent(filepath.Join(vmsDir, entry.Name()), VLABOSImageFile) {
+				host.ExistingVMs[entry.Name()] = true
+			}
+		}
+	}
+
+	fs := unix.Statfs_t{}
+	if err := unix.Statfs(statDir, &fs); err != nil {
+		return host, fmt.Errorf("getting free disk space for %q: %w", statDir, err)
+	}
+	host.DiskFree = uint(fs.Bavail * uint64(fs.Bsize) >> 30) //nolint:gosec,unconvert
+
+	for _, cmd := range VLABNetnsCmds {
+		if _, err := exec.LookPath(cmd); err != nil {
+			host.MissingCmds = append(host.MissingCmds, cmd)
+		}
+	}
+
+	host.KVM = isPresent("/dev", "kvm")
+	host.TUN = isPresent("/dev/net", "tun")
+
+	if groups, err := os.ReadDir("/sys/kernel/iommu_groups"); err == nil && len(groups) > 0 {
+		host.IOMMU = true
+	}
+	if devs, err := os.ReadDir("/sys/bus/pci/devices"); err == nil {
+		for _, dev := range devs {
+			host.PCIDevices = append(host.PCIDevices, dev.Name())
+		}
+	}
+
+	return host, nil
+}
+
+// PlanVLAB sums up resources needed by the VLAB VMs and checks them against the host, memory used by the stale VMs
+// is counted as available if they are going to be killed
+func PlanVLAB(vlab *VLAB, host VLABHost, killStale bool) *VLABPlan {
+	plan := &VLABPlan{Host: host}
+
+	byType := map[VMType]*VLABPlanUsage{}
+	for _, vm := range vlab.VMs {
+		if vm.Netns {
+			plan.Netns++
+
+			continue
+		}
+
+		usage, ok := byType[vm.Type]
+		if !ok {
+			usage = &VLABPlanUsage{Type: vm.Type, Size: vm.Size}
+			byType[vm.Type] = usage
+		}
+		usage.Count++
+		usage.Total.CPU += vm.Size.CPU
+		usage.Total.RAM += vm.Size.RAM
+		usage.Total.Disk += vm.Size.Disk
+
+		plan.Total.CPU += vm.Size.CPU
+		plan.Total.RAM += vm.Size.RAM
+		// existing VM images are already allocated (at least partially)
+		if !host.ExistingVMs[vm.Name] {
+			plan.Total.Disk += vm.Size.Disk
+		}
+	}
+	for _, vmType := range VMTypes {
+		if usage, ok := byType[vmType]; ok {
+			plan.Usage = append(plan.Usage, *usage)
+		}
+	}
+
+	vms := uint(len(vlab.VMs) - plan.Netns) //nolint:gosec
+	ramNeeded := plan.Total.RAM + vms*VLABPlanVMOverheadRAM
+	ramAvailable := host.RAMAvailable
+	if killStale {
+		ramAvailable += host.RAMStale
+	}
+
+	check := func(name string, status VLABPlanStatus, format string, args ...any) {
+		plan.Checks = append(plan.Checks, VLABPlanCheck{Name: name, Status: status, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case ramNeeded > ramAvailable:
+		check("ram", VLABPlanFail, "%d MB needed (incl. %d MB qemu overhead), only %d MB available of %d MB", ramNeeded, vms*VLABPlanVMOverheadRAM, ramAvailable, host.RAMTotal)
+	case float64(ramNeeded) > float64(ramAvailable)*VLABPlanRAMWarnRatio:
+		check("ram", VLABPlanWarn, "%d MB needed (incl. %d MB qemu overhead), %d MB available, host may start swapping", ramNeeded, vms*VLABPlanVMOverheadRAM, ramAvailable)
+	default:
+		check("ram", VLABPlanOK, "%d MB needed, %d MB available", ramNeeded, ramAvailable)
+	}
+	if host.RAMStale > 0 && !killStale {
+		check("stale-vms", VLABPlanWarn, "stale VLAB VMs are using %d MB, they will not be killed automatically", host.RAMStale)
+	}
+
+	if host.HugePagesRAM > 0 {
+		status := VLABPlanOK
+		if ramNeeded > ramAvailable {
+			status = VLABPlanWarn
+		}
+		check("hugepages", status, "%d MB reserved for hugepages isn't usable by VLAB VMs", host.HugePagesRAM)
+	}
+
+	if plan.Total.CPU > host.CPUs*VLABPlanMaxCPUOversubscription {
+		check("cpu", VLABPlanWarn, "%d vCPUs for %d host CPUs, VMs may be too slow to install in time", plan.Total.CPU, host.CPUs)
+	} else {
+		check("cpu", VLABPlanOK, "%d vCPUs for %d host CPUs", plan.Total.CPU, host.CPUs)
+	}
+
+	if plan.Total.Disk > host.DiskFree {
+		check("disk", VLABPlanWarn, "up to %d GB needed for new VMs, only %d GB free (images are sparse)", plan.Total.Disk, host.DiskFree)
+	} else {
+		check("disk", VLABPlanOK, "up to %d GB needed for new VMs, %d GB free", plan.Total.Disk, host.DiskFree)
+	}
+
+	if vms > 0 {
+		if host.KVM {
+			check("kvm", VLABPlanOK, "/dev/kvm is available")
+		} else {
+			check("kvm", VLABPlanFail, "/dev/kvm is missing, enable virtualization and load kvm module")
+		}
+	}
+
+	switch {
+	case !host.TUN && (vlab.Taps > 0 || plan.Netns > 0):
+		check("taps", VLABPlanFail, "/dev/net/tun is missing, load tun module")
+	case vlab.Taps > VLABMaxTaps:
+		check("taps", VLABPlanFail, "%d taps needed, only up to %d supported", vlab.Taps, VLABMaxTaps)
+	default:
+		check("taps", VLABPlanOK, "%d taps needed", vlab.Taps)
+	}
+
+	if plan.Netns > 0 {
+		if len(host.MissingCmds) > 0 {
+			check("netns", VLABPlanFail, "%d server(s) as network namespaces need missing host commands: %s", plan.Netns, strings.Join(host.MissingCmds, ", "))
+		} else {
+			check("netns", VLABPlanOK, "%d server(s) as network namespaces, required host commands are available", plan.Netns)
+		}
+	}
+
+	if len(vlab.Passthroughs) > 0 {
+		missing := []string{}
+		for _, dev := range vlab.Passthroughs {
+			if !slices.Contains(host.PCIDevices, dev) {
+				missing = append(missing, dev)
+			}
+		}
+
+		switch {
+		case !host.IOMMU:
+			check("vfio", VLABPlanFail, "IOMMU isn't enabled, it's required for %d passthrough device(s)", len(vlab.Passthroughs))
+		case len(missing) > 0:
+			check("vfio", VLABPlanFail, "passthrough device(s) not found: %s", strings.Join(missing, ", "))
+		default:
+			check("vfio", VLABPlanOK, "%d passthrough device(s) available", len(vlab.Passthroughs))
+		}
+	}
+
+	if ramNeeded > ramAvailable {
+		plan.suggestRAM(ramNeeded-ramAvailable, killStale)
+	}
+	if plan.Total.CPU > host.CPUs*VLABPlanMaxCPUOversubscription {
+		plan.suggestCPU()
+	}
+
+	return plan
+}
+
+// reducible VM types with the size flags available in vlab up
+var vlabPlanFlagPrefixes = map[VMType]string{
+	VMTypeControl: "control",
+	VMTypeGateway: "gateway",
+	VMTypeServer:  "server",
+}
+
+func reducedSize(vmType VMType) VMSize {
+	switch vmType {
+	case VMTypeControl:
+		return VLABReducedSizes.Control
+	case VMTypeGateway:
+		return VLABReducedSizes.Gateway
+	case VMTypeServer:
+		return VLABReducedSizes.Server
+	case VMTypeSwitch:
+		return VLABReducedSizes.Switch
+	}
+
+	return VMSize{}
+}
+
+func (p *VLABPlan) suggestRAM(deficit uint, killStale bool) {
+	if p.Host.HugePagesRAM > 0 {
+		p.Suggestions = append(p.Suggestions, fmt.Sprintf("free %d MB reserved for hugepages: sudo sysctl vm.nr_hugepages=0", p.Host.HugePagesRAM))
+	}
+	if p.Host.RAMStale > 0 && !killStale {
+		p.Suggestions = append(p.Suggestions, fmt.Sprintf("kill stale VLAB VMs to free %d MB: --kill-stale", p.Host.RAMStale))
+	}
+
+	// servers are the cheapest to get rid of as they don't need VMs at all in netns mode
+	for _, usage := range p.Usage {
+		if usage.Type == VMTypeServer {
+			p.Suggestions = append(p.Suggestions, fmt.Sprintf("run %d server(s) as network namespaces to save %d MB: --server-mode=%s -f",
+				usage.Count, usage.Total.RAM+usage.Count*VLABPlanVMOverheadRAM, VLABServerModeNetns))
+		}
+	}
+
+	flags := []string{}
+	for _, usage := range p.Usage {
+		prefix, ok := vlabPlanFlagPrefixes[usage.Type]
+		if !ok || deficit == 0 {
+			continue
+		}
+
+		minRAM := reducedSize(usage.Type).RAM
+		if usage.Size.RAM <= minRAM {
+			continue
+		}
+
+		// round up to 256 MB steps as it's how sizes are usually set
+		perVM := (deficit + usage.Count - 1) / usage.Count
+		ram := max(minRAM, usage.Size.RAM-min(usage.Size.RAM, (perVM+255)/256*256))
+		flags = append(flags, fmt.Sprintf("--%s-ram=%d", prefix, ram))
+		deficit -= min(deficit, (usage.Size.RAM-ram)*usage.Count)
+	}
+	if len(flags) > 0 {
+		p.Suggestions = append(p.Suggestions, "reduce VM RAM: "+strings.Join(flags, " "))
+	}
+
+	if deficit > 0 {
+		for _, usage := range p.Usage {
+			if usage.Type != VMTypeSwitch {
+				continue
+			}
+
+			perSwitch := usage.Size.RAM + VLABPlanVMOverheadRAM
+			count := (deficit + perSwitch - 1) / perSwitch
+			p.Suggestions = append(p.Suggestions, fmt.Sprintf("use smaller topology with at least %d switch(es) less (%d MB each), e.g. hhfab vlab gen with lower --spines-count, --fabric-links-count or leafs counts", count, perSwitch))
+		}
+	}
+}
+
+func (p *VLABPlan) suggestCPU() {
+	flags := []string{}
+	for _, usage := range p.Usage {
+		prefix, ok := vlabPlanFlagPrefixes[usage.Type]
+		if !ok {
+			continue
+		}
+
+		if cpu := reducedSize(usage.Type).CPU; usage.Size.CPU > cpu {
+			flags = append(flags, fmt.Sprintf("--%s-cpus=%d", prefix, cpu))
+		}
+	}
+	if len(flags) > 0 {
+		p.Suggestions = append(p.Suggestions, "reduce VM CPUs: "+strings.Join(flags, " "))
+	}
+}
+
+func (p *VLABPlan) Failed() bool {
+	return slices.ContainsFunc(p.Checks, func(c VLABPlanCheck) bool { return c.Status == VLABPlanFail })
+}
+
+func (p *VLABPlan) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	lines := []string{}
+	for _, usage := range p.Usage {
+		lines = append(lines, fmt.Sprintf("%s\t%d x %d vCPUs, %d MB RAM, %d GB disk\t= %d vCPUs, %d MB RAM, %d GB disk",
+			usage.Type, usage.Count, usage.Size.CPU, usage.Size.RAM, usage.Size.Disk, usage.Total.CPU, usage.Total.RAM, usage.Total.Disk))
+	}
+	if p.Netns > 0 {
+		lines = append(lines, fmt.Sprintf("netns\t%d server(s) as network namespaces", p.Netns))
+	}
+	lines = append(lines, fmt.Sprintf("total\t\t= %d vCPUs, %d MB RAM, %d GB disk", p.Total.CPU, p.Total.RAM, p.Total.Disk), "")
+
+	for _, c := range p.Checks {
+		lines = append(lines, fmt.Sprintf("[%s]\t%s\t%s", c.Status, c.Name, c.Message))
+	}
+
+	if len(p.Suggestions) > 0 {
+		lines = append(lines, "", "Suggestions:")
+		for _, s := range p.Suggestions {
+			lines = append(lines, "  - "+s)
+		}
+	}
+
+	if _, err := fmt.Fprintln(tw, strings.Join(lines, "\n")); err != nil {
+		return fmt.Errorf("writing: %w", err)
+	}
+
+	return tw.Flush() //nolint:wrapcheck
+}
+
+// preflightVLAB fails early if the VLAB doesn't fit the host instead of VMs getting OOM-killed in the middle of install
+func (c *Config) preflightVLAB(ctx context.Context, vlab *VLAB, opts VLABUpOpts) error {
+	host, err := GetVLABHost(ctx, c.WorkDir)
+	if err != nil {
+		return fmt.Errorf("getting host resources: %w", err)
+	}
+
+	plan := PlanVLAB(vlab, host, opts.KillStale)
+	for _, check := range plan.Checks {
+		switch check.Status {
+		case VLABPlanOK:
+			slog.Debug("Pre-flight check passed", "check", check.Name, "msg", check.Message)
+		case VLABPlanWarn:
+			slog.Warn("Pre-flight check", "check", check.Name, "msg", check.Message)
+		case VLABPlanFail:
+			slog.Error("Pre-flight check failed", "check", check.Name, "msg", check.Message)
+		}
+	}
+
+	if !plan.Failed() {
+		return nil
+	}
+
+	for _, suggestion := range plan.Suggestions {
+		slog.Info("Suggestion: " + suggestion)
+	}
+
+	return errors.New("VLAB doesn't fit the host, see hhfab vlab plan for details or use --skip-preflight") //nolint:goerr113
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanVLAB(t *testing.T) {
+	vlab := &VLAB{Taps: 3}
+	vlab.VMs = append(vlab.VMs, VM{Name: "control-1", Type: VMTypeControl, Size: DefaultSizes.Control})
+	for idx := range 3 {
+		vlab.VMs = append(vlab.VMs, VM{Name: fmt.Sprintf("leaf-%02d", idx+1), Type: VMTypeSwitch, Size: DefaultSizes.Switch})
+	}
+	for idx := range 4 {
+		vlab.VMs = append(vlab.VMs, VM{Name: fmt.Sprintf("server-%02d", idx+1), Type: VMTypeServer, Size: DefaultSizes.Server})
+	}
+
+	host := VLABHost{
+		CPUs:         16,
+		RAMTotal:     32768,
+		RAMAvailable: 20000,
+		RAMStale:     8192,
+		DiskFree:     1000,
+		KVM:          true,
+		TUN:          true,
+		ExistingVMs:  map[string]bool{"control-1": true},
+	}
+
+	plan := PlanVLAB(vlab, host, true)
+	require.False(t, plan.Failed())
+	require.Equal(t, uint(26), plan.Total.CPU)
+	require.Equal(t, uint(24576), plan.Total.RAM)
+	require.Equal(t, uint(3*50+4*14), plan.Total.Disk)
+	require.Len(t, plan.Usage, 3)
+	require.Equal(t, VLABPlanUsage{Type: VMTypeSwitch, Count: 3, Size: DefaultSizes.Switch, Total: VMSize{CPU: 12, RAM: 15360, Disk: 150}}, plan.Usage[1])
+	require.Empty(t, plan.Suggestions)
+
+	plan = PlanVLAB(vlab, host, false)
+	require.True(t, plan.Failed())
+	require.Equal(t, []string{
+		"kill stale VLAB VMs to free 8192 MB: --kill-stale",
+		"run 4 server(s) as network namespaces to save 4096 MB: --server-mode=netns -f",
+		"reduce VM RAM: --control-ram=4096 --server-ram=512",
+		"use smaller topology with at least 1 switch(es) less (5376 MB each), e.g. hhfab vlab gen with lower --spines-count, --fabric-links-count or leafs counts",
+	}, plan.Suggestions)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, plan.Print(buf))
+	require.Contains(t, buf.String(), "[fail]  ram")
+
+	host.KVM = false
+	vlab.Passthroughs = []string{"0000:01:00.0"}
+	plan = PlanVLAB(vlab, host, true)
+	failed := []string{}
+	for _, c := range plan.Checks {
+		if c.Status == VLABPlanFail {
+			failed = append(failed, c.Name)
+		}
+	}
+	require.Equal(t, []string{"kvm", "vfio"}, failed)
+
+	vlab.VMs[len(vlab.VMs)-1].Netns = true
+	host.MissingCmds = []string{"iperf3"}
+	plan = PlanVLAB(vlab, host, true)
+	idx := slices.IndexFunc(plan.Checks, func(c VLABPlanCheck) bool { return c.Name == "netns" })
+	require.GreaterOrEqual(t, idx, 0)
+	require.Equal(t, VLABPlanFail, plan.Checks[idx].Status)
+	require.Contains(t, plan.Checks[idx].Message, "iperf3")
+}
@@ -113,6 +113,7 @@ type VLABRunOpts struct {
 	ReleaseTestRegexes       []string
 	ReleaseTestRegexesInvert bool
 	ReleaseTestOnReadyOnly   bool
+	ReleaseTestShard         ReleaseTestShard
 	InterfaceMTU             uint16
 }
 
@@ -286,6 +287,10 @@ func (c *Config) VLABRun(ctx context.Context, vlab *VLAB, opts VLABRunOpts) erro
 	}
 
 	for _, vm := range vlab.VMs {
+		if vm.Netns {
+			continue
+		}
+
 		vmDir := filepath.Join(c.WorkDir, VLABDir, VLABVMsDir, vm.Name)
 
 		expectedFiles := []string{VLABOSImageFile}
@@ -440,10 +445,35 @@ func (c *Config) VLABRun(ctx context.Context, vlab *VLAB, opts VLABRunOpts) erro
 	postProcesses := &sync.WaitGroup{}
 	postProcessDone := make(chan struct{})
 
+	netnsServers := 0
+	for _, vm := range vlab.VMs {
+		if vm.Netns {
+			netnsServers++
+		}
+	}
+	if netnsServers > 0 {
+		group.Go(func() error {
+			if err := c.runNetnsServers(ctx, vlab); err != nil {
+				if c.Shutdown.Load() == int32(ShutdownTypeGraceful) {
+					return nil
+				}
+
+				return err
+			}
+
+			return nil
+		})
+	}
+
 	for _, vm := range vlab.VMs {
 		vmDir := filepath.Join(c.WorkDir, VLABDir, VLABVMsDir, vm.Name)
 
 		group.Go(func() error {
+			if vm.Netns {
+				// started together by the netns servers helper
+				return nil
+			}
+
 			args := []string{
 				"-name", vm.Name,
 				"-uuid", fmt.Sprintf(VLABUUIDTmpl, vm.ID),
@@ -566,7 +596,7 @@ func (c *Config) VLABRun(ctx context.Context, vlab *VLAB, opts VLABRunOpts) erro
 		}
 	}
 
-	slog.Info("Starting VMs", "count", len(vlab.VMs), "cpu", fmt.Sprintf("%d vCPUs", cpu), "ram", fmt.Sprintf("%d MB", ram), "disk", fmt.Sprintf("%d GB", disk))
+	slog.Info("Starting VMs", "count", len(vlab.VMs)-netnsServers, "netns", netnsServers, "cpu", fmt.Sprintf("%d vCPUs", cpu), "ram", fmt.Sprintf("%d MB", ram), "disk", fmt.Sprintf("%d GB", disk))
 
 	group.Go(func() error {
 		go func() {
@@ -768,6 +798,7 @@ func (c *Config) VLABRun(ctx context.Context, vlab *VLAB, opts VLABRunOpts) erro
 						ShowTechDump:   true,
 						IPerfsMinSpeed: 8200,
 						OnReadyTest:    opts.ReleaseTestOnReadyOnly,
+						Shard:          opts.ReleaseTestShard,
 					}
 					slog.Debug("Running release-test", "opts", releaseTestOpts)
 					if err := ReleaseTest(ctx, c, vlab, releaseTestOpts); err != nil {
@@ -991,6 +1022,13 @@ func (c *Config) vmPostProcess(ctx context.Context, vlab *VLAB, d *artificer.Dow
 		return fmt.Errorf("hostname mismatch: got %q, want %q", hostname, vm.Name) //nolint:goerr113
 	}
 
+	if vm.Netns {
+		// helpers are provided by the netns servers helper itself and there is no sftp
+		slog.Debug("SSH is ready", "vm", vm.Name, "type", vm.Type, "netns", true)
+
+		return nil
+	}
+
 	ftp, cleanup, err := ssh.NewSftp()
 	if cleanup != nil {
 		defer cleanup() //nolint:errcheck
@@ -0,0 +1,837 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	vpcapi "go.githedgehog.com/fabric/api/vpc/v1beta1"
+	"go.githedgehog.com/fabric/pkg/util/kubeutil"
+	"golang.org/x/sync/errgroup"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	VLABSnapshotsDir      = "snapshots"
+	VLABSnapshotFile      = "snapshot.yaml"
+	VLABSnapshotTagPrefix = "hhfab-"
+
+	// drive id of the OS disk as passed to qemu in VLABRun
+	vlabOSDriveID = "disk1"
+)
+
+var snapshotNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+type VLABSnapshot struct {
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+	VMs     []string  `json:"vms"`
+	VPCs    int       `json:"vpcs"`
+}
+
+type VLABSnapshotOpts struct {
+	WaitReady bool // wait for switches to be ready after restore
+}
+
+func snapshotDir(workDir, name string) string {
+	return filepath.Join(workDir, VLABDir, VLABSnapshotsDir, name)
+}
+
+func checkSnapshotName(name string) error {
+	if !snapshotNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid snapshot name %q: lowercase alphanumeric and '-' only", name) //nolint:goerr113
+	}
+
+	return nil
+}
+
+// qmp is a minimal QEMU Machine Protocol client
+type qmp struct {
+	vm     string
+	w      io.Writer
+	r      *bufio.Reader
+	closer func() error
+}
+
+type qmpResponse struct {
+	Return json.RawMessage `json:"return"`
+	Event  string          `json:"event"`
+	Error  *struct {
+		Class string `json:"class"`
+		Desc  string `json:"desc"`
+	} `json:"error"`
+}
+
+// dialQMP connects to the VM QMP socket, it's owned by root so socat is used under sudo same as for serial
+func dialQMP(ctx context.Context, vmDir, vm string) (*qmp, error) {
+	cmd := exec.CommandContext(ctx, VLABCmdSudo, VLABCmdSocat, "STDIO", "UNIX-CONNECT:"+filepath.Join(vmDir, VLABQMPSock))
+	cmd.Stderr = os.Stderr
+
+	w, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("creating stdin pipe: %w", err)
+	}
+	r, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("creating stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %s: %w", VLABCmdSocat, err)
+	}
+
+	q := newQMP(vm, w, r, func() error {
+		_ = w.Close()
+
+		return cmd.Wait() //nolint:wrapcheck
+	})
+	if err := q.handshake(); err != nil {
+		_ = q.Close()
+
+		return nil, fmt.Errorf("connecting to QMP of vm %s: %w", vm, err)
+	}
+
+	return q, nil
+}
+
+func newQMP(vm string, w io.Writer, r io.Reader, closer func() error) *qmp {
+	return &qmp{vm: vm, w: w, r: bufio.NewReader(r), closer: closer}
+}
+
+func (q *qmp) Close() error {
+	if q.closer == nil {
+		return nil
+	}
+
+	closer := q.closer
+	q.closer = nil
+
+	return closer()
+}
+
+func (q *qmp) handshake() error {
+	greeting, err := q.r.ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("reading greeting: %w", err)
+	}
+	if !strings.Contains(string(greeting), `"QMP"`) {
+		return fmt.Errorf("unexpected greeting: %s", strings.TrimSpace(string(greeting))) //nolint:goerr113
+	}
+
+	return q.execute("qmp_capabilities", nil, nil)
+}
+
+func (q *qmp) execute(cmd string, args, result any) error {
+	req := map[string]any{"execute": cmd}
+	if args != nil {
+		req["arguments"] = args
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshaling %s: %w", cmd, err)
+	}
+	if _, err := q.w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("sending %s: %w", cmd, err)
+	}
+
+	for {
+		line, err := q.r.ReadBytes('\n')
+		if err != nil {
+			return fmt.Errorf("reading %s response: %w", cmd, err)
+		}
+
+		resp := qmpResponse{}
+		if err := json.Unmarshal(line, &resp); err != nil {
+			return fmt.Errorf("unmarshaling %s response: %w", cmd, err)
+		}
+		if resp.Event != "" {
+			slog.Debug("QMP event", "vm", q.vm, "event", resp.Event)
+
+			continue
+		}
+		if resp.Error != nil {
+			return fmt.Errorf("%s: %s: %s", cmd, resp.Error.Class, resp.Error.Desc) //nolint:goerr113
+		}
+		if result != nil && len(resp.Return) > 0 {
+			if err := json.Unmarshal(resp.Return, result); err != nil {
+				return fmt.Errorf("unmarshaling %s result: %w", cmd, err)
+			}
+		}
+
+		return nil
+	}
+}
+
+func (q *qmp) running() (bool, error) {
+	status := struct {
+		Running bool `json:"running"`
+	}{}
+	if err := q.execute("query-status", nil, &status); err != nil {
+		return false, err
+	}
+
+	return status.Running, nil
+}
+
+func (q *qmp) osDiskNode() (string, error) {
+	blocks := []struct {
+		Device   string `json:"device"`
+		Inserted *struct {
+			NodeName string `json:"node-name"`
+		} `json:"inserted"`
+	}{}
+	if err := q.execute("query-block", nil, &blocks); err != nil {
+		return "", err
+	}
+
+	for _, block := range blocks {
+		if block.Device == vlabOSDriveID && block.Inserted != nil {
+			return block.Inserted.NodeName, nil
+		}
+	}
+
+	return "", fmt.Errorf("os disk %q not found", vlabOSDriveID) //nolint:goerr113
+}
+
+func (q *qmp) hasSnapshot(tag string) (bool, error) {
+	blocks := []struct {
+		Device   string `json:"device"`
+		Inserted *struct {
+			Image struct {
+				Snapshots []struct {
+					Name string `json:"name"`
+				} `json:"snapshots"`
+			} `json:"image"`
+		} `json:"inserted"`
+	}{}
+	if err := q.execute("query-block", nil, &blocks); err != nil {
+		return false, err
+	}
+
+	for _, block := range blocks {
+		if block.Device != vlabOSDriveID || block.Inserted == nil {
+			continue
+		}
+
+		for _, snap := range block.Inserted.Image.Snapshots {
+			if snap.Name == tag {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+
+	return false, fmt.Errorf("os disk %q not found", vlabOSDriveID) //nolint:goerr113
+}
+
+// snapshotJob runs one of snapshot-save, snapshot-load or snapshot-delete on the OS disk only (EFI vars are raw
+// pflash and can't hold internal snapshots) and waits for it to finish
+func (q *qmp) snapshotJob(ctx context.Context, cmd, tag string) error {
+	node, err := q.osDiskNode()
+	if err != nil {
+		return err
+	}
+
+	jobID := "hhfab-" + cmd
+	args := map[string]any{
+		"job-id":  jobID,
+		"tag":     tag,
+		"devices": []string{node},
+	}
+	if cmd != "snapshot-delete" {
+		args["vmstate"] = node
+	}
+	if err := q.execute(cmd, args, nil); err != nil {
+		return err
+	}
+
+	for {
+		jobs := []struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+			Error  string `json:"error"`
+		}{}
+		if err := q.execute("query-jobs", nil, &jobs); err != nil {
+			return err
+		}
+
+		for _, job := range jobs {
+			if job.ID != jobID || job.Status != "concluded" {
+				continue
+			}
+
+			if err := q.execute("job-dismiss", map[string]any{"id": jobID}, nil); err != nil {
+				return err
+			}
+			if job.Error != "" {
+				return fmt.Errorf("%s: %s", cmd, job.Error) //nolint:goerr113
+			}
+
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for %s: %w", cmd, ctx.Err())
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+type vlabQMPs map[string]*qmp
+
+func (c *Config) dialVLABQMPs(ctx context.Context, vlab *VLAB) (vlabQMPs, error) {
+	qmps := vlabQMPs{}
+	for _, vm := range vlab.VMs {
+		if vm.Netns {
+			continue
+		}
+
+		vmDir := filepath.Join(c.WorkDir, VLABDir, VLABVMsDir, vm.Name)
+		if !isPresent(vmDir, VLABQMPSock) {
+			qmps.Close()
+
+			return nil, fmt.Errorf("vm %s is not running, VLAB should be up", vm.Name) //nolint:goerr113
+		}
+
+		q, err := dialQMP(ctx, vmDir, vm.Name)
+		if err != nil {
+			qmps.Close()
+
+			return nil, err
+		}
+		qmps[vm.Name] = q
+	}
+
+	return qmps, nil
+}
+
+func (qmps vlabQMPs) Close() {
+	for vm, q := range qmps {
+		if err := q.Close(); err != nil {
+			slog.Debug("Closing QMP connection", "vm", vm, "err", err)
+		}
+	}
+}
+
+// stopAll pauses all VMs so the snapshot is consistent across the whole fabric and returns VMs that were running
+func (qmps vlabQMPs) stopAll() ([]string, error) {
+	running := []string{}
+	for vm, q := range qmps {
+		ok, err := q.running()
+		if err != nil {
+			return running, fmt.Errorf("querying vm %s status: %w", vm, err)
+		}
+		if !ok {
+			continue
+		}
+		if err := q.execute("stop", nil, nil); err != nil {
+			return running, fmt.Errorf("pausing vm %s: %w", vm, err)
+		}
+		running = append(running, vm)
+	}
+
+	return running, nil
+}
+
+func (qmps vlabQMPs) cont(vms []string) error {
+	errs := []error{}
+	for _, vm := range vms {
+		if err := qmps[vm].execute("cont", nil, nil); err != nil {
+			errs = append(errs, fmt.Errorf("resuming vm %s: %w", vm, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// snapshotJobs runs the snapshot job on all VMs in parallel and returns VMs it succeeded on even if some failed
+func (qmps vlabQMPs) snapshotJobs(ctx context.Context, cmd, tag string) ([]string, error) {
+	mu := sync.Mutex{}
+	done := []string{}
+
+	g, ctx := errgroup.WithContext(ctx)
+	for vm, q := range qmps {
+		g.Go(func() error {
+			start := time.Now()
+			if err := q.snapshotJob(ctx, cmd, tag); err != nil {
+				return fmt.Errorf("vm %s: %w", vm, err)
+			}
+			slog.Debug("Snapshot job done", "vm", vm, "job", cmd, "took", time.Since(start))
+
+			mu.Lock()
+			done = append(done, vm)
+			mu.Unlock()
+
+			return nil
+		})
+	}
+	err := g.Wait()
+	slices.Sort(done)
+
+	return done, err //nolint:wrapcheck
+}
+
+// withSnapshot returns the subset of VMs that have the snapshot on the OS disk
+func (qmps vlabQMPs) withSnapshot(tag string) (vlabQMPs, error) {
+	res := vlabQMPs{}
+	for vm, q := range qmps {
+		ok, err := q.hasSnapshot(tag)
+		if err != nil {
+			return nil, fmt.Errorf("checking vm %s snapshots: %w", vm, err)
+		}
+		if ok {
+			res[vm] = q
+		}
+	}
+
+	return res, nil
+}
+
+func (qmps vlabQMPs) only(vms []string) vlabQMPs {
+	res := vlabQMPs{}
+	for _, vm := range vms {
+		if q, ok := qmps[vm]; ok {
+			res[vm] = q
+		}
+	}
+
+	return res
+}
+
+func (c *Config) VLABSnapshotSave(ctx context.Context, vlab *VLAB, name string) error {
+	if err := checkSnapshotName(name); err != nil {
+		return err
+	}
+
+	dir := snapshotDir(c.WorkDir, name)
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("snapshot %q already exists", name) //nolint:goerr113
+	}
+
+	start := time.Now()
+	snap := VLABSnapshot{
+		Name: name,
+		VMs:  vlabVMNames(vlab),
+		VPCs: c.countVPCs(ctx),
+	}
+
+	qmps, err := c.dialVLABQMPs(ctx, vlab)
+	if err != nil {
+		return err
+	}
+	defer qmps.Close()
+
+	slog.Info("Pausing VMs", "count", len(qmps))
+	running, err := qmps.stopAll()
+	defer func() {
+		if err := qmps.cont(running); err != nil {
+			slog.Warn("Failed to resume VMs", "err", err)
+		}
+	}()
+	if err != nil {
+		return err
+	}
+	snap.Created = time.Now()
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("creating snapshot dir: %w", err)
+	}
+
+	// partially saved snapshot is removed from the VMs and the disk so it doesn't block saving with the same name
+	tag := VLABSnapshotTagPrefix + name
+	saved := []string{}
+	ok := false
+	defer func() {
+		if ok {
+			return
+		}
+
+		if len(saved) > 0 {
+			slog.Info("Removing partially saved snapshot", "name", name, "vms", len(saved))
+			if _, err := qmps.only(saved).snapshotJobs(context.WithoutCancel(ctx), "snapshot-delete", tag); err != nil {
+				slog.Warn("Failed to remove partially saved snapshot", "name", name, "err", err)
+			}
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			slog.Warn("Failed to remove snapshot dir", "name", name, "err", err)
+		}
+	}()
+
+	slog.Info("Saving snapshot", "name", name, "vms", len(qmps))
+	saved, err = qmps.snapshotJobs(ctx, "snapshot-save", tag)
+	if err != nil {
+		return fmt.Errorf("saving snapshot: %w", err)
+	}
+
+	for _, vm := range vlab.VMs {
+		if vm.Netns {
+			continue
+		}
+
+		if err := copySnapshotEFIVars(
+			filepath.Join(c.WorkDir, VLABDir, VLABVMsDir, vm.Name),
+			filepath.Join(dir, vm.Name),
+		); err != nil {
+			return fmt.Errorf("saving vm %s EFI vars: %w", vm.Name, err)
+		}
+	}
+
+	data, err := yaml.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("marshaling snapshot: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, VLABSnapshotFile), data, 0o600); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	ok = true
+
+	slog.Info("Snapshot saved", "name", name, "vpcs", snap.VPCs, "took", time.Since(start))
+
+	return nil
+}
+
+func (c *Config) VLABSnapshotRestore(ctx context.Context, vlab *VLAB, name string, opts VLABSnapshotOpts) error {
+	snap, err := LoadVLABSnapshot(c.WorkDir, name)
+	if err != nil {
+		return err
+	}
+
+	if vms := vlabVMNames(vlab); !slices.Equal(vms, snap.VMs) {
+		return fmt.Errorf("snapshot %q VMs %v don't match VLAB VMs %v", name, snap.VMs, vms) //nolint:goerr113
+	}
+
+	start := time.Now()
+
+	qmps, err := c.dialVLABQMPs(ctx, vlab)
+	if err != nil {
+		return err
+	}
+	defer qmps.Close()
+
+	slog.Info("Pausing VMs", "count", len(qmps))
+	running, err := qmps.stopAll()
+	// VMs that were running are resumed if restore fails in the middle so VLAB isn't left paused
+	defer func() {
+		if err := qmps.cont(running); err != nil {
+			slog.Warn("Failed to resume VMs", "err", err)
+		}
+	}()
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Restoring snapshot", "name", name, "created", snap.Created.Format(time.RFC3339))
+	if _, err := qmps.snapshotJobs(ctx, "snapshot-load", VLABSnapshotTagPrefix+name); err != nil {
+		return fmt.Errorf("loading snapshot: %w", err)
+	}
+
+	dir := snapshotDir(c.WorkDir, name)
+	for _, vm := range vlab.VMs {
+		if vm.Netns {
+			continue
+		}
+
+		if err := copySnapshotEFIVars(
+			filepath.Join(dir, vm.Name),
+			filepath.Join(c.WorkDir, VLABDir, VLABVMsDir, vm.Name),
+		); err != nil {
+			return fmt.Errorf("restoring vm %s EFI vars: %w", vm.Name, err)
+		}
+	}
+
+	// all VMs are resumed together so they keep the same relative state as at save time
+	running = nil
+	if err := qmps.cont(snap.VMs); err != nil {
+		return err
+	}
+	qmps.Close()
+
+	slog.Info("Snapshot loaded, syncing clocks", "behind", time.Since(snap.Created).Round(time.Second))
+	c.syncVLABClocks(ctx, vlab)
+
+	slog.Info("Renewing DHCP leases on servers")
+	c.renewVLABLeases(ctx, vlab)
+
+	if opts.WaitReady {
+		if err := c.Wait(ctx, vlab); err != nil {
+			return fmt.Errorf("waiting for ready: %w", err)
+		}
+	}
+
+	slog.Info("Snapshot restored", "name", name, "took", time.Since(start))
+
+	return nil
+}
+
+// VLABSnapshotDelete removes the snapshot from the VMs that have it and its dir, snapshot.yaml isn't required so
+// leftovers of the interrupted save could be cleaned up as well
+func (c *Config) VLABSnapshotDelete(ctx context.Context, vlab *VLAB, name string) error {
+	if err := checkSnapshotName(name); err != nil {
+		return err
+	}
+
+	dir := snapshotDir(c.WorkDir, name)
+	dirExists := true
+	if _, err := os.Stat(dir); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("checking snapshot dir: %w", err)
+		}
+		dirExists = false
+	}
+
+	tag := VLABSnapshotTagPrefix + name
+	deleted := 0
+	if qmps, err := c.dialVLABQMPs(ctx, vlab); err == nil {
+		defer qmps.Close()
+
+		tagged, err := qmps.withSnapshot(tag)
+		if err != nil {
+			return err
+		}
+
+		done, err := tagged.snapshotJobs(ctx, "snapshot-delete", tag)
+		deleted = len(done)
+		if err != nil {
+			return fmt.Errorf("deleting snapshot: %w", err)
+		}
+	} else {
+		slog.Debug("VLAB isn't running, deleting snapshot offline", "err", err)
+
+		for _, vm := range vlab.VMs {
+			if vm.Netns {
+				continue
+			}
+
+			vmDir := filepath.Join(c.WorkDir, VLABDir, VLABVMsDir, vm.Name)
+			if !isPresent(vmDir, VLABOSImageFile) {
+				continue
+			}
+
+			ok, err := qemuImgHasSnapshot(ctx, vmDir, tag)
+			if err != nil {
+				return fmt.Errorf("checking vm %s snapshots: %w", vm.Name, err)
+			}
+			if !ok {
+				continue
+			}
+
+			if err := execCmd(ctx, false, vmDir, VLABCmdQemuImg, []string{"snapshot", "-d", tag, VLABOSImageFile}, "vm", vm.Name); err != nil {
+				return fmt.Errorf("deleting vm %s snapshot: %w", vm.Name, err)
+			}
+			deleted++
+		}
+	}
+
+	if !dirExists && deleted == 0 {
+		return fmt.Errorf("snapshot %q not found", name) //nolint:goerr113
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("removing snapshot dir: %w", err)
+	}
+
+	slog.Info("Snapshot deleted", "name", name, "vms", deleted)
+
+	return nil
+}
+
+// qemuImgHasSnapshot checks if the stopped VM OS disk has the snapshot using tag column of qemu-img snapshot list
+func qemuImgHasSnapshot(ctx context.Context, vmDir, tag string) (bool, error) {
+	cmd := exec.CommandContext(ctx, VLABCmdQemuImg, "snapshot", "-l", VLABOSImageFile)
+	cmd.Dir = vmDir
+	out, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("listing snapshots: %w", err)
+	}
+
+	return qemuImgSnapshotListHas(string(out), tag), nil
+}
+
+func qemuImgSnapshotListHas(out, tag string) bool {
+	for _, line := range strings.Split(out, "\n") {
+		if fields := strings.Fields(line); len(fields) > 1 && fields[1] == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func LoadVLABSnapshot(workDir, name string) (*VLABSnapshot, error) {
+	if err := checkSnapshotName(name); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(snapshotDir(workDir, name), VLABSnapshotFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("snapshot %q not found", name) //nolint:goerr113
+		}
+
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+
+	snap := &VLABSnapshot{}
+	if err := yaml.UnmarshalStrict(data, snap); err != nil {
+		return nil, fmt.Errorf("unmarshaling snapshot %q: %w", name, err)
+	}
+
+	return snap, nil
+}
+
+func ListVLABSnapshots(workDir string) ([]VLABSnapshot, error) {
+	entries, err := os.ReadDir(filepath.Join(workDir, VLABDir, VLABSnapshotsDir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("reading snapshots dir: %w", err)
+	}
+
+	snaps := []VLABSnapshot{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		snap, err := LoadVLABSnapshot(workDir, entry.Name())
+		if err != nil {
+			slog.Warn("Skipping broken snapshot", "name", entry.Name(), "err", err)
+
+			continue
+		}
+		snaps = append(snaps, *snap)
+	}
+
+	sort.Slice(snaps, func(i, j int) bool {
+		return snaps[i].Created.Before(snaps[j].Created)
+	})
+
+	return snaps, nil
+}
+
+func vlabVMNames(vlab *VLAB) []string {
+	vms := []string{}
+	for _, vm := range vlab.VMs {
+		if vm.Netns {
+			continue
+		}
+
+		vms = append(vms, vm.Name)
+	}
+	sort.Strings(vms)
+
+	return vms
+}
+
+func copySnapshotEFIVars(from, to string) error {
+	src := filepath.Join(from, VLABEFIVarsFile)
+	if _, err := os.Stat(src); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err := os.MkdirAll(to, 0o700); err != nil {
+		return fmt.Errorf("creating dir: %w", err)
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("reading: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(to, VLABEFIVarsFile), data, 0o600); err != nil {
+		return fmt.Errorf("writing: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Config) countVPCs(ctx context.Context) int {
+	kube, err := kubeutil.NewClient(ctx, filepath.Join(c.WorkDir, VLABDir, VLABKubeConfig), vpcapi.AddToScheme)
+	if err != nil {
+		slog.Debug("Failed to create kube client to count VPCs", "err", err)
+
+		return 0
+	}
+
+	vpcs := &vpcapi.VPCList{}
+	if err := kube.List(ctx, vpcs); err != nil {
+		slog.Debug("Failed to list VPCs", "err", err)
+
+		return 0
+	}
+
+	return len(vpcs.Items)
+}
+
+// syncVLABClocks steps clocks on all VMs as after restore they are at the snapshot time, control node goes first so
+// k8s sees consistent time as soon as possible
+func (c *Config) syncVLABClocks(ctx context.Context, vlab *VLAB) {
+	vms := slices.Clone(vlab.VMs)
+	sort.SliceStable(vms, func(i, j int) bool {
+		return vms[i].Type == VMTypeControl && vms[j].Type != VMTypeControl
+	})
+
+	for _, vm := range vms {
+		if vm.Netns {
+			continue
+		}
+
+		if err := c.vmRun(ctx, vlab, vm, fmt.Sprintf("sudo date -u -s @%d", time.Now().Unix())); err != nil {
+			slog.Warn("Failed to sync clock", "vm", vm.Name, "err", err)
+		}
+	}
+}
+
+// renewVLABLeases renews DHCP leases on servers as they may be expired from the control node point of view
+func (c *Config) renewVLABLeases(ctx context.Context, vlab *VLAB) {
+	for _, vm := range vlab.VMs {
+		if vm.Type != VMTypeServer || vm.Netns {
+			continue
+		}
+
+		if err := c.vmRun(ctx, vlab, vm, "networkctl list --no-legend | awk '{print $2}' | xargs -r -n1 sudo networkctl renew 2>/dev/null || true"); err != nil {
+			slog.Warn("Failed to renew DHCP leases", "vm", vm.Name, "err", err)
+		}
+	}
+}
+
+func (c *Config) vmRun(ctx context.Context, vlab *VLAB, vm VM, cmd string) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	ssh, err := c.SSHVM(ctx, vlab, vm)
+	if err != nil {
+		return fmt.Errorf("creating ssh client: %w", err)
+	}
+
+	if err := ssh.Wait(ctx); err != nil {
+		return fmt.Errorf("waiting for ssh: %w", err)
+	}
+
+	if _, stderr, err := ssh.Run(ctx, cmd); err != nil {
+		return fmt.Errorf("running %q: %w: %s", cmd, err, strings.TrimSpace(stderr))
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+// fakeQMP answers QMP commands using the provided handler and returns the executed commands when done
+func fakeQMP(t *testing.T, handler func(cmd string, args map[string]any) string) (*qmp, <-chan []string) {
+	t.Helper()
+
+	reqR, reqW := io.Pipe()
+	respR, respW := io.Pipe()
+	done := make(chan []string, 1)
+
+	go func() {
+		cmds := []string{}
+		defer func() { done <- cmds }()
+
+		_, _ = respW.Write([]byte(`{"QMP": {"version": {}, "capabilities": []}}` + "\n"))
+
+		scanner := bufio.NewScanner(reqR)
+		for scanner.Scan() {
+			req := struct {
+				Execute   string         `json:"execute"`
+				Arguments map[string]any `json:"arguments"`
+			}{}
+			if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+				return
+			}
+			cmds = append(cmds, req.Execute)
+
+			resp := `{"return": {}}`
+			if req.Execute != "qmp_capabilities" {
+				resp = handler(req.Execute, req.Arguments)
+			}
+			_, _ = respW.Write([]byte(`{"event": "TEST", "data": {}}` + "\n" + resp + "\n"))
+		}
+	}()
+
+	q := newQMP("test", reqW, respR, func() error {
+		_ = reqW.Close()
+
+		return respW.Close()
+	})
+	require.NoError(t, q.handshake())
+
+	return q, done
+}
+
+func TestQMPSnapshotJob(t *testing.T) {
+	ctx := context.Background()
+
+	polls := 0
+	q, done := fakeQMP(t, func(cmd string, args map[string]any) string {
+		switch cmd {
+		case "query-block":
+			return `{"return": [{"device": "pflash1", "inserted": {"node-name": "#block1"}}, {"device": "disk1", "inserted": {"node-name": "#block2"}}]}`
+		case "snapshot-save":
+			if args["vmstate"] != "#block2" || args["tag"] != "hhfab-test" {
+				return `{"error": {"class": "GenericError", "desc": "unexpected args"}}`
+			}
+
+			return `{"return": {}}`
+		case "query-jobs":
+			polls++
+			if polls < 2 {
+				return `{"return": [{"id": "hhfab-snapshot-save", "status": "running"}]}`
+			}
+
+			return `{"return": [{"id": "hhfab-snapshot-save", "status": "concluded"}]}`
+		case "snapshot-load":
+			return `{"error": {"class": "GenericError", "desc": "not supported"}}`
+		}
+
+		return `{"return": {}}`
+	})
+
+	require.NoError(t, q.snapshotJob(ctx, "snapshot-save", VLABSnapshotTagPrefix+"test"))
+	require.ErrorContains(t, q.snapshotJob(ctx, "snapshot-load", VLABSnapshotTagPrefix+"test"), "not supported")
+	require.NoError(t, q.Close())
+
+	require.Equal(t, []string{
+		"qmp_capabilities",
+		"query-block", "snapshot-save", "query-jobs", "query-jobs", "job-dismiss",
+		"query-block", "snapshot-load",
+	}, <-done)
+}
+
+func TestQMPHasSnapshot(t *testing.T) {
+	q, done := fakeQMP(t, func(_ string, _ map[string]any) string {
+		return `{"return": [{"device": "pflash1", "inserted": {"image": {}}}, {"device": "disk1", "inserted": {"image": {"snapshots": [{"name": "hhfab-base"}]}}}]}`
+	})
+
+	ok, err := q.hasSnapshot(VLABSnapshotTagPrefix + "base")
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = q.hasSnapshot(VLABSnapshotTagPrefix + "vpcs")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.NoError(t, q.Close())
+
+	require.Equal(t, []string{"qmp_capabilities", "query-block", "query-block"}, <-done)
+}
+
+func TestQemuImgSnapshotListHas(t *testing.T) {
+	out := `Snapshot list:
+ID        TAG               VM_SIZE                DATE        VM_CLOCK     ICOUNT
+1         hhfab-base        1.2 GiB 2025-06-01 10:00:00  0000:10:00.000          0
+2         hhfab-base-vpcs   1.3 GiB 2025-06-01 11:00:00  0000:20:00.000          0
+`
+
+	require.True(t, qemuImgSnapshotListHas(out, VLABSnapshotTagPrefix+"base"))
+	require.True(t, qemuImgSnapshotListHas(out, VLABSnapshotTagPrefix+"base-vpcs"))
+	require.False(t, qemuImgSnapshotListHas(out, VLABSnapshotTagPrefix+"vpcs"))
+	require.False(t, qemuImgSnapshotListHas("", VLABSnapshotTagPrefix+"base"))
+}
+
+func TestVLABSnapshotDeleteWithoutFile(t *testing.T) {
+	ctx := context.Background()
+	c := &Config{WorkDir: t.TempDir()}
+
+	require.ErrorContains(t, c.VLABSnapshotDelete(ctx, &VLAB{}, "missing"), "not found")
+
+	// leftover of the interrupted save without snapshot.yaml
+	require.NoError(t, os.MkdirAll(snapshotDir(c.WorkDir, "partial"), 0o700))
+	require.NoError(t, c.VLABSnapshotDelete(ctx, &VLAB{}, "partial"))
+	require.NoDirExists(t, snapshotDir(c.WorkDir, "partial"))
+}
+
+func TestVLABSnapshotList(t *testing.T) {
+	workDir := t.TempDir()
+
+	snaps, err := ListVLABSnapshots(workDir)
+	require.NoError(t, err)
+	require.Empty(t, snaps)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for idx, name := range []string{"vpcs", "base"} {
+		data, err := yaml.Marshal(VLABSnapshot{Name: name, Created: now.Add(-time.Duration(idx) * time.Hour), VMs: []string{"control-1"}})
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(snapshotDir(workDir, name), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(snapshotDir(workDir, name), VLABSnapshotFile), data, 0o600))
+	}
+
+	snaps, err = ListVLABSnapshots(workDir)
+	require.NoError(t, err)
+	require.Len(t, snaps, 2)
+	require.Equal(t, "base", snaps[0].Name)
+	require.Equal(t, now, snaps[1].Created.UTC())
+
+	_, err = LoadVLABSnapshot(workDir, "missing")
+	require.ErrorContains(t, err, "not found")
+	_, err = LoadVLABSnapshot(workDir, "../base")
+	require.ErrorContains(t, err, "invalid snapshot name")
+}
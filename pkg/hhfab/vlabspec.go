@@ -0,0 +1,428 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	gwapi "go.githedgehog.com/fabric/api/gateway/v1alpha1"
+	"go.githedgehog.com/fabric/api/meta"
+	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
+	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
+	"go.githedgehog.com/fabricator/pkg/util/apiutil"
+	kyaml "sigs.k8s.io/yaml"
+)
+
+// VLABSpec is a declarative VLAB topology, it's an alternative to the VLAB generate flags that allows to describe
+// each switch group and leaf individually, e.g. to get leaves with different server mixes or profiles
+type VLABSpec struct {
+	DefaultSwitchProfile string                `json:"defaultSwitchProfile,omitempty"`
+	ServerPortBase       string                `json:"serverPortBase,omitempty"`
+	Spines               VLABSpecSpines        `json:"spines,omitempty"`
+	MeshLinks            uint8                 `json:"meshLinks,omitempty"`    // number of mesh links for each leaf pair, can't be used with spines
+	SwitchGroups         []VLABSpecSwitchGroup `json:"switchGroups,omitempty"` // ESLAG leaf groups
+	Leaves               []VLABSpecLeaf        `json:"leaves,omitempty"`       // orphan leaves
+	MultiHomedServers    uint8                 `json:"multiHomedServers,omitempty"`
+	Gateway              VLABSpecGateway       `json:"gateway,omitempty"`
+	Externals            VLABSpecExternals     `json:"externals,omitempty"`
+}
+
+type VLABSpecSpines struct {
+	Count       uint8  `json:"count,omitempty"`
+	FabricLinks uint8  `json:"fabricLinks,omitempty"` // default number of links for each spine <> leaf pair, 2 if not set
+	Profile     string `json:"profile,omitempty"`
+}
+
+type VLABSpecSwitchGroup struct {
+	Name         string         `json:"name,omitempty"` // eslag-N if not set
+	ESLAGServers uint8          `json:"eslagServers,omitempty"`
+	Leaves       []VLABSpecLeaf `json:"leaves,omitempty"` // 2-4 leaves
+}
+
+type VLABSpecLeaf struct {
+	Name        string          `json:"name,omitempty"` // leaf-NN if not set
+	Profile     string          `json:"profile,omitempty"`
+	FabricLinks uint8           `json:"fabricLinks,omitempty"` // overrides spines.fabricLinks for this leaf
+	Servers     VLABSpecServers `json:"servers,omitempty"`
+	Externals   uint8           `json:"externals,omitempty"` // number of external connections
+}
+
+type VLABSpecServers struct {
+	Unbundled uint8 `json:"unbundled,omitempty"`
+	Bundled   uint8 `json:"bundled,omitempty"`
+}
+
+type VLABSpecGateway struct {
+	Uplinks  uint8             `json:"uplinks,omitempty"`
+	Driver   string            `json:"driver,omitempty"`
+	Workers  uint8             `json:"workers,omitempty"`
+	LogLevel string            `json:"logLevel,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+type VLABSpecExternals struct {
+	BGP         uint8 `json:"bgp,omitempty"`
+	Static      uint8 `json:"static,omitempty"`
+	StaticProxy uint8 `json:"staticProxy,omitempty"`
+}
+
+func LoadVLABSpec(path string) (*VLABSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading VLAB spec %q: %w", path, err)
+	}
+
+	spec := &VLABSpec{}
+	if err := kyaml.UnmarshalStrict(data, spec); err != nil {
+		return nil, fmt.Errorf("unmarshaling VLAB spec %q: %w", path, err)
+	}
+
+	return spec, nil
+}
+
+type VLABBuilderSpec struct {
+	Spec    VLABSpec
+	YesFlag bool
+
+	VLABBuilderBase
+}
+
+var _ VLABBuilder = (*VLABBuilderSpec)(nil)
+
+func (b *VLABBuilderSpec) Build(ctx context.Context, l *apiutil.Loader, fabricMode meta.FabricMode, nodes []fabapi.FabNode) error {
+	if l == nil {
+		return fmt.Errorf("loader is nil") //nolint:goerr113
+	}
+	b.data = l
+
+	spec := b.Spec
+
+	if fabricMode != meta.FabricModeSpineLeaf {
+		return fmt.Errorf("unsupported fabric mode %s", fabricMode) //nolint:goerr113
+	}
+
+	if spec.DefaultSwitchProfile != "" {
+		b.DefaultSwitchProfile = spec.DefaultSwitchProfile
+	}
+	if spec.ServerPortBase != "" {
+		b.ServerPortBase = spec.ServerPortBase
+	}
+	if spec.Spines.FabricLinks == 0 {
+		spec.Spines.FabricLinks = 2
+	}
+
+	// assign names to all leaves upfront so they could be used for connections in any order
+	leaves := []VLABSpecLeaf{}
+	leafID := 1
+	for groupIdx, group := range spec.SwitchGroups {
+		if group.Name == "" {
+			spec.SwitchGroups[groupIdx].Name = fmt.Sprintf("eslag-%d", groupIdx+1)
+		}
+		if len(group.Leaves) < 2 || len(group.Leaves) > 4 {
+			return fmt.Errorf("switch group %s: ESLAG leaf group must have 2-4 leafs", spec.SwitchGroups[groupIdx].Name) //nolint:goerr113
+		}
+
+		for leafIdx, leaf := range group.Leaves {
+			if leaf.Name == "" {
+				leaf.Name = fmt.Sprintf("leaf-%02d", leafID)
+				spec.SwitchGroups[groupIdx].Leaves[leafIdx].Name = leaf.Name
+			}
+			leaves = append(leaves, leaf)
+			leafID++
+		}
+	}
+	for leafIdx, leaf := range spec.Leaves {
+		if leaf.Name == "" {
+			leaf.Name = fmt.Sprintf("leaf-%02d", leafID)
+			spec.Leaves[leafIdx].Name = leaf.Name
+		}
+		leaves = append(leaves, leaf)
+		leafID++
+	}
+
+	if spec.Spines.Count > 0 && spec.MeshLinks > 0 {
+		return fmt.Errorf("cannot use both spines and mesh links at the same time") //nolint:goerr113
+	}
+	if len(leaves) > 0 && spec.Spines.Count == 0 && spec.MeshLinks == 0 {
+		return fmt.Errorf("either spines or mesh links should be specified") //nolint:goerr113
+	}
+	if spec.MultiHomedServers > 0 && len(leaves) < 2 {
+		return fmt.Errorf("at least two leaves are needed for multihomed servers") //nolint:goerr113
+	}
+
+	overrides := maps.Clone(b.SwitchProfileOverrides)
+	if overrides == nil {
+		overrides = map[string]string{}
+	}
+	for spineID := uint8(1); spineID <= spec.Spines.Count; spineID++ {
+		if spec.Spines.Profile != "" {
+			overrides[fmt.Sprintf("spine-%02d", spineID)] = spec.Spines.Profile
+		}
+	}
+	extConns := 0
+	for _, leaf := range leaves {
+		if leaf.Profile != "" {
+			overrides[leaf.Name] = leaf.Profile
+		}
+		extConns += int(leaf.Externals)
+	}
+	b.SwitchProfileOverrides = overrides
+
+	if !slices.Contains(meta.VirtualSwitchProfiles, b.DefaultSwitchProfile) {
+		return fmt.Errorf("unsupported default switch profile %s", b.DefaultSwitchProfile) //nolint:goerr113
+	}
+	for name, profile := range b.SwitchProfileOverrides {
+		if !slices.Contains(meta.VirtualSwitchProfiles, profile) {
+			return fmt.Errorf("unsupported switch profile %s for %s", profile, name) //nolint:goerr113
+		}
+	}
+
+	// see VLABBuilderDefault for the reasons of the externals limitations
+	if extConns > 1 {
+		logMsg := "Multiple external connections are not supported if using virtual switches"
+		if b.YesFlag {
+			slog.Warn(logMsg, "externalConns", extConns)
+			slog.Warn("Proceeding anyway due to --yes flag")
+		} else {
+			slog.Error(logMsg, "externalConns", extConns)
+			slog.Error("Use --yes to proceed anyway")
+
+			return errors.New(logMsg) //nolint:goerr113
+		}
+	}
+	if spec.Externals.Static > 1 {
+		return fmt.Errorf("multiple non-proxy static externals are not supported") //nolint:goerr113
+	}
+
+	gws := gatewayNodes(nodes)
+	gwLogs := gwapi.GatewayLogs{}
+	gw := spec.Gateway
+	if len(gws) > 0 {
+		if gw.Driver == "" {
+			gw.Driver = GatewayDriverKernel
+		}
+		if gw.Workers == 0 {
+			gw.Workers = 8
+		}
+		if gw.LogLevel == "" {
+			gw.LogLevel = string(gwapi.GatewayLogLevelInfo)
+		}
+		if !slices.Contains(GatewayDrivers, gw.Driver) {
+			return fmt.Errorf("unsupported gateway driver %s", gw.Driver) //nolint:goerr113
+		}
+
+		var err error
+		gwLogs, err = gatewayLogs(gw.LogLevel, gw.Tags)
+		if err != nil {
+			return err
+		}
+
+		uplinkSwitches := len(leaves)
+		if spec.Spines.Count > 0 {
+			uplinkSwitches = int(spec.Spines.Count)
+		}
+		if gw.Uplinks == 0 {
+			gw.Uplinks = uint8(min(2, uplinkSwitches)) //nolint:gosec
+		} else if int(gw.Uplinks) > uplinkSwitches {
+			return fmt.Errorf("gateway uplinks count must be ≤ number of spines (or leaves for mesh)") //nolint:goerr113
+		}
+	}
+
+	slog.Info("Building VLAB wiring diagram from spec", "fabricMode", fabricMode)
+	slog.Info(">>>", "profile", b.DefaultSwitchProfile)
+	slog.Info(">>>", "spinesCount", spec.Spines.Count, "fabricLinksCount", spec.Spines.FabricLinks, "meshLinksCount", spec.MeshLinks)
+	slog.Info(">>>", "switchGroups", len(spec.SwitchGroups), "leaves", len(leaves), "multihomedServers", spec.MultiHomedServers)
+	if len(gws) > 0 {
+		slog.Info(">>>", "gateways", len(gws), "gatewayUplinks", gw.Uplinks, "gatewayDriver", gw.Driver)
+	}
+	slog.Info(">>>", "externalBGPCount", spec.Externals.BGP, "externalStaticCount", spec.Externals.Static, "externalStaticProxyCount", spec.Externals.StaticProxy, "externalConns", extConns)
+
+	if err := b.createDefaultNamespaces(ctx, 1000, "10.0.0.0/16"); err != nil {
+		return err
+	}
+
+	b.ifaceTracker = map[string]uint8{}
+
+	if err := b.createGateways(ctx, gws, gw.Uplinks, gw.Driver, gw.Workers, gwLogs); err != nil {
+		return err
+	}
+
+	if _, err := b.createSwitchGroup(ctx, "empty"); err != nil {
+		return err
+	}
+
+	switchID := 1
+	serverID := 1
+	externalConns := []wiringapi.Connection{}
+
+	createLeaf := func(leaf VLABSpecLeaf, spec wiringapi.SwitchSpec) error {
+		if _, err := b.createSwitch(ctx, leaf.Name, spec, nil); err != nil {
+			return err
+		}
+		switchID++
+
+		for range leaf.Externals {
+			var err error
+			externalConns, err = b.addExternalConnection(ctx, externalConns, leaf.Name)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	createLeafServers := func(leaf VLABSpecLeaf) error {
+		for range leaf.Servers.Unbundled {
+			serverName := fmt.Sprintf("server-%02d", serverID)
+			if err := b.createUnbundledServer(ctx, serverName, fmt.Sprintf("S-%02d Unbundled %s", serverID, leaf.Name), leaf.Name); err != nil {
+				return err
+			}
+			serverID++
+		}
+
+		for range leaf.Servers.Bundled {
+			serverName := fmt.Sprintf("server-%02d", serverID)
+			if err := b.createBundledServer(ctx, serverName, fmt.Sprintf("S-%02d Bundled %s", serverID, leaf.Name), leaf.Name); err != nil {
+				return err
+			}
+			serverID++
+		}
+
+		return nil
+	}
+
+	for groupIdx, group := range spec.SwitchGroups {
+		if _, err := b.createSwitchGroup(ctx, group.Name); err != nil {
+			return err
+		}
+
+		leafNames := []string{}
+		for _, leaf := range group.Leaves {
+			leafNames = append(leafNames, leaf.Name)
+
+			if err := createLeaf(leaf, wiringapi.SwitchSpec{
+				Role:        wiringapi.SwitchRoleServerLeaf,
+				Description: fmt.Sprintf("VS-%02d ESLAG %d", switchID, groupIdx+1),
+				Groups:      []string{group.Name},
+				Redundancy: wiringapi.SwitchRedundancy{
+					Group: group.Name,
+					Type:  meta.RedundancyTypeESLAG,
+				},
+			}); err != nil {
+				return err
+			}
+		}
+
+		for range group.ESLAGServers {
+			serverName := fmt.Sprintf("server-%02d", serverID)
+			if err := b.createESLAGServer(ctx, serverName, fmt.Sprintf("S-%02d ESLAG %s", serverID, strings.Join(leafNames, " ")), leafNames); err != nil {
+				return err
+			}
+			serverID++
+		}
+
+		for _, leaf := range group.Leaves {
+			if err := createLeafServers(leaf); err != nil {
+				return err
+			}
+		}
+	}
+
+	mhLeaves := []string{}
+	for _, leaf := range spec.Leaves {
+		mhLeaves = append(mhLeaves, leaf.Name)
+
+		if err := createLeaf(leaf, wiringapi.SwitchSpec{
+			Role:        wiringapi.SwitchRoleServerLeaf,
+			Description: fmt.Sprintf("VS-%02d", switchID),
+		}); err != nil {
+			return err
+		}
+
+		if err := createLeafServers(leaf); err != nil {
+			return err
+		}
+	}
+
+	// prefer orphan leaves for multihomed servers, same as the default builder
+	if len(mhLeaves) < 2 {
+		mhLeaves = []string{}
+		for _, leaf := range leaves {
+			mhLeaves = append(mhLeaves, leaf.Name)
+		}
+	}
+
+	mhIdx := 0
+	for range spec.MultiHomedServers {
+		serverName := fmt.Sprintf("server-%02d", serverID)
+		leaf1 := mhLeaves[mhIdx]
+		mhIdx = (mhIdx + 1) % len(mhLeaves)
+		leaf2 := mhLeaves[mhIdx]
+		mhIdx = (mhIdx + 1) % len(mhLeaves)
+
+		if err := b.createMultiHomedServer(ctx, serverName, fmt.Sprintf("S-%02d MultiHomed %s + %s", serverID, leaf1, leaf2), leaf1, leaf2); err != nil {
+			return err
+		}
+		serverID++
+	}
+
+	for spineID := uint8(1); spineID <= spec.Spines.Count; spineID++ {
+		spineName := fmt.Sprintf("spine-%02d", spineID)
+
+		if _, err := b.createSwitch(ctx, spineName, wiringapi.SwitchSpec{
+			Role:        wiringapi.SwitchRoleSpine,
+			Description: fmt.Sprintf("VS-%02d", switchID),
+		}, nil); err != nil {
+			return err
+		}
+		switchID++
+
+		for _, leaf := range leaves {
+			links := spec.Spines.FabricLinks
+			if leaf.FabricLinks > 0 {
+				links = leaf.FabricLinks
+			}
+
+			if err := b.createFabricConnection(ctx, spineName, leaf.Name, links); err != nil {
+				return err
+			}
+		}
+
+		if spineID <= gw.Uplinks {
+			for _, gwNode := range gws {
+				if err := b.createGatewayConnection(ctx, spineName, gwNode.Name, gw.Driver, spineID); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if spec.MeshLinks > 0 {
+		for idx1, leaf1 := range leaves {
+			for _, leaf2 := range leaves[idx1+1:] {
+				if err := b.createMeshConnection(ctx, leaf1.Name, leaf2.Name, spec.MeshLinks); err != nil {
+					return err
+				}
+			}
+		}
+
+		for idx := 0; idx < len(leaves) && idx < int(gw.Uplinks); idx++ {
+			for _, gwNode := range gws {
+				if err := b.createGatewayConnection(ctx, leaves[idx].Name, gwNode.Name, gw.Driver, uint8(idx+1)); err != nil { //nolint:gosec
+					return err
+				}
+			}
+		}
+	}
+
+	return b.createExternals(ctx, spec.Externals.BGP, spec.Externals.Static, spec.Externals.StaticProxy, externalConns)
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfab
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.githedgehog.com/fabric/api/meta"
+	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
+	"go.githedgehog.com/fabricator/pkg/util/apiutil"
+)
+
+func TestVLABBuilderSpec(t *testing.T) {
+	ctx := context.Background()
+
+	build := func(t *testing.T, builder VLABBuilder) *apiutil.Loader {
+		t.Helper()
+
+		l := apiutil.NewLoader()
+		require.NoError(t, builder.Build(ctx, l, meta.FabricModeSpineLeaf, nil))
+
+		return l
+	}
+
+	printWiring := func(t *testing.T, l *apiutil.Loader) string {
+		t.Helper()
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, apiutil.PrintInclude(ctx, l.GetClient(), buf))
+
+		return buf.String()
+	}
+
+	t.Run("same-as-default", func(t *testing.T) {
+		flags := build(t, &VLABBuilderDefault{
+			SpinesCount:      2,
+			FabricLinksCount: 2,
+			ESLAGLeafGroups:  "2",
+			OrphanLeafsCount: 1,
+			ESLAGServers:     2,
+			UnbundledServers: 1,
+			BundledServers:   1,
+			VLABBuilderBase:  VLABBuilderBase{DefaultSwitchProfile: meta.SwitchProfileVS},
+		})
+
+		spec := build(t, &VLABBuilderSpec{
+			Spec: VLABSpec{
+				Spines: VLABSpecSpines{Count: 2},
+				SwitchGroups: []VLABSpecSwitchGroup{{
+					ESLAGServers: 2,
+					Leaves: []VLABSpecLeaf{
+						{Servers: VLABSpecServers{Unbundled: 1}},
+						{Servers: VLABSpecServers{Bundled: 1}},
+					},
+				}},
+				Leaves: []VLABSpecLeaf{
+					{Servers: VLABSpecServers{Unbundled: 1, Bundled: 1}},
+				},
+			},
+			VLABBuilderBase: VLABBuilderBase{DefaultSwitchProfile: meta.SwitchProfileVS},
+		})
+
+		require.Equal(t, printWiring(t, flags), printWiring(t, spec))
+	})
+
+	t.Run("asymmetric", func(t *testing.T) {
+		l := build(t, &VLABBuilderSpec{
+			Spec: VLABSpec{
+				Spines: VLABSpecSpines{Count: 1, FabricLinks: 1},
+				Leaves: []VLABSpecLeaf{
+					{Name: "leaf-a", FabricLinks: 3, Servers: VLABSpecServers{Unbundled: 2}},
+					{Servers: VLABSpecServers{Bundled: 1}},
+				},
+			},
+			VLABBuilderBase: VLABBuilderBase{DefaultSwitchProfile: meta.SwitchProfileVS},
+		})
+
+		conns := &wiringapi.ConnectionList{}
+		require.NoError(t, l.GetClient().List(ctx, conns))
+		names := map[string]wiringapi.ConnectionSpec{}
+		for _, conn := range conns.Items {
+			names[conn.Name] = conn.Spec
+		}
+
+		require.Len(t, names, 5)
+		require.Len(t, names["spine-01--fabric--leaf-a"].Fabric.Links, 3)
+		require.Len(t, names["spine-01--fabric--leaf-02"].Fabric.Links, 1)
+		require.Contains(t, names, "server-02--unbundled--leaf-a")
+		require.Contains(t, names, "server-03--bundled--leaf-02")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, spec := range []VLABSpec{
+			{Spines: VLABSpecSpines{Count: 1}, MeshLinks: 1, Leaves: []VLABSpecLeaf{{}}},
+			{Leaves: []VLABSpecLeaf{{}}},
+			{Spines: VLABSpecSpines{Count: 1}, SwitchGroups: []VLABSpecSwitchGroup{{Leaves: []VLABSpecLeaf{{}}}}},
+			{Spines: VLABSpecSpines{Count: 1}, Leaves: []VLABSpecLeaf{{Profile: "unknown"}}},
+			{Spines: VLABSpecSpines{Count: 1}, Leaves: []VLABSpecLeaf{{}}, MultiHomedServers: 1},
+		} {
+			err := (&VLABBuilderSpec{
+				Spec:            spec,
+				VLABBuilderBase: VLABBuilderBase{DefaultSwitchProfile: meta.SwitchProfileVS},
+			}).Build(ctx, apiutil.NewLoader(), meta.FabricModeSpineLeaf, nil)
+			require.Error(t, err)
+		}
+	})
+}
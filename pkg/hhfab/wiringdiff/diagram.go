@@ -0,0 +1,104 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package wiringdiff
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
+	"go.githedgehog.com/fabricator/pkg/hhfab/diagram"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var opChanges = map[Op]string{
+	OpAdded:   diagram.ChangeAdded,
+	OpRemoved: diagram.ChangeRemoved,
+	OpChanged: diagram.ChangeChanged,
+}
+
+// Topology builds the diagram topology for the new wiring with the removed nodes and links taken from the old one
+// and all changed switches, servers, externals and connection links marked using diagram.PropChange
+func Topology(ctx context.Context, oldKube, newKube kclient.Reader, report *Report) (diagram.Topology, error) {
+	oldTopo, err := diagram.GetTopologyFor(ctx, oldKube)
+	if err != nil {
+		return diagram.Topology{}, fmt.Errorf("getting old topology: %w", err)
+	}
+	newTopo, err := diagram.GetTopologyFor(ctx, newKube)
+	if err != nil {
+		return diagram.Topology{}, fmt.Errorf("getting new topology: %w", err)
+	}
+
+	oldIdx, err := buildIndex(ctx, oldKube)
+	if err != nil {
+		return diagram.Topology{}, fmt.Errorf("indexing old wiring: %w", err)
+	}
+	newIdx, err := buildIndex(ctx, newKube)
+	if err != nil {
+		return diagram.Topology{}, fmt.Errorf("indexing new wiring: %w", err)
+	}
+
+	nodeChanges := map[string]string{}
+	portChanges := map[string]string{}
+	for _, change := range report.Changes {
+		switch change.Kind {
+		case wiringapi.KindSwitch, wiringapi.KindServer, kindExternal:
+			nodeChanges[change.Name] = opChanges[change.Op]
+		case wiringapi.KindConnection:
+			for _, port := range append(oldIdx.connPorts[change.Name], newIdx.connPorts[change.Name]...) {
+				portChanges[port] = opChanges[change.Op]
+			}
+		}
+	}
+
+	topo := newTopo
+	topo.HasAgentData = false
+
+	markNode := func(node diagram.Node) diagram.Node {
+		if change, ok := nodeChanges[node.ID]; ok {
+			node.Properties = maps.Clone(node.Properties)
+			if node.Properties == nil {
+				node.Properties = map[string]string{}
+			}
+			node.Properties[diagram.PropChange] = change
+		}
+
+		return node
+	}
+	markLink := func(link diagram.Link) (diagram.Link, bool) {
+		change, ok := portChanges[link.Properties[diagram.PropSourcePort]]
+		if !ok {
+			change, ok = portChanges[link.Properties[diagram.PropTargetPort]]
+		}
+		if ok {
+			link.Properties = maps.Clone(link.Properties)
+			link.Properties[diagram.PropChange] = change
+		}
+
+		return link, ok
+	}
+
+	nodes := map[string]bool{}
+	for idx, node := range topo.Nodes {
+		topo.Nodes[idx] = markNode(node)
+		nodes[node.ID] = true
+	}
+	for idx, link := range topo.Links {
+		topo.Links[idx], _ = markLink(link)
+	}
+
+	for _, node := range oldTopo.Nodes {
+		if !nodes[node.ID] && nodeChanges[node.ID] == diagram.ChangeRemoved {
+			topo.Nodes = append(topo.Nodes, markNode(node))
+		}
+	}
+	for _, link := range oldTopo.Links {
+		if link, ok := markLink(link); ok && link.Properties[diagram.PropChange] == diagram.ChangeRemoved {
+			topo.Links = append(topo.Links, link)
+		}
+	}
+
+	return topo, nil
+}
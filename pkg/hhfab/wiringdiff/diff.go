@@ -0,0 +1,267 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package wiringdiff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	vpcapi "go.githedgehog.com/fabric/api/vpc/v1beta1"
+	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
+	"go.githedgehog.com/fabricator/pkg/util/apiutil"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	includeDir = "include"
+	yamlExt    = ".yaml"
+
+	// there are no kind constants for the externals in the vpc API
+	kindExternal           = "External"
+	kindExternalAttachment = "ExternalAttachment"
+)
+
+type Op string
+
+const (
+	OpAdded   Op = "added"
+	OpRemoved Op = "removed"
+	OpChanged Op = "changed"
+)
+
+// Change is a single added, removed or changed object, Fields lists the changed top-level spec and metadata fields
+// and Switches lists the switches that would need agent config update because of the change
+type Change struct {
+	Kind     string
+	Name     string
+	Op       Op
+	Fields   []string
+	Switches []string
+}
+
+// SwitchImpact lists changed objects (as Kind/name) that affect the switch agent config
+type SwitchImpact struct {
+	Name    string
+	Reasons []string
+}
+
+type Report struct {
+	Changes  []Change
+	Switches []SwitchImpact
+}
+
+func (r *Report) Empty() bool {
+	return len(r.Changes) == 0
+}
+
+// kinds is the list of the compared object kinds in the order they're reported
+var kinds = []struct {
+	kind string
+	list func() kclient.ObjectList
+}{
+	{wiringapi.KindSwitch, func() kclient.ObjectList { return &wiringapi.SwitchList{} }},
+	{wiringapi.KindServer, func() kclient.ObjectList { return &wiringapi.ServerList{} }},
+	{wiringapi.KindConnection, func() kclient.ObjectList { return &wiringapi.ConnectionList{} }},
+	{wiringapi.KindSwitchGroup, func() kclient.ObjectList { return &wiringapi.SwitchGroupList{} }},
+	{wiringapi.KindVLANNamespace, func() kclient.ObjectList { return &wiringapi.VLANNamespaceList{} }},
+	{vpcapi.KindVPC, func() kclient.ObjectList { return &vpcapi.VPCList{} }},
+	{vpcapi.KindVPCAttachment, func() kclient.ObjectList { return &vpcapi.VPCAttachmentList{} }},
+	{vpcapi.KindVPCPeering, func() kclient.ObjectList { return &vpcapi.VPCPeeringList{} }},
+	{vpcapi.KindIPv4Namespace, func() kclient.ObjectList { return &vpcapi.IPv4NamespaceList{} }},
+	{kindExternal, func() kclient.ObjectList { return &vpcapi.ExternalList{} }},
+	{kindExternalAttachment, func() kclient.ObjectList { return &vpcapi.ExternalAttachmentList{} }},
+	{vpcapi.KindExternalPeering, func() kclient.ObjectList { return &vpcapi.ExternalPeeringList{} }},
+}
+
+// LoadDir loads wiring from the YAML file or directory, if directory has an include subdir (e.g. it's a hhfab
+// working dir) it's used instead
+func LoadDir(ctx context.Context, path string) (*apiutil.Loader, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("checking %q: %w", path, err)
+	}
+
+	files := []string{path}
+	if stat.IsDir() {
+		if stat, err := os.Stat(filepath.Join(path, includeDir)); err == nil && stat.IsDir() {
+			path = filepath.Join(path, includeDir)
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading dir %q: %w", path, err)
+		}
+
+		files = []string{}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), yamlExt) {
+				continue
+			}
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+
+	l := apiutil.NewLoader()
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading %q: %w", file, err)
+		}
+
+		if err := l.LoadAdd(ctx, apiutil.FabricGatewayGVKs, data); err != nil {
+			return nil, fmt.Errorf("loading %q: %w", file, err)
+		}
+	}
+
+	return l, nil
+}
+
+// Compare compares wiring from the old and new readers and returns the list of changes and impacted switches
+func Compare(ctx context.Context, oldKube, newKube kclient.Reader) (*Report, error) {
+	oldIdx, err := buildIndex(ctx, oldKube)
+	if err != nil {
+		return nil, fmt.Errorf("indexing old wiring: %w", err)
+	}
+	newIdx, err := buildIndex(ctx, newKube)
+	if err != nil {
+		return nil, fmt.Errorf("indexing new wiring: %w", err)
+	}
+
+	report := &Report{}
+	impacts := map[string][]string{}
+
+	for _, k := range kinds {
+		oldObjs, err := listObjects(ctx, oldKube, k.list())
+		if err != nil {
+			return nil, fmt.Errorf("listing old %s: %w", k.kind, err)
+		}
+		newObjs, err := listObjects(ctx, newKube, k.list())
+		if err != nil {
+			return nil, fmt.Errorf("listing new %s: %w", k.kind, err)
+		}
+
+		names := map[string]bool{}
+		for name := range oldObjs {
+			names[name] = true
+		}
+		for name := range newObjs {
+			names[name] = true
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(names)) {
+			oldObj, inOld := oldObjs[name]
+			newObj, inNew := newObjs[name]
+
+			change := Change{Kind: k.kind, Name: name}
+			switch {
+			case !inOld:
+				change.Op = OpAdded
+			case !inNew:
+				change.Op = OpRemoved
+			default:
+				change.Fields = changedFields(oldObj, newObj)
+				if len(change.Fields) == 0 {
+					continue
+				}
+				change.Op = OpChanged
+			}
+
+			switches := map[string]bool{}
+			if inOld {
+				for _, sw := range oldIdx.switchesFor(k.kind, name) {
+					switches[sw] = true
+				}
+			}
+			if inNew {
+				for _, sw := range newIdx.switchesFor(k.kind, name) {
+					switches[sw] = true
+				}
+			}
+			change.Switches = slices.Sorted(maps.Keys(switches))
+
+			for _, sw := range change.Switches {
+				impacts[sw] = append(impacts[sw], k.kind+"/"+name)
+			}
+
+			report.Changes = append(report.Changes, change)
+		}
+	}
+
+	for _, sw := range slices.Sorted(maps.Keys(impacts)) {
+		report.Switches = append(report.Switches, SwitchImpact{Name: sw, Reasons: impacts[sw]})
+	}
+
+	return report, nil
+}
+
+func listObjects(ctx context.Context, kube kclient.Reader, list kclient.ObjectList) (map[string]map[string]any, error) {
+	if err := kube.List(ctx, list); err != nil {
+		return nil, fmt.Errorf("listing: %w", err)
+	}
+
+	objs := map[string]map[string]any{}
+	for _, obj := range apiutil.KubeListItems(list) {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling %q: %w", obj.GetName(), err)
+		}
+
+		raw := map[string]any{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("unmarshaling %q: %w", obj.GetName(), err)
+		}
+
+		objs[obj.GetName()] = raw
+	}
+
+	return objs, nil
+}
+
+// changedFields returns list of the changed spec fields and metadata labels and annotations, status and the rest of
+// the metadata are ignored as they aren't part of the wiring
+func changedFields(oldObj, newObj map[string]any) []string {
+	fields := []string{}
+
+	oldMeta, _ := oldObj["metadata"].(map[string]any)
+	newMeta, _ := newObj["metadata"].(map[string]any)
+	for _, key := range []string{"labels", "annotations"} {
+		if !reflect.DeepEqual(oldMeta[key], newMeta[key]) {
+			fields = append(fields, "metadata."+key)
+		}
+	}
+
+	oldSpec, _ := oldObj["spec"].(map[string]any)
+	newSpec, _ := newObj["spec"].(map[string]any)
+	keys := map[string]bool{}
+	for key := range oldSpec {
+		keys[key] = true
+	}
+	for key := range newSpec {
+		keys[key] = true
+	}
+	for _, key := range slices.Sorted(maps.Keys(keys)) {
+		if !reflect.DeepEqual(oldSpec[key], newSpec[key]) {
+			fields = append(fields, "spec."+key)
+		}
+	}
+
+	return fields
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		if !slices.Contains(list, value) {
+			list = append(list, value)
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package wiringdiff
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	vpcapi "go.githedgehog.com/fabric/api/vpc/v1beta1"
+	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
+	"go.githedgehog.com/fabricator/pkg/hhfab/diagram"
+	"go.githedgehog.com/fabricator/pkg/util/apiutil"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func meta(name string) kmetav1.ObjectMeta {
+	return kmetav1.ObjectMeta{Name: name, Namespace: kmetav1.NamespaceDefault}
+}
+
+func unbundled(server, sw, port string) *wiringapi.Connection {
+	return &wiringapi.Connection{
+		ObjectMeta: meta(server + "--unbundled--" + sw),
+		Spec: wiringapi.ConnectionSpec{Unbundled: &wiringapi.ConnUnbundled{Link: wiringapi.ServerToSwitchLink{
+			Switch: wiringapi.BasePortName{Port: sw + "/" + port},
+			Server: wiringapi.BasePortName{Port: server + "/enp2s1"},
+		}}},
+	}
+}
+
+func attach(name, vpc, conn string) *vpcapi.VPCAttachment {
+	return &vpcapi.VPCAttachment{
+		ObjectMeta: meta(name),
+		Spec:       vpcapi.VPCAttachmentSpec{Subnet: vpc + "/default", Connection: conn},
+	}
+}
+
+func load(t *testing.T, objs ...kclient.Object) kclient.Client {
+	t.Helper()
+
+	l := apiutil.NewLoader()
+	require.NoError(t, l.Add(context.Background(), objs...))
+
+	return l.GetClient()
+}
+
+func TestCompare(t *testing.T) {
+	ctx := context.Background()
+
+	oldKube := load(t,
+		&wiringapi.Switch{ObjectMeta: meta("leaf-01"), Spec: wiringapi.SwitchSpec{Role: wiringapi.SwitchRoleServerLeaf}},
+		&wiringapi.Switch{ObjectMeta: meta("leaf-02"), Spec: wiringapi.SwitchSpec{Role: wiringapi.SwitchRoleServerLeaf}},
+		&wiringapi.Server{ObjectMeta: meta("server-01")},
+		&wiringapi.Server{ObjectMeta: meta("server-02")},
+		unbundled("server-01", "leaf-01", "E1/1"),
+		unbundled("server-02", "leaf-02", "E1/1"),
+		&vpcapi.VPC{ObjectMeta: meta("vpc-01"), Spec: vpcapi.VPCSpec{Subnets: map[string]*vpcapi.VPCSubnet{
+			"default": {Subnet: "10.0.1.0/24"},
+		}}},
+		attach("server-01--vpc-01", "vpc-01", "server-01--unbundled--leaf-01"),
+	)
+
+	newKube := load(t,
+		&wiringapi.Switch{ObjectMeta: meta("leaf-01"), Spec: wiringapi.SwitchSpec{Role: wiringapi.SwitchRoleServerLeaf, Description: "updated"}},
+		&wiringapi.Switch{ObjectMeta: meta("leaf-02"), Spec: wiringapi.SwitchSpec{Role: wiringapi.SwitchRoleServerLeaf}},
+		&wiringapi.Server{ObjectMeta: meta("server-01")},
+		unbundled("server-01", "leaf-01", "E1/1"),
+		&vpcapi.VPC{ObjectMeta: meta("vpc-01"), Spec: vpcapi.VPCSpec{Subnets: map[string]*vpcapi.VPCSubnet{
+			"default": {Subnet: "10.0.2.0/24"},
+		}}},
+		attach("server-01--vpc-01", "vpc-01", "server-01--unbundled--leaf-01"),
+	)
+
+	report, err := Compare(ctx, oldKube, newKube)
+	require.NoError(t, err)
+
+	require.Equal(t, []Change{
+		{Kind: wiringapi.KindSwitch, Name: "leaf-01", Op: OpChanged, Fields: []string{"spec.description"}, Switches: []string{"leaf-01"}},
+		{Kind: wiringapi.KindServer, Name: "server-02", Op: OpRemoved, Switches: []string{"leaf-02"}},
+		{Kind: wiringapi.KindConnection, Name: "server-02--unbundled--leaf-02", Op: OpRemoved, Switches: []string{"leaf-02"}},
+		{Kind: vpcapi.KindVPC, Name: "vpc-01", Op: OpChanged, Fields: []string{"spec.subnets"}, Switches: []string{"leaf-01"}},
+	}, report.Changes)
+
+	require.Equal(t, []SwitchImpact{
+		{Name: "leaf-01", Reasons: []string{"Switch/leaf-01", "VPC/vpc-01"}},
+		{Name: "leaf-02", Reasons: []string{"Server/server-02", "Connection/server-02--unbundled--leaf-02"}},
+	}, report.Switches)
+
+	topo, err := Topology(ctx, oldKube, newKube, report)
+	require.NoError(t, err)
+
+	changes := map[string]string{}
+	for _, node := range topo.Nodes {
+		changes[node.ID] = node.Properties[diagram.PropChange]
+	}
+	require.Equal(t, map[string]string{
+		"leaf-01":   diagram.ChangeChanged,
+		"leaf-02":   "",
+		"server-01": "",
+		"server-02": diagram.ChangeRemoved,
+	}, changes)
+
+	removed := 0
+	for _, link := range topo.Links {
+		if link.Properties[diagram.PropChange] == diagram.ChangeRemoved {
+			removed++
+			require.Equal(t, "leaf-02", link.Source)
+		}
+	}
+	require.Equal(t, 1, removed)
+}
@@ -0,0 +1,193 @@
+// Copyright 2025 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package wiringdiff
+
+import (
+	"context"
+	"fmt"
+
+	vpcapi "go.githedgehog.com/fabric/api/vpc/v1beta1"
+	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// index maps wiring objects to the switches which agent config depends on them
+type index struct {
+	switches       map[string]bool
+	connSwitches   map[string][]string // connection -> switches
+	connPorts      map[string][]string // connection -> ports
+	serverConns    map[string][]string // server -> connections
+	groupSwitches  map[string][]string // switch group -> switches
+	vlanNSSwitches map[string][]string // VLAN namespace -> switches
+	vpcConns       map[string][]string // VPC -> attached connections
+	ipnsVPCs       map[string][]string // IPv4 namespace -> VPCs
+	attachConn     map[string]string   // VPC attachment -> connection
+	peeringVPCs    map[string][]string // VPC peering -> VPCs
+	peeringRemote  map[string]string   // VPC peering -> remote switch group
+	extConns       map[string][]string // external -> attached connections
+	extAttachConn  map[string]string   // external attachment -> connection
+	extPeering     map[string][2]string
+}
+
+func buildIndex(ctx context.Context, kube kclient.Reader) (*index, error) {
+	idx := &index{
+		switches:       map[string]bool{},
+		connSwitches:   map[string][]string{},
+		connPorts:      map[string][]string{},
+		serverConns:    map[string][]string{},
+		groupSwitches:  map[string][]string{},
+		vlanNSSwitches: map[string][]string{},
+		vpcConns:       map[string][]string{},
+		ipnsVPCs:       map[string][]string{},
+		attachConn:     map[string]string{},
+		peeringVPCs:    map[string][]string{},
+		peeringRemote:  map[string]string{},
+		extConns:       map[string][]string{},
+		extAttachConn:  map[string]string{},
+		extPeering:     map[string][2]string{},
+	}
+
+	switches := &wiringapi.SwitchList{}
+	if err := kube.List(ctx, switches); err != nil {
+		return nil, fmt.Errorf("listing switches: %w", err)
+	}
+	for _, sw := range switches.Items {
+		idx.switches[sw.Name] = true
+		for _, group := range sw.Spec.Groups {
+			idx.groupSwitches[group] = append(idx.groupSwitches[group], sw.Name)
+		}
+		for _, ns := range sw.Spec.VLANNamespaces {
+			idx.vlanNSSwitches[ns] = append(idx.vlanNSSwitches[ns], sw.Name)
+		}
+	}
+
+	conns := &wiringapi.ConnectionList{}
+	if err := kube.List(ctx, conns); err != nil {
+		return nil, fmt.Errorf("listing connections: %w", err)
+	}
+	for _, conn := range conns.Items {
+		switches, servers, ports, _, err := conn.Spec.Endpoints()
+		if err != nil {
+			return nil, fmt.Errorf("getting endpoints for connection %q: %w", conn.Name, err)
+		}
+		idx.connSwitches[conn.Name] = switches
+		idx.connPorts[conn.Name] = ports
+		for _, server := range servers {
+			idx.serverConns[server] = append(idx.serverConns[server], conn.Name)
+		}
+	}
+
+	vpcs := &vpcapi.VPCList{}
+	if err := kube.List(ctx, vpcs); err != nil {
+		return nil, fmt.Errorf("listing VPCs: %w", err)
+	}
+	for _, vpc := range vpcs.Items {
+		ipns := vpc.Spec.IPv4Namespace
+		if ipns == "" {
+			ipns = vpcapi.DefaultIPv4Namespace
+		}
+		idx.ipnsVPCs[ipns] = append(idx.ipnsVPCs[ipns], vpc.Name)
+	}
+
+	attaches := &vpcapi.VPCAttachmentList{}
+	if err := kube.List(ctx, attaches); err != nil {
+		return nil, fmt.Errorf("listing VPC attachments: %w", err)
+	}
+	for _, attach := range attaches.Items {
+		idx.attachConn[attach.Name] = attach.Spec.Connection
+		vpc := attach.Spec.VPCName()
+		idx.vpcConns[vpc] = append(idx.vpcConns[vpc], attach.Spec.Connection)
+	}
+
+	peerings := &vpcapi.VPCPeeringList{}
+	if err := kube.List(ctx, peerings); err != nil {
+		return nil, fmt.Errorf("listing VPC peerings: %w", err)
+	}
+	for _, peering := range peerings.Items {
+		for _, permit := range peering.Spec.Permit {
+			for vpc := range permit {
+				idx.peeringVPCs[peering.Name] = appendUnique(idx.peeringVPCs[peering.Name], vpc)
+			}
+		}
+		idx.peeringRemote[peering.Name] = peering.Spec.Remote
+	}
+
+	extAttaches := &vpcapi.ExternalAttachmentList{}
+	if err := kube.List(ctx, extAttaches); err != nil {
+		return nil, fmt.Errorf("listing external attachments: %w", err)
+	}
+	for _, attach := range extAttaches.Items {
+		idx.extAttachConn[attach.Name] = attach.Spec.Connection
+		idx.extConns[attach.Spec.External] = append(idx.extConns[attach.Spec.External], attach.Spec.Connection)
+	}
+
+	extPeerings := &vpcapi.ExternalPeeringList{}
+	if err := kube.List(ctx, extPeerings); err != nil {
+		return nil, fmt.Errorf("listing external peerings: %w", err)
+	}
+	for _, peering := range extPeerings.Items {
+		idx.extPeering[peering.Name] = [2]string{peering.Spec.Permit.VPC.Name, peering.Spec.Permit.External.Name}
+	}
+
+	return idx, nil
+}
+
+func (idx *index) connsSwitches(conns ...string) []string {
+	res := []string{}
+	for _, conn := range conns {
+		res = appendUnique(res, idx.connSwitches[conn]...)
+	}
+
+	return res
+}
+
+func (idx *index) vpcSwitches(vpcs ...string) []string {
+	res := []string{}
+	for _, vpc := range vpcs {
+		res = appendUnique(res, idx.connsSwitches(idx.vpcConns[vpc]...)...)
+	}
+
+	return res
+}
+
+// switchesFor returns the switches which agent config depends on the object
+func (idx *index) switchesFor(kind, name string) []string {
+	switch kind {
+	case wiringapi.KindSwitch:
+		if idx.switches[name] {
+			return []string{name}
+		}
+	case wiringapi.KindServer:
+		return idx.connsSwitches(idx.serverConns[name]...)
+	case wiringapi.KindConnection:
+		return idx.connSwitches[name]
+	case wiringapi.KindSwitchGroup:
+		return idx.groupSwitches[name]
+	case wiringapi.KindVLANNamespace:
+		return idx.vlanNSSwitches[name]
+	case vpcapi.KindVPC:
+		return idx.vpcSwitches(name)
+	case vpcapi.KindVPCAttachment:
+		return idx.connsSwitches(idx.attachConn[name])
+	case vpcapi.KindVPCPeering:
+		res := idx.vpcSwitches(idx.peeringVPCs[name]...)
+		if remote := idx.peeringRemote[name]; remote != "" {
+			res = appendUnique(res, idx.groupSwitches[remote]...)
+		}
+
+		return res
+	case vpcapi.KindIPv4Namespace:
+		return idx.vpcSwitches(idx.ipnsVPCs[name]...)
+	case kindExternal:
+		return idx.connsSwitches(idx.extConns[name]...)
+	case kindExternalAttachment:
+		return idx.connsSwitches(idx.extAttachConn[name])
+	case vpcapi.KindExternalPeering:
+		peering := idx.extPeering[name]
+
+		return appendUnique(idx.vpcSwitches(peering[0]), idx.connsSwitches(idx.extConns[peering[1]]...)...)
+	}
+
+	return nil
+}
@@ -0,0 +1,429 @@
+// Copyright 2026 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package hhfabctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"go.githedgehog.com/fabric/pkg/util/kubeutil"
+	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
+	"go.githedgehog.com/fabricator/pkg/fab/comp"
+	"go.githedgehog.com/fabricator/pkg/fab/comp/certmanager"
+	"go.githedgehog.com/fabricator/pkg/fab/comp/f8r"
+	"go.githedgehog.com/fabricator/pkg/fab/node"
+	appsapi "k8s.io/api/apps/v1"
+	coreapi "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	caWaitTimeout = 15 * time.Minute
+)
+
+type CAOpts struct {
+	// Don't update the trust store of the node hhfabctl is running on
+	SkipLocal bool
+	// Retire previous CA even if some certificates aren't re-issued yet
+	Force bool
+}
+
+func CAShow(ctx context.Context) error {
+	kube, err := newCAKubeClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	bundle, err := getFabCABundle(ctx, kube)
+	if err != nil {
+		return err
+	}
+
+	cas, err := certmanager.ParseCertificates(bundle)
+	if err != nil {
+		return fmt.Errorf("parsing fab-ca bundle: %w", err)
+	}
+
+	for idx, ca := range cas {
+		slog.Info("Trusted CA", "active", idx == 0, "subject", ca.Subject.String(), "serial", ca.SerialNumber.String(),
+			"notAfter", ca.NotAfter.Format(time.RFC3339), "expiresIn", time.Until(ca.NotAfter).Round(time.Hour))
+	}
+
+	state, err := certmanager.GetFabCAState(ctx, kube)
+	if err != nil {
+		return fmt.Errorf("getting fab-ca state: %w", err)
+	}
+	if state.Next != "" {
+		slog.Warn("CA rotation is in progress, new CA isn't used yet, re-run 'hhfabctl ca rotate' to continue")
+	} else if state.Previous != "" {
+		slog.Warn("CA rotation is in progress, retire previous CA once all certificates are re-issued")
+	}
+
+	certs, err := certmanager.ListFabCACertificates(ctx, kube)
+	if err != nil {
+		return fmt.Errorf("listing fab-ca certificates: %w", err)
+	}
+
+	for _, cert := range certs {
+		notAfter := ""
+		if cert.Status.NotAfter != nil {
+			notAfter = cert.Status.NotAfter.Format(time.RFC3339)
+		}
+
+		activeCA := false
+		if len(cas) > 0 {
+			activeCA, err = certmanager.IsCertificateIssuedBy(ctx, kube, cert, bundle)
+			if err != nil {
+				slog.Warn("Can't check certificate issuer", "name", cert.Name, "err", err)
+			}
+		}
+
+		slog.Info("Certificate", "name", cert.Name, "secret", cert.Spec.SecretName, "notAfter", notAfter, "activeCA", activeCA)
+	}
+
+	return nil
+}
+
+// CARotate only changes the rotation state (next and previous fab CA secrets) and lets the same enforcement as in the
+// controller build the CA bundle from it, so it's safe if the fabricator is reconciled at any moment
+func CARotate(ctx context.Context, opts CAOpts) error {
+	kube, err := newCAKubeClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	f, err := getFab(ctx, kube)
+	if err != nil {
+		return err
+	}
+
+	if f.Spec.Config.Control.FabCA.IsExternal() {
+		return fmt.Errorf("fab CA is provided externally, update it in the fabricator config instead") //nolint:goerr113
+	}
+
+	state, err := certmanager.GetFabCAState(ctx, kube)
+	if err != nil {
+		return fmt.Errorf("getting fab-ca state: %w", err)
+	}
+	if state.Previous != "" && state.Next == "" {
+		return fmt.Errorf("CA rotation is already in progress, retire previous CA first") //nolint:goerr113
+	}
+
+	// previous CA is already saved if rotation was interrupted while switching to the new CA
+	previous := state.Current.Crt
+	if state.Previous != "" {
+		previous = state.Previous
+	}
+
+	next, err := getFabCANext(ctx, kube)
+	if err != nil {
+		return err
+	}
+	if next == nil {
+		next, err = certmanager.NewFabCA()
+		if err != nil {
+			return fmt.Errorf("creating new fab-ca: %w", err)
+		}
+	} else {
+		slog.Info("Continuing interrupted fab CA rotation")
+	}
+
+	slog.Info("Rotating fab CA")
+
+	// new CA should be trusted everywhere before anything is issued by it
+	if err := comp.EnforceKubeInstall(ctx, kube, f, certmanager.InstallFabCANext(next)); err != nil {
+		return fmt.Errorf("saving new fab-ca: %w", err)
+	}
+
+	if err := distributeFabCABundle(ctx, kube, f, opts); err != nil {
+		return err
+	}
+
+	slog.Info("Switching fab CA issuer to the new CA")
+
+	if err := comp.EnforceKubeInstall(ctx, kube, f, certmanager.InstallFabCAPrevious(previous)); err != nil {
+		return fmt.Errorf("saving previous fab-ca: %w", err)
+	}
+
+	if err := comp.EnforceKubeInstall(ctx, kube, f, certmanager.InstallFabCASecret(next)); err != nil {
+		return fmt.Errorf("enforcing new fab-ca: %w", err)
+	}
+
+	if err := deleteSecret(ctx, kube, comp.FabCANextSecret); err != nil {
+		return err
+	}
+
+	if err := certmanager.EnforceFabCA(ctx, kube, f); err != nil {
+		return fmt.Errorf("enforcing fab-ca: %w", err)
+	}
+
+	if err := renewFabCACertificates(ctx, kube, next.Crt); err != nil {
+		return err
+	}
+
+	slog.Info("Fab CA rotated, previous CA is still trusted")
+	slog.Info("Run 'hhfabctl ca retire' once all switches and clients are updated to trust the new CA")
+
+	return nil
+}
+
+func CARetire(ctx context.Context, opts CAOpts) error {
+	kube, err := newCAKubeClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	f, err := getFab(ctx, kube)
+	if err != nil {
+		return err
+	}
+
+	state, err := certmanager.GetFabCAState(ctx, kube)
+	if err != nil {
+		return fmt.Errorf("getting fab-ca state: %w", err)
+	}
+	if state.Next != "" {
+		return fmt.Errorf("CA rotation was interrupted, re-run 'hhfabctl ca rotate' to finish it first") //nolint:goerr113
+	}
+	if state.Previous == "" {
+		return fmt.Errorf("no CA rotation in progress") //nolint:goerr113
+	}
+
+	certs, err := certmanager.ListFabCACertificates(ctx, kube)
+	if err != nil {
+		return fmt.Errorf("listing fab-ca certificates: %w", err)
+	}
+
+	for _, cert := range certs {
+		ok, err := certmanager.IsCertificateIssuedBy(ctx, kube, cert, state.Current.Crt)
+		if err != nil {
+			return fmt.Errorf("checking certificate %q: %w", cert.Name, err)
+		}
+		if !ok {
+			if !opts.Force {
+				return fmt.Errorf("certificate %q isn't re-issued by the new CA yet, run 'hhfabctl ca renew' or use --force to retire anyway", cert.Name) //nolint:goerr113
+			}
+
+			slog.Warn("Certificate isn't re-issued by the new CA yet", "name", cert.Name)
+		}
+	}
+
+	slog.Info("Retiring previous fab CA")
+
+	if err := deleteSecret(ctx, kube, comp.FabCAPreviousSecret); err != nil {
+		return err
+	}
+
+	if err := distributeFabCABundle(ctx, kube, f, opts); err != nil {
+		return err
+	}
+
+	slog.Info("Previous fab CA retired")
+
+	return nil
+}
+
+func CARenew(ctx context.Context) error {
+	kube, err := newCAKubeClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	state, err := certmanager.GetFabCAState(ctx, kube)
+	if err != nil {
+		return fmt.Errorf("getting fab-ca state: %w", err)
+	}
+
+	if err := renewFabCACertificates(ctx, kube, state.Current.Crt); err != nil {
+		return err
+	}
+
+	slog.Info("All certificates are issued by the active fab CA")
+
+	return nil
+}
+
+func newCAKubeClient(ctx context.Context) (kclient.Client, error) {
+	kube, err := kubeutil.NewClient(ctx, "",
+		fabapi.AddToScheme, coreapi.AddToScheme, appsapi.AddToScheme,
+		cmapi.AddToScheme, cmmeta.AddToScheme,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating k8s client: %w", err)
+	}
+
+	return kube, nil
+}
+
+func getFab(ctx context.Context, kube kclient.Reader) (fabapi.Fabricator, error) {
+	f := fabapi.Fabricator{}
+	if err := kube.Get(ctx, kclient.ObjectKey{Name: comp.FabName, Namespace: comp.FabNamespace}, &f); err != nil {
+		return f, fmt.Errorf("getting fabricator: %w", err)
+	}
+
+	return f, nil
+}
+
+func getFabCANext(ctx context.Context, kube kclient.Reader) (*certmanager.CA, error) {
+	secret := &coreapi.Secret{}
+	if err := kube.Get(ctx, kclient.ObjectKey{Name: comp.FabCANextSecret, Namespace: comp.FabNamespace}, secret); err != nil {
+		if kapierrors.IsNotFound(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("getting next fab-ca secret: %w", err)
+	}
+
+	ca := &certmanager.CA{
+		Crt: string(secret.Data["tls.crt"]),
+		Key: string(secret.Data["tls.key"]),
+	}
+	if ca.Crt == "" || ca.Key == "" {
+		return nil, fmt.Errorf("next fab-ca secret missing cert or key") //nolint:goerr113
+	}
+
+	return ca, nil
+}
+
+func deleteSecret(ctx context.Context, kube kclient.Client, name string) error {
+	if err := comp.DeleteIfPresent(ctx, kube, &coreapi.Secret{
+		ObjectMeta: kmetav1.ObjectMeta{
+			Name:      name,
+			Namespace: comp.FabNamespace,
+		},
+	}); err != nil {
+		return fmt.Errorf("deleting %s secret: %w", name, err)
+	}
+
+	return nil
+}
+
+func getFabCABundle(ctx context.Context, kube kclient.Reader) (string, error) {
+	cm := &coreapi.ConfigMap{}
+	if err := kube.Get(ctx, kclient.ObjectKey{Name: comp.FabCAConfigMap, Namespace: comp.FabNamespace}, cm); err != nil {
+		return "", fmt.Errorf("getting fab-ca config map: %w", err)
+	}
+
+	bundle := cm.Data[comp.FabCAConfigMapKey]
+	if bundle == "" {
+		return "", errors.New("fab-ca config map missing data") //nolint:goerr113
+	}
+
+	return bundle, nil
+}
+
+// distributeFabCABundle enforces the bundle consumed by the fabric manager and node config based on the current
+// rotation state and waits for nodes to trust it
+func distributeFabCABundle(ctx context.Context, kube kclient.Client, f fabapi.Fabricator, opts CAOpts) error {
+	if err := certmanager.EnforceFabCA(ctx, kube, f); err != nil {
+		return fmt.Errorf("enforcing fab-ca: %w", err)
+	}
+
+	bundle, err := getFabCABundle(ctx, kube)
+	if err != nil {
+		return err
+	}
+
+	crts, err := certmanager.SplitCABundle(bundle)
+	if err != nil {
+		return fmt.Errorf("splitting fab-ca bundle: %w", err)
+	}
+
+	slog.Info("Distributing fab CA bundle", "cas", len(crts))
+
+	if !opts.SkipLocal {
+		if _, err := os.Stat(certmanager.FabCAPath); err == nil {
+			if err := node.EnforceFabCA(ctx, bundle); err != nil {
+				return fmt.Errorf("updating local trust store (use --skip-local if not running on the control node): %w", err)
+			}
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("checking local fab-ca: %w", err)
+		}
+	}
+
+	if err := restartNodeConfig(ctx, kube); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func restartNodeConfig(ctx context.Context, kube kclient.Client) error {
+	ds := &appsapi.DaemonSet{}
+	if err := kube.Get(ctx, kclient.ObjectKey{Name: f8r.NodeConfigDaemonSet, Namespace: comp.FabNamespace}, ds); err != nil {
+		return fmt.Errorf("getting node config daemonset: %w", err)
+	}
+
+	slog.Info("Restarting node config to apply fab CA bundle")
+
+	orig := ds.DeepCopy()
+	if ds.Spec.Template.Annotations == nil {
+		ds.Spec.Template.Annotations = map[string]string{}
+	}
+	ds.Spec.Template.Annotations[comp.RestartedAtAnnotation] = time.Now().Format(time.RFC3339)
+
+	if err := kube.Patch(ctx, ds, kclient.MergeFrom(orig)); err != nil {
+		return fmt.Errorf("restarting node config daemonset: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, caWaitTimeout)
+	defer cancel()
+
+	return waitFor(ctx, "node config rollout", func() (bool, error) {
+		if err := kube.Get(ctx, kclient.ObjectKeyFromObject(ds), ds); err != nil {
+			return false, fmt.Errorf("getting node config daemonset: %w", err)
+		}
+
+		return ds.Status.ObservedGeneration >= ds.Generation &&
+			ds.Status.UpdatedNumberScheduled == ds.Status.DesiredNumberScheduled &&
+			ds.Status.NumberAvailable == ds.Status.DesiredNumberScheduled, nil
+	})
+}
+
+// renewFabCACertificates triggers re-issuance of the certificates not issued by the CA yet and waits for it
+func renewFabCACertificates(ctx context.Context, kube kclient.Client, ca string) error {
+	if _, err := certmanager.RenewFabCACertificates(ctx, kube, ca); err != nil {
+		return fmt.Errorf("renewing fab-ca certificates: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, caWaitTimeout)
+	defer cancel()
+
+	return waitFor(ctx, "certificates re-issued", func() (bool, error) {
+		pending, err := certmanager.RenewFabCACertificates(ctx, kube, ca)
+		if err != nil {
+			return false, fmt.Errorf("renewing fab-ca certificates: %w", err)
+		}
+
+		return len(pending) == 0, nil
+	})
+}
+
+func waitFor(ctx context.Context, what string, check func() (bool, error)) error {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		ok, err := check()
+		if err != nil {
+			slog.Debug("Waiting", "for", what, "err", err)
+		} else if ok {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for %s: %w", what, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
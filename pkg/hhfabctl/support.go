@@ -5,18 +5,25 @@ package hhfabctl
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
+	"go.githedgehog.com/fabric/pkg/hhfctl/inspect"
 	"go.githedgehog.com/fabricator/pkg/support"
+	kyaml "sigs.k8s.io/yaml"
 )
 
 type SupportDumpOpts struct {
-	WorkDir string
-	Name    string
-	Force   bool
+	WorkDir     string
+	Name        string
+	Force       bool
+	Diagnostics support.DiagnosticsOpts
 }
 
 func SupportDump(ctx context.Context, opts SupportDumpOpts) error {
@@ -39,7 +46,7 @@ func SupportDump(ctx context.Context, opts SupportDumpOpts) error {
 		return fmt.Errorf("path is not a directory") //nolint:goerr113
 	}
 
-	fullPath := filepath.Join(opts.WorkDir, opts.Name+support.FileExt)
+	fullPath := filepath.Join(opts.WorkDir, opts.Name+support.ArchiveFileExt)
 
 	if stat, err := os.Stat(fullPath); err == nil {
 		if !opts.Force {
@@ -57,18 +64,22 @@ func SupportDump(ctx context.Context, opts SupportDumpOpts) error {
 		return fmt.Errorf("stat dump file: %w", err)
 	}
 
-	dump, err := support.Collect(ctx, opts.Name, "", false)
+	f, err := os.OpenFile(fullPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
 	if err != nil {
-		return fmt.Errorf("collecting support dump: %w", err)
+		return fmt.Errorf("creating dump file: %w", err)
 	}
+	defer f.Close()
 
-	data, err := support.Marshal(dump)
-	if err != nil {
-		return fmt.Errorf("marshaling dump: %w", err)
+	if err := support.CollectArchive(ctx, f, opts.Name, support.CollectOpts{
+		Diagnostics: opts.Diagnostics,
+	}); err != nil {
+		_ = os.Remove(fullPath)
+
+		return fmt.Errorf("collecting support dump: %w", err)
 	}
 
-	if err := os.WriteFile(fullPath, data, 0o600); err != nil {
-		return fmt.Errorf("writing dump file: %w", err)
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing dump file: %w", err)
 	}
 
 	wd, err := os.Getwd()
@@ -85,3 +96,97 @@ func SupportDump(ctx context.Context, opts SupportDumpOpts) error {
 
 	return nil
 }
+
+type SupportAnalyzeOpts struct {
+	Output  inspect.OutputType
+	Details bool
+}
+
+func SupportAnalyze(ctx context.Context, path string, opts SupportAnalyzeOpts) error {
+	if path == "" {
+		return fmt.Errorf("empty path") //nolint:goerr113
+	}
+
+	if opts.Output == inspect.OutputTypeUndefined {
+		opts.Output = inspect.OutputTypeText
+	}
+	if !slices.Contains(inspect.OutputTypes, opts.Output) {
+		return fmt.Errorf("unsupported output type %q", opts.Output) //nolint:goerr113
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading dump file: %w", err)
+	}
+
+	dump := &support.Dump{}
+	if err := support.Unmarshal(data, dump); err != nil {
+		return fmt.Errorf("unmarshalling dump: %w", err)
+	}
+
+	slog.Info("Analyzing support dump", "name", dump.Name, "createdAt", dump.CreatedAt.Time, "ctlVersion", dump.CreatedBy.CtlVersion)
+
+	findings, err := support.Analyze(ctx, dump)
+	if err != nil {
+		return fmt.Errorf("analyzing dump: %w", err)
+	}
+
+	if err := renderFindings(os.Stdout, findings, opts); err != nil {
+		return err
+	}
+
+	if len(findings) == 0 {
+		slog.Info("No issues found")
+	} else {
+		slog.Warn("Issues found", "count", len(findings))
+	}
+
+	return nil
+}
+
+func renderFindings(w io.Writer, findings []support.Finding, opts SupportAnalyzeOpts) error {
+	var data []byte
+
+	switch opts.Output {
+	case inspect.OutputTypeJSON:
+		var err error
+		if data, err = json.MarshalIndent(findings, "", "  "); err != nil {
+			return fmt.Errorf("marshalling findings: %w", err)
+		}
+	case inspect.OutputTypeYAML:
+		var err error
+		if data, err = kyaml.Marshal(findings); err != nil {
+			return fmt.Errorf("marshalling findings: %w", err)
+		}
+	default:
+		if len(findings) == 0 {
+			return nil
+		}
+
+		rows := [][]string{}
+		for _, f := range findings {
+			rows = append(rows, []string{strings.ToUpper(f.Severity.String()), f.Check, f.Object, f.Message})
+		}
+
+		str := &strings.Builder{}
+		str.WriteString(inspect.RenderTable([]string{"Severity", "Check", "Object", "Message"}, rows))
+
+		if opts.Details {
+			for _, f := range findings {
+				if f.Details == "" {
+					continue
+				}
+
+				fmt.Fprintf(str, "\n%s: %s\n%s\n", f.Object, f.Message, f.Details)
+			}
+		}
+
+		data = []byte(str.String())
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("writing findings: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,607 @@
+// Copyright 2026 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package support
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	helmapi "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	agentapi "go.githedgehog.com/fabric/api/agent/v1beta1"
+	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	kmeta "k8s.io/apimachinery/pkg/api/meta"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	analyzeAgentHeartbeatStale = 5 * time.Minute
+	analyzeCertExpiryWarning   = 30 * 24 * time.Hour
+	analyzeCertExpiryCritical  = 7 * 24 * time.Hour
+	analyzePodRestartsWarning  = 3
+	analyzeLogTailLines        = 10
+)
+
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Check    string   `json:"check"`
+	Object   string   `json:"object"`
+	Message  string   `json:"message"`
+	Details  string   `json:"details,omitempty"`
+}
+
+type analyzer struct {
+	kube kclient.Reader
+	dump *Dump
+	now  time.Time
+}
+
+type analyzeCheck struct {
+	name string
+	run  func(ctx context.Context, a *analyzer) ([]Finding, error)
+}
+
+var analyzeChecks = []analyzeCheck{
+	{name: "components", run: analyzeComponents},
+	{name: "agents", run: analyzeAgents},
+	{name: "helmcharts", run: analyzeHelmCharts},
+	{name: "pods", run: analyzePods},
+	{name: "gateways", run: analyzeGateways},
+	{name: "certificates", run: analyzeCertificates},
+}
+
+// Analyze runs all health checks against the dump and returns findings sorted by severity (most severe first)
+func Analyze(ctx context.Context, dump *Dump) ([]Finding, error) {
+	if dump == nil {
+		return nil, fmt.Errorf("no dump") //nolint:goerr113
+	}
+
+	kube, err := loadDumpResources(dump.Resources)
+	if err != nil {
+		return nil, fmt.Errorf("loading resources: %w", err)
+	}
+
+	a := &analyzer{
+		kube: kube,
+		dump: dump,
+		now:  dump.CreatedAt.Time,
+	}
+	if a.now.IsZero() {
+		a.now = time.Now()
+	}
+
+	findings := []Finding{}
+	for _, check := range analyzeChecks {
+		res, err := check.run(ctx, a)
+		if err != nil {
+			return nil, fmt.Errorf("running check %q: %w", check.name, err)
+		}
+
+		for idx := range res {
+			res[idx].Check = check.name
+		}
+
+		findings = append(findings, res...)
+	}
+
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		if a.Severity != b.Severity {
+			return int(b.Severity - a.Severity)
+		}
+		if a.Check != b.Check {
+			return strings.Compare(a.Check, b.Check)
+		}
+
+		return strings.Compare(a.Object, b.Object)
+	})
+
+	return findings, nil
+}
+
+// loadDumpResources loads serialized resources into the in-memory client skipping unknown kinds
+func loadDumpResources(resources string) (kclient.Reader, error) {
+	scheme := runtime.NewScheme()
+	for _, add := range schemeBuilders {
+		if err := add(scheme); err != nil {
+			return nil, fmt.Errorf("adding scheme: %w", err)
+		}
+	}
+
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	objs := []kclient.Object{}
+
+	multidocReader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(resources)))
+	for idx := 1; ; idx++ {
+		buf, err := multidocReader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, fmt.Errorf("object %d: reading: %w", idx, err)
+		}
+
+		if len(bytes.TrimSpace(buf)) == 0 {
+			continue
+		}
+
+		rObj, gvk, err := decoder.Decode(buf, nil, nil)
+		if err != nil {
+			if runtime.IsNotRegisteredError(err) {
+				slog.Debug("Skipping unknown object", "idx", idx, "err", err)
+
+				continue
+			}
+
+			return nil, fmt.Errorf("object %d: decoding: %w", idx, err)
+		}
+
+		obj, ok := rObj.(kclient.Object)
+		if !ok {
+			return nil, fmt.Errorf("object %d: %s: not a client.Object", idx, gvk.Kind) //nolint:goerr113
+		}
+
+		obj.SetResourceVersion("")
+		objs = append(objs, obj)
+	}
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(), nil
+}
+
+func analyzeComponents(ctx context.Context, a *analyzer) ([]Finding, error) {
+	fabs := &fabapi.FabricatorList{}
+	if err := a.kube.List(ctx, fabs); err != nil {
+		return nil, fmt.Errorf("listing fabricators: %w", err)
+	}
+
+	if len(fabs.Items) == 0 {
+		return []Finding{{
+			Severity: SeverityCritical,
+			Object:   "Fabricator",
+			Message:  "No fabricator object found in the dump",
+		}}, nil
+	}
+
+	res := []Finding{}
+	for _, f := range fabs.Items {
+		obj := "Fabricator " + f.Name
+
+		if cond := kmeta.FindStatusCondition(f.Status.Conditions, fabapi.ConditionReady); cond == nil || cond.Status != kmetav1.ConditionTrue {
+			msg := "Fabricator isn't ready"
+			if cond != nil && cond.Message != "" {
+				msg += ": " + cond.Message
+			}
+			res = append(res, Finding{Severity: SeverityCritical, Object: obj, Message: msg})
+		}
+
+		comps := reflect.ValueOf(f.Status.Components)
+		for idx := range comps.NumField() {
+			name := strings.Split(comps.Type().Field(idx).Tag.Get("json"), ",")[0]
+
+			switch field := comps.Field(idx).Interface().(type) {
+			case fabapi.ComponentStatus:
+				// node config isn't running on control-only setups
+				if name == "fabricatorNodeConfig" && field == fabapi.CompStatusUnknown {
+					continue
+				}
+
+				if finding, ok := componentFinding(obj, name, field); ok {
+					res = append(res, finding)
+				}
+			case map[string]fabapi.ComponentStatus:
+				for key, status := range field {
+					if finding, ok := componentFinding(obj, name+"/"+key, status); ok {
+						res = append(res, finding)
+					}
+				}
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func componentFinding(obj, name string, status fabapi.ComponentStatus) (Finding, bool) {
+	switch status {
+	case fabapi.CompStatusReady, fabapi.CompStatusSkipped:
+		return Finding{}, false
+	case fabapi.CompStatusNotFound:
+		return Finding{Severity: SeverityCritical, Object: obj, Message: fmt.Sprintf("Component %s not found", name)}, true
+	case fabapi.CompStatusUnknown:
+		return Finding{Severity: SeverityWarning, Object: obj, Message: fmt.Sprintf("Component %s status is unknown", name)}, true
+	default:
+		return Finding{Severity: SeverityWarning, Object: obj, Message: fmt.Sprintf("Component %s is %s", name, status)}, true
+	}
+}
+
+func analyzeAgents(ctx context.Context, a *analyzer) ([]Finding, error) {
+	agents := &agentapi.AgentList{}
+	if err := a.kube.List(ctx, agents); err != nil {
+		return nil, fmt.Errorf("listing agents: %w", err)
+	}
+
+	res := []Finding{}
+	for _, agent := range agents.Items {
+		obj := "Agent " + agent.Name
+
+		if agent.Status.LastHeartbeat.IsZero() {
+			res = append(res, Finding{Severity: SeverityCritical, Object: obj, Message: "Agent never reported heartbeat"})
+
+			continue
+		}
+
+		if since := a.now.Sub(agent.Status.LastHeartbeat.Time); since > analyzeAgentHeartbeatStale {
+			res = append(res, Finding{
+				Severity: SeverityCritical,
+				Object:   obj,
+				Message:  fmt.Sprintf("Last heartbeat %s before the dump", since.Round(time.Second)),
+			})
+		}
+
+		if agent.Status.LastAppliedGen < agent.Generation {
+			msg := fmt.Sprintf("Config generation %d isn't applied, last applied %d", agent.Generation, agent.Status.LastAppliedGen)
+			if agent.Status.LastAttemptGen == agent.Generation {
+				msg += fmt.Sprintf(", last attempt at %s", agent.Status.LastAttemptTime.Format(time.RFC3339))
+			}
+
+			res = append(res, Finding{Severity: SeverityWarning, Object: obj, Message: msg})
+		}
+
+		if agent.Status.RebootRequired {
+			res = append(res, Finding{Severity: SeverityInfo, Object: obj, Message: "Reboot required"})
+		}
+	}
+
+	return res, nil
+}
+
+func analyzeHelmCharts(ctx context.Context, a *analyzer) ([]Finding, error) {
+	charts := &helmapi.HelmChartList{}
+	if err := a.kube.List(ctx, charts); err != nil {
+		return nil, fmt.Errorf("listing helm charts: %w", err)
+	}
+
+	res := []Finding{}
+	for _, chart := range charts.Items {
+		for _, cond := range chart.Status.Conditions {
+			if cond.Type != helmapi.HelmChartFailed || cond.Status != corev1.ConditionTrue {
+				continue
+			}
+
+			msg := "Helm chart install failed"
+			if cond.Message != "" {
+				msg += ": " + cond.Message
+			}
+
+			res = append(res, Finding{
+				Severity: SeverityCritical,
+				Object:   "HelmChart " + chart.Namespace + "/" + chart.Name,
+				Message:  msg,
+			})
+		}
+	}
+
+	pods := &corev1.PodList{}
+	if err := a.kube.List(ctx, pods); err != nil {
+		return nil, fmt.Errorf("listing pods: %w", err)
+	}
+
+	for _, pod := range pods.Items {
+		if !strings.HasPrefix(pod.Name, "helm-install-") || pod.Status.Phase != corev1.PodFailed {
+			continue
+		}
+
+		res = append(res, Finding{
+			Severity: SeverityWarning,
+			Object:   "Pod " + pod.Namespace + "/" + pod.Name,
+			Message:  "Helm install job pod failed",
+			Details:  a.logsTail(pod.Namespace, pod.Name, "", false),
+		})
+	}
+
+	return res, nil
+}
+
+func analyzePods(ctx context.Context, a *analyzer) ([]Finding, error) {
+	pods := &corev1.PodList{}
+	if err := a.kube.List(ctx, pods); err != nil {
+		return nil, fmt.Errorf("listing pods: %w", err)
+	}
+
+	res := []Finding{}
+	for _, pod := range pods.Items {
+		obj := "Pod " + pod.Namespace + "/" + pod.Name
+
+		if pod.Status.Phase == corev1.PodPending {
+			res = append(res, Finding{Severity: SeverityWarning, Object: obj, Message: "Pod is pending"})
+
+			continue
+		}
+
+		for _, cs := range pod.Status.ContainerStatuses {
+			switch {
+			case cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff":
+				res = append(res, Finding{
+					Severity: SeverityCritical,
+					Object:   obj,
+					Message:  fmt.Sprintf("Container %s is crash-looping (%d restarts)", cs.Name, cs.RestartCount),
+					Details:  a.logsTail(pod.Namespace, pod.Name, cs.Name, true),
+				})
+			case cs.RestartCount >= analyzePodRestartsWarning:
+				msg := fmt.Sprintf("Container %s restarted %d times", cs.Name, cs.RestartCount)
+				if cs.LastTerminationState.Terminated != nil {
+					msg += fmt.Sprintf(", last exit code %d (%s)", cs.LastTerminationState.Terminated.ExitCode,
+						cs.LastTerminationState.Terminated.Reason)
+				}
+
+				res = append(res, Finding{
+					Severity: SeverityWarning,
+					Object:   obj,
+					Message:  msg,
+					Details:  a.logsTail(pod.Namespace, pod.Name, cs.Name, true),
+				})
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// logsTail returns last lines of the container logs from the dump, all containers are used if container is empty
+func (a *analyzer) logsTail(ns, pod, container string, previous bool) string {
+	logs, ok := a.dump.PodLogs[ns][pod]
+	if !ok {
+		return ""
+	}
+
+	parts := []string{}
+	for name, cl := range logs {
+		if container != "" && name != container {
+			continue
+		}
+
+		log := cl.Current
+		if previous && cl.Previous != "" {
+			log = cl.Previous
+		}
+
+		lines := strings.Split(strings.TrimRight(log, "\n"), "\n")
+		if len(lines) > analyzeLogTailLines {
+			lines = lines[len(lines)-analyzeLogTailLines:]
+		}
+
+		tail := strings.Join(lines, "\n")
+		if tail == "" {
+			continue
+		}
+		if container == "" {
+			tail = name + ":\n" + tail
+		}
+
+		parts = append(parts, tail)
+	}
+	slices.Sort(parts)
+
+	return strings.Join(parts, "\n")
+}
+
+func analyzeGateways(_ context.Context, a *analyzer) ([]Finding, error) {
+	res := []Finding{}
+	for gwName, outputs := range a.dump.GatewayInsights {
+		obj := "Gateway " + gwName
+
+		for cmd, out := range outputs {
+			if out.Error != "" {
+				res = append(res, Finding{
+					Severity: SeverityWarning,
+					Object:   obj,
+					Message:  fmt.Sprintf("Collecting %q failed: %s", cmd, out.Error),
+					Details:  out.Stderr,
+				})
+
+				continue
+			}
+
+			switch cmd {
+			case "frr: show daemons":
+				for _, daemon := range []string{"zebra", "bgpd", "bfdd"} {
+					if !slices.Contains(strings.Fields(out.Stdout), daemon) {
+						res = append(res, Finding{Severity: SeverityCritical, Object: obj, Message: fmt.Sprintf("FRR daemon %s isn't running", daemon)})
+					}
+				}
+			case "frr: show bgp summary":
+				for _, peer := range bgpSummaryNotEstablished(out.Stdout) {
+					res = append(res, Finding{Severity: SeverityCritical, Object: obj, Message: "BGP session not established: " + peer})
+				}
+			case "frr: show bfd peers":
+				for _, peer := range bfdPeersDown(out.Stdout) {
+					res = append(res, Finding{Severity: SeverityWarning, Object: obj, Message: "BFD peer is down: " + peer})
+				}
+			default:
+				if strings.HasPrefix(cmd, "dataplane: ") && strings.TrimSpace(out.Stdout) == "" {
+					res = append(res, Finding{
+						Severity: SeverityWarning,
+						Object:   obj,
+						Message:  fmt.Sprintf("Dataplane returned no output for %q", strings.TrimPrefix(cmd, "dataplane: ")),
+						Details:  out.Stderr,
+					})
+				}
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// bgpSummaryNotEstablished returns neighbors (with their state) from the FRR "show bgp summary" output that aren't
+// in the established state, which is shown as a number of received prefixes
+func bgpSummaryNotEstablished(out string) []string {
+	res := []string{}
+	stateIdx := -1
+
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(line, "Total number") {
+			stateIdx = -1
+
+			continue
+		}
+
+		if fields[0] == "Neighbor" {
+			stateIdx = slices.Index(fields, "State/PfxRcd")
+
+			continue
+		}
+
+		if stateIdx < 0 || len(fields) <= stateIdx {
+			continue
+		}
+
+		state := fields[stateIdx]
+		if _, err := strconv.Atoi(state); err == nil || state == "(Policy)" {
+			continue
+		}
+
+		res = append(res, fields[0]+" ("+state+")")
+	}
+
+	return res
+}
+
+// bfdPeersDown returns peers from the FRR "show bfd peers" output that aren't up
+func bfdPeersDown(out string) []string {
+	res := []string{}
+	peer := ""
+
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "peer ") {
+			peer = strings.TrimPrefix(line, "peer ")
+
+			continue
+		}
+
+		if status, ok := strings.CutPrefix(line, "Status: "); ok && peer != "" && status != "up" {
+			res = append(res, peer+" ("+status+")")
+		}
+	}
+
+	return res
+}
+
+func analyzeCertificates(ctx context.Context, a *analyzer) ([]Finding, error) {
+	res := []Finding{}
+
+	certs := &cmapi.CertificateList{}
+	if err := a.kube.List(ctx, certs); err != nil {
+		return nil, fmt.Errorf("listing certificates: %w", err)
+	}
+
+	for _, cert := range certs.Items {
+		obj := "Certificate " + cert.Namespace + "/" + cert.Name
+
+		for _, cond := range cert.Status.Conditions {
+			if cond.Type == cmapi.CertificateConditionReady && cond.Status != cmmeta.ConditionTrue {
+				res = append(res, Finding{Severity: SeverityWarning, Object: obj, Message: "Certificate isn't ready: " + cond.Message})
+			}
+		}
+
+		if cert.Status.NotAfter != nil {
+			if finding, ok := a.expiryFinding(obj, "Certificate", cert.Status.NotAfter.Time); ok {
+				res = append(res, finding)
+			}
+		}
+	}
+
+	fabs := &fabapi.FabricatorList{}
+	if err := a.kube.List(ctx, fabs); err != nil {
+		return nil, fmt.Errorf("listing fabricators: %w", err)
+	}
+
+	for _, f := range fabs.Items {
+		obj := "Fabricator " + f.Name
+		expiry := func(name string, notAfter *kmetav1.Time) {
+			if notAfter == nil {
+				return
+			}
+			if finding, ok := a.expiryFinding(obj, name, notAfter.Time); ok {
+				res = append(res, finding)
+			}
+		}
+
+		status := f.Status.Certificates
+		for idx, notAfter := range status.FabCA {
+			name := "Fab CA"
+			if idx > 0 {
+				name = "Fab CA chain certificate"
+			}
+			expiry(name, &notAfter)
+		}
+		expiry("Next fab CA", status.NextFabCA)
+		expiry("Previous fab CA", status.PreviousFabCA)
+		for _, notAfter := range status.TrustedCA {
+			expiry("Trusted CA", &notAfter)
+		}
+	}
+
+	return res, nil
+}
+
+func (a *analyzer) expiryFinding(obj, name string, notAfter time.Time) (Finding, bool) {
+	left := notAfter.Sub(a.now)
+
+	switch {
+	case left <= 0:
+		return Finding{Severity: SeverityCritical, Object: obj, Message: fmt.Sprintf("%s expired at %s", name, notAfter.Format(time.RFC3339))}, true
+	case left <= analyzeCertExpiryCritical:
+		return Finding{Severity: SeverityCritical, Object: obj, Message: fmt.Sprintf("%s expires in %s", name, left.Round(time.Hour))}, true
+	case left <= analyzeCertExpiryWarning:
+		return Finding{Severity: SeverityWarning, Object: obj, Message: fmt.Sprintf("%s expires in %s", name, left.Round(time.Hour))}, true
+	default:
+		return Finding{}, false
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package support_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.githedgehog.com/fabricator/pkg/support"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const analyzeTestResources = `apiVersion: agent.githedgehog.com/v1beta1
+kind: Agent
+metadata:
+  name: leaf-01
+  namespace: default
+  generation: 5
+status:
+  lastHeartbeat: "2026-01-01T11:59:30Z"
+  lastAppliedGen: 4
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: fabric-ctrl-abc
+  namespace: fab
+status:
+  phase: Running
+  containerStatuses:
+    - name: manager
+      restartCount: 7
+      state:
+        waiting:
+          reason: CrashLoopBackOff
+---
+apiVersion: example.com/v1
+kind: Unknown
+metadata:
+  name: skipped
+`
+
+const analyzeTestBGPSummary = `IPv4 Unicast Summary (VRF default):
+BGP router identifier 172.30.8.2, local AS number 65534 vrf-id 0
+
+Neighbor        V         AS   MsgRcvd   MsgSent   TblVer  InQ OutQ  Up/Down State/PfxRcd   PfxSnt Desc
+172.30.128.1    4      65100       120       118        0    0    0 01:00:00           12       10 N/A
+172.30.128.3    4      65101         0         0        0    0    0    never       Active        0 N/A
+
+Total number of neighbors 2
+`
+
+func TestAnalyze(t *testing.T) {
+	dump := &support.Dump{
+		CreatedAt: kmetav1.NewTime(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)),
+		Resources: analyzeTestResources,
+		PodLogs: map[string]map[string]support.PodLogs{
+			"fab": {
+				"fabric-ctrl-abc": {
+					"manager": {Previous: "starting\npanic: boom\n"},
+				},
+			},
+		},
+		GatewayInsights: map[string]map[string]support.ExecOutputs{
+			"gw-1": {
+				"frr: show bgp summary": {Stdout: analyzeTestBGPSummary},
+			},
+		},
+	}
+
+	findings, err := support.Analyze(t.Context(), dump)
+	require.NoError(t, err)
+
+	got := map[string]support.Finding{}
+	for _, f := range findings {
+		got[f.Check+" "+f.Object] = f
+	}
+
+	require.Contains(t, got, "components Fabricator")
+	require.Equal(t, support.SeverityCritical, findings[0].Severity)
+
+	require.Contains(t, got, "agents Agent leaf-01")
+	require.Equal(t, support.SeverityWarning, got["agents Agent leaf-01"].Severity)
+
+	require.Contains(t, got, "pods Pod fab/fabric-ctrl-abc")
+	require.Equal(t, support.SeverityCritical, got["pods Pod fab/fabric-ctrl-abc"].Severity)
+	require.Contains(t, got["pods Pod fab/fabric-ctrl-abc"].Details, "panic: boom")
+
+	require.Contains(t, got, "gateways Gateway gw-1")
+	require.Contains(t, got["gateways Gateway gw-1"].Message, "172.30.128.3 (Active)")
+}
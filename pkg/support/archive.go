@@ -0,0 +1,446 @@
+// Copyright 2026 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package support
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+	kyaml "sigs.k8s.io/yaml"
+)
+
+const (
+	ArchiveFileExt = ".hhs.tar.zst"
+
+	archiveManifest       = "manifest.yaml"
+	archiveResourcesDir   = "resources"
+	archiveLogsDir        = "logs"
+	archiveGatewaysDir    = "gateways"
+	archiveDiagnosticsDir = "diagnostics"
+	archiveClusterScoped  = "_cluster"
+	archiveMaxEntrySize   = 1 << 30
+	archiveEntryMode      = 0o644
+	archiveLogExt         = ".log"
+	archivePreviousLogExt = ".previous.log"
+)
+
+var (
+	archiveMagic     = []byte{0x28, 0xb5, 0x2f, 0xfd} // zstd frame magic number
+	archiveSlugRegex = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Manifest is the index of the archive, it's written last as manifest.yaml
+type Manifest struct {
+	DumpVersion     `json:",inline"`
+	Name            string                    `json:"name,omitempty"`
+	CreatedBy       DumpCreator               `json:"createdBy,omitempty"`
+	CreatedAt       kmetav1.Time              `json:"createdAt,omitempty"`
+	Resources       []ManifestResource        `json:"resources,omitempty"`
+	PodLogs         []ManifestPodLogs         `json:"podLogs,omitempty"`
+	GatewayInsights []ManifestGatewayInsights `json:"gatewayInsights,omitempty"`
+	Diagnostics     []ManifestDiagnostics     `json:"diagnostics,omitempty"`
+}
+
+type ManifestResource struct {
+	Path       string `json:"path"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+type ManifestPodLogs struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Current   string `json:"current,omitempty"`  // path to the current logs
+	Previous  string `json:"previous,omitempty"` // path to the previous logs
+}
+
+type ManifestGatewayInsights struct {
+	Gateway string `json:"gateway"`
+	Command string `json:"command"`
+	Stdout  string `json:"stdout,omitempty"` // path to the stdout
+	Stderr  string `json:"stderr,omitempty"` // path to the stderr
+	Error   string `json:"error,omitempty"`  // path to the error
+}
+
+type ManifestDiagnostics struct {
+	Target  string `json:"target"`
+	Command string `json:"command"`
+	Stdout  string `json:"stdout,omitempty"` // path to the stdout
+	Stderr  string `json:"stderr,omitempty"` // path to the stderr
+	Error   string `json:"error,omitempty"`  // path to the error
+}
+
+// ArchiveWriter streams collected data into the tar.zst archive entry by entry, so only index is kept in memory
+type ArchiveWriter struct {
+	zw       *zstd.Encoder
+	tw       *tar.Writer
+	modTime  time.Time
+	manifest Manifest
+	paths    map[string]bool
+}
+
+var _ dumpSink = (*ArchiveWriter)(nil)
+
+func NewArchiveWriter(w io.Writer, modTime time.Time) (*ArchiveWriter, error) {
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return nil, fmt.Errorf("creating zstd writer: %w", err)
+	}
+
+	return &ArchiveWriter{
+		zw:      zw,
+		tw:      tar.NewWriter(zw),
+		modTime: modTime,
+		paths:   map[string]bool{},
+	}, nil
+}
+
+func (a *ArchiveWriter) addFile(name string, data []byte) error {
+	if a.paths[name] {
+		return fmt.Errorf("duplicate archive entry %q", name) //nolint:goerr113
+	}
+	a.paths[name] = true
+
+	if err := a.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     int64(len(data)),
+		Mode:     archiveEntryMode,
+		ModTime:  a.modTime,
+	}); err != nil {
+		return fmt.Errorf("writing header for %q: %w", name, err)
+	}
+
+	if _, err := a.tw.Write(data); err != nil {
+		return fmt.Errorf("writing %q: %w", name, err)
+	}
+
+	return nil
+}
+
+func (a *ArchiveWriter) addResource(gvk schema.GroupVersionKind, obj kclient.Object, data []byte) error {
+	kind := gvk.Kind + "." + gvk.Version
+	if gvk.Group != "" {
+		kind += "." + gvk.Group
+	}
+
+	ns := obj.GetNamespace()
+	if ns == "" {
+		ns = archiveClusterScoped
+	}
+
+	name := path.Join(archiveResourcesDir, kind, ns, obj.GetName()+".yaml")
+	if err := a.addFile(name, data); err != nil {
+		return err
+	}
+
+	apiVersion, _ := gvk.ToAPIVersionAndKind()
+	a.manifest.Resources = append(a.manifest.Resources, ManifestResource{
+		Path:       name,
+		APIVersion: apiVersion,
+		Kind:       gvk.Kind,
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	})
+
+	return nil
+}
+
+func (a *ArchiveWriter) addPodLogs(ns, pod, container string, logs ContainerLogs) error {
+	entry := ManifestPodLogs{
+		Namespace: ns,
+		Pod:       pod,
+		Container: container,
+	}
+
+	base := path.Join(archiveLogsDir, ns, pod, container)
+	if logs.Current != "" {
+		entry.Current = base + archiveLogExt
+		if err := a.addFile(entry.Current, []byte(logs.Current)); err != nil {
+			return err
+		}
+	}
+	if logs.Previous != "" {
+		entry.Previous = base + archivePreviousLogExt
+		if err := a.addFile(entry.Previous, []byte(logs.Previous)); err != nil {
+			return err
+		}
+	}
+
+	a.manifest.PodLogs = append(a.manifest.PodLogs, entry)
+
+	return nil
+}
+
+func (a *ArchiveWriter) addGatewayInsights(gw, cmd string, out ExecOutputs) error {
+	entry := ManifestGatewayInsights{
+		Gateway: gw,
+		Command: cmd,
+	}
+
+	var err error
+	if entry.Stdout, entry.Stderr, entry.Error, err = a.addExecOutputs(path.Join(archiveGatewaysDir, gw), cmd, out); err != nil {
+		return err
+	}
+
+	a.manifest.GatewayInsights = append(a.manifest.GatewayInsights, entry)
+
+	return nil
+}
+
+func (a *ArchiveWriter) addDiagnostics(target, cmd string, out ExecOutputs) error {
+	entry := ManifestDiagnostics{
+		Target:  target,
+		Command: cmd,
+	}
+
+	var err error
+	if entry.Stdout, entry.Stderr, entry.Error, err = a.addExecOutputs(path.Join(archiveDiagnosticsDir, target), cmd, out); err != nil {
+		return err
+	}
+
+	a.manifest.Diagnostics = append(a.manifest.Diagnostics, entry)
+
+	return nil
+}
+
+// addExecOutputs writes non-empty outputs into the dir using the command slug as a name and returns their paths
+func (a *ArchiveWriter) addExecOutputs(dir, cmd string, out ExecOutputs) (string, string, string, error) {
+	slug := strings.Trim(archiveSlugRegex.ReplaceAllString(strings.ToLower(cmd), "-"), "-")
+	base := path.Join(dir, slug)
+	for idx := 1; a.paths[base+".stdout"] || a.paths[base+".stderr"] || a.paths[base+".error"]; idx++ {
+		base = path.Join(dir, fmt.Sprintf("%s-%d", slug, idx))
+	}
+
+	paths := [3]string{}
+	for idx, f := range []struct {
+		ext  string
+		data string
+	}{
+		{".stdout", out.Stdout},
+		{".stderr", out.Stderr},
+		{".error", out.Error},
+	} {
+		if f.data == "" {
+			continue
+		}
+
+		paths[idx] = base + f.ext
+		if err := a.addFile(paths[idx], []byte(f.data)); err != nil {
+			return "", "", "", err
+		}
+	}
+
+	return paths[0], paths[1], paths[2], nil
+}
+
+// Close writes the manifest and flushes the archive, it doesn't close the underlying writer
+func (a *ArchiveWriter) Close(name string, createdBy DumpCreator, createdAt kmetav1.Time) error {
+	a.manifest.Version = CurrentVersion.String()
+	a.manifest.Name = name
+	a.manifest.CreatedBy = createdBy
+	a.manifest.CreatedAt = createdAt
+
+	data, err := kyaml.Marshal(a.manifest)
+	if err != nil {
+		return fmt.Errorf("marshalling manifest: %w", err)
+	}
+
+	if err := a.addFile(archiveManifest, data); err != nil {
+		return err
+	}
+
+	if err := a.tw.Close(); err != nil {
+		return fmt.Errorf("closing tar writer: %w", err)
+	}
+
+	if err := a.zw.Close(); err != nil {
+		return fmt.Errorf("closing zstd writer: %w", err)
+	}
+
+	return nil
+}
+
+func isArchive(data []byte) bool {
+	return bytes.HasPrefix(data, archiveMagic)
+}
+
+func unmarshalArchive(r io.Reader, d *Dump) error {
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("creating zstd reader: %w", err)
+	}
+	defer zr.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading archive: %w", err)
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if hdr.Size > archiveMaxEntrySize {
+			return fmt.Errorf("archive entry %q is too large", hdr.Name) //nolint:goerr113
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("reading %q: %w", hdr.Name, err)
+		}
+
+		files[path.Clean(hdr.Name)] = data
+	}
+
+	manifestData, ok := files[archiveManifest]
+	if !ok {
+		return fmt.Errorf("archive manifest %q not found", archiveManifest) //nolint:goerr113
+	}
+
+	dv := &DumpVersion{}
+	if err := kyaml.Unmarshal(manifestData, dv); err != nil {
+		return fmt.Errorf("unmarshalling manifest version: %w", err)
+	}
+
+	version, err := parseDumpVersion(dv)
+	if err != nil {
+		return err
+	}
+
+	manifest := &Manifest{}
+	if err := kyaml.UnmarshalStrict(manifestData, manifest); err != nil {
+		return fmt.Errorf("unmarshalling manifest: %w", err)
+	}
+
+	file := func(name string) (string, error) {
+		if name == "" {
+			return "", nil
+		}
+
+		data, ok := files[path.Clean(name)]
+		if !ok {
+			return "", fmt.Errorf("archive entry %q not found", name) //nolint:goerr113
+		}
+
+		return string(data), nil
+	}
+
+	resources := &strings.Builder{}
+	for idx, res := range manifest.Resources {
+		data, err := file(res.Path)
+		if err != nil {
+			return err
+		}
+
+		if idx > 0 {
+			resources.WriteString("---\n")
+		}
+		resources.WriteString(data)
+	}
+
+	podLogs := map[string]map[string]PodLogs{}
+	for _, entry := range manifest.PodLogs {
+		current, err := file(entry.Current)
+		if err != nil {
+			return err
+		}
+		previous, err := file(entry.Previous)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := podLogs[entry.Namespace]; !ok {
+			podLogs[entry.Namespace] = map[string]PodLogs{}
+		}
+		if _, ok := podLogs[entry.Namespace][entry.Pod]; !ok {
+			podLogs[entry.Namespace][entry.Pod] = PodLogs{}
+		}
+
+		podLogs[entry.Namespace][entry.Pod][entry.Container] = ContainerLogs{Current: current, Previous: previous}
+	}
+
+	insights := map[string]map[string]ExecOutputs{}
+	for _, entry := range manifest.GatewayInsights {
+		out, err := readExecOutputs(file, entry.Stdout, entry.Stderr, entry.Error)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := insights[entry.Gateway]; !ok {
+			insights[entry.Gateway] = map[string]ExecOutputs{}
+		}
+
+		insights[entry.Gateway][entry.Command] = out
+	}
+
+	var diagnostics map[string]map[string]ExecOutputs
+	for _, entry := range manifest.Diagnostics {
+		out, err := readExecOutputs(file, entry.Stdout, entry.Stderr, entry.Error)
+		if err != nil {
+			return err
+		}
+
+		if diagnostics == nil {
+			diagnostics = map[string]map[string]ExecOutputs{}
+		}
+		if _, ok := diagnostics[entry.Target]; !ok {
+			diagnostics[entry.Target] = map[string]ExecOutputs{}
+		}
+
+		diagnostics[entry.Target][entry.Command] = out
+	}
+
+	*d = Dump{
+		DumpVersion: DumpVersion{
+			Version:       manifest.Version,
+			parsedVersion: version,
+		},
+		Name:            manifest.Name,
+		CreatedBy:       manifest.CreatedBy,
+		CreatedAt:       manifest.CreatedAt,
+		Resources:       resources.String(),
+		PodLogs:         podLogs,
+		GatewayInsights: insights,
+		Diagnostics:     diagnostics,
+	}
+
+	return nil
+}
+
+func readExecOutputs(file func(string) (string, error), stdout, stderr, errPath string) (ExecOutputs, error) {
+	out := ExecOutputs{}
+
+	var err error
+	if out.Stdout, err = file(stdout); err != nil {
+		return out, err
+	}
+	if out.Stderr, err = file(stderr); err != nil {
+		return out, err
+	}
+	if out.Error, err = file(errPath); err != nil {
+		return out, err
+	}
+
+	return out, nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package support
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	createdAt := kmetav1.NewTime(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	expected := &Dump{
+		DumpVersion: DumpVersion{Version: CurrentVersion.String()},
+		Name:        "test",
+		CreatedBy:   DumpCreator{Hostname: "host", CtlVersion: "v0.0.0"},
+		CreatedAt:   createdAt,
+	}
+
+	buf := &bytes.Buffer{}
+	archive, err := NewArchiveWriter(buf, createdAt.Time)
+	require.NoError(t, err)
+
+	for _, sink := range []dumpSink{expected, archive} {
+		for _, name := range []string{"ns-1", "ns-2"} {
+			obj := &corev1.Namespace{ObjectMeta: kmetav1.ObjectMeta{Name: name}}
+			require.NoError(t, sink.addResource(corev1.SchemeGroupVersion.WithKind("Namespace"), obj,
+				[]byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: "+name+"\n")))
+		}
+		require.NoError(t, sink.addPodLogs("fab", "pod-1", "manager", ContainerLogs{Current: "current\n", Previous: "previous\n"}))
+		require.NoError(t, sink.addPodLogs("fab", "pod-1", "sidecar", ContainerLogs{Current: "sidecar\n"}))
+		require.NoError(t, sink.addGatewayInsights("gw-1", "frr: show bgp summary", ExecOutputs{Stdout: "summary"}))
+		require.NoError(t, sink.addGatewayInsights("gw-1", "frr: show-bgp summary", ExecOutputs{Error: "failed"}))
+		require.NoError(t, sink.addDiagnostics(DiagnosticsSwitchPrefix+"leaf-01", "show version", ExecOutputs{Stdout: "version", Stderr: "warn"}))
+	}
+
+	require.NoError(t, archive.Close(expected.Name, expected.CreatedBy, expected.CreatedAt))
+
+	actual := &Dump{}
+	require.NoError(t, Unmarshal(buf.Bytes(), actual))
+	require.NotNil(t, actual.parsedVersion)
+	actual.parsedVersion = nil
+	require.True(t, expected.CreatedAt.Equal(&actual.CreatedAt))
+	actual.CreatedAt = expected.CreatedAt
+
+	require.Equal(t, expected, actual)
+
+	legacy, err := Marshal(expected)
+	require.NoError(t, err)
+
+	actual = &Dump{}
+	require.NoError(t, Unmarshal(legacy, actual))
+	require.Equal(t, LegacyVersion.String(), actual.Version)
+	require.Equal(t, expected.Resources, actual.Resources)
+	require.Equal(t, expected.PodLogs, actual.PodLogs)
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/user"
@@ -14,7 +15,9 @@ import (
 
 	"go.githedgehog.com/fabricator/pkg/version"
 	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/wait"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -28,15 +31,77 @@ var longBackoff = wait.Backoff{
 	Jitter:   0,
 }
 
+// dumpSink receives collected data, it's implemented by the in-memory Dump and the streaming ArchiveWriter
+type dumpSink interface {
+	addResource(gvk schema.GroupVersionKind, obj kclient.Object, data []byte) error
+	addPodLogs(ns, pod, container string, logs ContainerLogs) error
+	addGatewayInsights(gw, cmd string, out ExecOutputs) error
+	addDiagnostics(target, cmd string, out ExecOutputs) error
+}
+
+type CollectOpts struct {
+	KubeconfigPath string
+	Quiet          bool
+	Diagnostics    DiagnosticsOpts
+}
+
 type collector struct {
 	kubeconfigPath string
 	quiet          bool
+	diagnostics    DiagnosticsOpts
+	sink           dumpSink
 }
 
-func Collect(ctx context.Context, name, kubeconfigPath string, quiet bool) (*Dump, error) {
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
-	defer cancel()
+// Collect collects support dump into memory, use CollectArchive for large fabrics
+func Collect(ctx context.Context, name string, opts CollectOpts) (*Dump, error) {
+	dump := &Dump{
+		DumpVersion: DumpVersion{
+			Version:       CurrentVersion.String(),
+			parsedVersion: CurrentVersion,
+		},
+		Name:      name,
+		CreatedBy: newDumpCreator(),
+		CreatedAt: kmetav1.Now(),
+	}
+
+	if err := collect(ctx, collector{
+		kubeconfigPath: opts.KubeconfigPath,
+		quiet:          opts.Quiet,
+		diagnostics:    opts.Diagnostics,
+		sink:           dump,
+	}); err != nil {
+		return nil, err
+	}
+
+	return dump, nil
+}
 
+// CollectArchive collects support dump streaming it into the tar.zst archive while collecting
+func CollectArchive(ctx context.Context, w io.Writer, name string, opts CollectOpts) error {
+	createdAt := kmetav1.Now()
+
+	archive, err := NewArchiveWriter(w, createdAt.Time)
+	if err != nil {
+		return err
+	}
+
+	if err := collect(ctx, collector{
+		kubeconfigPath: opts.KubeconfigPath,
+		quiet:          opts.Quiet,
+		diagnostics:    opts.Diagnostics,
+		sink:           archive,
+	}); err != nil {
+		return err
+	}
+
+	if err := archive.Close(name, newDumpCreator(), createdAt); err != nil {
+		return fmt.Errorf("closing archive: %w", err)
+	}
+
+	return nil
+}
+
+func newDumpCreator() DumpCreator {
 	hostname, err := os.Hostname()
 	if err != nil {
 		slog.Warn("Can't get hostname, skipping", "err", err)
@@ -57,37 +122,44 @@ func Collect(ctx context.Context, name, kubeconfigPath string, quiet bool) (*Dum
 		slog.Warn("Can't read /etc/os-release, skipping", "err", err)
 	}
 
-	dump := &Dump{
-		DumpVersion: DumpVersion{
-			Version:       CurrentVersion.String(),
-			parsedVersion: CurrentVersion,
-		},
-		Name: name,
-		CreatedBy: DumpCreator{
-			Hostname:   hostname,
-			Username:   username,
-			OSRelease:  string(osRelease),
-			CtlVersion: version.Version,
-		},
-		CreatedAt: kmetav1.Now(),
+	return DumpCreator{
+		Hostname:   hostname,
+		Username:   username,
+		OSRelease:  string(osRelease),
+		CtlVersion: version.Version,
 	}
+}
+
+func collect(ctx context.Context, c collector) error {
+	c.quiet = c.quiet || os.Getenv("GITHUB_ACTIONS") == githubActionsValue
 
-	c := collector{
-		kubeconfigPath: kubeconfigPath,
-		quiet:          quiet || os.Getenv("GITHUB_ACTIONS") == githubActionsValue,
+	if err := c.collectKube(ctx); err != nil {
+		return err
 	}
 
-	if err := c.collectKubeResources(ctx, dump); err != nil {
-		return nil, fmt.Errorf("collecting kube resources: %w", err)
+	// diagnostics are bounded by the per-target timeout instead
+	if err := c.collectDiagnostics(ctx); err != nil {
+		return fmt.Errorf("collecting diagnostics: %w", err)
 	}
 
-	if err := c.collectPodLogs(ctx, dump); err != nil {
-		return nil, fmt.Errorf("collecting pod logs: %w", err)
+	return nil
+}
+
+func (c collector) collectKube(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	if err := c.collectKubeResources(ctx); err != nil {
+		return fmt.Errorf("collecting kube resources: %w", err)
 	}
 
-	if err := c.collectGatewayInsights(ctx, dump); err != nil {
-		return nil, fmt.Errorf("collecting gateway insights: %w", err)
+	if err := c.collectPodLogs(ctx); err != nil {
+		return fmt.Errorf("collecting pod logs: %w", err)
 	}
 
-	return dump, nil
+	if err := c.collectGatewayInsights(ctx); err != nil {
+		return fmt.Errorf("collecting gateway insights: %w", err)
+	}
+
+	return nil
 }
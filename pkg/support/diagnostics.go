@@ -0,0 +1,341 @@
+// Copyright 2026 Hedgehog
+// SPDX-License-Identifier: Apache-2.0
+
+package support
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	wiringapi "go.githedgehog.com/fabric/api/wiring/v1beta1"
+	"go.githedgehog.com/fabric/pkg/util/kubeutil"
+	fabapi "go.githedgehog.com/fabricator/api/fabricator/v1beta1"
+	"go.githedgehog.com/fabricator/pkg/util/sshutil"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	DiagnosticsSwitchPrefix = "switch/"
+	DiagnosticsHostPrefix   = "host/"
+
+	DefaultDiagnosticsConcurrency     = 8
+	DefaultDiagnosticsTargetTimeout   = 5 * time.Minute
+	DefaultDiagnosticsShowTechTimeout = 15 * time.Minute
+	DefaultDiagnosticsSwitchUser      = "admin"
+	DefaultDiagnosticsHostUser        = "core"
+)
+
+type DiagnosticsOpts struct {
+	Switches        bool          // collect diagnostics from switches over SSH
+	Hosts           bool          // collect diagnostics from control and gateway nodes over SSH
+	SSHKeyPath      string        // SSH private key to access switches and hosts
+	SwitchUser      string        // defaults to DefaultDiagnosticsSwitchUser
+	SwitchPassword  string        // used if set instead of the SSH key for switches
+	HostUser        string        // defaults to DefaultDiagnosticsHostUser
+	Concurrency     int           // max number of targets collected in parallel
+	TargetTimeout   time.Duration // timeout for collecting all commands from a single target
+	ShowTech        bool          // collect NOS show tech-support from switches, it's slow so it's opt-in
+	ShowTechTimeout time.Duration // timeout for show tech-support on a single switch, separate from TargetTimeout
+}
+
+type diagnosticsCommand struct {
+	name string
+	cmd  string
+}
+
+func sonicCLI(cmd string) diagnosticsCommand {
+	return diagnosticsCommand{name: cmd, cmd: fmt.Sprintf("sonic-cli -c '%s | no-more'", cmd)}
+}
+
+var switchDiagnosticsCommands = []diagnosticsCommand{
+	sonicCLI("show version"),
+	sonicCLI("show running-configuration"),
+	sonicCLI("show system status brief"),
+	sonicCLI("show interface status"),
+	sonicCLI("show lldp table"),
+	sonicCLI("show port-channel summary"),
+	sonicCLI("show mclag brief"),
+	sonicCLI("show ip bgp summary"),
+	sonicCLI("show bgp l2vpn evpn summary"),
+	sonicCLI("show bfd peers"),
+	sonicCLI("show ip route vrf all"),
+	sonicCLI("show platform environment"),
+	{name: "agent status", cmd: "systemctl status hedgehog-agent --no-pager"},
+	{name: "agent logs", cmd: "sudo tail -n 5000 /var/log/agent.log"},
+	{name: "syslog", cmd: "sudo tail -n 5000 /var/log/syslog"},
+}
+
+// switchShowTechCommand makes NOS generate the tech-support archive on the switch, output includes the archive path
+var switchShowTechCommand = sonicCLI("show tech-support")
+
+func hostDiagnosticsCommands(k3sUnit string) []diagnosticsCommand {
+	return []diagnosticsCommand{
+		{name: "os-release", cmd: "cat /etc/os-release"},
+		{name: "uptime", cmd: "uptime"},
+		{name: "disk", cmd: "df -h"},
+		{name: "memory", cmd: "free -h"},
+		{name: "k3s journal", cmd: fmt.Sprintf("sudo journalctl -u %s --no-pager --since '1 hour ago'", k3sUnit)},
+		{name: "containerd log", cmd: "sudo tail -n 5000 /var/lib/rancher/k3s/agent/containerd/containerd.log"},
+		{name: "networkd journal", cmd: "sudo journalctl -u systemd-networkd --no-pager --since '1 hour ago'"},
+		{name: "dmesg", cmd: "sudo dmesg -T | tail -n 5000"},
+		{name: "nftables", cmd: "sudo nft list ruleset"},
+	}
+}
+
+type diagnosticsTarget struct {
+	name     string // e.g. switch/leaf-01
+	ssh      *sshutil.Config
+	commands []diagnosticsCommand
+	showTech *diagnosticsCommand // collected after the commands with its own timeout
+}
+
+func (c collector) collectDiagnostics(ctx context.Context) error {
+	opts := c.diagnostics
+	if !opts.Switches && !opts.Hosts {
+		return nil
+	}
+
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultDiagnosticsConcurrency
+	}
+	if opts.TargetTimeout <= 0 {
+		opts.TargetTimeout = DefaultDiagnosticsTargetTimeout
+	}
+	if opts.ShowTechTimeout <= 0 {
+		opts.ShowTechTimeout = DefaultDiagnosticsShowTechTimeout
+	}
+	if opts.SwitchUser == "" {
+		opts.SwitchUser = DefaultDiagnosticsSwitchUser
+	}
+	if opts.HostUser == "" {
+		opts.HostUser = DefaultDiagnosticsHostUser
+	}
+
+	targets, err := c.diagnosticsTargets(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Collecting diagnostics", "targets", len(targets), "concurrency", opts.Concurrency, "timeout", opts.TargetTimeout)
+
+	mu := sync.Mutex{}
+	g := &errgroup.Group{}
+	g.SetLimit(opts.Concurrency)
+
+	for _, target := range targets {
+		g.Go(func() error {
+			outputs := c.collectTargetDiagnostics(ctx, target, opts.TargetTimeout)
+
+			var showTech ExecOutputs
+			if target.showTech != nil {
+				showTech = c.collectTargetShowTech(ctx, target, opts.ShowTechTimeout)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			for _, cmd := range target.commands {
+				if err := c.sink.addDiagnostics(target.name, cmd.name, outputs[cmd.name]); err != nil {
+					return fmt.Errorf("adding %s diagnostics: %w", target.name, err)
+				}
+			}
+			if target.showTech != nil {
+				if err := c.sink.addDiagnostics(target.name, target.showTech.name, showTech); err != nil {
+					return fmt.Errorf("adding %s show tech: %w", target.name, err)
+				}
+			}
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return fmt.Errorf("collecting diagnostics: %w", err)
+	}
+
+	return nil
+}
+
+func (c collector) diagnosticsTargets(ctx context.Context, opts DiagnosticsOpts) ([]diagnosticsTarget, error) {
+	kube, err := kubeutil.NewClient(ctx, c.kubeconfigPath, wiringapi.AddToScheme, fabapi.AddToScheme)
+	if err != nil {
+		return nil, fmt.Errorf("creating kube client: %w", err)
+	}
+
+	targets := []diagnosticsTarget{}
+
+	if opts.Switches {
+		switches := &wiringapi.SwitchList{}
+		if err := retry.OnError(longBackoff, func(err error) bool { return true }, func() error {
+			return kube.List(ctx, switches) //nolint:wrapcheck
+		}); err != nil {
+			return nil, fmt.Errorf("listing switches: %w", err)
+		}
+
+		for _, sw := range switches.Items {
+			ssh, err := newDiagnosticsSSH(sw.Spec.IP, opts.SwitchUser, opts.SSHKeyPath, opts.SwitchPassword)
+			if err != nil {
+				slog.Warn("Skipping switch diagnostics", "switch", sw.Name, "err", err)
+
+				continue
+			}
+
+			target := diagnosticsTarget{
+				name:     DiagnosticsSwitchPrefix + sw.Name,
+				ssh:      ssh,
+				commands: switchDiagnosticsCommands,
+			}
+			if opts.ShowTech {
+				target.showTech = &switchShowTechCommand
+			}
+
+			targets = append(targets, target)
+		}
+	}
+
+	if opts.Hosts {
+		controls := &fabapi.ControlNodeList{}
+		if err := retry.OnError(longBackoff, func(err error) bool { return true }, func() error {
+			return kube.List(ctx, controls) //nolint:wrapcheck
+		}); err != nil {
+			return nil, fmt.Errorf("listing control nodes: %w", err)
+		}
+
+		for _, node := range controls.Items {
+			ssh, err := newDiagnosticsSSH(string(node.Spec.Management.IP), opts.HostUser, opts.SSHKeyPath, "")
+			if err != nil {
+				slog.Warn("Skipping control node diagnostics", "node", node.Name, "err", err)
+
+				continue
+			}
+
+			targets = append(targets, diagnosticsTarget{
+				name:     DiagnosticsHostPrefix + node.Name,
+				ssh:      ssh,
+				commands: hostDiagnosticsCommands("k3s"),
+			})
+		}
+
+		nodes := &fabapi.FabNodeList{}
+		if err := retry.OnError(longBackoff, func(err error) bool { return true }, func() error {
+			return kube.List(ctx, nodes) //nolint:wrapcheck
+		}); err != nil {
+			return nil, fmt.Errorf("listing nodes: %w", err)
+		}
+
+		for _, node := range nodes.Items {
+			ssh, err := newDiagnosticsSSH(string(node.Spec.Management.IP), opts.HostUser, opts.SSHKeyPath, "")
+			if err != nil {
+				slog.Warn("Skipping node diagnostics", "node", node.Name, "err", err)
+
+				continue
+			}
+
+			targets = append(targets, diagnosticsTarget{
+				name:     DiagnosticsHostPrefix + node.Name,
+				ssh:      ssh,
+				commands: hostDiagnosticsCommands("k3s-agent"),
+			})
+		}
+	}
+
+	slices.SortFunc(targets, func(a, b diagnosticsTarget) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return targets, nil
+}
+
+func newDiagnosticsSSH(ip, user, keyPath, password string) (*sshutil.Config, error) {
+	if ip == "" {
+		return nil, errors.New("no IP") //nolint:goerr113
+	}
+
+	prefix, err := netip.ParsePrefix(ip)
+	if err != nil {
+		return nil, fmt.Errorf("parsing IP %q: %w", ip, err)
+	}
+
+	if keyPath == "" && password == "" {
+		return nil, errors.New("no SSH key or password") //nolint:goerr113
+	}
+
+	return &sshutil.Config{
+		Remote: sshutil.Remote{
+			User: user,
+			Host: prefix.Addr().String(),
+			Port: 22,
+		},
+		SSHKeyPath: keyPath,
+		Password:   password,
+		SSHTimeout: 30 * time.Second,
+	}, nil
+}
+
+// collectTargetDiagnostics runs all commands on the target sequentially, it's stopped on the target timeout or if
+// target is unreachable and remaining commands are reported as skipped
+func (c collector) collectTargetDiagnostics(ctx context.Context, target diagnosticsTarget, timeout time.Duration) map[string]ExecOutputs {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if !c.quiet {
+		slog.Debug("Collecting diagnostics", "target", target.name)
+	}
+
+	res := map[string]ExecOutputs{}
+	failed := 0
+	var skipErr error
+	for _, cmd := range target.commands {
+		if skipErr == nil {
+			skipErr = ctx.Err()
+		}
+		if skipErr != nil {
+			res[cmd.name] = ExecOutputs{Error: fmt.Sprintf("skipped: %s", skipErr)}
+			failed++
+
+			continue
+		}
+
+		stdout, stderr, err := target.ssh.Run(ctx, cmd.cmd)
+		out := ExecOutputs{Stdout: stdout, Stderr: stderr}
+		if err != nil {
+			out.Error = err.Error()
+			failed++
+
+			if _, ok := sshutil.ExitStatus(err); !ok {
+				skipErr = err
+			}
+		}
+
+		res[cmd.name] = out
+	}
+
+	if failed > 0 {
+		slog.Warn("Some diagnostics collection failed", "target", target.name, "failed", failed, "total", len(target.commands))
+	}
+
+	return res
+}
+
+// collectTargetShowTech runs show tech on the target with its own timeout so the slow archive generation doesn't eat
+// into the time for the regular commands
+func (c collector) collectTargetShowTech(ctx context.Context, target diagnosticsTarget, timeout time.Duration) ExecOutputs {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if !c.quiet {
+		slog.Debug("Collecting show tech", "target", target.name, "timeout", timeout)
+	}
+
+	return collectShowTech(ctx, target.name, func(ctx context.Context) (string, string, error) {
+		return target.ssh.Run(ctx, target.showTech.cmd) //nolint:wrapcheck
+	})
+}
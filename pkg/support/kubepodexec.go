@@ -22,9 +22,7 @@ import (
 	"k8s.io/client-go/util/retry"
 )
 
-func (c collector) collectGatewayInsights(ctx context.Context, dump *Dump) error {
-	insights := map[string]map[string]ExecOutputs{}
-
+func (c collector) collectGatewayInsights(ctx context.Context) error {
 	cfg, err := kubeutil.NewClientConfig(ctx, c.kubeconfigPath)
 	if err != nil {
 		return fmt.Errorf("creating kube config: %w", err)
@@ -61,11 +59,6 @@ func (c collector) collectGatewayInsights(ctx context.Context, dump *Dump) error
 			continue
 		}
 
-		gw, ok := insights[gwName]
-		if !ok {
-			gw = map[string]ExecOutputs{}
-		}
-
 		switch {
 		// Collect dataplane insights
 		case strings.Contains(pod.Name, "--dataplane-"):
@@ -73,22 +66,13 @@ func (c collector) collectGatewayInsights(ctx context.Context, dump *Dump) error
 				slog.Debug("Collecting dataplane insights", "pod", pod.Name)
 			}
 
-			for _, cmd := range []string{
-				"show tech",
-			} {
-				if !c.quiet {
-					slog.Debug("Executing dataplane/cli", "pod", pod.Name, "cmd", cmd)
-				}
-
-				stdout, stderr, err := execPodContainerCommand(ctx, clientset, cfg,
+			out := collectShowTech(ctx, pod.Name, func(ctx context.Context) (string, string, error) {
+				return execPodContainerCommand(ctx, clientset, cfg,
 					pod.Namespace, pod.Name, "dataplane",
-					[]string{"/dataplane-cli", "-c", cmd})
-				errStr := ""
-				if err != nil {
-					slog.Error("Failed to exec dataplane/cli", "pod", pod.Name, "cmd", cmd, "err", err)
-					errStr = err.Error()
-				}
-				gw["dataplane: "+cmd] = ExecOutputs{Stdout: stdout, Stderr: stderr, Error: errStr}
+					[]string{"/dataplane-cli", "-c", "show tech"})
+			})
+			if err := c.sink.addGatewayInsights(gwName, "dataplane: show tech", out); err != nil {
+				return fmt.Errorf("adding gateway %s insights: %w", gwName, err)
 			}
 
 		// Collect FRR insights
@@ -119,18 +103,32 @@ func (c collector) collectGatewayInsights(ctx context.Context, dump *Dump) error
 					slog.Error("Failed to exec frr/vtysh", "pod", pod.Name, "cmd", cmd, "err", err)
 					errStr = err.Error()
 				}
-				gw["frr: "+cmd] = ExecOutputs{Stdout: stdout, Stderr: stderr, Error: errStr}
+				if err := c.sink.addGatewayInsights(gwName, "frr: "+cmd, ExecOutputs{Stdout: stdout, Stderr: stderr, Error: errStr}); err != nil {
+					return fmt.Errorf("adding gateway %s insights: %w", gwName, err)
+				}
 			}
 		}
 
-		insights[gwName] = gw
 	}
 
-	dump.GatewayInsights = insights
-
 	return nil
 }
 
+// collectShowTech runs show tech on the gateway dataplane or switch NOS, failure is recorded in the outputs so the rest
+// of the collection continues
+func collectShowTech(ctx context.Context, target string, run func(ctx context.Context) (string, string, error)) ExecOutputs {
+	slog.Debug("Executing show tech", "target", target)
+
+	stdout, stderr, err := run(ctx)
+	out := ExecOutputs{Stdout: stdout, Stderr: stderr}
+	if err != nil {
+		slog.Error("Failed to collect show tech", "target", target, "err", err)
+		out.Error = err.Error()
+	}
+
+	return out
+}
+
 // gatewayName extracts the gateway name — everything between the first "--" and the last "--" in a pod name like
 // "gw--he-f2-gw-1--dataplane-wcbjv"
 func gatewayName(s string) (string, bool) {
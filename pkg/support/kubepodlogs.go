@@ -21,9 +21,7 @@ import (
 	"k8s.io/client-go/util/retry"
 )
 
-func (c collector) collectPodLogs(ctx context.Context, dump *Dump) error {
-	logs := map[string]map[string]PodLogs{}
-
+func (c collector) collectPodLogs(ctx context.Context) error {
 	clientset, err := kubeutil.NewClientset(ctx, c.kubeconfigPath)
 	if err != nil {
 		return fmt.Errorf("creating kubernetes client: %w", err)
@@ -62,22 +60,15 @@ func (c collector) collectPodLogs(ctx context.Context, dump *Dump) error {
 				continue
 			}
 
-			if _, ok := logs[pod.Namespace]; !ok {
-				logs[pod.Namespace] = map[string]PodLogs{}
-			}
-			if _, ok := logs[pod.Namespace][pod.Name]; !ok {
-				logs[pod.Namespace][pod.Name] = PodLogs{}
-			}
-
-			logs[pod.Namespace][pod.Name][container] = ContainerLogs{
+			if err := c.sink.addPodLogs(pod.Namespace, pod.Name, container, ContainerLogs{
 				Current:  string(current),
 				Previous: string(previous),
+			}); err != nil {
+				return fmt.Errorf("adding pod %s/%s container %s logs: %w", pod.Namespace, pod.Name, container, err)
 			}
 		}
 	}
 
-	dump.PodLogs = logs
-
 	return nil
 }
 
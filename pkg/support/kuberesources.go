@@ -4,10 +4,8 @@
 package support
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"log/slog"
 	"reflect"
 	"strings"
@@ -33,6 +31,7 @@ import (
 	kmeta "k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/util/retry"
 	metricsapi "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 	kclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -68,6 +67,8 @@ var kubeResourceGVKs = []schema.GroupVersionKind{
 	appsv1.SchemeGroupVersion.WithKind(""),
 	apiextv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"),
 	metricsapi.SchemeGroupVersion.WithKind(""),
+	helmapi.SchemeGroupVersion.WithKind("HelmChart"),
+	cmapi.SchemeGroupVersion.WithKind(cmapi.CertificateKind),
 	fabapi.GroupVersion.WithKind(""),
 	wiringapi.GroupVersion.WithKind(""),
 	vpcapi.GroupVersion.WithKind(""),
@@ -96,6 +97,16 @@ var kubeResourceRedactors = map[schema.GroupVersionKind]kubeResourceRedactorFunc
 			}
 		}
 	},
+	helmapi.SchemeGroupVersion.WithKind("HelmChart"): func(obj kclient.Object) {
+		chart := obj.(*helmapi.HelmChart)
+
+		if chart.Spec.ValuesContent != "" {
+			chart.Spec.ValuesContent = RedactedValue
+		}
+		for k := range chart.Spec.Set {
+			chart.Spec.Set[k] = intstr.FromString(RedactedValue)
+		}
+	},
 	fabapi.GroupVersion.WithKind(fabapi.KindFabricator): func(obj kclient.Object) {
 		fab := obj.(*fabapi.Fabricator)
 
@@ -122,6 +133,10 @@ var kubeResourceRedactors = map[schema.GroupVersionKind]kubeResourceRedactorFunc
 		if fab.Spec.Config.Control.JoinToken != "" {
 			fab.Spec.Config.Control.JoinToken = RedactedValue
 		}
+
+		if fab.Spec.Config.Control.FabCA != nil && fab.Spec.Config.Control.FabCA.Key != "" {
+			fab.Spec.Config.Control.FabCA.Key = RedactedValue
+		}
 	},
 	agentapi.GroupVersion.WithKind(agentapi.KindAgent): func(obj kclient.Object) {
 		agent := obj.(*agentapi.Agent)
@@ -162,29 +177,24 @@ func redactAlloyTarget(target *alloy.Target) {
 	}
 }
 
-func (c collector) collectKubeResources(ctx context.Context, dump *Dump) error {
+func (c collector) collectKubeResources(ctx context.Context) error {
 	kube, err := kubeutil.NewClient(ctx, c.kubeconfigPath, schemeBuilders...)
 	if err != nil {
 		return fmt.Errorf("creating kube client: %w", err)
 	}
 
-	resources := &bytes.Buffer{}
 	if err := c.collectKubeObjects(ctx, kube, kube.Scheme(),
-		kubeResourceGVKs, kubeResourceRedactors, resources); err != nil {
+		kubeResourceGVKs, kubeResourceRedactors); err != nil {
 		return fmt.Errorf("collecting kube objects: %w", err)
 	}
 
-	dump.Resources = resources.String()
-
 	return nil
 }
 
 func (c collector) collectKubeObjects(ctx context.Context, kube kclient.Reader, scheme *runtime.Scheme,
 	withGVKs []schema.GroupVersionKind, redactors map[schema.GroupVersionKind]kubeResourceRedactorFunc,
-	w io.Writer,
 ) error {
 	kubeObjListType := reflect.TypeFor[kclient.ObjectList]()
-	objs := 0
 
 	for gvk, objType := range scheme.AllKnownTypes() {
 		// skip deprecated resources
@@ -276,15 +286,13 @@ func (c collector) collectKubeObjects(ctx context.Context, kube kclient.Reader,
 				}
 			}
 
-			if objs > 0 {
-				if _, err := fmt.Fprintf(w, "---\n"); err != nil {
-					return fmt.Errorf("writing separator: %w", err)
-				}
+			data, err := marshalKubeObject(itemValue)
+			if err != nil {
+				return fmt.Errorf("marshalling item %d of %s: %w", idx, gvk.String(), err)
 			}
-			objs++
 
-			if err := printKubeObject(itemValue, w); err != nil {
-				return fmt.Errorf("printing item %d of %s: %w", idx, gvk.String(), err)
+			if err := c.sink.addResource(gvk, itemValue, data); err != nil {
+				return fmt.Errorf("adding item %d of %s: %w", idx, gvk.String(), err)
 			}
 		}
 	}
@@ -292,18 +300,14 @@ func (c collector) collectKubeObjects(ctx context.Context, kube kclient.Reader,
 	return nil
 }
 
-func printKubeObject(obj kclient.Object, w io.Writer) error {
+func marshalKubeObject(obj kclient.Object) ([]byte, error) {
 	obj.SetManagedFields(nil)
 	delete(obj.GetAnnotations(), "kubectl.kubernetes.io/last-applied-configuration")
 
 	buf, err := kyaml.Marshal(obj)
 	if err != nil {
-		return fmt.Errorf("marshalling: %w", err)
-	}
-	_, err = w.Write(buf)
-	if err != nil {
-		return fmt.Errorf("writing: %w", err)
+		return nil, fmt.Errorf("marshalling: %w", err)
 	}
 
-	return nil
+	return buf, nil
 }
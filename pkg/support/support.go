@@ -4,11 +4,14 @@
 package support
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 
 	"github.com/Masterminds/semver/v3"
 	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 	kyaml "sigs.k8s.io/yaml"
 )
 
@@ -17,8 +20,9 @@ const (
 )
 
 var (
-	CurrentVersion   = mustVersion("0.1.0")
-	SupportedVersion = mustConstraint("~0.1")
+	LegacyVersion    = mustVersion("0.1.0") // single YAML file, see Marshal
+	CurrentVersion   = mustVersion("0.2.0") // tar.zst archive, see ArchiveWriter
+	SupportedVersion = mustConstraint(">=0.1.0, <0.3.0")
 )
 
 var (
@@ -46,6 +50,7 @@ type Dump struct {
 	Resources       string                            `json:"resources,omitempty"`       // Serialized resources
 	PodLogs         map[string]map[string]PodLogs     `json:"podLogs,omitempty"`         // Logs for all running pods: namespace -> pod name -> container logs
 	GatewayInsights map[string]map[string]ExecOutputs `json:"gatewayInsights,omitempty"` // Gateway insights: gateway-name -> exec-cmd -> outputs
+	Diagnostics     map[string]map[string]ExecOutputs `json:"diagnostics,omitempty"`     // Switch and host diagnostics: target (e.g. switch/leaf-01) -> cmd -> outputs
 }
 
 type PodLogs map[string]ContainerLogs // Logs for all containers in the pod: container name -> logs
@@ -61,8 +66,12 @@ type ExecOutputs struct {
 	Error  string `json:"error,omitempty"`
 }
 
+// Marshal serializes the dump into the legacy single YAML file format
 func Marshal(d *Dump) ([]byte, error) {
-	data, err := kyaml.Marshal(d)
+	legacy := *d
+	legacy.Version = LegacyVersion.String()
+
+	data, err := kyaml.Marshal(legacy)
 	if err != nil {
 		return nil, fmt.Errorf("marshalling dump: %w", err)
 	}
@@ -70,30 +79,95 @@ func Marshal(d *Dump) ([]byte, error) {
 	return data, nil
 }
 
+// Unmarshal reads the dump in both the legacy YAML and the archive formats
 func Unmarshal(data []byte, d *Dump) error {
+	if isArchive(data) {
+		return unmarshalArchive(bytes.NewReader(data), d)
+	}
+
 	dv := &DumpVersion{}
 	if err := kyaml.Unmarshal(data, dv); err != nil {
 		return fmt.Errorf("unmarshalling dump version: %w", err)
 	}
 
+	dumpVersion, err := parseDumpVersion(dv)
+	if err != nil {
+		return err
+	}
+
+	if err := kyaml.UnmarshalStrict(data, d); err != nil {
+		return fmt.Errorf("unmarshalling dump: %w", err)
+	}
+
+	d.parsedVersion = dumpVersion
+
+	return nil
+}
+
+func parseDumpVersion(dv *DumpVersion) (*semver.Version, error) {
 	if dv.Version == "" {
-		return ErrVersionMissing
+		return nil, ErrVersionMissing
 	}
 
 	dumpVersion, err := semver.NewVersion(dv.Version)
 	if err != nil {
-		return fmt.Errorf("parsing dump version: %w", err)
+		return nil, fmt.Errorf("parsing dump version: %w", err)
 	}
 
 	if ok, errs := SupportedVersion.Validate(dumpVersion); !ok {
-		return fmt.Errorf("dump version %q is not supported: %w", dv.Version, errors.Join(errs...))
+		return nil, fmt.Errorf("dump version %q is not supported: %w", dv.Version, errors.Join(errs...))
 	}
 
-	if err := kyaml.UnmarshalStrict(data, d); err != nil {
-		return fmt.Errorf("unmarshalling dump: %w", err)
+	return dumpVersion, nil
+}
+
+func (d *Dump) addResource(_ schema.GroupVersionKind, _ kclient.Object, data []byte) error {
+	if d.Resources != "" {
+		d.Resources += "---\n"
 	}
+	d.Resources += string(data)
 
-	d.parsedVersion = dumpVersion
+	return nil
+}
+
+func (d *Dump) addPodLogs(ns, pod, container string, logs ContainerLogs) error {
+	if d.PodLogs == nil {
+		d.PodLogs = map[string]map[string]PodLogs{}
+	}
+	if _, ok := d.PodLogs[ns]; !ok {
+		d.PodLogs[ns] = map[string]PodLogs{}
+	}
+	if _, ok := d.PodLogs[ns][pod]; !ok {
+		d.PodLogs[ns][pod] = PodLogs{}
+	}
+
+	d.PodLogs[ns][pod][container] = logs
+
+	return nil
+}
+
+func (d *Dump) addGatewayInsights(gw, cmd string, out ExecOutputs) error {
+	if d.GatewayInsights == nil {
+		d.GatewayInsights = map[string]map[string]ExecOutputs{}
+	}
+	if _, ok := d.GatewayInsights[gw]; !ok {
+		d.GatewayInsights[gw] = map[string]ExecOutputs{}
+	}
+
+	d.GatewayInsights[gw][cmd] = out
+
+	return nil
+}
+
+func (d *Dump) addDiagnostics(target, cmd string, out ExecOutputs) error {
+	if d.Diagnostics == nil {
+		d.Diagnostics = map[string]map[string]ExecOutputs{}
+	}
+	if _, ok := d.Diagnostics[target]; !ok {
+		d.Diagnostics[target] = map[string]ExecOutputs{}
+	}
+
+	d.Diagnostics[target][cmd] = out
 
 	return nil
 }